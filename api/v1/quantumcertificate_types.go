@@ -28,14 +28,234 @@ type QuantumCertificateSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// Foo is an example field of QuantumCertificate. Edit quantumcertificate_types.go to remove/update
+	// Algorithm is the signature algorithm of the certificate key. It must have a registered
+	// X.509 OID: ML-DSA-44/65/87, an SLH-DSA parameter set such as SLH-DSA-SHA2-128f, or an
+	// ML-DSA + ECDSA composite such as ML-DSA-65-ECDSA-P256-SHA512. With kemKeyPairRef it is
+	// the ML-KEM parameter set of the key pair. Defaults to the profile algorithm, then ML-DSA-87.
 	Algorithm string `json:"algorithm,omitempty"`
-	Domain    string `json:"domain,omitempty"`
-	Days      int    `json:"days,omitempty"`
+	// Domain is the subject common name, unless subject.commonName is set. It is also added
+	// as a DNS name when dnsNames is empty.
+	Domain string `json:"domain,omitempty"`
+	// Days is the validity period in days. Defaults to the profile days, then 365.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	Days int `json:"days,omitempty"`
 	// Optional name of the Secret to store certificate and key. Defaults to resource name.
 	SecretName string `json:"secretName,omitempty"`
+
+	// CryptoProvider selects the implementation used for this resource (liboqs or go).
+	// Defaults to the operator-wide --crypto-provider setting.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=liboqs;go
+	CryptoProvider string `json:"cryptoProvider,omitempty"`
+
+	// PrivateKeyRef is a reference to a QuantumSignatureKeyPair whose key the certificate
+	// binds, instead of a key generated for the certificate. The key pair must be in the
+	// namespace of the certificate. Its algorithm must match spec.algorithm when both are
+	// set. tls.key holds a PKCS#8 copy of the private key.
+	// +kubebuilder:validation:Optional
+	PrivateKeyRef *ObjectReference `json:"privateKeyRef,omitempty"`
+
+	// KEMKeyPairRef is a reference to a QuantumKEMKeyPair whose ML-KEM public key the
+	// certificate certifies, so that encapsulating parties can authenticate it. The key pair
+	// must be in the namespace of the certificate. A KEM key cannot
+	// sign, so issuerRef is required, and the only key usage is key encipherment. Its algorithm
+	// must match spec.algorithm when both are set. tls.key holds a PKCS#8 copy of the private key.
+	// +kubebuilder:validation:Optional
+	KEMKeyPairRef *ObjectReference `json:"kemKeyPairRef,omitempty"`
+
+	// RotationPolicy controls the key on renewal. Never keeps the current key; Always
+	// uses a new key, and with privateKeyRef or kemKeyPairRef regenerates the key of the
+	// referenced key pair. Defaults to Always for generated keys and Never with a key pair reference.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Never;Always
+	RotationPolicy string `json:"rotationPolicy,omitempty"`
+
+	// RenewBeforeDays is how many days before expiry the certificate is renewed.
+	// Defaults to a third of the validity period.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	RenewBeforeDays int `json:"renewBeforeDays,omitempty"`
+
+	// IssuerRef is the QuantumIssuer or QuantumClusterIssuer that signs the certificate.
+	// When unset, the certificate is self-signed.
+	// +kubebuilder:validation:Optional
+	IssuerRef *IssuerReference `json:"issuerRef,omitempty"`
+
+	// ProfileRef is a reference to a QuantumCertificateProfile. Fields set on the certificate
+	// override the profile.
+	// +kubebuilder:validation:Optional
+	ProfileRef *ObjectReference `json:"profileRef,omitempty"`
+
+	// CertificateProfile holds the subject, key usages and basic constraints
+	CertificateProfile `json:",inline"`
+
+	// DNSNames are the DNS subject alternative names. Wildcards such as *.example.com are allowed.
+	// +kubebuilder:validation:Optional
+	DNSNames []string `json:"dnsNames,omitempty"`
+
+	// IPAddresses are the IP address subject alternative names
+	// +kubebuilder:validation:Optional
+	IPAddresses []string `json:"ipAddresses,omitempty"`
+
+	// URIs are the URI subject alternative names, for example SPIFFE IDs
+	// +kubebuilder:validation:Optional
+	URIs []string `json:"uris,omitempty"`
+
+	// EmailAddresses are the email subject alternative names
+	// +kubebuilder:validation:Optional
+	EmailAddresses []string `json:"emailAddresses,omitempty"`
+
+	// Classical also issues a certificate with a classical key for the same subject, names,
+	// usages and validity, for clients without post-quantum support. It is signed by the
+	// classical CA of the issuer, or self-signed, and renewed together with the certificate.
+	// +kubebuilder:validation:Optional
+	Classical *ClassicalCertificate `json:"classical,omitempty"`
+
+	// Keystores adds keystores holding the certificate, its key and CA chain to the Secret,
+	// for applications that cannot read PEM. They are rebuilt whenever the certificate is
+	// renewed or a password changes.
+	// +kubebuilder:validation:Optional
+	Keystores *CertificateKeystores `json:"keystores,omitempty"`
+
+	// PrivateKeyEncryption adds a passphrase-encrypted PKCS#8 key to the Secret as
+	// tls-encrypted.key, or replaces tls.key with it. The classical companion keeps a
+	// plaintext key.
+	// +kubebuilder:validation:Optional
+	PrivateKeyEncryption *PrivateKeyEncryption `json:"privateKeyEncryption,omitempty"`
 }
 
+// CertificateKeystores configures the keystores of a certificate. The Secret keys are
+// named as in cert-manager.
+type CertificateKeystores struct {
+	// PKCS12 adds keystore.p12 with the key and chain, and truststore.p12 with ca.crt
+	// +kubebuilder:validation:Optional
+	PKCS12 *PKCS12Keystore `json:"pkcs12,omitempty"`
+
+	// JKS adds keystore.jks with the key and chain, and truststore.jks with ca.crt
+	// +kubebuilder:validation:Optional
+	JKS *JKSKeystore `json:"jks,omitempty"`
+}
+
+// KeystoreOptions holds the password and alias of a keystore
+type KeystoreOptions struct {
+	// PasswordSecretRef is a reference to the Secret holding the keystore password. The
+	// Secret must be in the namespace of the certificate.
+	// +kubebuilder:validation:Required
+	PasswordSecretRef ObjectReference `json:"passwordSecretRef"`
+
+	// PasswordKey selects the key in PasswordSecretRef data (default: "password")
+	// +kubebuilder:validation:Optional
+	PasswordKey string `json:"passwordKey,omitempty"`
+
+	// Alias is the alias, or friendly name, of the key entry. The trust store entries are
+	// named ca, ca-1 and so on.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9][a-zA-Z0-9 ._-]*$`
+	// +kubebuilder:default=certificate
+	Alias string `json:"alias,omitempty"`
+}
+
+// PKCS12Keystore configures the PKCS#12 keystores of a certificate
+type PKCS12Keystore struct {
+	KeystoreOptions `json:",inline"`
+
+	// Profile selects the encryption. Modern2023 uses PBES2 with AES-256-CBC and SHA-256;
+	// LegacyDES uses 3DES and SHA-1 for older Java and Windows versions.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Modern2023;LegacyDES
+	// +kubebuilder:default=Modern2023
+	Profile string `json:"profile,omitempty"`
+}
+
+// JKSKeystore configures the Java keystores of a certificate
+type JKSKeystore struct {
+	KeystoreOptions `json:",inline"`
+}
+
+// ClassicalCertificate configures the classical companion of a post-quantum certificate
+type ClassicalCertificate struct {
+	// Algorithm of the classical key
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=ECDSA-P256;ECDSA-P384
+	// +kubebuilder:default=ECDSA-P256
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// SecretName is the name of the Secret that stores the classical certificate (tls.crt),
+	// its key (tls.key) and root (ca.crt). Defaults to the post-quantum Secret name with a
+	// -classical suffix.
+	// +kubebuilder:validation:Optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// ClassicalCertificateStatus describes the classical companion of a certificate
+type ClassicalCertificateStatus struct {
+	// CertificateReference points to the Secret holding the classical certificate
+	CertificateReference *ObjectReference `json:"certificateReference,omitempty"`
+
+	// Algorithm is the algorithm of the classical key
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// SerialNumber is the serial number of the classical certificate (hex-encoded)
+	SerialNumber string `json:"serialNumber,omitempty"`
+}
+
+// CertificateProfile is the part of a certificate that can be shared through a
+// QuantumCertificateProfile
+type CertificateProfile struct {
+	// Subject holds the distinguished name fields of the certificate subject
+	// +kubebuilder:validation:Optional
+	Subject *CertificateSubject `json:"subject,omitempty"`
+
+	// Usages are the key usages and extended key usages of the certificate. Defaults to
+	// digital signature and server auth, cert sign and crl sign for a CA, or key
+	// encipherment for an ML-KEM key.
+	// +kubebuilder:validation:Optional
+	Usages []CertificateUsage `json:"usages,omitempty"`
+
+	// IsCA marks the certificate as a certificate authority in its basic constraints.
+	// An issuer only signs CA certificates if its policy sets allowCA.
+	// +kubebuilder:validation:Optional
+	IsCA bool `json:"isCA,omitempty"`
+
+	// MaxPathLen limits the number of intermediate CAs below a CA certificate.
+	// Unset means no limit.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MaxPathLen *int32 `json:"maxPathLen,omitempty"`
+}
+
+// CertificateSubject holds the distinguished name fields of a certificate subject
+type CertificateSubject struct {
+	// CommonName overrides the domain as the subject common name
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=64
+	CommonName string `json:"commonName,omitempty"`
+	// +kubebuilder:validation:Optional
+	Organizations []string `json:"organizations,omitempty"`
+	// +kubebuilder:validation:Optional
+	OrganizationalUnits []string `json:"organizationalUnits,omitempty"`
+	// +kubebuilder:validation:Optional
+	Countries []string `json:"countries,omitempty"`
+	// +kubebuilder:validation:Optional
+	Provinces []string `json:"provinces,omitempty"`
+	// +kubebuilder:validation:Optional
+	Localities []string `json:"localities,omitempty"`
+	// +kubebuilder:validation:Optional
+	StreetAddresses []string `json:"streetAddresses,omitempty"`
+	// +kubebuilder:validation:Optional
+	PostalCodes []string `json:"postalCodes,omitempty"`
+	// +kubebuilder:validation:Optional
+	SerialNumber string `json:"serialNumber,omitempty"`
+}
+
+// CertificateUsage is a key usage or extended key usage, named as in cert-manager.
+// Post-quantum signature keys cannot be used for encipherment or key agreement, and
+// ML-KEM keys only for key encipherment.
+// +kubebuilder:validation:Enum="digital signature";"content commitment";"cert sign";"crl sign";"key encipherment";"server auth";"client auth";"code signing";"email protection";"timestamping";"ocsp signing";"any"
+type CertificateUsage string
+
 // QuantumCertificateStatus defines the observed state of QuantumCertificate
 type QuantumCertificateStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -54,6 +274,21 @@ type QuantumCertificateStatus struct {
 	// CertificateFingerprint is a hash of the certificate for verification (hex-encoded)
 	CertificateFingerprint string `json:"certificateFingerprint,omitempty"`
 
+	// SerialNumber is the serial number of the certificate (hex-encoded)
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// NotAfter is when the certificate expires
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+
+	// RenewalTime is when the certificate will be renewed
+	RenewalTime *metav1.Time `json:"renewalTime,omitempty"`
+
+	// Revision counts the certificates issued for this resource, starting at 1
+	Revision int `json:"revision,omitempty"`
+
+	// Classical describes the classical companion certificate
+	Classical *ClassicalCertificateStatus `json:"classical,omitempty"`
+
 	// Error message if generation failed
 	Error string `json:"error,omitempty"`
 }
@@ -64,6 +299,9 @@ type QuantumCertificateStatus struct {
 //+kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
 //+kubebuilder:printcolumn:name="Algorithm",type=string,JSONPath=`.spec.algorithm`
 //+kubebuilder:printcolumn:name="Domain",type=string,JSONPath=`.spec.domain`
+//+kubebuilder:printcolumn:name="CA",type=boolean,JSONPath=`.spec.isCA`
+//+kubebuilder:printcolumn:name="Issuer",type=string,JSONPath=`.spec.issuerRef.name`
+//+kubebuilder:printcolumn:name="Renewal",type=date,JSONPath=`.status.renewalTime`
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // QuantumCertificate is the Schema for the quantumcertificates API
@@ -0,0 +1,69 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// QuantumCertificateProfileSpec defines a reusable certificate template
+type QuantumCertificateProfileSpec struct {
+	// Algorithm is the default signature algorithm of certificates using this profile
+	// +kubebuilder:validation:Optional
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// Days is the default validity period in days
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	Days int `json:"days,omitempty"`
+
+	// CertificateProfile holds the subject, key usages and basic constraints
+	CertificateProfile `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=qcp
+// +kubebuilder:printcolumn:name="Algorithm",type=string,JSONPath=`.spec.algorithm`
+// +kubebuilder:printcolumn:name="CA",type=boolean,JSONPath=`.spec.isCA`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// QuantumCertificateProfile is the Schema for reusable certificate profiles such as "server" or "client".
+// QuantumCertificates reference a profile with spec.profileRef.
+type QuantumCertificateProfile struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the certificate template
+	// +required
+	Spec QuantumCertificateProfileSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// QuantumCertificateProfileList contains a list of QuantumCertificateProfile
+type QuantumCertificateProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuantumCertificateProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QuantumCertificateProfile{}, &QuantumCertificateProfileList{})
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// QuantumCertificateRequestSpec defines the desired state of QuantumCertificateRequest
+type QuantumCertificateRequestSpec struct {
+	// Request is a PEM-encoded PKCS#10 certificate signing request. Its public key must be
+	// an ML-DSA or SLH-DSA key, and it must be signed by that key.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Request string `json:"request"`
+
+	// IssuerRef is the QuantumIssuer or QuantumClusterIssuer that signs the request
+	// +kubebuilder:validation:Required
+	IssuerRef IssuerReference `json:"issuerRef"`
+
+	// Days is the requested validity in days (default: 365). The issuer policy and the
+	// expiry of the CA may shorten it.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	Days int `json:"days,omitempty"`
+
+	// Usages of the certificate (default: digital signature, server auth).
+	// CA usages cannot be requested.
+	// +kubebuilder:validation:Optional
+	Usages []CertificateUsage `json:"usages,omitempty"`
+
+	// CryptoProvider verifies the signature of the request
+	// (default: the operator-wide default provider)
+	// +kubebuilder:validation:Optional
+	CryptoProvider string `json:"cryptoProvider,omitempty"`
+}
+
+// QuantumCertificateRequestStatus defines the observed state of QuantumCertificateRequest
+type QuantumCertificateRequestStatus struct {
+	// Status of the request
+	// +kubebuilder:validation:Enum=Pending;Success;Failed
+	Status string `json:"status,omitempty"`
+
+	// Certificate is the PEM-encoded signed certificate followed by the intermediate CA certificates
+	Certificate string `json:"certificate,omitempty"`
+
+	// CA is the PEM-encoded root CA certificate of the chain
+	CA string `json:"ca,omitempty"`
+
+	// PublicKeyAlgorithm is the algorithm of the requested public key
+	PublicKeyAlgorithm string `json:"publicKeyAlgorithm,omitempty"`
+
+	// SerialNumber is the serial number of the certificate (hex-encoded)
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// NotAfter is when the certificate expires
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+
+	// CertificateFingerprint is the SHA256 hash of the certificate (hex-encoded)
+	CertificateFingerprint string `json:"certificateFingerprint,omitempty"`
+
+	// LastUpdateTime is when the certificate was issued
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// Error message if the request was denied or failed
+	Error string `json:"error,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=qcr
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
+// +kubebuilder:printcolumn:name="Issuer",type=string,JSONPath=`.spec.issuerRef.name`
+// +kubebuilder:printcolumn:name="Algorithm",type=string,JSONPath=`.status.publicKeyAlgorithm`
+// +kubebuilder:printcolumn:name="Expires",type=date,JSONPath=`.status.notAfter`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// QuantumCertificateRequest is the Schema for signing externally generated
+// certificate signing requests with a post-quantum CA
+type QuantumCertificateRequest struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of QuantumCertificateRequest
+	// +required
+	Spec QuantumCertificateRequestSpec `json:"spec"`
+
+	// status defines the observed state of QuantumCertificateRequest
+	// +optional
+	Status QuantumCertificateRequestStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// QuantumCertificateRequestList contains a list of QuantumCertificateRequest
+type QuantumCertificateRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuantumCertificateRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QuantumCertificateRequest{}, &QuantumCertificateRequestList{})
+}
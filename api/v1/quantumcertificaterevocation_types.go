@@ -0,0 +1,125 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// QuantumCertificateRevocationSpec defines the certificate to revoke. Set either
+// certificateRef, or serialNumber together with issuerRef.
+// +kubebuilder:validation:XValidation:rule="has(self.certificateRef) != has(self.serialNumber)",message="exactly one of certificateRef and serialNumber must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.serialNumber) || has(self.issuerRef)",message="issuerRef is required with serialNumber"
+type QuantumCertificateRevocationSpec struct {
+	// CertificateRef is the resource, in the same namespace, whose current certificate is
+	// revoked. The serial number is recorded when the revocation takes effect, so a later
+	// renewal is not revoked.
+	// +kubebuilder:validation:Optional
+	CertificateRef *RevokedCertificateReference `json:"certificateRef,omitempty"`
+
+	// SerialNumber is the hex-encoded serial number of a certificate issued by issuerRef.
+	// With a QuantumClusterIssuer, the certificate must be the current certificate of a
+	// QuantumCertificate, QuantumCertificateRequest or QuantumIssuer in the same namespace.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^(0x)?[0-9a-fA-F:]+$`
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// IssuerRef is the issuer of the certificate with serialNumber. A QuantumIssuer must be
+	// in the same namespace.
+	// +kubebuilder:validation:Optional
+	IssuerRef *IssuerReference `json:"issuerRef,omitempty"`
+
+	// Reason is the RFC 5280 revocation reason
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Unspecified;KeyCompromise;CACompromise;AffiliationChanged;Superseded;CessationOfOperation;CertificateHold;PrivilegeWithdrawn;AACompromise
+	// +kubebuilder:default=Unspecified
+	Reason string `json:"reason,omitempty"`
+}
+
+// RevokedCertificateReference is a reference to a resource holding an issued certificate
+type RevokedCertificateReference struct {
+	// Name of the resource
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Kind of the resource
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=QuantumCertificate;QuantumCertificateRequest;QuantumIssuer
+	// +kubebuilder:default=QuantumCertificate
+	Kind string `json:"kind,omitempty"`
+}
+
+// QuantumCertificateRevocationStatus defines the observed state of QuantumCertificateRevocation
+type QuantumCertificateRevocationStatus struct {
+	// Status of the revocation
+	// +kubebuilder:validation:Enum=Pending;Revoked;Failed
+	Status string `json:"status,omitempty"`
+
+	// SerialNumber is the serial number of the revoked certificate (hex-encoded)
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// IssuerRef is the issuer whose CRL lists the certificate
+	IssuerRef *IssuerReference `json:"issuerRef,omitempty"`
+
+	// RevocationTime is when the certificate was revoked
+	RevocationTime *metav1.Time `json:"revocationTime,omitempty"`
+
+	// Error message if the certificate could not be revoked
+	Error string `json:"error,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=qrv
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
+// +kubebuilder:printcolumn:name="Issuer",type=string,JSONPath=`.status.issuerRef.name`
+// +kubebuilder:printcolumn:name="Serial",type=string,JSONPath=`.status.serialNumber`,priority=1
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.spec.reason`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// QuantumCertificateRevocation is the Schema for revoking a certificate issued by a
+// QuantumIssuer or QuantumClusterIssuer. The issuer lists it in its CRL for as long as
+// the revocation exists.
+type QuantumCertificateRevocation struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the certificate to revoke
+	// +required
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+	Spec QuantumCertificateRevocationSpec `json:"spec"`
+
+	// status defines the observed state of QuantumCertificateRevocation
+	// +optional
+	Status QuantumCertificateRevocationStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// QuantumCertificateRevocationList contains a list of QuantumCertificateRevocation
+type QuantumCertificateRevocationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuantumCertificateRevocation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QuantumCertificateRevocation{}, &QuantumCertificateRevocationList{})
+}
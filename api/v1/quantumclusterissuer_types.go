@@ -0,0 +1,61 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=qci
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
+// +kubebuilder:printcolumn:name="Algorithm",type=string,JSONPath=`.status.algorithm`
+// +kubebuilder:printcolumn:name="Parent",type=string,JSONPath=`.spec.issuerRef.name`
+// +kubebuilder:printcolumn:name="Expires",type=date,JSONPath=`.status.notAfter`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// QuantumClusterIssuer is the Schema for a cluster-scoped post-quantum certificate authority
+// that QuantumCertificates in every namespace can reference
+type QuantumClusterIssuer struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of QuantumClusterIssuer
+	// +required
+	Spec IssuerSpec `json:"spec"`
+
+	// status defines the observed state of QuantumClusterIssuer
+	// +optional
+	Status IssuerStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// QuantumClusterIssuerList contains a list of QuantumClusterIssuer
+type QuantumClusterIssuerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuantumClusterIssuer `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QuantumClusterIssuer{}, &QuantumClusterIssuerList{})
+}
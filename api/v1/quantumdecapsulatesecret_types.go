@@ -47,9 +47,21 @@ type QuantumDecapsulateSecretSpec struct {
 	// +kubebuilder:validation:Required
 	Algorithm string `json:"algorithm"`
 
-	// SecretName is the name of the secret to store the decapsulated shared secret in
+	// SecretName is the name of the secret to store the decapsulated shared secret in, or the
+	// derived keys when derive is set
 	// +kubebuilder:validation:Optional
 	SecretName string `json:"secretName,omitempty"`
+
+	// CryptoProvider selects the implementation used for this resource (liboqs or go).
+	// Defaults to the operator-wide --crypto-provider setting.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=liboqs;go
+	CryptoProvider string `json:"cryptoProvider,omitempty"`
+
+	// Derive derives keys from the shared secret in memory. When set, the raw shared secret
+	// is never stored; only the derived keys and fingerprints are.
+	// +kubebuilder:validation:Optional
+	Derive *SharedSecretDerivation `json:"derive,omitempty"`
 }
 
 // QuantumDecapsulateSecretStatus defines the observed state of QuantumDecapsulateSecret.
@@ -58,7 +70,7 @@ type QuantumDecapsulateSecretStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// Status of the shared secret decapsulation
-	// +kubebuilder:validation:Enum=Pending;Success;Failed
+	// +kubebuilder:validation:Enum=Pending;Success;Failed;ImplementationMismatch
 	Status string `json:"status,omitempty"`
 
 	// SharedSecretReference points to where the shared secret is stored
@@ -67,6 +79,12 @@ type QuantumDecapsulateSecretStatus struct {
 	// Fingerprint is the SHA256 hash of the shared secret (first 10 characters)
 	Fingerprint string `json:"fingerprint,omitempty"`
 
+	// DerivedKeysReference points to where the derived keys are stored when derive is set
+	DerivedKeysReference *ObjectReference `json:"derivedKeysReference,omitempty"`
+
+	// DerivedKeys records the fingerprints of the derived keys
+	DerivedKeys []DerivedKeyFingerprint `json:"derivedKeys,omitempty"`
+
 	// LastUpdateTime is when the shared secret was last decapsulated
 	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
 
@@ -25,16 +25,31 @@ type QuantumDerivedKeySpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// SharedSecretRef is a reference to a QuantumEncapsulateSecret that contains the shared secret
-	// +kubebuilder:validation:Required
-	SharedSecretRef ObjectReference `json:"sharedSecretRef"`
+	// SharedSecretRef is a reference to a QuantumEncapsulateSecret that contains the shared secret.
+	// Exactly one of sharedSecretRef and passphraseSecretRef must be set.
+	// +kubebuilder:validation:Optional
+	SharedSecretRef *ObjectReference `json:"sharedSecretRef,omitempty"`
+
+	// PassphraseSecretRef is a reference to a Secret in the same namespace holding a passphrase.
+	// The key is derived with the memory-hard KDF configured in kdf instead of HKDF.
+	// +kubebuilder:validation:Optional
+	PassphraseSecretRef *ObjectReference `json:"passphraseSecretRef,omitempty"`
+
+	// PassphraseKey selects the key in PassphraseSecretRef data (default: "passphrase")
+	// +kubebuilder:validation:Optional
+	PassphraseKey string `json:"passphraseKey,omitempty"`
+
+	// KDF configures the passphrase key derivation function
+	// +kubebuilder:validation:Optional
+	KDF *PassphraseKDF `json:"kdf,omitempty"`
 
 	// KeyType specifies the type of key to derive (e.g., AES-256, ChaCha20)
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:Enum=AES-256;ChaCha20;HMAC-SHA256
 	KeyType string `json:"keyType"`
 
-	// Salt is optional salt for the HKDF derivation (hex-encoded)
+	// Salt is optional salt for the HKDF derivation (hex-encoded). Passphrase derivations need
+	// at least 16 bytes; when unset a random salt is generated and stored with the key.
 	// +kubebuilder:validation:Optional
 	Salt string `json:"salt,omitempty"`
 
@@ -47,6 +62,48 @@ type QuantumDerivedKeySpec struct {
 	SecretName string `json:"secretName,omitempty"`
 }
 
+// PassphraseKDF configures Argon2id or scrypt. Unset costs use the algorithm defaults.
+type PassphraseKDF struct {
+	// Algorithm is Argon2id (default) or scrypt
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Argon2id;scrypt
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// Iterations is the Argon2id time cost (default: 3)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=64
+	Iterations int32 `json:"iterations,omitempty"`
+	// MemoryKiB is the Argon2id memory cost in KiB (default: 65536). It counts against the
+	// operator's memory limit and must not exceed 64 MiB.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=8
+	// +kubebuilder:validation:Maximum=65536
+	MemoryKiB int32 `json:"memoryKiB,omitempty"`
+	// Parallelism is the number of Argon2id lanes (default: 4)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=16
+	Parallelism int32 `json:"parallelism,omitempty"`
+
+	// CostExponent is log2 of the scrypt CPU/memory cost N (default: 15). scrypt uses
+	// 128 * blockSize * N bytes, which must not exceed 64 MiB.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=19
+	CostExponent int32 `json:"costExponent,omitempty"`
+	// BlockSize is the scrypt block size r (default: 8)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=32
+	BlockSize int32 `json:"blockSize,omitempty"`
+	// Parallelization is the scrypt parallelization p (default: 1)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=16
+	Parallelization int32 `json:"parallelization,omitempty"`
+}
+
 // QuantumDerivedKeyStatus defines the observed state of QuantumDerivedKey
 type QuantumDerivedKeyStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -74,6 +131,9 @@ type QuantumDerivedKeyStatus struct {
 	// UsedInfo is the info that was used in the derivation (hex-encoded or empty if not used)
 	UsedInfo string `json:"usedInfo,omitempty"`
 
+	// UsedKDF records the passphrase KDF and cost parameters, with defaults applied
+	UsedKDF *PassphraseKDF `json:"usedKDF,omitempty"`
+
 	// Error message if derivation failed
 	Error string `json:"error,omitempty"`
 }
@@ -86,7 +146,7 @@ type QuantumDerivedKeyStatus struct {
 // +kubebuilder:printcolumn:name="Fingerprint",type=string,JSONPath=`.status.fingerprint`
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
-// QuantumDerivedKey is the Schema for deriving cryptographic keys from shared secrets using HKDF
+// QuantumDerivedKey is the Schema for deriving cryptographic keys from shared secrets using HKDF, or from passphrases using Argon2id or scrypt
 type QuantumDerivedKey struct {
 	metav1.TypeMeta `json:",inline"`
 
@@ -24,21 +24,103 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // QuantumEncapsulateSecretSpec defines the desired state of QuantumEncapsulateSecret
+// +kubebuilder:validation:XValidation:rule="!has(self.certificateRef) || has(self.issuerRef) != has(self.trustBundleRef)",message="exactly one of issuerRef and trustBundleRef is required with certificateRef"
 type QuantumEncapsulateSecretSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// PublicKeyRef is a reference to a QuantumKEMKeyPair that contains the public key
-	// +kubebuilder:validation:Required
-	PublicKeyRef ObjectReference `json:"publicKeyRef"`
+	// PublicKeyRef is a reference to a QuantumKEMKeyPair that contains the public key.
+	// Exactly one of publicKeyRef and certificateRef must be set.
+	// +kubebuilder:validation:Optional
+	PublicKeyRef ObjectReference `json:"publicKeyRef,omitempty"`
+
+	// CertificateRef points to a Secret holding a PEM ML-KEM certificate under certificateKey
+	// (default: "tls.crt"), followed by its intermediates, such as the Secret of a
+	// QuantumCertificate with kemKeyPairRef. The certificate chain is validated before the
+	// secret is encapsulated to the certified public key.
+	// +kubebuilder:validation:Optional
+	CertificateRef *ObjectReference `json:"certificateRef,omitempty"`
+
+	// CertificateKey selects the key in CertificateRef data that contains the certificate (default: "tls.crt").
+	// +kubebuilder:validation:Optional
+	CertificateKey string `json:"certificateKey,omitempty"`
+
+	// IssuerRef is the QuantumIssuer or QuantumClusterIssuer whose CA certificate is the trust
+	// anchor of the certificate chain. Exactly one of issuerRef and trustBundleRef must be set
+	// with certificateRef.
+	// +kubebuilder:validation:Optional
+	IssuerRef *IssuerReference `json:"issuerRef,omitempty"`
+
+	// TrustBundleRef points to a Secret whose ca.crt holds the PEM roots the certificate chain
+	// is validated against, instead of the CA certificate of issuerRef.
+	// +kubebuilder:validation:Optional
+	TrustBundleRef *ObjectReference `json:"trustBundleRef,omitempty"`
 
-	// Algorithm is the KEM algorithm to use (e.g., Kyber1024, Kyber768)
+	// Algorithm is the KEM algorithm to use (e.g., Kyber1024, Kyber768). With certificateRef it
+	// must match the algorithm of the certified key.
 	// +kubebuilder:validation:Required
 	Algorithm string `json:"algorithm"`
 
-	// SecretName is the name of the secret to store the shared secret in
+	// SecretName is the name of the secret to store the shared secret in, or the derived keys
+	// when derive is set
 	// +kubebuilder:validation:Optional
 	SecretName string `json:"secretName,omitempty"`
+
+	// CryptoProvider selects the implementation used for this resource (liboqs or go).
+	// Defaults to the operator-wide --crypto-provider setting.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=liboqs;go
+	CryptoProvider string `json:"cryptoProvider,omitempty"`
+
+	// Derive derives keys from the shared secret in memory. When set, the raw shared secret
+	// is never stored; only the derived keys and fingerprints are.
+	// +kubebuilder:validation:Optional
+	Derive *SharedSecretDerivation `json:"derive,omitempty"`
+}
+
+// SharedSecretDerivation lists the keys derived directly from a KEM shared secret
+type SharedSecretDerivation struct {
+	// Keys to derive with HKDF-SHA256. Each key equals the key of a QuantumDerivedKey with
+	// the same salt and info.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=32
+	Keys []EphemeralDerivedKey `json:"keys"`
+}
+
+// EphemeralDerivedKey is one key derived from a KEM shared secret
+type EphemeralDerivedKey struct {
+	// Name is the key in the output Secret data
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	// +kubebuilder:validation:MaxLength=253
+	Name string `json:"name"`
+
+	// KeyType specifies the type of key to derive
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=AES-256;ChaCha20;HMAC-SHA256
+	// +kubebuilder:default=AES-256
+	KeyType string `json:"keyType,omitempty"`
+
+	// Salt is optional salt for the HKDF derivation (hex-encoded)
+	// +kubebuilder:validation:Optional
+	Salt string `json:"salt,omitempty"`
+
+	// Info is optional info string for the HKDF derivation (hex-encoded)
+	// +kubebuilder:validation:Optional
+	Info string `json:"info,omitempty"`
+}
+
+// DerivedKeyFingerprint records a key derived from a KEM shared secret
+type DerivedKeyFingerprint struct {
+	// Name is the key in the output Secret data
+	Name string `json:"name"`
+
+	// KeyType is the type of the derived key
+	KeyType string `json:"keyType,omitempty"`
+
+	// Fingerprint is the SHA256 hash of the derived key (first 10 characters)
+	Fingerprint string `json:"fingerprint,omitempty"`
 }
 
 // ObjectReference contains enough information to let you inspect or modify the referred object
@@ -58,7 +140,7 @@ type QuantumEncapsulateSecretStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// Status of the shared secret derivation
-	// +kubebuilder:validation:Enum=Pending;Success;Failed
+	// +kubebuilder:validation:Enum=Pending;Success;Failed;ImplementationMismatch
 	Status string `json:"status,omitempty"`
 
 	// Ciphertext is the encapsulated ciphertext (hex-encoded)
@@ -70,9 +152,21 @@ type QuantumEncapsulateSecretStatus struct {
 	// Fingerprint is the SHA256 hash of the shared secret (first 10 characters)
 	Fingerprint string `json:"fingerprint,omitempty"`
 
+	// DerivedKeysReference points to where the derived keys are stored when derive is set
+	DerivedKeysReference *ObjectReference `json:"derivedKeysReference,omitempty"`
+
+	// DerivedKeys records the fingerprints of the derived keys
+	DerivedKeys []DerivedKeyFingerprint `json:"derivedKeys,omitempty"`
+
 	// LastUpdateTime is when the shared secret was last derived
 	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
 
+	// CertificateSubject is the subject of the validated certificate the secret was encapsulated to
+	CertificateSubject string `json:"certificateSubject,omitempty"`
+
+	// CertificateSerialNumber is the serial number of the validated certificate (hex-encoded)
+	CertificateSerialNumber string `json:"certificateSerialNumber,omitempty"`
+
 	// Error message if derivation failed
 	Error string `json:"error,omitempty"`
 }
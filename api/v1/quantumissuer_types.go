@@ -0,0 +1,302 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IssuerSpec defines a post-quantum certificate authority. It is shared by
+// QuantumIssuer and QuantumClusterIssuer.
+type IssuerSpec struct {
+	// KeyPairRef is a reference to the QuantumSignatureKeyPair holding the CA key.
+	// Its algorithm must have a registered X.509 OID (ML-DSA, SLH-DSA or a composite).
+	// A QuantumIssuer uses a key pair in its own namespace; a QuantumClusterIssuer must
+	// set the namespace.
+	// +kubebuilder:validation:Required
+	KeyPairRef ObjectReference `json:"keyPairRef"`
+
+	// IssuerRef is the parent issuer that signs this CA as an intermediate.
+	// When unset, the issuer is a self-signed root CA.
+	// +kubebuilder:validation:Optional
+	IssuerRef *IssuerReference `json:"issuerRef,omitempty"`
+
+	// Subject holds the distinguished name fields of the CA certificate.
+	// The common name defaults to the issuer name.
+	// +kubebuilder:validation:Optional
+	Subject *CertificateSubject `json:"subject,omitempty"`
+
+	// Days is the validity period of the CA certificate in days (default: 3650)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	Days int `json:"days,omitempty"`
+
+	// MaxPathLen limits the number of intermediate CAs below this CA. Unset means no
+	// limit beyond the parent's; 0 allows leaf certificates only.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MaxPathLen *int32 `json:"maxPathLen,omitempty"`
+
+	// Policy restricts the certificates this issuer signs
+	// +kubebuilder:validation:Optional
+	Policy *IssuerPolicy `json:"policy,omitempty"`
+
+	// CRL configures the certificate revocation list of this issuer
+	// +kubebuilder:validation:Optional
+	CRL *IssuerCRL `json:"crl,omitempty"`
+
+	// OCSP configures the OCSP responder of this issuer
+	// +kubebuilder:validation:Optional
+	OCSP *IssuerOCSP `json:"ocsp,omitempty"`
+
+	// Classical creates a classical CA next to the post-quantum CA, with the same subject
+	// and validity, that signs the classical certificates of QuantumCertificates. Its key
+	// is generated once and stored with its certificate. An intermediate issuer requires
+	// a parent with a classical CA.
+	// +kubebuilder:validation:Optional
+	Classical *ClassicalCertificate `json:"classical,omitempty"`
+
+	// SecretName is the name of the Secret that stores the CA certificate (tls.crt) and the
+	// root certificate (ca.crt). It is created in the namespace of the issuer, or of the key
+	// pair for a QuantumClusterIssuer. Defaults to <name>-ca.
+	// The private key stays in the QuantumSignatureKeyPair Secret.
+	// +kubebuilder:validation:Optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// IssuerPolicy restricts the names and validity of the certificates an issuer signs,
+// for QuantumCertificates, QuantumCertificateRequests and intermediate issuers alike.
+// An empty name list allows any name of that type.
+type IssuerPolicy struct {
+	// AllowedDNSNames are the DNS names certificates may contain. A leading "*."
+	// matches exactly one label, e.g. "*.example.com" matches "api.example.com".
+	// +kubebuilder:validation:Optional
+	AllowedDNSNames []string `json:"allowedDNSNames,omitempty"`
+
+	// AllowedIPRanges are the IP ranges, in CIDR notation, certificates may contain
+	// +kubebuilder:validation:Optional
+	AllowedIPRanges []string `json:"allowedIPRanges,omitempty"`
+
+	// AllowedURIs are URI patterns certificates may contain. "*" matches within one
+	// path segment, e.g. "spiffe://cluster.local/ns/*/sa/*".
+	// +kubebuilder:validation:Optional
+	AllowedURIs []string `json:"allowedURIs,omitempty"`
+
+	// AllowedEmailAddresses are email patterns certificates may contain, e.g. "*@example.com"
+	// +kubebuilder:validation:Optional
+	AllowedEmailAddresses []string `json:"allowedEmailAddresses,omitempty"`
+
+	// MaxDays is the longest validity of signed certificates; longer requests are shortened
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MaxDays int `json:"maxDays,omitempty"`
+
+	// AllowCA lets the issuer sign CA certificates: QuantumCertificates with isCA and
+	// intermediate issuers. CA certificates are refused by default.
+	// +kubebuilder:validation:Optional
+	AllowCA bool `json:"allowCA,omitempty"`
+
+	// AllowedRestrictedUsages are the usages among "cert sign", "crl sign", "ocsp signing"
+	// and "any" that signed certificates may have. They let a certificate sign for the
+	// issuer or serve any purpose, so they are refused by default. CA certificates allowed
+	// by allowCA may always have cert sign and crl sign.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:items:Enum="cert sign";"crl sign";"ocsp signing";any
+	AllowedRestrictedUsages []string `json:"allowedRestrictedUsages,omitempty"`
+}
+
+// IssuerCRL configures the certificate revocation list an issuer maintains for the
+// QuantumCertificateRevocations that name it
+type IssuerCRL struct {
+	// Target is the kind of object the PEM-encoded CRL is published to, under ca.crl
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	// +kubebuilder:default=ConfigMap
+	Target string `json:"target,omitempty"`
+
+	// Name of the ConfigMap or Secret, in the namespace of the CA certificate.
+	// Defaults to <name>-crl.
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// ValidityHours is the time from thisUpdate to nextUpdate of each CRL (default: 24).
+	// The CRL is regenerated when half of it has passed and whenever a revocation changes.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	ValidityHours int `json:"validityHours,omitempty"`
+
+	// DistributionPoints are the URLs relying parties fetch the CRL from, such as the CRL
+	// endpoint of the manager. They are added to the certificates this issuer signs from now on.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:items:Pattern=`^https?://`
+	DistributionPoints []string `json:"distributionPoints,omitempty"`
+}
+
+// IssuerOCSP configures the OCSP responder that answers for the certificates an issuer
+// signs. The manager serves it when started with --ocsp-bind-address.
+type IssuerOCSP struct {
+	// URL of the OCSP responder. It is added to the authority information access
+	// extension of the certificates this issuer signs from now on.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// KeyPairRef is the QuantumSignatureKeyPair holding the delegated responder key,
+	// which must not be the CA key. A QuantumIssuer uses a key pair in its own namespace;
+	// a QuantumClusterIssuer looks it up in the namespace of its CA key pair unless the
+	// namespace is set.
+	// +kubebuilder:validation:Required
+	KeyPairRef ObjectReference `json:"keyPairRef"`
+
+	// ResponderDays is the validity of the responder certificate in days (default: 30).
+	// It is renewed when a third of its validity remains.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	ResponderDays int `json:"responderDays,omitempty"`
+
+	// ValidityHours is the time from thisUpdate to nextUpdate of each response (default: 1)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	ValidityHours int `json:"validityHours,omitempty"`
+
+	// SecretName is the name of the Secret that stores the responder certificate (tls.crt),
+	// next to the CA certificate. Defaults to <name>-ocsp.
+	// +kubebuilder:validation:Optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// IssuerReference is a reference to a QuantumIssuer or QuantumClusterIssuer
+type IssuerReference struct {
+	// Name of the issuer. A QuantumIssuer is looked up in the namespace of the referrer.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Kind of the issuer
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=QuantumIssuer;QuantumClusterIssuer
+	// +kubebuilder:default=QuantumIssuer
+	Kind string `json:"kind,omitempty"`
+}
+
+// IssuerStatus defines the observed state of a QuantumIssuer or QuantumClusterIssuer
+type IssuerStatus struct {
+	// Status of the CA certificate
+	// +kubebuilder:validation:Enum=Pending;Success;Failed
+	Status string `json:"status,omitempty"`
+
+	// CertificateReference points to where the CA certificate is stored
+	CertificateReference *ObjectReference `json:"certificateReference,omitempty"`
+
+	// Algorithm is the signature algorithm of the CA key
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// SerialNumber is the serial number of the CA certificate (hex-encoded)
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// NotAfter is when the CA certificate expires
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+
+	// CertificateFingerprint is the SHA256 hash of the CA certificate (hex-encoded)
+	CertificateFingerprint string `json:"certificateFingerprint,omitempty"`
+
+	// LastUpdateTime is when the CA certificate was last issued
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// CRL describes the last published certificate revocation list
+	CRL *IssuerCRLStatus `json:"crl,omitempty"`
+
+	// OCSP describes the certificate of the delegated OCSP responder
+	OCSP *IssuerOCSPStatus `json:"ocsp,omitempty"`
+
+	// Classical describes the certificate of the classical CA
+	Classical *ClassicalCertificateStatus `json:"classical,omitempty"`
+
+	// Error message if issuance failed
+	Error string `json:"error,omitempty"`
+}
+
+// IssuerCRLStatus describes the certificate revocation list of an issuer
+type IssuerCRLStatus struct {
+	// Reference points to the ConfigMap or Secret the CRL is published to
+	Reference *ObjectReference `json:"reference,omitempty"`
+
+	// Number is the CRL number, which increases with every CRL
+	Number int64 `json:"number,omitempty"`
+
+	// RevokedCertificates is the number of entries in the CRL
+	RevokedCertificates int `json:"revokedCertificates,omitempty"`
+
+	// ThisUpdate is when the CRL was issued
+	ThisUpdate *metav1.Time `json:"thisUpdate,omitempty"`
+
+	// NextUpdate is when the CRL expires; a new one is published before then
+	NextUpdate *metav1.Time `json:"nextUpdate,omitempty"`
+}
+
+// IssuerOCSPStatus describes the delegated OCSP responder certificate of an issuer
+type IssuerOCSPStatus struct {
+	// CertificateReference points to the Secret holding the responder certificate
+	CertificateReference *ObjectReference `json:"certificateReference,omitempty"`
+
+	// Algorithm is the signature algorithm of the responder key
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// SerialNumber is the serial number of the responder certificate (hex-encoded)
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// NotAfter is when the responder certificate expires
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=qi
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
+// +kubebuilder:printcolumn:name="Algorithm",type=string,JSONPath=`.status.algorithm`
+// +kubebuilder:printcolumn:name="Parent",type=string,JSONPath=`.spec.issuerRef.name`
+// +kubebuilder:printcolumn:name="Expires",type=date,JSONPath=`.status.notAfter`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// QuantumIssuer is the Schema for a namespaced post-quantum certificate authority
+type QuantumIssuer struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of QuantumIssuer
+	// +required
+	Spec IssuerSpec `json:"spec"`
+
+	// status defines the observed state of QuantumIssuer
+	// +optional
+	Status IssuerStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// QuantumIssuerList contains a list of QuantumIssuer
+type QuantumIssuerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuantumIssuer `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QuantumIssuer{}, &QuantumIssuerList{})
+}
@@ -32,6 +32,94 @@ type QuantumKEMKeyPairSpec struct {
 	Algorithm string `json:"algorithm,omitempty"`
 	// Optional name of the Secret to store public/private keys. Defaults to resource name.
 	SecretName string `json:"secretName,omitempty"`
+
+	// CryptoProvider selects the implementation used for this resource (liboqs or go).
+	// Defaults to the operator-wide --crypto-provider setting.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=liboqs;go
+	CryptoProvider string `json:"cryptoProvider,omitempty"`
+
+	// RNGProvider selects the random number source used for key generation: system or OpenSSL.
+	// Defaults to the system RNG of the crypto provider.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=system;OpenSSL
+	RNGProvider string `json:"rngProvider,omitempty"`
+
+	// KeyFormat is the encoding of the keys in the Secret. PKCS8 stores a SubjectPublicKeyInfo
+	// and a PKCS#8 private key with the IETF OID of the algorithm; Legacy stores the raw keys in
+	// "<algorithm> PUBLIC KEY" and "<algorithm> SECRET KEY" PEM blocks. New keys default to the
+	// operator-wide --key-format, or Legacy for algorithms without an IETF OID. Setting it
+	// re-encodes the keys of an existing Secret in place.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=PKCS8;Legacy
+	KeyFormat string `json:"keyFormat,omitempty"`
+
+	// PrivateKeyEncryption adds a passphrase-encrypted PKCS#8 private key to the Secret, or
+	// replaces the plaintext key with it. It needs the PKCS8 key format.
+	// +kubebuilder:validation:Optional
+	PrivateKeyEncryption *PrivateKeyEncryption `json:"privateKeyEncryption,omitempty"`
+
+	// ImportFrom imports an existing key pair from a Secret instead of generating one. Keys may be
+	// PEM (SPKI/PKCS#8, encrypted PKCS#8 or "<algorithm> PUBLIC/SECRET KEY"), DER SPKI/PKCS#8 or
+	// raw liboqs encodings. The keys must match the algorithm and each other. Changing the keys
+	// in the Secret imports them again, which is how an imported key pair is rotated.
+	// +kubebuilder:validation:Optional
+	ImportFrom *KeyImport `json:"importFrom,omitempty"`
+}
+
+// PrivateKeyEncryption configures the encryption of a private key as a PBES2
+// encrypted PKCS#8 private key
+type PrivateKeyEncryption struct {
+	// PassphraseSecretRef is a reference to the Secret holding the passphrase. The Secret
+	// must be in the namespace of the resource.
+	// +kubebuilder:validation:Required
+	PassphraseSecretRef ObjectReference `json:"passphraseSecretRef"`
+
+	// PassphraseKey selects the key in PassphraseSecretRef data (default: "passphrase")
+	// +kubebuilder:validation:Optional
+	PassphraseKey string `json:"passphraseKey,omitempty"`
+
+	// Cipher encrypts the key. AES-256-CBC is read by OpenSSL and Java; AES-256-GCM needs a
+	// reader that supports AES-GCM in PBES2 (RFC 5084).
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=AES-256-CBC;AES-256-GCM
+	// +kubebuilder:default=AES-256-CBC
+	Cipher string `json:"cipher,omitempty"`
+
+	// KDF derives the encryption key from the passphrase: PBKDF2 with HMAC-SHA256, or scrypt,
+	// which OpenSSL reads but Java does not.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=PBKDF2;Scrypt
+	// +kubebuilder:default=PBKDF2
+	KDF string `json:"kdf,omitempty"`
+
+	// Mode selects whether the encrypted key is written next to the plaintext key
+	// (Additional) or replaces it (Only). The operator reads an encrypted key with the passphrase.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Additional;Only
+	// +kubebuilder:default=Additional
+	Mode string `json:"mode,omitempty"`
+}
+
+// KeyImport references an existing key pair in a Secret
+type KeyImport struct {
+	// SecretRef is a reference to the Secret holding the key pair. The Secret must be in the
+	// namespace of the key pair.
+	// +kubebuilder:validation:Required
+	SecretRef ObjectReference `json:"secretRef"`
+
+	// PublicKeyKey selects the public key in SecretRef data (default: "public-key")
+	// +kubebuilder:validation:Optional
+	PublicKeyKey string `json:"publicKeyKey,omitempty"`
+
+	// PrivateKeyKey selects the private key in SecretRef data (default: "private-key")
+	// +kubebuilder:validation:Optional
+	PrivateKeyKey string `json:"privateKeyKey,omitempty"`
+
+	// PassphraseKey selects the passphrase of an encrypted PKCS#8 private key in SecretRef data
+	// (default: "passphrase")
+	// +kubebuilder:validation:Optional
+	PassphraseKey string `json:"passphraseKey,omitempty"`
 }
 
 // QuantumKEMKeyPairStatus defines the observed state of QuantumKEMKeyPair
@@ -40,7 +128,7 @@ type QuantumKEMKeyPairStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// Status of key generation
-	// +kubebuilder:validation:Enum=Pending;Success;Failed
+	// +kubebuilder:validation:Enum=Pending;Success;Failed;ImplementationMismatch
 	Status string `json:"status,omitempty"`
 
 	// KeyPairReference points to where the keys are stored
@@ -52,6 +140,9 @@ type QuantumKEMKeyPairStatus struct {
 	// PublicKeyFingerprint is a hash of the public key (hex-encoded)
 	PublicKeyFingerprint string `json:"publicKeyFingerprint,omitempty"`
 
+	// KeyFormat is the encoding of the keys in the Secret
+	KeyFormat string `json:"keyFormat,omitempty"`
+
 	// Error message if generation failed
 	Error string `json:"error,omitempty"`
 }
@@ -61,6 +152,7 @@ type QuantumKEMKeyPairStatus struct {
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
 //+kubebuilder:printcolumn:name="Algorithm",type=string,JSONPath=`.spec.algorithm`
+//+kubebuilder:printcolumn:name="Format",type=string,JSONPath=`.status.keyFormat`
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // QuantumKEMKeyPair is the Schema for the QuantumKEMKeyPairs API
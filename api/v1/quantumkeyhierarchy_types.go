@@ -0,0 +1,140 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// QuantumKeyHierarchySpec defines the desired state of QuantumKeyHierarchy
+type QuantumKeyHierarchySpec struct {
+	// Master is the secret the whole tree is derived from
+	// +kubebuilder:validation:Required
+	Master MasterSecretSource `json:"master"`
+
+	// Keys are the nodes of the tree whose keys are stored in Secrets. Intermediate nodes
+	// are derived implicitly.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=1000
+	Keys []KeyHierarchyNode `json:"keys"`
+}
+
+// MasterSecretSource references the master secret of a key hierarchy. Exactly one
+// reference must be set.
+type MasterSecretSource struct {
+	// RandomNumberRef is a reference to a QuantumRandomNumber in the same namespace with a
+	// single raw value of at least 32 bytes and no refreshInterval
+	// +kubebuilder:validation:Optional
+	RandomNumberRef *ObjectReference `json:"randomNumberRef,omitempty"`
+
+	// SharedSecretRef is a reference to a QuantumEncapsulateSecret or QuantumDecapsulateSecret
+	// in the same namespace
+	// +kubebuilder:validation:Optional
+	SharedSecretRef *ObjectReference `json:"sharedSecretRef,omitempty"`
+}
+
+// KeyHierarchyNode declares a node of the key tree
+type KeyHierarchyNode struct {
+	// Path of the node from the master node m, e.g. m/tenant-a/db/enc. Labels are
+	// lowercase alphanumerics and '-'.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^m(/[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	Path string `json:"path"`
+
+	// KeyType specifies the type of key to derive (e.g., AES-256, ChaCha20)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=AES-256;ChaCha20;HMAC-SHA256
+	KeyType string `json:"keyType"`
+
+	// SecretName is the name of the secret to store the key in. Defaults to the hierarchy
+	// name followed by the path labels, e.g. <name>-tenant-a-db-enc.
+	// +kubebuilder:validation:Optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// KeyHierarchyNodeStatus reports a derived node key
+type KeyHierarchyNodeStatus struct {
+	Path string `json:"path"`
+
+	// KeyReference points to where the node key is stored
+	KeyReference *ObjectReference `json:"keyReference,omitempty"`
+
+	// Fingerprint is the first 10 characters of the SHA-256 of the node key
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// QuantumKeyHierarchyStatus defines the observed state of QuantumKeyHierarchy
+type QuantumKeyHierarchyStatus struct {
+	// Status of the key derivation
+	// +kubebuilder:validation:Enum=Pending;Success;Failed
+	Status string `json:"status,omitempty"`
+
+	// MasterFingerprint is the first 10 characters of the SHA-256 of the master secret. A
+	// restored master must match it to rebuild the same tree.
+	MasterFingerprint string `json:"masterFingerprint,omitempty"`
+
+	// KeyCount is the number of node keys stored in Secrets
+	KeyCount int `json:"keyCount,omitempty"`
+
+	// Keys reports each derived node key
+	Keys []KeyHierarchyNodeStatus `json:"keys,omitempty"`
+
+	// LastUpdateTime is when the keys were last derived
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// Error message if derivation failed
+	Error string `json:"error,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=qkh
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
+// +kubebuilder:printcolumn:name="Keys",type=integer,JSONPath=`.status.keyCount`
+// +kubebuilder:printcolumn:name="Master",type=string,JSONPath=`.status.masterFingerprint`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// QuantumKeyHierarchy is the Schema for deriving a tree of keys from one master secret using HKDF chaining
+type QuantumKeyHierarchy struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of QuantumKeyHierarchy
+	// +required
+	Spec QuantumKeyHierarchySpec `json:"spec"`
+
+	// status defines the observed state of QuantumKeyHierarchy
+	// +optional
+	Status QuantumKeyHierarchyStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// QuantumKeyHierarchyList contains a list of QuantumKeyHierarchy
+type QuantumKeyHierarchyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []QuantumKeyHierarchy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QuantumKeyHierarchy{}, &QuantumKeyHierarchyList{})
+}
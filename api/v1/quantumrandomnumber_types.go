@@ -28,18 +28,172 @@ type QuantumRandomNumberSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// Number of bytes to generate
+	// Number of random bytes in each value of the raw, hex, base64 and base64url formats
+	// (default: 32)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1024
 	Bytes int `json:"bytes,omitempty"`
+	// Format of each value: raw bytes, hex, base64, base64url (unpadded), uuid (version 4)
+	// or password. Defaults to raw.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=raw;hex;base64;base64url;uuid;password
+	Format string `json:"format,omitempty"`
+	// Password configures the generated passwords when format is password
+	// +kubebuilder:validation:Optional
+	Password *PasswordPolicy `json:"password,omitempty"`
+	// Count of values to store in the Secret (default: 1). A single value is stored under
+	// "quantumrandomnumber", several under "quantumrandomnumber-0", "quantumrandomnumber-1", ...
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Count int `json:"count,omitempty"`
+	// RefreshInterval regenerates the values on a schedule, e.g. "720h"
+	// +kubebuilder:validation:Optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+	// GracePeriod keeps the previous values under "<key>.previous" for this long after a refresh
+	// +kubebuilder:validation:Optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
 	// Provider to use for random number generation: system or OpenSSL
 	// +kubebuilder:validation:Enum=system;OpenSSL
 	Provider string `json:"provider,omitempty"`
-	// Seed value for deterministic generation
-	Seed    string `json:"seed,omitempty"`
+	// Seed value for deterministic generation, decoded according to seedEncoding.
+	// When set, the output is expanded from the seed with a DRBG.
+	Seed string `json:"seed,omitempty"`
+	// SeedEncoding of seed: raw (the bytes of the string itself), hex or base64
+	// +kubebuilder:validation:Enum=raw;hex;base64
+	// +kubebuilder:default=raw
+	SeedEncoding string `json:"seedEncoding,omitempty"`
+	// SeedURI fetches a hex seed over HTTPS. The host must be on the operator's entropy host allowlist.
 	SeedURI string `json:"seedURI,omitempty"`
+	// EntropySources are fetched and conditioned into the seed of the DRBG.
+	// Mutually exclusive with seed and seedURI.
+	// +kubebuilder:validation:Optional
+	EntropySources []EntropySource `json:"entropySources,omitempty"`
+	// DRBG used to expand the seed: CTR_DRBG (AES-256, matches the liboqs NIST KAT RNG and
+	// takes a 48-byte seed) or HMAC_DRBG (SHA-256). Defaults to CTR_DRBG.
+	// +kubebuilder:validation:Enum=CTR_DRBG;HMAC_DRBG
+	DRBG string `json:"drbg,omitempty"`
+	// Personalization string for the DRBG instantiation
+	Personalization string `json:"personalization,omitempty"`
+	// StatisticalTests runs the SP 800-22 frequency, runs and cumulative sums tests on the
+	// output in addition to the SP 800-90B health tests. Failing outputs are rejected.
+	// +kubebuilder:validation:Optional
+	StatisticalTests bool `json:"statisticalTests,omitempty"`
 	// Optional name of the Secret to store the random number. Defaults to resource name.
 	SecretName string `json:"secretName,omitempty"`
 }
 
+// PasswordPolicy configures password generation
+type PasswordPolicy struct {
+	// Length of each password in characters (default: 24)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=8
+	// +kubebuilder:validation:Maximum=256
+	Length int `json:"length,omitempty"`
+	// Charset replaces the default alphabet of letters, digits and !#$%&()*+,-./:;<=>?@[]^_{|}~
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=256
+	Charset string `json:"charset,omitempty"`
+	// MinLowercase is the minimum number of lowercase letters
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MinLowercase int `json:"minLowercase,omitempty"`
+	// MinUppercase is the minimum number of uppercase letters
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MinUppercase int `json:"minUppercase,omitempty"`
+	// MinDigits is the minimum number of digits
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MinDigits int `json:"minDigits,omitempty"`
+	// MinSymbols is the minimum number of characters that are neither letters nor digits
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MinSymbols int `json:"minSymbols,omitempty"`
+}
+
+// EntropySource configures one input of the entropy conditioning function
+type EntropySource struct {
+	// Name identifies the source in status
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// Type of the source: Secret, File, HTTPS or QRNG (HTTPS with a JSON response)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=Secret;File;HTTPS;QRNG
+	Type string `json:"type"`
+	// Optional sources may fail without failing the generation
+	// +kubebuilder:validation:Optional
+	Optional bool `json:"optional,omitempty"`
+
+	// SecretRef points to the Secret holding the entropy (Secret sources), in the same namespace
+	// +kubebuilder:validation:Optional
+	SecretRef *ObjectReference `json:"secretRef,omitempty"`
+	// SecretKey selects the key in SecretRef data (default: "entropy")
+	// +kubebuilder:validation:Optional
+	SecretKey string `json:"secretKey,omitempty"`
+
+	// Path of the file or device to read (File sources). Must be on the operator's entropy file allowlist.
+	// +kubebuilder:validation:Optional
+	Path string `json:"path,omitempty"`
+	// Bytes to read from the file (default: 48)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=16
+	// +kubebuilder:validation:Maximum=4096
+	Bytes int `json:"bytes,omitempty"`
+
+	// URL of the endpoint (HTTPS and QRNG sources). The host must be on the operator's entropy host allowlist.
+	// +kubebuilder:validation:Optional
+	URL string `json:"url,omitempty"`
+	// JSONField is the dotted path of the random data in a QRNG response, e.g. "data"
+	// +kubebuilder:validation:Optional
+	JSONField string `json:"jsonField,omitempty"`
+	// CASecretRef points to a Secret in the same namespace with a CA bundle that replaces the system roots
+	// +kubebuilder:validation:Optional
+	CASecretRef *ObjectReference `json:"caSecretRef,omitempty"`
+	// CAKey selects the key in CASecretRef data (default: "ca.crt")
+	// +kubebuilder:validation:Optional
+	CAKey string `json:"caKey,omitempty"`
+	// TimeoutSeconds bounds the request (default: 10)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=60
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// EntropySourceStatus reports the health and contribution of an entropy source
+type EntropySourceStatus struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Healthy is true when the source returned usable entropy
+	Healthy bool `json:"healthy"`
+	// Bytes contributed to the conditioning function
+	Bytes int `json:"bytes,omitempty"`
+	// Error message if the source failed
+	Error string `json:"error,omitempty"`
+}
+
+// RandomnessAssessment reports how generated output was assessed
+type RandomnessAssessment struct {
+	// MostCommonValue is the SP 800-90B most common value estimate in bits per byte
+	MostCommonValue string `json:"mostCommonValue,omitempty"`
+	// Collision is the SP 800-90B collision estimate in bits per byte
+	Collision string `json:"collision,omitempty"`
+	// Tests lists the health and statistical test results
+	Tests []RandomnessTestResult `json:"tests,omitempty"`
+}
+
+// RandomnessTestResult is the outcome of a single randomness test
+type RandomnessTestResult struct {
+	Name string `json:"name"`
+	// Result of the test: Pass, Fail or Skipped
+	// +kubebuilder:validation:Enum=Pass;Fail;Skipped
+	Result string `json:"result"`
+	// PValue of SP 800-22 tests
+	PValue string `json:"pValue,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
 // QuantumRandomNumberStatus defines the observed state of QuantumRandomNumber
 type QuantumRandomNumberStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -55,9 +209,30 @@ type QuantumRandomNumberStatus struct {
 	// LastUpdateTime is when the random number was last generated
 	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
 
+	// Bytes of random data requested per value
 	Bytes    int    `json:"bytes,omitempty"`
 	Provider string `json:"provider,omitempty"`
-	Entropy  string `json:"entropy,omitempty"`
+	// SampleBytes of random data drawn for all values, which the assessment covers
+	SampleBytes int `json:"sampleBytes,omitempty"`
+	// Format and Count of the stored values
+	Format string `json:"format,omitempty"`
+	Count  int    `json:"count,omitempty"`
+
+	// NextRefreshTime is when the values are regenerated
+	NextRefreshTime *metav1.Time `json:"nextRefreshTime,omitempty"`
+	// PreviousExpiryTime is when the previous values are removed from the Secret
+	PreviousExpiryTime *metav1.Time `json:"previousExpiryTime,omitempty"`
+	// Entropy is the SP 800-90B min-entropy estimate of the output in bits per byte
+	Entropy string `json:"entropy,omitempty"`
+	// Assessment reports the health tests, estimators and statistical tests of the output
+	Assessment *RandomnessAssessment `json:"assessment,omitempty"`
+
+	// Deterministic is true when the output was expanded from the seed and can be reproduced
+	Deterministic bool `json:"deterministic,omitempty"`
+	// DRBG used to expand the seed
+	DRBG string `json:"drbg,omitempty"`
+	// EntropySources reports each configured entropy source
+	EntropySources []EntropySourceStatus `json:"entropySources,omitempty"`
 
 	// Error message if generation failed
 	Error string `json:"error,omitempty"`
@@ -69,7 +244,9 @@ type QuantumRandomNumberStatus struct {
 //+kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
 //+kubebuilder:printcolumn:name="Bytes",type=integer,JSONPath=`.status.bytes`
 //+kubebuilder:printcolumn:name="Provider",type=string,JSONPath=`.status.provider`
+//+kubebuilder:printcolumn:name="Format",type=string,JSONPath=`.status.format`
 //+kubebuilder:printcolumn:name="Entropy",type=string,JSONPath=`.status.entropy`
+//+kubebuilder:printcolumn:name="Deterministic",type=boolean,JSONPath=`.status.deterministic`
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // QuantumRandomNumber is the Schema for the quantumrandomnumbers API
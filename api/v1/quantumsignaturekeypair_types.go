@@ -26,15 +26,49 @@ import (
 // QuantumSignatureKeyPairSpec defines the desired state of QuantumSignatureKeyPair
 type QuantumSignatureKeyPairSpec struct {
 	// Algorithm selects the signature scheme to use.
-	// Supports liboqs names (Dilithium2/3/5, Falcon512/1024, SPHINCS+) and NIST names (ML-DSA-44/65/87, SLH-DSA-SHA2-128f)
+	// Supports liboqs names (Dilithium2/3/5, Falcon512/1024, SPHINCS+), NIST names (ML-DSA-44/65/87, SLH-DSA-SHA2-128f)
+	// and ML-DSA + ECDSA composites such as ML-DSA-65-ECDSA-P256-SHA512 (go provider only)
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Enum=Dilithium2;Dilithium3;Dilithium5;Falcon512;Falcon1024;SPHINCS+-SHA2-128f-simple;ML-DSA-44;ML-DSA-65;ML-DSA-87;SLH-DSA-SHA2-128f;SLH-DSA-SHA2-256f;CRYSTALS-Dilithium2;CRYSTALS-Dilithium3;CRYSTALS-Dilithium5
+	// +kubebuilder:validation:Enum=Dilithium2;Dilithium3;Dilithium5;Falcon512;Falcon1024;SPHINCS+-SHA2-128f-simple;ML-DSA-44;ML-DSA-65;ML-DSA-87;SLH-DSA-SHA2-128f;SLH-DSA-SHA2-256f;CRYSTALS-Dilithium2;CRYSTALS-Dilithium3;CRYSTALS-Dilithium5;ML-DSA-44-ECDSA-P256-SHA256;ML-DSA-65-ECDSA-P256-SHA512;ML-DSA-65-ECDSA-P384-SHA512;ML-DSA-87-ECDSA-P384-SHA512
 	// +kubebuilder:default=Dilithium2
 	Algorithm string `json:"algorithm"`
 
 	// Optional name of the Secret to store public/private keys. Defaults to resource name.
 	// +kubebuilder:validation:Optional
 	SecretName string `json:"secretName,omitempty"`
+
+	// CryptoProvider selects the implementation used for this resource (liboqs or go).
+	// Defaults to the operator-wide --crypto-provider setting.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=liboqs;go
+	CryptoProvider string `json:"cryptoProvider,omitempty"`
+
+	// RNGProvider selects the random number source used for key generation: system or OpenSSL.
+	// Defaults to the system RNG of the crypto provider.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=system;OpenSSL
+	RNGProvider string `json:"rngProvider,omitempty"`
+
+	// KeyFormat is the encoding of the keys in the Secret. PKCS8 stores a SubjectPublicKeyInfo
+	// and a PKCS#8 private key with the IETF OID of the algorithm; Legacy stores the raw keys in
+	// "<algorithm> PUBLIC KEY" and "<algorithm> SECRET KEY" PEM blocks. New keys default to the
+	// operator-wide --key-format, or Legacy for algorithms without an IETF OID. Setting it
+	// re-encodes the keys of an existing Secret in place.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=PKCS8;Legacy
+	KeyFormat string `json:"keyFormat,omitempty"`
+
+	// PrivateKeyEncryption adds a passphrase-encrypted PKCS#8 private key to the Secret, or
+	// replaces the plaintext key with it. It needs the PKCS8 key format.
+	// +kubebuilder:validation:Optional
+	PrivateKeyEncryption *PrivateKeyEncryption `json:"privateKeyEncryption,omitempty"`
+
+	// ImportFrom imports an existing key pair from a Secret instead of generating one. Keys may be
+	// PEM (SPKI/PKCS#8, encrypted PKCS#8 or "<algorithm> PUBLIC/SECRET KEY"), DER SPKI/PKCS#8 or
+	// raw liboqs encodings. The keys must match the algorithm and each other. Changing the keys
+	// in the Secret imports them again, which is how an imported key pair is rotated.
+	// +kubebuilder:validation:Optional
+	ImportFrom *KeyImport `json:"importFrom,omitempty"`
 }
 
 // QuantumSignatureKeyPairStatus defines the observed state of QuantumSignatureKeyPair
@@ -43,7 +77,7 @@ type QuantumSignatureKeyPairStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// Status of key generation
-	// +kubebuilder:validation:Enum=Pending;Success;Failed
+	// +kubebuilder:validation:Enum=Pending;Success;Failed;ImplementationMismatch
 	Status string `json:"status,omitempty"`
 
 	// KeyPairReference points to where the keys are stored
@@ -55,6 +89,9 @@ type QuantumSignatureKeyPairStatus struct {
 	// PublicKeyFingerprint is a hash of the public key (hex-encoded)
 	PublicKeyFingerprint string `json:"publicKeyFingerprint,omitempty"`
 
+	// KeyFormat is the encoding of the keys in the Secret
+	KeyFormat string `json:"keyFormat,omitempty"`
+
 	// Error message if generation failed
 	Error string `json:"error,omitempty"`
 }
@@ -64,6 +101,7 @@ type QuantumSignatureKeyPairStatus struct {
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
 //+kubebuilder:printcolumn:name="Algorithm",type=string,JSONPath=`.spec.algorithm`
+//+kubebuilder:printcolumn:name="Format",type=string,JSONPath=`.status.keyFormat`
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // QuantumSignatureKeyPair is the Schema for the quantumsignaturekeypairs API
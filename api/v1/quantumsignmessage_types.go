@@ -16,9 +16,10 @@ type QuantumSignMessageSpec struct {
 	MessageRef ObjectReference `json:"messageRef"`
 
 	// Algorithm selects the signature scheme to use.
-	// Supports liboqs names (Dilithium2/3/5, Falcon512/1024, SPHINCS+) and NIST names (ML-DSA-44/65/87, SLH-DSA-SHA2-128f)
+	// Supports liboqs names (Dilithium2/3/5, Falcon512/1024, SPHINCS+), NIST names (ML-DSA-44/65/87, SLH-DSA-SHA2-128f)
+	// and ML-DSA + ECDSA composites such as ML-DSA-65-ECDSA-P256-SHA512 (go provider only)
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Enum=Dilithium2;Dilithium3;Dilithium5;Falcon512;Falcon1024;SPHINCS+-SHA2-128f-simple;ML-DSA-44;ML-DSA-65;ML-DSA-87;SLH-DSA-SHA2-128f;SLH-DSA-SHA2-256f;CRYSTALS-Dilithium2;CRYSTALS-Dilithium3;CRYSTALS-Dilithium5
+	// +kubebuilder:validation:Enum=Dilithium2;Dilithium3;Dilithium5;Falcon512;Falcon1024;SPHINCS+-SHA2-128f-simple;ML-DSA-44;ML-DSA-65;ML-DSA-87;SLH-DSA-SHA2-128f;SLH-DSA-SHA2-256f;CRYSTALS-Dilithium2;CRYSTALS-Dilithium3;CRYSTALS-Dilithium5;ML-DSA-44-ECDSA-P256-SHA256;ML-DSA-65-ECDSA-P256-SHA512;ML-DSA-65-ECDSA-P384-SHA512;ML-DSA-87-ECDSA-P384-SHA512
 	// +kubebuilder:default=Dilithium2
 	Algorithm string `json:"algorithm"`
 
@@ -34,11 +35,17 @@ type QuantumSignMessageSpec struct {
 	// SignatureKey selects the key used to write the signature into the output Secret (default: "signature").
 	// +kubebuilder:validation:Optional
 	SignatureKey string `json:"signatureKey,omitempty"`
+
+	// CryptoProvider selects the implementation used for this resource (liboqs or go).
+	// Defaults to the operator-wide --crypto-provider setting.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=liboqs;go
+	CryptoProvider string `json:"cryptoProvider,omitempty"`
 }
 
 // QuantumSignMessageStatus defines the observed state of QuantumSignMessage.
 type QuantumSignMessageStatus struct {
-	// +kubebuilder:validation:Enum=Pending;Success;Failed
+	// +kubebuilder:validation:Enum=Pending;Success;Failed;ImplementationMismatch
 	Status string `json:"status,omitempty"`
 
 	// Signature contains the base64-encoded signature (also written to the output Secret).
@@ -0,0 +1,113 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// QuantumUnwrapKeySpec defines the desired state of QuantumUnwrapKey
+type QuantumUnwrapKeySpec struct {
+	// KEKRef is a reference to the AES-256 QuantumDerivedKey in the same namespace the key was
+	// wrapped under
+	// +kubebuilder:validation:Required
+	KEKRef ObjectReference `json:"kekRef"`
+
+	// WrappedKey is the wrapped key (hex-encoded), e.g. exported from another system
+	// +kubebuilder:validation:Optional
+	WrappedKey string `json:"wrappedKey,omitempty"`
+
+	// WrappedKeyRef optionally points to a QuantumWrapKey in the same namespace to read the
+	// wrapped key from status
+	// If provided and wrappedKey is empty, the controller will fetch the wrapped key from that resource
+	// +kubebuilder:validation:Optional
+	WrappedKeyRef *ObjectReference `json:"wrappedKeyRef,omitempty"`
+
+	// Algorithm is the key wrap algorithm the key was wrapped with. Defaults to AES-KWP.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=AES-KW;AES-KWP
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// SecretName is the name of the secret to store the unwrapped key in
+	// +kubebuilder:validation:Optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// SecretKey is the key of the unwrapped key in the secret data (default: "key")
+	// +kubebuilder:validation:Optional
+	SecretKey string `json:"secretKey,omitempty"`
+}
+
+// QuantumUnwrapKeyStatus defines the observed state of QuantumUnwrapKey
+type QuantumUnwrapKeyStatus struct {
+	// Status of the key unwrapping
+	// +kubebuilder:validation:Enum=Pending;Success;Failed
+	Status string `json:"status,omitempty"`
+
+	// UnwrappedKeyReference points to where the unwrapped key is stored
+	UnwrappedKeyReference *ObjectReference `json:"unwrappedKeyReference,omitempty"`
+
+	// KEKFingerprint is the SHA256 hash of the KEK (first 10 characters)
+	KEKFingerprint string `json:"kekFingerprint,omitempty"`
+
+	// KeyFingerprint is the SHA256 hash of the unwrapped key (first 10 characters). It matches
+	// the key fingerprint of the QuantumWrapKey.
+	KeyFingerprint string `json:"keyFingerprint,omitempty"`
+
+	// LastUpdateTime is when the key was last unwrapped
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// Error message if unwrapping failed
+	Error string `json:"error,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=quk
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
+// +kubebuilder:printcolumn:name="KEK",type=string,JSONPath=`.status.kekFingerprint`
+// +kubebuilder:printcolumn:name="Fingerprint",type=string,JSONPath=`.status.keyFingerprint`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// QuantumUnwrapKey is the Schema for unwrapping AES-KW or AES-KWP wrapped keys with a key-encryption key
+type QuantumUnwrapKey struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of QuantumUnwrapKey
+	// +required
+	Spec QuantumUnwrapKeySpec `json:"spec"`
+
+	// status defines the observed state of QuantumUnwrapKey
+	// +optional
+	Status QuantumUnwrapKeyStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// QuantumUnwrapKeyList contains a list of QuantumUnwrapKey
+type QuantumUnwrapKeyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []QuantumUnwrapKey `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QuantumUnwrapKey{}, &QuantumUnwrapKeyList{})
+}
@@ -8,8 +8,19 @@ import (
 // QuantumVerifySignatureSpec defines the desired state of QuantumVerifySignature.
 type QuantumVerifySignatureSpec struct {
 	// PublicKeyRef points to a QuantumSignatureKeyPair secret containing the public key.
-	// +kubebuilder:validation:Required
-	PublicKeyRef ObjectReference `json:"publicKeyRef"`
+	// Exactly one of publicKeyRef and certificateRef must be set.
+	// +kubebuilder:validation:Optional
+	PublicKeyRef ObjectReference `json:"publicKeyRef,omitempty"`
+
+	// CertificateRef points to a Secret holding a PEM certificate under certificateKey
+	// (default: "tls.crt"), such as the Secret of a QuantumCertificate. The signature is
+	// verified with the certificate's public key, and the algorithm is taken from it.
+	// +kubebuilder:validation:Optional
+	CertificateRef *ObjectReference `json:"certificateRef,omitempty"`
+
+	// CertificateKey selects the key in CertificateRef data that contains the certificate (default: "tls.crt").
+	// +kubebuilder:validation:Optional
+	CertificateKey string `json:"certificateKey,omitempty"`
 
 	// MessageRef points to a Secret that holds the message bytes under messageKey (default: "message").
 	// +kubebuilder:validation:Required
@@ -20,9 +31,10 @@ type QuantumVerifySignatureSpec struct {
 	SignatureRef ObjectReference `json:"signatureRef"`
 
 	// Algorithm selects the signature scheme to use.
-	// Supports liboqs names (Dilithium2/3/5, Falcon512/1024, SPHINCS+) and NIST names (ML-DSA-44/65/87, SLH-DSA-SHA2-128f)
+	// Supports liboqs names (Dilithium2/3/5, Falcon512/1024, SPHINCS+), NIST names (ML-DSA-44/65/87, SLH-DSA-SHA2-128f)
+	// and ML-DSA + ECDSA composites such as ML-DSA-65-ECDSA-P256-SHA512 (go provider only)
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Enum=Dilithium2;Dilithium3;Dilithium5;Falcon512;Falcon1024;SPHINCS+-SHA2-128f-simple;ML-DSA-44;ML-DSA-65;ML-DSA-87;SLH-DSA-SHA2-128f;SLH-DSA-SHA2-256f;CRYSTALS-Dilithium2;CRYSTALS-Dilithium3;CRYSTALS-Dilithium5
+	// +kubebuilder:validation:Enum=Dilithium2;Dilithium3;Dilithium5;Falcon512;Falcon1024;SPHINCS+-SHA2-128f-simple;ML-DSA-44;ML-DSA-65;ML-DSA-87;SLH-DSA-SHA2-128f;SLH-DSA-SHA2-256f;CRYSTALS-Dilithium2;CRYSTALS-Dilithium3;CRYSTALS-Dilithium5;ML-DSA-44-ECDSA-P256-SHA256;ML-DSA-65-ECDSA-P256-SHA512;ML-DSA-65-ECDSA-P384-SHA512;ML-DSA-87-ECDSA-P384-SHA512
 	// +kubebuilder:default=Dilithium2
 	Algorithm string `json:"algorithm"`
 
@@ -33,11 +45,17 @@ type QuantumVerifySignatureSpec struct {
 	// SignatureKey selects the key that contains the signature bytes (default: "signature").
 	// +kubebuilder:validation:Optional
 	SignatureKey string `json:"signatureKey,omitempty"`
+
+	// CryptoProvider selects the implementation used for this resource (liboqs or go).
+	// Defaults to the operator-wide --crypto-provider setting.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=liboqs;go
+	CryptoProvider string `json:"cryptoProvider,omitempty"`
 }
 
 // QuantumVerifySignatureStatus defines the observed state of QuantumVerifySignature.
 type QuantumVerifySignatureStatus struct {
-	// +kubebuilder:validation:Enum=Pending;Valid;Invalid;Failed
+	// +kubebuilder:validation:Enum=Pending;Valid;Invalid;Failed;ImplementationMismatch
 	Status string `json:"status,omitempty"`
 
 	// Verified is true when the signature is valid for the provided message.
@@ -0,0 +1,115 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// QuantumWrapKeySpec defines the desired state of QuantumWrapKey
+type QuantumWrapKeySpec struct {
+	// KEKRef is a reference to an AES-256 QuantumDerivedKey in the same namespace, used as the
+	// key-encryption key
+	// +kubebuilder:validation:Required
+	KEKRef ObjectReference `json:"kekRef"`
+
+	// KeySecretRef is a reference to the Secret in the same namespace holding the key to wrap
+	// +kubebuilder:validation:Required
+	KeySecretRef ObjectReference `json:"keySecretRef"`
+
+	// KeySecretKey selects the key in KeySecretRef data (default: "derived-key")
+	// +kubebuilder:validation:Optional
+	KeySecretKey string `json:"keySecretKey,omitempty"`
+
+	// Algorithm is AES-KW (RFC 3394, keys of at least 16 bytes in multiples of 8) or
+	// AES-KWP (RFC 5649, keys of any length). Defaults to AES-KWP.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=AES-KW;AES-KWP
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// SecretName is the name of the secret to store the wrapped key in
+	// +kubebuilder:validation:Optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// QuantumWrapKeyStatus defines the observed state of QuantumWrapKey
+type QuantumWrapKeyStatus struct {
+	// Status of the key wrapping
+	// +kubebuilder:validation:Enum=Pending;Success;Failed
+	Status string `json:"status,omitempty"`
+
+	// WrappedKeyReference points to where the wrapped key is stored
+	WrappedKeyReference *ObjectReference `json:"wrappedKeyReference,omitempty"`
+
+	// WrappedKey is the wrapped key (hex-encoded). It can only be unwrapped with the KEK.
+	WrappedKey string `json:"wrappedKey,omitempty"`
+
+	// Algorithm used to wrap the key
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// KEKFingerprint is the SHA256 hash of the KEK (first 10 characters). It matches the
+	// fingerprint of the QuantumDerivedKey.
+	KEKFingerprint string `json:"kekFingerprint,omitempty"`
+
+	// KeyFingerprint is the SHA256 hash of the wrapped key (first 10 characters)
+	KeyFingerprint string `json:"keyFingerprint,omitempty"`
+
+	// LastUpdateTime is when the key was last wrapped
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// Error message if wrapping failed
+	Error string `json:"error,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=qwk
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
+// +kubebuilder:printcolumn:name="Algorithm",type=string,JSONPath=`.status.algorithm`
+// +kubebuilder:printcolumn:name="KEK",type=string,JSONPath=`.status.kekFingerprint`
+// +kubebuilder:printcolumn:name="Fingerprint",type=string,JSONPath=`.status.keyFingerprint`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// QuantumWrapKey is the Schema for wrapping keys under a key-encryption key with AES-KW or AES-KWP
+type QuantumWrapKey struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of QuantumWrapKey
+	// +required
+	Spec QuantumWrapKeySpec `json:"spec"`
+
+	// status defines the observed state of QuantumWrapKey
+	// +optional
+	Status QuantumWrapKeyStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// QuantumWrapKeyList contains a list of QuantumWrapKey
+type QuantumWrapKeyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []QuantumWrapKey `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QuantumWrapKey{}, &QuantumWrapKeyList{})
+}
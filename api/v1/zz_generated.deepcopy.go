@@ -21,45 +21,1411 @@ limitations under the License.
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateKeystores) DeepCopyInto(out *CertificateKeystores) {
+	*out = *in
+	if in.PKCS12 != nil {
+		in, out := &in.PKCS12, &out.PKCS12
+		*out = new(PKCS12Keystore)
+		**out = **in
+	}
+	if in.JKS != nil {
+		in, out := &in.JKS, &out.JKS
+		*out = new(JKSKeystore)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateKeystores.
+func (in *CertificateKeystores) DeepCopy() *CertificateKeystores {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateKeystores)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateProfile) DeepCopyInto(out *CertificateProfile) {
+	*out = *in
+	if in.Subject != nil {
+		in, out := &in.Subject, &out.Subject
+		*out = new(CertificateSubject)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Usages != nil {
+		in, out := &in.Usages, &out.Usages
+		*out = make([]CertificateUsage, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxPathLen != nil {
+		in, out := &in.MaxPathLen, &out.MaxPathLen
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateProfile.
+func (in *CertificateProfile) DeepCopy() *CertificateProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateSubject) DeepCopyInto(out *CertificateSubject) {
+	*out = *in
+	if in.Organizations != nil {
+		in, out := &in.Organizations, &out.Organizations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OrganizationalUnits != nil {
+		in, out := &in.OrganizationalUnits, &out.OrganizationalUnits
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Countries != nil {
+		in, out := &in.Countries, &out.Countries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Provinces != nil {
+		in, out := &in.Provinces, &out.Provinces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Localities != nil {
+		in, out := &in.Localities, &out.Localities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StreetAddresses != nil {
+		in, out := &in.StreetAddresses, &out.StreetAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PostalCodes != nil {
+		in, out := &in.PostalCodes, &out.PostalCodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateSubject.
+func (in *CertificateSubject) DeepCopy() *CertificateSubject {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateSubject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClassicalCertificate) DeepCopyInto(out *ClassicalCertificate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClassicalCertificate.
+func (in *ClassicalCertificate) DeepCopy() *ClassicalCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(ClassicalCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClassicalCertificateStatus) DeepCopyInto(out *ClassicalCertificateStatus) {
+	*out = *in
+	if in.CertificateReference != nil {
+		in, out := &in.CertificateReference, &out.CertificateReference
+		*out = new(ObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClassicalCertificateStatus.
+func (in *ClassicalCertificateStatus) DeepCopy() *ClassicalCertificateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClassicalCertificateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DerivedKeyFingerprint) DeepCopyInto(out *DerivedKeyFingerprint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DerivedKeyFingerprint.
+func (in *DerivedKeyFingerprint) DeepCopy() *DerivedKeyFingerprint {
+	if in == nil {
+		return nil
+	}
+	out := new(DerivedKeyFingerprint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EntropySource) DeepCopyInto(out *EntropySource) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.CASecretRef != nil {
+		in, out := &in.CASecretRef, &out.CASecretRef
+		*out = new(ObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EntropySource.
+func (in *EntropySource) DeepCopy() *EntropySource {
+	if in == nil {
+		return nil
+	}
+	out := new(EntropySource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EntropySourceStatus) DeepCopyInto(out *EntropySourceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EntropySourceStatus.
+func (in *EntropySourceStatus) DeepCopy() *EntropySourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EntropySourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EphemeralDerivedKey) DeepCopyInto(out *EphemeralDerivedKey) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralDerivedKey.
+func (in *EphemeralDerivedKey) DeepCopy() *EphemeralDerivedKey {
+	if in == nil {
+		return nil
+	}
+	out := new(EphemeralDerivedKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuerCRL) DeepCopyInto(out *IssuerCRL) {
+	*out = *in
+	if in.DistributionPoints != nil {
+		in, out := &in.DistributionPoints, &out.DistributionPoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuerCRL.
+func (in *IssuerCRL) DeepCopy() *IssuerCRL {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuerCRL)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuerCRLStatus) DeepCopyInto(out *IssuerCRLStatus) {
+	*out = *in
+	if in.Reference != nil {
+		in, out := &in.Reference, &out.Reference
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.ThisUpdate != nil {
+		in, out := &in.ThisUpdate, &out.ThisUpdate
+		*out = (*in).DeepCopy()
+	}
+	if in.NextUpdate != nil {
+		in, out := &in.NextUpdate, &out.NextUpdate
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuerCRLStatus.
+func (in *IssuerCRLStatus) DeepCopy() *IssuerCRLStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuerCRLStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuerOCSP) DeepCopyInto(out *IssuerOCSP) {
+	*out = *in
+	out.KeyPairRef = in.KeyPairRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuerOCSP.
+func (in *IssuerOCSP) DeepCopy() *IssuerOCSP {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuerOCSP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuerOCSPStatus) DeepCopyInto(out *IssuerOCSPStatus) {
+	*out = *in
+	if in.CertificateReference != nil {
+		in, out := &in.CertificateReference, &out.CertificateReference
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuerOCSPStatus.
+func (in *IssuerOCSPStatus) DeepCopy() *IssuerOCSPStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuerOCSPStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuerPolicy) DeepCopyInto(out *IssuerPolicy) {
+	*out = *in
+	if in.AllowedDNSNames != nil {
+		in, out := &in.AllowedDNSNames, &out.AllowedDNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedIPRanges != nil {
+		in, out := &in.AllowedIPRanges, &out.AllowedIPRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedURIs != nil {
+		in, out := &in.AllowedURIs, &out.AllowedURIs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedEmailAddresses != nil {
+		in, out := &in.AllowedEmailAddresses, &out.AllowedEmailAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedRestrictedUsages != nil {
+		in, out := &in.AllowedRestrictedUsages, &out.AllowedRestrictedUsages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuerPolicy.
+func (in *IssuerPolicy) DeepCopy() *IssuerPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuerPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuerReference) DeepCopyInto(out *IssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuerReference.
+func (in *IssuerReference) DeepCopy() *IssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuerSpec) DeepCopyInto(out *IssuerSpec) {
+	*out = *in
+	out.KeyPairRef = in.KeyPairRef
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(IssuerReference)
+		**out = **in
+	}
+	if in.Subject != nil {
+		in, out := &in.Subject, &out.Subject
+		*out = new(CertificateSubject)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxPathLen != nil {
+		in, out := &in.MaxPathLen, &out.MaxPathLen
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Policy != nil {
+		in, out := &in.Policy, &out.Policy
+		*out = new(IssuerPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CRL != nil {
+		in, out := &in.CRL, &out.CRL
+		*out = new(IssuerCRL)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OCSP != nil {
+		in, out := &in.OCSP, &out.OCSP
+		*out = new(IssuerOCSP)
+		**out = **in
+	}
+	if in.Classical != nil {
+		in, out := &in.Classical, &out.Classical
+		*out = new(ClassicalCertificate)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuerSpec.
+func (in *IssuerSpec) DeepCopy() *IssuerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuerStatus) DeepCopyInto(out *IssuerStatus) {
+	*out = *in
+	if in.CertificateReference != nil {
+		in, out := &in.CertificateReference, &out.CertificateReference
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CRL != nil {
+		in, out := &in.CRL, &out.CRL
+		*out = new(IssuerCRLStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OCSP != nil {
+		in, out := &in.OCSP, &out.OCSP
+		*out = new(IssuerOCSPStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Classical != nil {
+		in, out := &in.Classical, &out.Classical
+		*out = new(ClassicalCertificateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuerStatus.
+func (in *IssuerStatus) DeepCopy() *IssuerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JKSKeystore) DeepCopyInto(out *JKSKeystore) {
+	*out = *in
+	out.KeystoreOptions = in.KeystoreOptions
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JKSKeystore.
+func (in *JKSKeystore) DeepCopy() *JKSKeystore {
+	if in == nil {
+		return nil
+	}
+	out := new(JKSKeystore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyHierarchyNode) DeepCopyInto(out *KeyHierarchyNode) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyHierarchyNode.
+func (in *KeyHierarchyNode) DeepCopy() *KeyHierarchyNode {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyHierarchyNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyHierarchyNodeStatus) DeepCopyInto(out *KeyHierarchyNodeStatus) {
+	*out = *in
+	if in.KeyReference != nil {
+		in, out := &in.KeyReference, &out.KeyReference
+		*out = new(ObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyHierarchyNodeStatus.
+func (in *KeyHierarchyNodeStatus) DeepCopy() *KeyHierarchyNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyHierarchyNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyImport) DeepCopyInto(out *KeyImport) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyImport.
+func (in *KeyImport) DeepCopy() *KeyImport {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyImport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeystoreOptions) DeepCopyInto(out *KeystoreOptions) {
+	*out = *in
+	out.PasswordSecretRef = in.PasswordSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeystoreOptions.
+func (in *KeystoreOptions) DeepCopy() *KeystoreOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(KeystoreOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MasterSecretSource) DeepCopyInto(out *MasterSecretSource) {
+	*out = *in
+	if in.RandomNumberRef != nil {
+		in, out := &in.RandomNumberRef, &out.RandomNumberRef
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.SharedSecretRef != nil {
+		in, out := &in.SharedSecretRef, &out.SharedSecretRef
+		*out = new(ObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MasterSecretSource.
+func (in *MasterSecretSource) DeepCopy() *MasterSecretSource {
+	if in == nil {
+		return nil
+	}
+	out := new(MasterSecretSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObjectReference) DeepCopyInto(out *ObjectReference) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectReference.
-func (in *ObjectReference) DeepCopy() *ObjectReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectReference.
+func (in *ObjectReference) DeepCopy() *ObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PKCS12Keystore) DeepCopyInto(out *PKCS12Keystore) {
+	*out = *in
+	out.KeystoreOptions = in.KeystoreOptions
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PKCS12Keystore.
+func (in *PKCS12Keystore) DeepCopy() *PKCS12Keystore {
+	if in == nil {
+		return nil
+	}
+	out := new(PKCS12Keystore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PassphraseKDF) DeepCopyInto(out *PassphraseKDF) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PassphraseKDF.
+func (in *PassphraseKDF) DeepCopy() *PassphraseKDF {
+	if in == nil {
+		return nil
+	}
+	out := new(PassphraseKDF)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordPolicy) DeepCopyInto(out *PasswordPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordPolicy.
+func (in *PasswordPolicy) DeepCopy() *PasswordPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrivateKeyEncryption) DeepCopyInto(out *PrivateKeyEncryption) {
+	*out = *in
+	out.PassphraseSecretRef = in.PassphraseSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrivateKeyEncryption.
+func (in *PrivateKeyEncryption) DeepCopy() *PrivateKeyEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(PrivateKeyEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumCertificate) DeepCopyInto(out *QuantumCertificate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificate.
+func (in *QuantumCertificate) DeepCopy() *QuantumCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumCertificate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumCertificateList) DeepCopyInto(out *QuantumCertificateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]QuantumCertificate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateList.
+func (in *QuantumCertificateList) DeepCopy() *QuantumCertificateList {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumCertificateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumCertificateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumCertificateProfile) DeepCopyInto(out *QuantumCertificateProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateProfile.
+func (in *QuantumCertificateProfile) DeepCopy() *QuantumCertificateProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumCertificateProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumCertificateProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumCertificateProfileList) DeepCopyInto(out *QuantumCertificateProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]QuantumCertificateProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateProfileList.
+func (in *QuantumCertificateProfileList) DeepCopy() *QuantumCertificateProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumCertificateProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumCertificateProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumCertificateProfileSpec) DeepCopyInto(out *QuantumCertificateProfileSpec) {
+	*out = *in
+	in.CertificateProfile.DeepCopyInto(&out.CertificateProfile)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateProfileSpec.
+func (in *QuantumCertificateProfileSpec) DeepCopy() *QuantumCertificateProfileSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumCertificateProfileSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumCertificateRequest) DeepCopyInto(out *QuantumCertificateRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateRequest.
+func (in *QuantumCertificateRequest) DeepCopy() *QuantumCertificateRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumCertificateRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumCertificateRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumCertificateRequestList) DeepCopyInto(out *QuantumCertificateRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]QuantumCertificateRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateRequestList.
+func (in *QuantumCertificateRequestList) DeepCopy() *QuantumCertificateRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumCertificateRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumCertificateRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumCertificateRequestSpec) DeepCopyInto(out *QuantumCertificateRequestSpec) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+	if in.Usages != nil {
+		in, out := &in.Usages, &out.Usages
+		*out = make([]CertificateUsage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateRequestSpec.
+func (in *QuantumCertificateRequestSpec) DeepCopy() *QuantumCertificateRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumCertificateRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumCertificateRequestStatus) DeepCopyInto(out *QuantumCertificateRequestStatus) {
+	*out = *in
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateRequestStatus.
+func (in *QuantumCertificateRequestStatus) DeepCopy() *QuantumCertificateRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumCertificateRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumCertificateRevocation) DeepCopyInto(out *QuantumCertificateRevocation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateRevocation.
+func (in *QuantumCertificateRevocation) DeepCopy() *QuantumCertificateRevocation {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumCertificateRevocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumCertificateRevocation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumCertificateRevocationList) DeepCopyInto(out *QuantumCertificateRevocationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]QuantumCertificateRevocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateRevocationList.
+func (in *QuantumCertificateRevocationList) DeepCopy() *QuantumCertificateRevocationList {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumCertificateRevocationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumCertificateRevocationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumCertificateRevocationSpec) DeepCopyInto(out *QuantumCertificateRevocationSpec) {
+	*out = *in
+	if in.CertificateRef != nil {
+		in, out := &in.CertificateRef, &out.CertificateRef
+		*out = new(RevokedCertificateReference)
+		**out = **in
+	}
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(IssuerReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateRevocationSpec.
+func (in *QuantumCertificateRevocationSpec) DeepCopy() *QuantumCertificateRevocationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumCertificateRevocationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumCertificateRevocationStatus) DeepCopyInto(out *QuantumCertificateRevocationStatus) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(IssuerReference)
+		**out = **in
+	}
+	if in.RevocationTime != nil {
+		in, out := &in.RevocationTime, &out.RevocationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateRevocationStatus.
+func (in *QuantumCertificateRevocationStatus) DeepCopy() *QuantumCertificateRevocationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumCertificateRevocationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumCertificateSpec) DeepCopyInto(out *QuantumCertificateSpec) {
+	*out = *in
+	if in.PrivateKeyRef != nil {
+		in, out := &in.PrivateKeyRef, &out.PrivateKeyRef
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.KEMKeyPairRef != nil {
+		in, out := &in.KEMKeyPairRef, &out.KEMKeyPairRef
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(IssuerReference)
+		**out = **in
+	}
+	if in.ProfileRef != nil {
+		in, out := &in.ProfileRef, &out.ProfileRef
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	in.CertificateProfile.DeepCopyInto(&out.CertificateProfile)
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPAddresses != nil {
+		in, out := &in.IPAddresses, &out.IPAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.URIs != nil {
+		in, out := &in.URIs, &out.URIs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EmailAddresses != nil {
+		in, out := &in.EmailAddresses, &out.EmailAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Classical != nil {
+		in, out := &in.Classical, &out.Classical
+		*out = new(ClassicalCertificate)
+		**out = **in
+	}
+	if in.Keystores != nil {
+		in, out := &in.Keystores, &out.Keystores
+		*out = new(CertificateKeystores)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrivateKeyEncryption != nil {
+		in, out := &in.PrivateKeyEncryption, &out.PrivateKeyEncryption
+		*out = new(PrivateKeyEncryption)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateSpec.
+func (in *QuantumCertificateSpec) DeepCopy() *QuantumCertificateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumCertificateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumCertificateStatus) DeepCopyInto(out *QuantumCertificateStatus) {
+	*out = *in
+	if in.CertificateReference != nil {
+		in, out := &in.CertificateReference, &out.CertificateReference
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.RenewalTime != nil {
+		in, out := &in.RenewalTime, &out.RenewalTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Classical != nil {
+		in, out := &in.Classical, &out.Classical
+		*out = new(ClassicalCertificateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateStatus.
+func (in *QuantumCertificateStatus) DeepCopy() *QuantumCertificateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumCertificateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumClusterIssuer) DeepCopyInto(out *QuantumClusterIssuer) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumClusterIssuer.
+func (in *QuantumClusterIssuer) DeepCopy() *QuantumClusterIssuer {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumClusterIssuer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumClusterIssuer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumClusterIssuerList) DeepCopyInto(out *QuantumClusterIssuerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]QuantumClusterIssuer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumClusterIssuerList.
+func (in *QuantumClusterIssuerList) DeepCopy() *QuantumClusterIssuerList {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumClusterIssuerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumClusterIssuerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumDecapsulateSecret) DeepCopyInto(out *QuantumDecapsulateSecret) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDecapsulateSecret.
+func (in *QuantumDecapsulateSecret) DeepCopy() *QuantumDecapsulateSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumDecapsulateSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumDecapsulateSecret) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumDecapsulateSecretList) DeepCopyInto(out *QuantumDecapsulateSecretList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]QuantumDecapsulateSecret, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDecapsulateSecretList.
+func (in *QuantumDecapsulateSecretList) DeepCopy() *QuantumDecapsulateSecretList {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumDecapsulateSecretList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumDecapsulateSecretList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumDecapsulateSecretSpec) DeepCopyInto(out *QuantumDecapsulateSecretSpec) {
+	*out = *in
+	out.PrivateKeyRef = in.PrivateKeyRef
+	if in.CiphertextRef != nil {
+		in, out := &in.CiphertextRef, &out.CiphertextRef
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.Derive != nil {
+		in, out := &in.Derive, &out.Derive
+		*out = new(SharedSecretDerivation)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDecapsulateSecretSpec.
+func (in *QuantumDecapsulateSecretSpec) DeepCopy() *QuantumDecapsulateSecretSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumDecapsulateSecretSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumDecapsulateSecretStatus) DeepCopyInto(out *QuantumDecapsulateSecretStatus) {
+	*out = *in
+	if in.SharedSecretReference != nil {
+		in, out := &in.SharedSecretReference, &out.SharedSecretReference
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.DerivedKeysReference != nil {
+		in, out := &in.DerivedKeysReference, &out.DerivedKeysReference
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.DerivedKeys != nil {
+		in, out := &in.DerivedKeys, &out.DerivedKeys
+		*out = make([]DerivedKeyFingerprint, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDecapsulateSecretStatus.
+func (in *QuantumDecapsulateSecretStatus) DeepCopy() *QuantumDecapsulateSecretStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumDecapsulateSecretStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumDerivedKey) DeepCopyInto(out *QuantumDerivedKey) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDerivedKey.
+func (in *QuantumDerivedKey) DeepCopy() *QuantumDerivedKey {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumDerivedKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumDerivedKey) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumDerivedKeyList) DeepCopyInto(out *QuantumDerivedKeyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]QuantumDerivedKey, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDerivedKeyList.
+func (in *QuantumDerivedKeyList) DeepCopy() *QuantumDerivedKeyList {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumDerivedKeyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumDerivedKeyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumDerivedKeySpec) DeepCopyInto(out *QuantumDerivedKeySpec) {
+	*out = *in
+	if in.SharedSecretRef != nil {
+		in, out := &in.SharedSecretRef, &out.SharedSecretRef
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.PassphraseSecretRef != nil {
+		in, out := &in.PassphraseSecretRef, &out.PassphraseSecretRef
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.KDF != nil {
+		in, out := &in.KDF, &out.KDF
+		*out = new(PassphraseKDF)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDerivedKeySpec.
+func (in *QuantumDerivedKeySpec) DeepCopy() *QuantumDerivedKeySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectReference)
+	out := new(QuantumDerivedKeySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumCertificate) DeepCopyInto(out *QuantumCertificate) {
+func (in *QuantumDerivedKeyStatus) DeepCopyInto(out *QuantumDerivedKeyStatus) {
+	*out = *in
+	if in.DerivedKeyReference != nil {
+		in, out := &in.DerivedKeyReference, &out.DerivedKeyReference
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.UsedKDF != nil {
+		in, out := &in.UsedKDF, &out.UsedKDF
+		*out = new(PassphraseKDF)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDerivedKeyStatus.
+func (in *QuantumDerivedKeyStatus) DeepCopy() *QuantumDerivedKeyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumDerivedKeyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumEncapsulateSecret) DeepCopyInto(out *QuantumEncapsulateSecret) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificate.
-func (in *QuantumCertificate) DeepCopy() *QuantumCertificate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumEncapsulateSecret.
+func (in *QuantumEncapsulateSecret) DeepCopy() *QuantumEncapsulateSecret {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumCertificate)
+	out := new(QuantumEncapsulateSecret)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *QuantumCertificate) DeepCopyObject() runtime.Object {
+func (in *QuantumEncapsulateSecret) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -67,31 +1433,31 @@ func (in *QuantumCertificate) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumCertificateList) DeepCopyInto(out *QuantumCertificateList) {
+func (in *QuantumEncapsulateSecretList) DeepCopyInto(out *QuantumEncapsulateSecretList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]QuantumCertificate, len(*in))
+		*out = make([]QuantumEncapsulateSecret, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateList.
-func (in *QuantumCertificateList) DeepCopy() *QuantumCertificateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumEncapsulateSecretList.
+func (in *QuantumEncapsulateSecretList) DeepCopy() *QuantumEncapsulateSecretList {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumCertificateList)
+	out := new(QuantumEncapsulateSecretList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *QuantumCertificateList) DeepCopyObject() runtime.Object {
+func (in *QuantumEncapsulateSecretList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -99,46 +1465,77 @@ func (in *QuantumCertificateList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumCertificateSpec) DeepCopyInto(out *QuantumCertificateSpec) {
+func (in *QuantumEncapsulateSecretSpec) DeepCopyInto(out *QuantumEncapsulateSecretSpec) {
 	*out = *in
+	out.PublicKeyRef = in.PublicKeyRef
+	if in.CertificateRef != nil {
+		in, out := &in.CertificateRef, &out.CertificateRef
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(IssuerReference)
+		**out = **in
+	}
+	if in.TrustBundleRef != nil {
+		in, out := &in.TrustBundleRef, &out.TrustBundleRef
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.Derive != nil {
+		in, out := &in.Derive, &out.Derive
+		*out = new(SharedSecretDerivation)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateSpec.
-func (in *QuantumCertificateSpec) DeepCopy() *QuantumCertificateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumEncapsulateSecretSpec.
+func (in *QuantumEncapsulateSecretSpec) DeepCopy() *QuantumEncapsulateSecretSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumCertificateSpec)
+	out := new(QuantumEncapsulateSecretSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumCertificateStatus) DeepCopyInto(out *QuantumCertificateStatus) {
+func (in *QuantumEncapsulateSecretStatus) DeepCopyInto(out *QuantumEncapsulateSecretStatus) {
 	*out = *in
-	if in.CertificateReference != nil {
-		in, out := &in.CertificateReference, &out.CertificateReference
+	if in.SharedSecretReference != nil {
+		in, out := &in.SharedSecretReference, &out.SharedSecretReference
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.DerivedKeysReference != nil {
+		in, out := &in.DerivedKeysReference, &out.DerivedKeysReference
 		*out = new(ObjectReference)
 		**out = **in
 	}
+	if in.DerivedKeys != nil {
+		in, out := &in.DerivedKeys, &out.DerivedKeys
+		*out = make([]DerivedKeyFingerprint, len(*in))
+		copy(*out, *in)
+	}
 	if in.LastUpdateTime != nil {
 		in, out := &in.LastUpdateTime, &out.LastUpdateTime
 		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumCertificateStatus.
-func (in *QuantumCertificateStatus) DeepCopy() *QuantumCertificateStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumEncapsulateSecretStatus.
+func (in *QuantumEncapsulateSecretStatus) DeepCopy() *QuantumEncapsulateSecretStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumCertificateStatus)
+	out := new(QuantumEncapsulateSecretStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumDecapsulateSecret) DeepCopyInto(out *QuantumDecapsulateSecret) {
+func (in *QuantumIssuer) DeepCopyInto(out *QuantumIssuer) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -146,18 +1543,18 @@ func (in *QuantumDecapsulateSecret) DeepCopyInto(out *QuantumDecapsulateSecret)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDecapsulateSecret.
-func (in *QuantumDecapsulateSecret) DeepCopy() *QuantumDecapsulateSecret {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumIssuer.
+func (in *QuantumIssuer) DeepCopy() *QuantumIssuer {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumDecapsulateSecret)
+	out := new(QuantumIssuer)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *QuantumDecapsulateSecret) DeepCopyObject() runtime.Object {
+func (in *QuantumIssuer) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -165,31 +1562,31 @@ func (in *QuantumDecapsulateSecret) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumDecapsulateSecretList) DeepCopyInto(out *QuantumDecapsulateSecretList) {
+func (in *QuantumIssuerList) DeepCopyInto(out *QuantumIssuerList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]QuantumDecapsulateSecret, len(*in))
+		*out = make([]QuantumIssuer, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDecapsulateSecretList.
-func (in *QuantumDecapsulateSecretList) DeepCopy() *QuantumDecapsulateSecretList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumIssuerList.
+func (in *QuantumIssuerList) DeepCopy() *QuantumIssuerList {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumDecapsulateSecretList)
+	out := new(QuantumIssuerList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *QuantumDecapsulateSecretList) DeepCopyObject() runtime.Object {
+func (in *QuantumIssuerList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -197,31 +1594,94 @@ func (in *QuantumDecapsulateSecretList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumDecapsulateSecretSpec) DeepCopyInto(out *QuantumDecapsulateSecretSpec) {
+func (in *QuantumKEMKeyPair) DeepCopyInto(out *QuantumKEMKeyPair) {
 	*out = *in
-	out.PrivateKeyRef = in.PrivateKeyRef
-	if in.CiphertextRef != nil {
-		in, out := &in.CiphertextRef, &out.CiphertextRef
-		*out = new(ObjectReference)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumKEMKeyPair.
+func (in *QuantumKEMKeyPair) DeepCopy() *QuantumKEMKeyPair {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumKEMKeyPair)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumKEMKeyPair) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumKEMKeyPairList) DeepCopyInto(out *QuantumKEMKeyPairList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]QuantumKEMKeyPair, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumKEMKeyPairList.
+func (in *QuantumKEMKeyPairList) DeepCopy() *QuantumKEMKeyPairList {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumKEMKeyPairList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuantumKEMKeyPairList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumKEMKeyPairSpec) DeepCopyInto(out *QuantumKEMKeyPairSpec) {
+	*out = *in
+	if in.PrivateKeyEncryption != nil {
+		in, out := &in.PrivateKeyEncryption, &out.PrivateKeyEncryption
+		*out = new(PrivateKeyEncryption)
+		**out = **in
+	}
+	if in.ImportFrom != nil {
+		in, out := &in.ImportFrom, &out.ImportFrom
+		*out = new(KeyImport)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDecapsulateSecretSpec.
-func (in *QuantumDecapsulateSecretSpec) DeepCopy() *QuantumDecapsulateSecretSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumKEMKeyPairSpec.
+func (in *QuantumKEMKeyPairSpec) DeepCopy() *QuantumKEMKeyPairSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumDecapsulateSecretSpec)
+	out := new(QuantumKEMKeyPairSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumDecapsulateSecretStatus) DeepCopyInto(out *QuantumDecapsulateSecretStatus) {
+func (in *QuantumKEMKeyPairStatus) DeepCopyInto(out *QuantumKEMKeyPairStatus) {
 	*out = *in
-	if in.SharedSecretReference != nil {
-		in, out := &in.SharedSecretReference, &out.SharedSecretReference
+	if in.KeyPairReference != nil {
+		in, out := &in.KeyPairReference, &out.KeyPairReference
 		*out = new(ObjectReference)
 		**out = **in
 	}
@@ -231,37 +1691,37 @@ func (in *QuantumDecapsulateSecretStatus) DeepCopyInto(out *QuantumDecapsulateSe
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDecapsulateSecretStatus.
-func (in *QuantumDecapsulateSecretStatus) DeepCopy() *QuantumDecapsulateSecretStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumKEMKeyPairStatus.
+func (in *QuantumKEMKeyPairStatus) DeepCopy() *QuantumKEMKeyPairStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumDecapsulateSecretStatus)
+	out := new(QuantumKEMKeyPairStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumDerivedKey) DeepCopyInto(out *QuantumDerivedKey) {
+func (in *QuantumKeyHierarchy) DeepCopyInto(out *QuantumKeyHierarchy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDerivedKey.
-func (in *QuantumDerivedKey) DeepCopy() *QuantumDerivedKey {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumKeyHierarchy.
+func (in *QuantumKeyHierarchy) DeepCopy() *QuantumKeyHierarchy {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumDerivedKey)
+	out := new(QuantumKeyHierarchy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *QuantumDerivedKey) DeepCopyObject() runtime.Object {
+func (in *QuantumKeyHierarchy) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -269,31 +1729,31 @@ func (in *QuantumDerivedKey) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumDerivedKeyList) DeepCopyInto(out *QuantumDerivedKeyList) {
+func (in *QuantumKeyHierarchyList) DeepCopyInto(out *QuantumKeyHierarchyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]QuantumDerivedKey, len(*in))
+		*out = make([]QuantumKeyHierarchy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDerivedKeyList.
-func (in *QuantumDerivedKeyList) DeepCopy() *QuantumDerivedKeyList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumKeyHierarchyList.
+func (in *QuantumKeyHierarchyList) DeepCopy() *QuantumKeyHierarchyList {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumDerivedKeyList)
+	out := new(QuantumKeyHierarchyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *QuantumDerivedKeyList) DeepCopyObject() runtime.Object {
+func (in *QuantumKeyHierarchyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -301,28 +1761,35 @@ func (in *QuantumDerivedKeyList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumDerivedKeySpec) DeepCopyInto(out *QuantumDerivedKeySpec) {
+func (in *QuantumKeyHierarchySpec) DeepCopyInto(out *QuantumKeyHierarchySpec) {
 	*out = *in
-	out.SharedSecretRef = in.SharedSecretRef
+	in.Master.DeepCopyInto(&out.Master)
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]KeyHierarchyNode, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDerivedKeySpec.
-func (in *QuantumDerivedKeySpec) DeepCopy() *QuantumDerivedKeySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumKeyHierarchySpec.
+func (in *QuantumKeyHierarchySpec) DeepCopy() *QuantumKeyHierarchySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumDerivedKeySpec)
+	out := new(QuantumKeyHierarchySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumDerivedKeyStatus) DeepCopyInto(out *QuantumDerivedKeyStatus) {
+func (in *QuantumKeyHierarchyStatus) DeepCopyInto(out *QuantumKeyHierarchyStatus) {
 	*out = *in
-	if in.DerivedKeyReference != nil {
-		in, out := &in.DerivedKeyReference, &out.DerivedKeyReference
-		*out = new(ObjectReference)
-		**out = **in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]KeyHierarchyNodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.LastUpdateTime != nil {
 		in, out := &in.LastUpdateTime, &out.LastUpdateTime
@@ -330,37 +1797,37 @@ func (in *QuantumDerivedKeyStatus) DeepCopyInto(out *QuantumDerivedKeyStatus) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumDerivedKeyStatus.
-func (in *QuantumDerivedKeyStatus) DeepCopy() *QuantumDerivedKeyStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumKeyHierarchyStatus.
+func (in *QuantumKeyHierarchyStatus) DeepCopy() *QuantumKeyHierarchyStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumDerivedKeyStatus)
+	out := new(QuantumKeyHierarchyStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumEncapsulateSecret) DeepCopyInto(out *QuantumEncapsulateSecret) {
+func (in *QuantumRandomNumber) DeepCopyInto(out *QuantumRandomNumber) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumEncapsulateSecret.
-func (in *QuantumEncapsulateSecret) DeepCopy() *QuantumEncapsulateSecret {
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumRandomNumber.
+func (in *QuantumRandomNumber) DeepCopy() *QuantumRandomNumber {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumEncapsulateSecret)
+	out := new(QuantumRandomNumber)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *QuantumEncapsulateSecret) DeepCopyObject() runtime.Object {
+func (in *QuantumRandomNumber) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -368,31 +1835,31 @@ func (in *QuantumEncapsulateSecret) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumEncapsulateSecretList) DeepCopyInto(out *QuantumEncapsulateSecretList) {
+func (in *QuantumRandomNumberList) DeepCopyInto(out *QuantumRandomNumberList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]QuantumEncapsulateSecret, len(*in))
+		*out = make([]QuantumRandomNumber, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumEncapsulateSecretList.
-func (in *QuantumEncapsulateSecretList) DeepCopy() *QuantumEncapsulateSecretList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumRandomNumberList.
+func (in *QuantumRandomNumberList) DeepCopy() *QuantumRandomNumberList {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumEncapsulateSecretList)
+	out := new(QuantumRandomNumberList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *QuantumEncapsulateSecretList) DeepCopyObject() runtime.Object {
+func (in *QuantumRandomNumberList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -400,26 +1867,47 @@ func (in *QuantumEncapsulateSecretList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumEncapsulateSecretSpec) DeepCopyInto(out *QuantumEncapsulateSecretSpec) {
+func (in *QuantumRandomNumberSpec) DeepCopyInto(out *QuantumRandomNumberSpec) {
 	*out = *in
-	out.PublicKeyRef = in.PublicKeyRef
+	if in.Password != nil {
+		in, out := &in.Password, &out.Password
+		*out = new(PasswordPolicy)
+		**out = **in
+	}
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.EntropySources != nil {
+		in, out := &in.EntropySources, &out.EntropySources
+		*out = make([]EntropySource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumEncapsulateSecretSpec.
-func (in *QuantumEncapsulateSecretSpec) DeepCopy() *QuantumEncapsulateSecretSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumRandomNumberSpec.
+func (in *QuantumRandomNumberSpec) DeepCopy() *QuantumRandomNumberSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumEncapsulateSecretSpec)
+	out := new(QuantumRandomNumberSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumEncapsulateSecretStatus) DeepCopyInto(out *QuantumEncapsulateSecretStatus) {
+func (in *QuantumRandomNumberStatus) DeepCopyInto(out *QuantumRandomNumberStatus) {
 	*out = *in
-	if in.SharedSecretReference != nil {
-		in, out := &in.SharedSecretReference, &out.SharedSecretReference
+	if in.RandomNumberReference != nil {
+		in, out := &in.RandomNumberReference, &out.RandomNumberReference
 		*out = new(ObjectReference)
 		**out = **in
 	}
@@ -427,39 +1915,98 @@ func (in *QuantumEncapsulateSecretStatus) DeepCopyInto(out *QuantumEncapsulateSe
 		in, out := &in.LastUpdateTime, &out.LastUpdateTime
 		*out = (*in).DeepCopy()
 	}
+	if in.NextRefreshTime != nil {
+		in, out := &in.NextRefreshTime, &out.NextRefreshTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PreviousExpiryTime != nil {
+		in, out := &in.PreviousExpiryTime, &out.PreviousExpiryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Assessment != nil {
+		in, out := &in.Assessment, &out.Assessment
+		*out = new(RandomnessAssessment)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EntropySources != nil {
+		in, out := &in.EntropySources, &out.EntropySources
+		*out = make([]EntropySourceStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumEncapsulateSecretStatus.
-func (in *QuantumEncapsulateSecretStatus) DeepCopy() *QuantumEncapsulateSecretStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumRandomNumberStatus.
+func (in *QuantumRandomNumberStatus) DeepCopy() *QuantumRandomNumberStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumEncapsulateSecretStatus)
+	out := new(QuantumRandomNumberStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumKEMKeyPair) DeepCopyInto(out *QuantumKEMKeyPair) {
+func (in *QuantumSignMessageSpec) DeepCopyInto(out *QuantumSignMessageSpec) {
+	*out = *in
+	out.PrivateKeyRef = in.PrivateKeyRef
+	out.MessageRef = in.MessageRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumSignMessageSpec.
+func (in *QuantumSignMessageSpec) DeepCopy() *QuantumSignMessageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumSignMessageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumSignMessageStatus) DeepCopyInto(out *QuantumSignMessageStatus) {
+	*out = *in
+	if in.SignatureReference != nil {
+		in, out := &in.SignatureReference, &out.SignatureReference
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumSignMessageStatus.
+func (in *QuantumSignMessageStatus) DeepCopy() *QuantumSignMessageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QuantumSignMessageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuantumSignatureKeyPair) DeepCopyInto(out *QuantumSignatureKeyPair) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumKEMKeyPair.
-func (in *QuantumKEMKeyPair) DeepCopy() *QuantumKEMKeyPair {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumSignatureKeyPair.
+func (in *QuantumSignatureKeyPair) DeepCopy() *QuantumSignatureKeyPair {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumKEMKeyPair)
+	out := new(QuantumSignatureKeyPair)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *QuantumKEMKeyPair) DeepCopyObject() runtime.Object {
+func (in *QuantumSignatureKeyPair) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -467,31 +2014,31 @@ func (in *QuantumKEMKeyPair) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumKEMKeyPairList) DeepCopyInto(out *QuantumKEMKeyPairList) {
+func (in *QuantumSignatureKeyPairList) DeepCopyInto(out *QuantumSignatureKeyPairList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]QuantumKEMKeyPair, len(*in))
+		*out = make([]QuantumSignatureKeyPair, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumKEMKeyPairList.
-func (in *QuantumKEMKeyPairList) DeepCopy() *QuantumKEMKeyPairList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumSignatureKeyPairList.
+func (in *QuantumSignatureKeyPairList) DeepCopy() *QuantumSignatureKeyPairList {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumKEMKeyPairList)
+	out := new(QuantumSignatureKeyPairList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *QuantumKEMKeyPairList) DeepCopyObject() runtime.Object {
+func (in *QuantumSignatureKeyPairList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -499,22 +2046,32 @@ func (in *QuantumKEMKeyPairList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumKEMKeyPairSpec) DeepCopyInto(out *QuantumKEMKeyPairSpec) {
+func (in *QuantumSignatureKeyPairSpec) DeepCopyInto(out *QuantumSignatureKeyPairSpec) {
 	*out = *in
+	if in.PrivateKeyEncryption != nil {
+		in, out := &in.PrivateKeyEncryption, &out.PrivateKeyEncryption
+		*out = new(PrivateKeyEncryption)
+		**out = **in
+	}
+	if in.ImportFrom != nil {
+		in, out := &in.ImportFrom, &out.ImportFrom
+		*out = new(KeyImport)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumKEMKeyPairSpec.
-func (in *QuantumKEMKeyPairSpec) DeepCopy() *QuantumKEMKeyPairSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumSignatureKeyPairSpec.
+func (in *QuantumSignatureKeyPairSpec) DeepCopy() *QuantumSignatureKeyPairSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumKEMKeyPairSpec)
+	out := new(QuantumSignatureKeyPairSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumKEMKeyPairStatus) DeepCopyInto(out *QuantumKEMKeyPairStatus) {
+func (in *QuantumSignatureKeyPairStatus) DeepCopyInto(out *QuantumSignatureKeyPairStatus) {
 	*out = *in
 	if in.KeyPairReference != nil {
 		in, out := &in.KeyPairReference, &out.KeyPairReference
@@ -527,37 +2084,37 @@ func (in *QuantumKEMKeyPairStatus) DeepCopyInto(out *QuantumKEMKeyPairStatus) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumKEMKeyPairStatus.
-func (in *QuantumKEMKeyPairStatus) DeepCopy() *QuantumKEMKeyPairStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumSignatureKeyPairStatus.
+func (in *QuantumSignatureKeyPairStatus) DeepCopy() *QuantumSignatureKeyPairStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumKEMKeyPairStatus)
+	out := new(QuantumSignatureKeyPairStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumRandomNumber) DeepCopyInto(out *QuantumRandomNumber) {
+func (in *QuantumUnwrapKey) DeepCopyInto(out *QuantumUnwrapKey) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumRandomNumber.
-func (in *QuantumRandomNumber) DeepCopy() *QuantumRandomNumber {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumUnwrapKey.
+func (in *QuantumUnwrapKey) DeepCopy() *QuantumUnwrapKey {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumRandomNumber)
+	out := new(QuantumUnwrapKey)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *QuantumRandomNumber) DeepCopyObject() runtime.Object {
+func (in *QuantumUnwrapKey) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -565,31 +2122,31 @@ func (in *QuantumRandomNumber) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumRandomNumberList) DeepCopyInto(out *QuantumRandomNumberList) {
+func (in *QuantumUnwrapKeyList) DeepCopyInto(out *QuantumUnwrapKeyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]QuantumRandomNumber, len(*in))
+		*out = make([]QuantumUnwrapKey, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumRandomNumberList.
-func (in *QuantumRandomNumberList) DeepCopy() *QuantumRandomNumberList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumUnwrapKeyList.
+func (in *QuantumUnwrapKeyList) DeepCopy() *QuantumUnwrapKeyList {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumRandomNumberList)
+	out := new(QuantumUnwrapKeyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *QuantumRandomNumberList) DeepCopyObject() runtime.Object {
+func (in *QuantumUnwrapKeyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -597,25 +2154,31 @@ func (in *QuantumRandomNumberList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumRandomNumberSpec) DeepCopyInto(out *QuantumRandomNumberSpec) {
+func (in *QuantumUnwrapKeySpec) DeepCopyInto(out *QuantumUnwrapKeySpec) {
 	*out = *in
+	out.KEKRef = in.KEKRef
+	if in.WrappedKeyRef != nil {
+		in, out := &in.WrappedKeyRef, &out.WrappedKeyRef
+		*out = new(ObjectReference)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumRandomNumberSpec.
-func (in *QuantumRandomNumberSpec) DeepCopy() *QuantumRandomNumberSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumUnwrapKeySpec.
+func (in *QuantumUnwrapKeySpec) DeepCopy() *QuantumUnwrapKeySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumRandomNumberSpec)
+	out := new(QuantumUnwrapKeySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumRandomNumberStatus) DeepCopyInto(out *QuantumRandomNumberStatus) {
+func (in *QuantumUnwrapKeyStatus) DeepCopyInto(out *QuantumUnwrapKeyStatus) {
 	*out = *in
-	if in.RandomNumberReference != nil {
-		in, out := &in.RandomNumberReference, &out.RandomNumberReference
+	if in.UnwrappedKeyReference != nil {
+		in, out := &in.UnwrappedKeyReference, &out.UnwrappedKeyReference
 		*out = new(ObjectReference)
 		**out = **in
 	}
@@ -625,59 +2188,60 @@ func (in *QuantumRandomNumberStatus) DeepCopyInto(out *QuantumRandomNumberStatus
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumRandomNumberStatus.
-func (in *QuantumRandomNumberStatus) DeepCopy() *QuantumRandomNumberStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumUnwrapKeyStatus.
+func (in *QuantumUnwrapKeyStatus) DeepCopy() *QuantumUnwrapKeyStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumRandomNumberStatus)
+	out := new(QuantumUnwrapKeyStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumSignMessageSpec) DeepCopyInto(out *QuantumSignMessageSpec) {
+func (in *QuantumVerifySignatureSpec) DeepCopyInto(out *QuantumVerifySignatureSpec) {
 	*out = *in
-	out.PrivateKeyRef = in.PrivateKeyRef
+	out.PublicKeyRef = in.PublicKeyRef
+	if in.CertificateRef != nil {
+		in, out := &in.CertificateRef, &out.CertificateRef
+		*out = new(ObjectReference)
+		**out = **in
+	}
 	out.MessageRef = in.MessageRef
+	out.SignatureRef = in.SignatureRef
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumSignMessageSpec.
-func (in *QuantumSignMessageSpec) DeepCopy() *QuantumSignMessageSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumVerifySignatureSpec.
+func (in *QuantumVerifySignatureSpec) DeepCopy() *QuantumVerifySignatureSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumSignMessageSpec)
+	out := new(QuantumVerifySignatureSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumSignMessageStatus) DeepCopyInto(out *QuantumSignMessageStatus) {
+func (in *QuantumVerifySignatureStatus) DeepCopyInto(out *QuantumVerifySignatureStatus) {
 	*out = *in
-	if in.SignatureReference != nil {
-		in, out := &in.SignatureReference, &out.SignatureReference
-		*out = new(ObjectReference)
-		**out = **in
-	}
-	if in.LastUpdateTime != nil {
-		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+	if in.LastCheckedTime != nil {
+		in, out := &in.LastCheckedTime, &out.LastCheckedTime
 		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumSignMessageStatus.
-func (in *QuantumSignMessageStatus) DeepCopy() *QuantumSignMessageStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumVerifySignatureStatus.
+func (in *QuantumVerifySignatureStatus) DeepCopy() *QuantumVerifySignatureStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumSignMessageStatus)
+	out := new(QuantumVerifySignatureStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumSignatureKeyPair) DeepCopyInto(out *QuantumSignatureKeyPair) {
+func (in *QuantumWrapKey) DeepCopyInto(out *QuantumWrapKey) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -685,18 +2249,18 @@ func (in *QuantumSignatureKeyPair) DeepCopyInto(out *QuantumSignatureKeyPair) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumSignatureKeyPair.
-func (in *QuantumSignatureKeyPair) DeepCopy() *QuantumSignatureKeyPair {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumWrapKey.
+func (in *QuantumWrapKey) DeepCopy() *QuantumWrapKey {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumSignatureKeyPair)
+	out := new(QuantumWrapKey)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *QuantumSignatureKeyPair) DeepCopyObject() runtime.Object {
+func (in *QuantumWrapKey) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -704,31 +2268,31 @@ func (in *QuantumSignatureKeyPair) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumSignatureKeyPairList) DeepCopyInto(out *QuantumSignatureKeyPairList) {
+func (in *QuantumWrapKeyList) DeepCopyInto(out *QuantumWrapKeyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]QuantumSignatureKeyPair, len(*in))
+		*out = make([]QuantumWrapKey, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumSignatureKeyPairList.
-func (in *QuantumSignatureKeyPairList) DeepCopy() *QuantumSignatureKeyPairList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumWrapKeyList.
+func (in *QuantumWrapKeyList) DeepCopy() *QuantumWrapKeyList {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumSignatureKeyPairList)
+	out := new(QuantumWrapKeyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *QuantumSignatureKeyPairList) DeepCopyObject() runtime.Object {
+func (in *QuantumWrapKeyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -736,25 +2300,27 @@ func (in *QuantumSignatureKeyPairList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumSignatureKeyPairSpec) DeepCopyInto(out *QuantumSignatureKeyPairSpec) {
+func (in *QuantumWrapKeySpec) DeepCopyInto(out *QuantumWrapKeySpec) {
 	*out = *in
+	out.KEKRef = in.KEKRef
+	out.KeySecretRef = in.KeySecretRef
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumSignatureKeyPairSpec.
-func (in *QuantumSignatureKeyPairSpec) DeepCopy() *QuantumSignatureKeyPairSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumWrapKeySpec.
+func (in *QuantumWrapKeySpec) DeepCopy() *QuantumWrapKeySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumSignatureKeyPairSpec)
+	out := new(QuantumWrapKeySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumSignatureKeyPairStatus) DeepCopyInto(out *QuantumSignatureKeyPairStatus) {
+func (in *QuantumWrapKeyStatus) DeepCopyInto(out *QuantumWrapKeyStatus) {
 	*out = *in
-	if in.KeyPairReference != nil {
-		in, out := &in.KeyPairReference, &out.KeyPairReference
+	if in.WrappedKeyReference != nil {
+		in, out := &in.WrappedKeyReference, &out.WrappedKeyReference
 		*out = new(ObjectReference)
 		**out = **in
 	}
@@ -764,49 +2330,82 @@ func (in *QuantumSignatureKeyPairStatus) DeepCopyInto(out *QuantumSignatureKeyPa
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumSignatureKeyPairStatus.
-func (in *QuantumSignatureKeyPairStatus) DeepCopy() *QuantumSignatureKeyPairStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumWrapKeyStatus.
+func (in *QuantumWrapKeyStatus) DeepCopy() *QuantumWrapKeyStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumSignatureKeyPairStatus)
+	out := new(QuantumWrapKeyStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumVerifySignatureSpec) DeepCopyInto(out *QuantumVerifySignatureSpec) {
+func (in *RandomnessAssessment) DeepCopyInto(out *RandomnessAssessment) {
 	*out = *in
-	out.PublicKeyRef = in.PublicKeyRef
-	out.MessageRef = in.MessageRef
-	out.SignatureRef = in.SignatureRef
+	if in.Tests != nil {
+		in, out := &in.Tests, &out.Tests
+		*out = make([]RandomnessTestResult, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumVerifySignatureSpec.
-func (in *QuantumVerifySignatureSpec) DeepCopy() *QuantumVerifySignatureSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RandomnessAssessment.
+func (in *RandomnessAssessment) DeepCopy() *RandomnessAssessment {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumVerifySignatureSpec)
+	out := new(RandomnessAssessment)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuantumVerifySignatureStatus) DeepCopyInto(out *QuantumVerifySignatureStatus) {
+func (in *RandomnessTestResult) DeepCopyInto(out *RandomnessTestResult) {
 	*out = *in
-	if in.LastCheckedTime != nil {
-		in, out := &in.LastCheckedTime, &out.LastCheckedTime
-		*out = (*in).DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RandomnessTestResult.
+func (in *RandomnessTestResult) DeepCopy() *RandomnessTestResult {
+	if in == nil {
+		return nil
 	}
+	out := new(RandomnessTestResult)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuantumVerifySignatureStatus.
-func (in *QuantumVerifySignatureStatus) DeepCopy() *QuantumVerifySignatureStatus {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevokedCertificateReference) DeepCopyInto(out *RevokedCertificateReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevokedCertificateReference.
+func (in *RevokedCertificateReference) DeepCopy() *RevokedCertificateReference {
 	if in == nil {
 		return nil
 	}
-	out := new(QuantumVerifySignatureStatus)
+	out := new(RevokedCertificateReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedSecretDerivation) DeepCopyInto(out *SharedSecretDerivation) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]EphemeralDerivedKey, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedSecretDerivation.
+func (in *SharedSecretDerivation) DeepCopy() *SharedSecretDerivation {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedSecretDerivation)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -19,6 +19,7 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -34,6 +35,9 @@ import (
 
 	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
 	"github.com/QubeSec/QubeSec/internal/controller"
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
+	"github.com/QubeSec/QubeSec/internal/entropy"
+	"github.com/QubeSec/QubeSec/internal/keypair"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -53,11 +57,36 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var crlAddr string
+	var ocspAddr string
+	var cryptoProvider string
+	var keyFormat string
+	var entropyHosts string
+	var entropyFiles string
+	var certManagerIssuer bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&crlAddr, "crl-bind-address", "0",
+		"The address the CRL endpoint binds to, serving issuer CRLs under /crl/. Use 0 to disable it.")
+	flag.StringVar(&ocspAddr, "ocsp-bind-address", "0",
+		"The address the OCSP responder binds to, answering for issuers with OCSP configured. Use 0 to disable it.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&cryptoProvider, "crypto-provider", cryptoprovider.Auto,
+		"Crypto implementation used when a resource does not select one: auto, liboqs or go. "+
+			"auto prefers liboqs when it is compiled in and supports the algorithm.")
+	flag.StringVar(&keyFormat, "key-format", keypair.FormatPKCS8,
+		"Encoding of new key pair Secrets: PKCS8 (SubjectPublicKeyInfo and PKCS#8 with IETF OIDs) or Legacy. "+
+			"Algorithms without an IETF OID always use Legacy.")
+	flag.StringVar(&entropyHosts, "entropy-allowed-hosts", "",
+		"Comma-separated hosts that HTTPS and QRNG entropy sources may connect to. "+
+			"Entries starting with *. match subdomains. Empty disables network entropy sources.")
+	flag.StringVar(&entropyFiles, "entropy-allowed-files", "/dev/hwrng,/dev/random",
+		"Comma-separated files and devices that File entropy sources may read.")
+	flag.BoolVar(&certManagerIssuer, "cert-manager-issuer", true,
+		"Sign cert-manager CertificateRequests that reference a QuantumIssuer or QuantumClusterIssuer. "+
+			"Skipped when the cert-manager CRDs are not installed when the manager starts.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -66,6 +95,24 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if err := cryptoprovider.SetDefault(cryptoProvider); err != nil {
+		setupLog.Error(err, "invalid crypto provider", "available", cryptoprovider.Available())
+		os.Exit(1)
+	}
+	if err := keypair.SetDefaultFormat(keyFormat); err != nil {
+		setupLog.Error(err, "invalid key format")
+		os.Exit(1)
+	}
+	entropyPolicy := entropy.Policy{
+		AllowedHosts: splitList(entropyHosts),
+		AllowedFiles: splitList(entropyFiles),
+	}
+	if err := entropyPolicy.Validate(); err != nil {
+		setupLog.Error(err, "invalid entropy allowlist")
+		os.Exit(1)
+	}
+	setupLog.Info("crypto providers", "default", cryptoprovider.Default(), "available", cryptoprovider.Available())
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
@@ -90,8 +137,9 @@ func main() {
 	}
 
 	if err = (&controller.QuantumRandomNumberReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		EntropyPolicy: entropyPolicy,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "QuantumRandomNumber")
 		os.Exit(1)
@@ -131,6 +179,55 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "QuantumDerivedKey")
 		os.Exit(1)
 	}
+	if err := (&controller.QuantumKeyHierarchyReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "QuantumKeyHierarchy")
+		os.Exit(1)
+	}
+	if err := (&controller.QuantumWrapKeyReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "QuantumWrapKey")
+		os.Exit(1)
+	}
+	if err := (&controller.QuantumUnwrapKeyReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "QuantumUnwrapKey")
+		os.Exit(1)
+	}
+	if err := (&controller.QuantumIssuerReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "QuantumIssuer")
+		os.Exit(1)
+	}
+	if err := (&controller.QuantumClusterIssuerReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "QuantumClusterIssuer")
+		os.Exit(1)
+	}
+	if err := (&controller.QuantumCertificateRequestReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "QuantumCertificateRequest")
+		os.Exit(1)
+	}
+	if err := (&controller.QuantumCertificateRevocationReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "QuantumCertificateRevocation")
+		os.Exit(1)
+	}
 	if err := (&controller.QuantumDecapsulateSecretReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -152,8 +249,43 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "QuantumVerifySignature")
 		os.Exit(1)
 	}
+	if certManagerIssuer {
+		installed, err := controller.CertManagerInstalled(mgr.GetRESTMapper())
+		if err != nil {
+			setupLog.Error(err, "unable to look up the cert-manager CertificateRequest API")
+			os.Exit(1)
+		}
+		if !installed {
+			setupLog.Info("cert-manager CRDs not found, not signing cert-manager CertificateRequests")
+		} else if err := (&controller.CertManagerCertificateRequestReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "CertManagerCertificateRequest")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
+	if crlAddr != "0" {
+		if err := mgr.Add(&controller.CRLServer{
+			Reader:      mgr.GetClient(),
+			BindAddress: crlAddr,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up CRL endpoint")
+			os.Exit(1)
+		}
+	}
+	if ocspAddr != "0" {
+		if err := mgr.Add(&controller.OCSPServer{
+			Reader:      mgr.GetClient(),
+			BindAddress: ocspAddr,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up OCSP responder")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -169,3 +301,14 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// splitList parses a comma-separated flag value, ignoring empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
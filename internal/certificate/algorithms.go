@@ -0,0 +1,280 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"crypto/subtle"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
+)
+
+// algorithm describes a signature or KEM algorithm with a registered X.509
+// OID. For signature algorithms the same OID identifies the public key in
+// SubjectPublicKeyInfo and the signature algorithm, always without
+// parameters.
+type algorithm struct {
+	name string
+	oid  asn1.ObjectIdentifier
+	// expandedKey marks ML-DSA and ML-KEM, whose PKCS#8 private key is a
+	// CHOICE that wraps the expanded key in an inner OCTET STRING
+	expandedKey bool
+	// seedSize is the size of the seed form of the private key, which is
+	// encoded as [0] IMPLICIT OCTET STRING in the same CHOICE
+	seedSize int
+	// kem marks ML-KEM, whose keys can be certified but cannot sign
+	kem bool
+}
+
+// algorithms lists the signature algorithms that can be used in certificates:
+// ML-DSA (FIPS 204, RFC 9881), SLH-DSA (FIPS 205, RFC 9909) and the ML-DSA +
+// ECDSA composites of draft-ietf-lamps-pq-composite-sigs, whose keys are the
+// concatenated component keys. It also lists ML-KEM (FIPS 203,
+// draft-ietf-lamps-kyber-certificates), whose public keys can be certified.
+var algorithms = []algorithm{
+	{name: "ML-DSA-44", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 17}, expandedKey: true},
+	{name: "ML-DSA-65", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 18}, expandedKey: true},
+	{name: "ML-DSA-87", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 19}, expandedKey: true},
+	{name: "SLH-DSA-SHA2-128s", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 20}},
+	{name: "SLH-DSA-SHA2-128f", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 21}},
+	{name: "SLH-DSA-SHA2-192s", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 22}},
+	{name: "SLH-DSA-SHA2-192f", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 23}},
+	{name: "SLH-DSA-SHA2-256s", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 24}},
+	{name: "SLH-DSA-SHA2-256f", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 25}},
+	{name: "SLH-DSA-SHAKE-128s", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 26}},
+	{name: "SLH-DSA-SHAKE-128f", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 27}},
+	{name: "SLH-DSA-SHAKE-192s", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 28}},
+	{name: "SLH-DSA-SHAKE-192f", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 29}},
+	{name: "SLH-DSA-SHAKE-256s", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 30}},
+	{name: "SLH-DSA-SHAKE-256f", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 31}},
+	{name: "ML-DSA-44-ECDSA-P256-SHA256", oid: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 6, 40}},
+	{name: "ML-DSA-65-ECDSA-P256-SHA512", oid: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 6, 45}},
+	{name: "ML-DSA-65-ECDSA-P384-SHA512", oid: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 6, 46}},
+	{name: "ML-DSA-87-ECDSA-P384-SHA512", oid: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 6, 49}},
+	{name: "ML-KEM-512", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 4, 1}, expandedKey: true, seedSize: 64, kem: true},
+	{name: "ML-KEM-768", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 4, 2}, expandedKey: true, seedSize: 64, kem: true},
+	{name: "ML-KEM-1024", oid: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 4, 3}, expandedKey: true, seedSize: 64, kem: true},
+}
+
+// lookupAlgorithm returns the algorithm with the given name.
+func lookupAlgorithm(name string) (algorithm, error) {
+	for _, a := range algorithms {
+		if a.name == name {
+			return a, nil
+		}
+	}
+	return algorithm{}, fmt.Errorf("signature algorithm %q has no registered X.509 OID", name)
+}
+
+// lookupSignatureAlgorithm returns the signature algorithm with the given
+// name.
+func lookupSignatureAlgorithm(name string) (algorithm, error) {
+	a, err := lookupAlgorithm(name)
+	if err == nil && a.kem {
+		return algorithm{}, fmt.Errorf("%s is a KEM algorithm and cannot sign", name)
+	}
+	return a, err
+}
+
+// lookupOID returns the algorithm with the given OID.
+func lookupOID(oid asn1.ObjectIdentifier) (algorithm, error) {
+	for _, a := range algorithms {
+		if a.oid.Equal(oid) {
+			return a, nil
+		}
+	}
+	return algorithm{}, fmt.Errorf("unsupported public key algorithm %v", oid)
+}
+
+// lookupSignatureOID returns the signature algorithm with the given OID.
+func lookupSignatureOID(oid asn1.ObjectIdentifier) (algorithm, error) {
+	a, err := lookupOID(oid)
+	if err == nil && a.kem {
+		return algorithm{}, fmt.Errorf("%s is a KEM algorithm and cannot sign", a.name)
+	}
+	return a, err
+}
+
+// Supported reports whether certificates can be issued with the algorithm.
+func Supported(name string) bool {
+	_, err := lookupSignatureAlgorithm(name)
+	return err == nil
+}
+
+// SupportedKEM reports whether KEM public keys of the algorithm can be
+// certified.
+func SupportedKEM(name string) bool {
+	a, err := lookupAlgorithm(name)
+	return err == nil && a.kem
+}
+
+// IsKEM reports whether a public key is a KEM key.
+func (key PublicKey) IsKEM() bool {
+	return SupportedKEM(key.Algorithm)
+}
+
+// PublicKey is a raw public key together with its signature algorithm name.
+type PublicKey struct {
+	Algorithm string
+	Bytes     []byte
+}
+
+// subjectPublicKeyInfo is the RFC 5280 SubjectPublicKeyInfo structure
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// MarshalPublicKey encodes a public key as a DER SubjectPublicKeyInfo.
+func MarshalPublicKey(key PublicKey) ([]byte, error) {
+	a, err := lookupAlgorithm(key.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: a.oid},
+		PublicKey: asn1.BitString{Bytes: key.Bytes, BitLength: 8 * len(key.Bytes)},
+	})
+}
+
+// ParsePublicKey decodes a DER SubjectPublicKeyInfo.
+func ParsePublicKey(der []byte) (PublicKey, error) {
+	var spki subjectPublicKeyInfo
+	rest, err := asn1.Unmarshal(der, &spki)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	if len(rest) != 0 {
+		return PublicKey{}, fmt.Errorf("trailing data after public key")
+	}
+	if len(spki.Algorithm.Parameters.FullBytes) != 0 {
+		return PublicKey{}, fmt.Errorf("public key algorithm parameters must be absent")
+	}
+
+	a, err := lookupOID(spki.Algorithm.Algorithm)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	return PublicKey{Algorithm: a.name, Bytes: spki.PublicKey.RightAlign()}, nil
+}
+
+// oneAsymmetricKey is the RFC 5958 private key structure (PKCS#8 v1)
+type oneAsymmetricKey struct {
+	Version    int
+	Algorithm  pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// MarshalPrivateKey encodes a raw private key as DER PKCS#8. ML-DSA keys use
+// the expandedKey form of RFC 9881, ML-KEM keys the seed or expandedKey form
+// of draft-ietf-lamps-kyber-certificates depending on their size, and SLH-DSA
+// keys are stored as is.
+func MarshalPrivateKey(algorithmName string, privateKey []byte) ([]byte, error) {
+	a, err := lookupAlgorithm(algorithmName)
+	if err != nil {
+		return nil, err
+	}
+
+	key := privateKey
+	switch {
+	case a.seedSize > 0 && len(privateKey) == a.seedSize:
+		if key, err = asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: privateKey}); err != nil {
+			return nil, err
+		}
+	case a.expandedKey:
+		if key, err = asn1.Marshal(privateKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return asn1.Marshal(oneAsymmetricKey{
+		Algorithm:  pkix.AlgorithmIdentifier{Algorithm: a.oid},
+		PrivateKey: key,
+	})
+}
+
+// ParsePrivateKey decodes a DER PKCS#8 private key and returns its algorithm
+// name and the raw private key. An ML-KEM key in the both form holds the seed
+// and the expanded key; it must expand from its seed, which is returned.
+func ParsePrivateKey(der []byte) (string, []byte, error) {
+	var key oneAsymmetricKey
+	rest, err := asn1.Unmarshal(der, &key)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	if len(rest) != 0 {
+		return "", nil, fmt.Errorf("trailing data after private key")
+	}
+
+	a, err := lookupOID(key.Algorithm.Algorithm)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !a.expandedKey {
+		return a.name, key.PrivateKey, nil
+	}
+
+	var choice asn1.RawValue
+	if rest, err := asn1.Unmarshal(key.PrivateKey, &choice); err != nil || len(rest) != 0 {
+		return "", nil, fmt.Errorf("failed to parse %s private key", a.name)
+	}
+	switch {
+	case choice.Class == asn1.ClassUniversal && choice.Tag == asn1.TagOctetString && !choice.IsCompound:
+		return a.name, choice.Bytes, nil
+	case a.seedSize > 0 && choice.Class == asn1.ClassContextSpecific && choice.Tag == 0 && !choice.IsCompound:
+		if len(choice.Bytes) != a.seedSize {
+			return "", nil, fmt.Errorf("%s private key seed must be %d bytes", a.name, a.seedSize)
+		}
+		return a.name, choice.Bytes, nil
+	case a.seedSize > 0 && choice.Class == asn1.ClassUniversal && choice.Tag == asn1.TagSequence && choice.IsCompound:
+		return parseBothPrivateKey(a, choice.FullBytes)
+	}
+	if a.seedSize > 0 {
+		return "", nil, fmt.Errorf("failed to parse %s private key: only the seed, expandedKey and both forms are supported", a.name)
+	}
+	return "", nil, fmt.Errorf("failed to parse %s private key: only the expandedKey form is supported", a.name)
+}
+
+// bothPrivateKey is the both form of an ML-KEM private key
+type bothPrivateKey struct {
+	Seed        []byte
+	ExpandedKey []byte
+}
+
+// parseBothPrivateKey decodes the both form of a private key and checks that
+// the expanded key is the one derived from the seed
+func parseBothPrivateKey(a algorithm, der []byte) (string, []byte, error) {
+	var both bothPrivateKey
+	if rest, err := asn1.Unmarshal(der, &both); err != nil || len(rest) != 0 {
+		return "", nil, fmt.Errorf("failed to parse %s private key", a.name)
+	}
+	if len(both.Seed) != a.seedSize {
+		return "", nil, fmt.Errorf("%s private key seed must be %d bytes", a.name, a.seedSize)
+	}
+	expanded, err := cryptoprovider.ExpandMLKEMKey(a.name, both.Seed)
+	if err != nil {
+		return "", nil, err
+	}
+	if subtle.ConstantTimeCompare(expanded, both.ExpandedKey) != 1 {
+		return "", nil, fmt.Errorf("%s private key seed does not match its expanded key", a.name)
+	}
+	return a.name, both.Seed, nil
+}
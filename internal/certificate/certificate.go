@@ -1,110 +1,314 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package certificate builds X.509 certificates signed with post-quantum
+// algorithms in process. crypto/x509 cannot sign with ML-DSA or SLH-DSA, so
+// the certificate is encoded here from a crypto/x509 template and signed by
+// a Signer backed by a crypto provider.
 package certificate
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
-	"strconv"
+	"io"
+	"math/big"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
-	"github.com/google/uuid"
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
 )
 
-func Certificate(algorithm, domain string, days int) (string, string) {
+// ErrInvalidSignature is returned when a signature does not verify.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// tbsCertificate is the RFC 5280 TBSCertificate structure
+type tbsCertificate struct {
+	Version            int `asn1:"optional,explicit,default:0,tag:0"`
+	SerialNumber       *big.Int
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Issuer             asn1.RawValue
+	Validity           validity
+	Subject            asn1.RawValue
+	PublicKey          asn1.RawValue
+	Extensions         []pkix.Extension `asn1:"omitempty,optional,explicit,tag:3"`
+}
+
+type validity struct {
+	NotBefore, NotAfter time.Time
+}
+
+// signedCertificate is the RFC 5280 Certificate structure
+type signedCertificate struct {
+	TBSCertificate     asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	SignatureValue     asn1.BitString
+}
+
+// CreateCertificate creates a DER certificate for publicKey from template,
+// signed by signer. If parent is nil the certificate is self-signed and
+// signer must hold the private key for publicKey.
+//
+// The template fields used are SerialNumber, Subject (or RawSubject),
+// NotBefore, NotAfter, KeyUsage, ExtKeyUsage, UnknownExtKeyUsage,
+// BasicConstraintsValid, IsCA, MaxPathLen, MaxPathLenZero, DNSNames,
+// EmailAddresses, IPAddresses, URIs, SubjectKeyId, AuthorityKeyId,
+// OCSPServer, IssuingCertificateURL, CRLDistributionPoints and
+// ExtraExtensions. The output only depends on its inputs, so a deterministic
+// signer produces the same certificate every time.
+func CreateCertificate(template *x509.Certificate, parent *x509.Certificate, publicKey PublicKey, signer Signer) ([]byte, error) {
+	publicKeyDER, err := MarshalPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkKeyUsage(template, publicKey); err != nil {
+		return nil, err
+	}
+	if signerKey := signer.Public(); parent == nil && (signerKey.Algorithm != publicKey.Algorithm || !bytes.Equal(signerKey.Bytes, publicKey.Bytes)) {
+		return nil, fmt.Errorf("self-signed certificates must be signed by the subject key")
+	}
+	return createCertificate(template, parent, publicKeyDER, keyIdentifier(publicKey.Bytes), signer)
+}
+
+// createCertificate creates a DER certificate for the DER SubjectPublicKeyInfo
+// publicKeyDER from template, signed by signer
+func createCertificate(template *x509.Certificate, parent *x509.Certificate, publicKeyDER []byte, subjectKeyId []byte, signer Signer) ([]byte, error) {
+	if template.SerialNumber == nil || template.SerialNumber.Sign() <= 0 {
+		return nil, fmt.Errorf("certificate serial number must be positive")
+	}
+	if template.SerialNumber.BitLen() > 8*20-1 {
+		return nil, fmt.Errorf("certificate serial number must be at most 20 bytes")
+	}
+	if !template.NotAfter.After(template.NotBefore) {
+		return nil, fmt.Errorf("certificate notAfter must be after notBefore")
+	}
 
-	folderName, err := createFolder()
+	signerKey := signer.Public()
+	signatureAlgorithm, err := lookupSignatureAlgorithm(signerKey.Algorithm)
 	if err != nil {
-		fmt.Println("Error creating folder:", err)
-		return "", ""
-	}
-	defer cleanUp(folderName)
-
-	cmd := exec.Command(
-		"openssl",
-		"req",
-		"-x509",
-		"-new",
-		"-newkey",
-		algorithm,
-		"-keyout",
-		folderName+"/tls.key",
-		"-out",
-		folderName+"/tls.crt",
-		"-nodes",
-		"-days",
-		strconv.Itoa(days),
-		"-subj",
-		"/CN="+domain,
-	)
-
-	// Capture stderr for better error messages
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-
-	err = cmd.Run()
+		return nil, err
+	}
+
+	subject := template.RawSubject
+	if len(subject) == 0 {
+		if subject, err = asn1.Marshal(template.Subject.ToRDNSequence()); err != nil {
+			return nil, fmt.Errorf("failed to marshal subject: %w", err)
+		}
+	}
+
+	// A self-signed certificate is its own issuer
+	issuer := subject
+	authorityKey := template.AuthorityKeyId
+	if parent != nil {
+		parentKey, err := ParsePublicKey(parent.RawSubjectPublicKeyInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse issuer public key: %w", err)
+		}
+		if parentKey.Algorithm != signerKey.Algorithm || !bytes.Equal(parentKey.Bytes, signerKey.Bytes) {
+			return nil, fmt.Errorf("signer key does not match the issuer certificate")
+		}
+		issuer = parent.RawSubject
+		if len(parent.SubjectKeyId) > 0 {
+			authorityKey = parent.SubjectKeyId
+		}
+	}
+
+	if len(template.SubjectKeyId) > 0 {
+		subjectKeyId = template.SubjectKeyId
+	}
+
+	extensions, err := buildExtensions(template, subjectKeyId, authorityKey, bytes.Equal(subject, emptySubject))
 	if err != nil {
-		fmt.Printf("Error executing openssl: %v\nStderr: %s\n", err, stderr.String())
-		return "", ""
+		return nil, err
 	}
 
-	certificateFile, err := readFile(folderName + "/tls.crt")
+	algorithmIdentifier := pkix.AlgorithmIdentifier{Algorithm: signatureAlgorithm.oid}
+	tbs, err := asn1.Marshal(tbsCertificate{
+		Version:            2,
+		SerialNumber:       template.SerialNumber,
+		SignatureAlgorithm: algorithmIdentifier,
+		Issuer:             asn1.RawValue{FullBytes: issuer},
+		Validity:           validity{NotBefore: template.NotBefore.UTC(), NotAfter: template.NotAfter.UTC()},
+		Subject:            asn1.RawValue{FullBytes: subject},
+		PublicKey:          asn1.RawValue{FullBytes: publicKeyDER},
+		Extensions:         extensions,
+	})
 	if err != nil {
-		fmt.Println("Error reading file:", err)
-		return "", ""
+		return nil, fmt.Errorf("failed to marshal certificate: %w", err)
 	}
 
-	keyFile, err := readFile(folderName + "/tls.key")
+	signature, err := signer.Sign(tbs)
 	if err != nil {
-		fmt.Println("Error reading file:", err)
-		return "", ""
+		return nil, fmt.Errorf("failed to sign certificate: %w", err)
 	}
 
-	return certificateFile, keyFile
+	return asn1.Marshal(signedCertificate{
+		TBSCertificate:     asn1.RawValue{FullBytes: tbs},
+		SignatureAlgorithm: algorithmIdentifier,
+		SignatureValue:     asn1.BitString{Bytes: signature, BitLength: 8 * len(signature)},
+	})
 }
 
-func createFolder() (string, error) {
+// checkKeyUsage checks the usages of template against the certified key. An
+// ML-KEM key is only certified for key encipherment (draft-ietf-lamps-kyber-
+// certificates), and a signature key cannot be used for encipherment or key
+// agreement.
+func checkKeyUsage(template *x509.Certificate, publicKey PublicKey) error {
+	if !publicKey.IsKEM() {
+		if template.KeyUsage&(x509.KeyUsageKeyEncipherment|x509.KeyUsageDataEncipherment|x509.KeyUsageKeyAgreement) != 0 {
+			return fmt.Errorf("%s keys cannot be used for encipherment or key agreement", publicKey.Algorithm)
+		}
+		return nil
+	}
+	if template.IsCA {
+		return fmt.Errorf("%s keys cannot be certified as a CA", publicKey.Algorithm)
+	}
+	if template.KeyUsage != x509.KeyUsageKeyEncipherment {
+		return fmt.Errorf("%s certificates must have the key encipherment usage only", publicKey.Algorithm)
+	}
+	return nil
+}
 
-	newUUID := uuid.New().String()
+// emptySubject is the DER encoding of an empty distinguished name
+var emptySubject = []byte{0x30, 0x00}
 
-	folderName := "/tmp/qubesec/certificates/" + newUUID
+// keyIdentifier derives a key identifier from the subjectPublicKey bits with
+// truncated SHA-256 (RFC 7093 section 2, method 1)
+func keyIdentifier(publicKey []byte) []byte {
+	hash := sha256.Sum256(publicKey)
+	return hash[:20]
+}
 
-	err := os.MkdirAll(folderName, 0755)
+// CheckCertificateSignature verifies the signature of cert with the issuer's
+// public key.
+func CheckCertificateSignature(provider string, cert *x509.Certificate, issuerKey PublicKey, ctx context.Context) error {
+	var signed signedCertificate
+	if _, err := asn1.Unmarshal(cert.Raw, &signed); err != nil {
+		return fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	a, err := lookupSignatureOID(signed.SignatureAlgorithm.Algorithm)
 	if err != nil {
-		fmt.Println("Error creating folder:", err)
-		return "", err
+		return err
 	}
+	if a.name != issuerKey.Algorithm {
+		return fmt.Errorf("certificate is signed with %s, the issuer key is %s", a.name, issuerKey.Algorithm)
+	}
+	return CheckSignature(provider, issuerKey, cert.RawTBSCertificate, cert.Signature, ctx)
+}
 
-	return folderName, nil
+// NewSerialNumber returns a random positive 128-bit serial number.
+func NewSerialNumber(random io.Reader) (*big.Int, error) {
+	serial, err := rand.Int(random, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	// Zero is not a valid serial number
+	return serial.Add(serial, big.NewInt(1)), nil
 }
 
-func cleanUp(folderPath string) error {
-	// Get the list of files and subdirectories in the folder
-	files, err := filepath.Glob(filepath.Join(folderPath, "*"))
+// GenerateKey generates a key pair with the named crypto provider and returns
+// a Signer for it together with the raw private key.
+func GenerateKey(provider string, algorithm string, ctx context.Context) (Signer, []byte, error) {
+	log := log.FromContext(ctx)
+
+	if _, err := lookupSignatureAlgorithm(algorithm); err != nil {
+		return nil, nil, err
+	}
+
+	// Resolve crypto provider
+	cryptoProvider, err := cryptoprovider.ForSignature(provider, algorithm, ctx)
 	if err != nil {
-		return err
+		log.Error(err, "Failed to resolve crypto provider")
+		return nil, nil, err
 	}
 
-	// Delete files in the folder
-	for _, file := range files {
-		if err := os.RemoveAll(file); err != nil {
-			return err
+	// Generate key pair
+	publicKey, privateKey, err := cryptoProvider.GenerateSignatureKeyPair(algorithm, nil)
+	if err != nil {
+		log.Error(err, "Failed to generate key pair", "provider", cryptoProvider.Name())
+		return nil, nil, err
+	}
+
+	signer, err := NewSigner(cryptoProvider.Name(), algorithm, publicKey, privateKey, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return signer, privateKey, nil
+}
+
+// Sign creates a DER certificate for the key of subject from template. The
+// certificate is signed by parentSigner on behalf of parent, or self-signed
+// by subject if parent is nil. A random serial number is used if the template
+// has none.
+func Sign(template *x509.Certificate, subject Signer, parent *x509.Certificate, parentSigner Signer) ([]byte, error) {
+	if parent != nil {
+		return SignPublicKey(template, subject.Public(), parent, parentSigner)
+	}
+
+	if template.SerialNumber == nil {
+		serialNumber, err := NewSerialNumber(rand.Reader)
+		if err != nil {
+			return nil, err
 		}
+		template.SerialNumber = serialNumber
 	}
+	return CreateCertificate(template, nil, subject.Public(), subject)
+}
 
-	// Delete the folder itself
-	if err := os.Remove(folderPath); err != nil {
-		return err
+// SignPublicKey creates a DER certificate for publicKey from template, signed
+// by parentSigner on behalf of parent. Unlike Sign it needs no private key for
+// publicKey, so it also certifies keys that cannot sign, such as ML-KEM keys.
+// A random serial number is used if the template has none.
+func SignPublicKey(template *x509.Certificate, publicKey PublicKey, parent *x509.Certificate, parentSigner Signer) ([]byte, error) {
+	if template.SerialNumber == nil {
+		serialNumber, err := NewSerialNumber(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		template.SerialNumber = serialNumber
 	}
 
-	return nil
+	if err := ApplyIssuerConstraints(parent, template); err != nil {
+		return nil, err
+	}
+	return CreateCertificate(template, parent, publicKey, parentSigner)
 }
 
-func readFile(filename string) (string, error) {
-	content, err := os.ReadFile(filename)
+// EncodePrivateKeyPEM returns the PEM encoded PKCS#8 form of a raw private key.
+func EncodePrivateKeyPEM(algorithm string, privateKey []byte) (string, error) {
+	der, err := MarshalPrivateKey(algorithm, privateKey)
 	if err != nil {
 		return "", err
 	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
 
-	return string(content), nil
+// EncodeCertificatePEM returns the PEM encoding of DER certificates.
+func EncodeCertificatePEM(certificates ...[]byte) string {
+	var out bytes.Buffer
+	for _, der := range certificates {
+		_ = pem.Encode(&out, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	return out.String()
 }
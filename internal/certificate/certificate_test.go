@@ -0,0 +1,404 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
+)
+
+// signatureAlgorithms are the algorithms the Go provider signs with
+var signatureAlgorithms = []string{
+	"ML-DSA-44",
+	"ML-DSA-65",
+	"ML-DSA-87",
+	"ML-DSA-44-ECDSA-P256-SHA256",
+	"ML-DSA-65-ECDSA-P256-SHA512",
+	"ML-DSA-65-ECDSA-P384-SHA512",
+	"ML-DSA-87-ECDSA-P384-SHA512",
+}
+
+// newCA returns a self-signed CA certificate and its signer
+func newCA(t *testing.T, algorithm string) (*x509.Certificate, Signer) {
+	t.Helper()
+	signer, _, err := GenerateKey(cryptoprovider.Go, algorithm, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	der, err := Sign(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, signer, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ca, signer
+}
+
+// newLeaf returns a leaf certificate signed by ca, its signer and its raw
+// private key
+func newLeaf(t *testing.T, algorithm string, ca *x509.Certificate, caSigner Signer) (*x509.Certificate, Signer, []byte) {
+	t.Helper()
+	signer, privateKey, err := GenerateKey(cryptoprovider.Go, algorithm, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	der, err := Sign(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: "leaf.example.com"},
+		DNSNames:    []string{"leaf.example.com"},
+		NotBefore:   now.Add(-time.Minute),
+		NotAfter:    now.Add(time.Hour),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, signer, ca, caSigner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf, signer, privateKey
+}
+
+func TestSignRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	for _, algorithm := range signatureAlgorithms {
+		t.Run(algorithm, func(t *testing.T) {
+			ca, caSigner := newCA(t, algorithm)
+			leaf, leafSigner, _ := newLeaf(t, algorithm, ca, caSigner)
+
+			if err := CheckCertificateSignature(cryptoprovider.Go, ca, caSigner.Public(), ctx); err != nil {
+				t.Errorf("self-signed CA: %v", err)
+			}
+			if err := CheckCertificateSignature(cryptoprovider.Go, leaf, caSigner.Public(), ctx); err != nil {
+				t.Errorf("leaf: %v", err)
+			}
+
+			// The leaf is not signed by its own key
+			if err := CheckCertificateSignature(cryptoprovider.Go, leaf, leafSigner.Public(), ctx); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("leaf checked with its own key: err = %v, want %v", err, ErrInvalidSignature)
+			}
+
+			// Any change to the signed data breaks the signature
+			tampered := *leaf
+			tampered.RawTBSCertificate = bytes.Clone(leaf.RawTBSCertificate)
+			tampered.RawTBSCertificate[len(tampered.RawTBSCertificate)-1] ^= 1
+			if err := CheckSignature(cryptoprovider.Go, caSigner.Public(), tampered.RawTBSCertificate, leaf.Signature, ctx); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("tampered leaf: err = %v, want %v", err, ErrInvalidSignature)
+			}
+
+			publicKey, err := ParsePublicKey(leaf.RawSubjectPublicKeyInfo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := leafSigner.Public(); publicKey.Algorithm != want.Algorithm || !bytes.Equal(publicKey.Bytes, want.Bytes) {
+				t.Errorf("leaf public key = %s %x, want %s %x", publicKey.Algorithm, publicKey.Bytes, want.Algorithm, want.Bytes)
+			}
+			if !bytes.Equal(leaf.RawIssuer, ca.RawSubject) {
+				t.Error("leaf issuer does not match the CA subject")
+			}
+			if !bytes.Equal(leaf.AuthorityKeyId, ca.SubjectKeyId) {
+				t.Error("leaf authority key identifier does not match the CA subject key identifier")
+			}
+			if !ca.IsCA || leaf.IsCA {
+				t.Errorf("IsCA: CA = %t, leaf = %t", ca.IsCA, leaf.IsCA)
+			}
+			if len(leaf.DNSNames) != 1 || leaf.DNSNames[0] != "leaf.example.com" {
+				t.Errorf("leaf DNS names = %v", leaf.DNSNames)
+			}
+		})
+	}
+}
+
+// TestCompositeComponents checks that both components of a composite
+// signature are verified: a signature whose ML-DSA or ECDSA half comes from
+// another key is rejected.
+func TestCompositeComponents(t *testing.T) {
+	ctx := context.Background()
+	message := []byte("composite")
+
+	for _, algorithm := range signatureAlgorithms[3:] {
+		t.Run(algorithm, func(t *testing.T) {
+			signer, _, err := GenerateKey(cryptoprovider.Go, algorithm, ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			other, _, err := GenerateKey(cryptoprovider.Go, algorithm, ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signature, err := signer.Sign(message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			otherSignature, err := other.Sign(message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := CheckSignature(cryptoprovider.Go, signer.Public(), message, signature, ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			// The ML-DSA signature has a fixed size and comes first
+			mldsaSize := map[string]int{"ML-DSA-44": 2420, "ML-DSA-65": 3309, "ML-DSA-87": 4627}[algorithm[:9]]
+			tests := map[string][]byte{
+				"foreign ML-DSA component": append(bytes.Clone(otherSignature[:mldsaSize]), signature[mldsaSize:]...),
+				"foreign ECDSA component":  append(bytes.Clone(signature[:mldsaSize]), otherSignature[mldsaSize:]...),
+				"truncated":                signature[:mldsaSize],
+			}
+			for name, forged := range tests {
+				if err := CheckSignature(cryptoprovider.Go, signer.Public(), message, forged, ctx); err == nil {
+					t.Errorf("%s: signature verified", name)
+				}
+			}
+		})
+	}
+}
+
+func TestSignKEMPublicKey(t *testing.T) {
+	ca, caSigner := newCA(t, "ML-DSA-65")
+	provider, err := cryptoprovider.ForKEM(cryptoprovider.Go, "ML-KEM-768", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, _, err := provider.GenerateKEMKeyPair("ML-KEM-768", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kemKey := PublicKey{Algorithm: "ML-KEM-768", Bytes: publicKey}
+
+	now := time.Now()
+	tests := []struct {
+		name     string
+		template x509.Certificate
+		wantErr  bool
+	}{
+		{
+			name:     "key encipherment",
+			template: x509.Certificate{KeyUsage: x509.KeyUsageKeyEncipherment},
+		},
+		{
+			name:     "digital signature",
+			template: x509.Certificate{KeyUsage: x509.KeyUsageDigitalSignature},
+			wantErr:  true,
+		},
+		{
+			name:     "CA",
+			template: x509.Certificate{KeyUsage: x509.KeyUsageKeyEncipherment, BasicConstraintsValid: true, IsCA: true},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := tt.template
+			template.Subject = pkix.Name{CommonName: "kem"}
+			template.NotBefore = now
+			template.NotAfter = now.Add(time.Hour)
+
+			der, err := SignPublicKey(&template, kemKey, ca, caSigner)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := CheckCertificateSignature(cryptoprovider.Go, cert, caSigner.Public(), context.Background()); err != nil {
+				t.Error(err)
+			}
+			parsed, err := ParsePublicKey(cert.RawSubjectPublicKeyInfo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !parsed.IsKEM() || !bytes.Equal(parsed.Bytes, publicKey) {
+				t.Errorf("certified key = %s, want the ML-KEM-768 key", parsed.Algorithm)
+			}
+		})
+	}
+}
+
+func TestSignPublicKeyIssuerConstraints(t *testing.T) {
+	ca, caSigner := newCA(t, "ML-DSA-44")
+	leaf, _, _ := newLeaf(t, "ML-DSA-44", ca, caSigner)
+	subject, _, err := GenerateKey(cryptoprovider.Go, "ML-DSA-44", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := GenerateKey(cryptoprovider.Go, "ML-DSA-44", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	tests := []struct {
+		name         string
+		parent       *x509.Certificate
+		parentSigner Signer
+		notBefore    time.Time
+	}{
+		{name: "parent is not a CA", parent: leaf, parentSigner: caSigner, notBefore: now},
+		{name: "signer does not match the parent", parent: ca, parentSigner: other, notBefore: now},
+		{name: "parent expired", parent: ca, parentSigner: caSigner, notBefore: ca.NotAfter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := &x509.Certificate{
+				Subject:   pkix.Name{CommonName: "subject"},
+				NotBefore: tt.notBefore,
+				NotAfter:  tt.notBefore.Add(time.Hour),
+			}
+			if _, err := SignPublicKey(template, subject.Public(), tt.parent, tt.parentSigner); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	// The validity is cut to the parent's
+	template := &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "subject"},
+		NotBefore: now,
+		NotAfter:  ca.NotAfter.Add(time.Hour),
+	}
+	if _, err := SignPublicKey(template, subject.Public(), ca, caSigner); err != nil {
+		t.Fatal(err)
+	}
+	if !template.NotAfter.Equal(ca.NotAfter) {
+		t.Errorf("notAfter = %s, want the CA's %s", template.NotAfter, ca.NotAfter)
+	}
+}
+
+func TestPrivateKeyRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	for _, algorithm := range append(signatureAlgorithms, "ML-KEM-512", "ML-KEM-768", "ML-KEM-1024") {
+		t.Run(algorithm, func(t *testing.T) {
+			var privateKey []byte
+			if SupportedKEM(algorithm) {
+				provider, err := cryptoprovider.ForKEM(cryptoprovider.Go, algorithm, ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, privateKey, err = provider.GenerateKEMKeyPair(algorithm, nil); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				var err error
+				if _, privateKey, err = GenerateKey(cryptoprovider.Go, algorithm, ctx); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			der, err := MarshalPrivateKey(algorithm, privateKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			name, parsed, err := ParsePrivateKey(der)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != algorithm || !bytes.Equal(parsed, privateKey) {
+				t.Errorf("ParsePrivateKey = %s, %d bytes, want %s, %d bytes", name, len(parsed), algorithm, len(privateKey))
+			}
+
+			if _, _, err := ParsePrivateKey(append(der, 0)); err == nil {
+				t.Error("trailing data was accepted")
+			}
+		})
+	}
+}
+
+func TestParsePrivateKeyBothForm(t *testing.T) {
+	for _, algorithm := range []string{"ML-KEM-512", "ML-KEM-768", "ML-KEM-1024"} {
+		t.Run(algorithm, func(t *testing.T) {
+			a, err := lookupAlgorithm(algorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			seed := bytes.Repeat([]byte{0x5a}, a.seedSize)
+			expanded, err := cryptoprovider.ExpandMLKEMKey(algorithm, seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tampered := bytes.Clone(expanded)
+			tampered[0] ^= 1
+
+			marshal := func(both bothPrivateKey) []byte {
+				key, err := asn1.Marshal(both)
+				if err != nil {
+					t.Fatal(err)
+				}
+				der, err := asn1.Marshal(oneAsymmetricKey{Algorithm: pkix.AlgorithmIdentifier{Algorithm: a.oid}, PrivateKey: key})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return der
+			}
+
+			name, parsed, err := ParsePrivateKey(marshal(bothPrivateKey{Seed: seed, ExpandedKey: expanded}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != algorithm || !bytes.Equal(parsed, seed) {
+				t.Errorf("ParsePrivateKey = %s, %d bytes, want %s and the seed", name, len(parsed), algorithm)
+			}
+
+			tests := []struct {
+				name string
+				both bothPrivateKey
+			}{
+				{name: "expanded key of another seed", both: bothPrivateKey{Seed: seed, ExpandedKey: tampered}},
+				{name: "truncated expanded key", both: bothPrivateKey{Seed: seed, ExpandedKey: expanded[:len(expanded)-1]}},
+				{name: "short seed", both: bothPrivateKey{Seed: seed[:32], ExpandedKey: expanded}},
+			}
+			for _, tt := range tests {
+				if _, _, err := ParsePrivateKey(marshal(tt.both)); err == nil {
+					t.Errorf("%s: expected an error", tt.name)
+				}
+			}
+		})
+	}
+}
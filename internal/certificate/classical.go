@@ -0,0 +1,168 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+)
+
+// classicalCurves maps the classical algorithms of companion certificates to
+// their curves
+var classicalCurves = map[string]elliptic.Curve{
+	"ECDSA-P256": elliptic.P256(),
+	"ECDSA-P384": elliptic.P384(),
+}
+
+// GenerateClassicalKey generates a classical key for a companion certificate.
+func GenerateClassicalKey(algorithm string) (crypto.Signer, error) {
+	curve, ok := classicalCurves[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported classical algorithm %q", algorithm)
+	}
+	return ecdsa.GenerateKey(curve, rand.Reader)
+}
+
+// ClassicalAlgorithm returns the classical algorithm name of a public key, or
+// an empty string if it is not one.
+func ClassicalAlgorithm(publicKey crypto.PublicKey) string {
+	key, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return ""
+	}
+	for name, curve := range classicalCurves {
+		if key.Curve == curve {
+			return name
+		}
+	}
+	return ""
+}
+
+// ClassicalTemplate returns a template for the classical companion of a
+// certificate, with the same subject, names, usages, basic constraints and
+// validity.
+func ClassicalTemplate(cert *x509.Certificate) *x509.Certificate {
+	return &x509.Certificate{
+		RawSubject:            cert.RawSubject,
+		NotBefore:             cert.NotBefore,
+		NotAfter:              cert.NotAfter,
+		KeyUsage:              cert.KeyUsage,
+		ExtKeyUsage:           cert.ExtKeyUsage,
+		UnknownExtKeyUsage:    cert.UnknownExtKeyUsage,
+		BasicConstraintsValid: cert.BasicConstraintsValid,
+		IsCA:                  cert.IsCA,
+		MaxPathLen:            cert.MaxPathLen,
+		MaxPathLenZero:        cert.MaxPathLenZero,
+		DNSNames:              cert.DNSNames,
+		EmailAddresses:        cert.EmailAddresses,
+		IPAddresses:           cert.IPAddresses,
+		URIs:                  cert.URIs,
+	}
+}
+
+// SignClassical creates a DER certificate for key from template with
+// crypto/x509. The certificate is signed by parentKey on behalf of parent,
+// or self-signed by key if parent is nil. A random serial number is used if
+// the template has none.
+func SignClassical(template *x509.Certificate, key crypto.Signer, parent *x509.Certificate, parentKey crypto.Signer) ([]byte, error) {
+	if template.SerialNumber == nil {
+		serialNumber, err := NewSerialNumber(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		template.SerialNumber = serialNumber
+	}
+
+	if parent == nil {
+		return x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	}
+	if err := ApplyIssuerConstraints(parent, template); err != nil {
+		return nil, err
+	}
+	return x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+}
+
+// CreateClassicalKeyCertificate creates a DER certificate for an RSA, ECDSA or
+// Ed25519 public key from template, signed by the post-quantum signer of
+// parent. It certifies keys generated by clients such as cert-manager under a
+// post-quantum CA.
+func CreateClassicalKeyCertificate(template *x509.Certificate, parent *x509.Certificate, publicKey crypto.PublicKey, signer Signer) ([]byte, error) {
+	if parent == nil {
+		return nil, fmt.Errorf("certificates for classical keys must be signed by an issuer")
+	}
+	if err := checkClassicalPublicKey(publicKey); err != nil {
+		return nil, err
+	}
+
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(publicKeyDER, &spki); err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return createCertificate(template, parent, publicKeyDER, keyIdentifier(spki.PublicKey.Bytes), signer)
+}
+
+// checkClassicalPublicKey checks that a public key is an RSA, ECDSA or Ed25519
+// key
+func checkClassicalPublicKey(publicKey crypto.PublicKey) error {
+	switch publicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return nil
+	}
+	return fmt.Errorf("unsupported public key type %T, use ML-DSA, SLH-DSA, RSA, ECDSA or Ed25519", publicKey)
+}
+
+// EncodeClassicalKeyPEM returns the PEM encoded PKCS#8 form of a classical key.
+func EncodeClassicalKeyPEM(key crypto.Signer) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// ParseClassicalKeyPEM decodes a PEM encoded PKCS#8 classical key.
+func ParseClassicalKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("no PKCS#8 private key found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok || ClassicalAlgorithm(signer.Public()) == "" {
+		return nil, fmt.Errorf("unsupported classical private key")
+	}
+	return signer, nil
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var (
+	oidExtensionCRLNumber  = asn1.ObjectIdentifier{2, 5, 29, 20}
+	oidExtensionReasonCode = asn1.ObjectIdentifier{2, 5, 29, 21}
+)
+
+// ReasonCodes maps the RFC 5280 CRLReason names to their codes
+var ReasonCodes = map[string]int{
+	"Unspecified":          0,
+	"KeyCompromise":        1,
+	"CACompromise":         2,
+	"AffiliationChanged":   3,
+	"Superseded":           4,
+	"CessationOfOperation": 5,
+	"CertificateHold":      6,
+	"PrivilegeWithdrawn":   9,
+	"AACompromise":         10,
+}
+
+// tbsCertList is the RFC 5280 TBSCertList structure
+type tbsCertList struct {
+	Version             int
+	Signature           pkix.AlgorithmIdentifier
+	Issuer              asn1.RawValue
+	ThisUpdate          time.Time
+	NextUpdate          time.Time
+	RevokedCertificates []revokedCertificate `asn1:"optional,omitempty"`
+	Extensions          []pkix.Extension     `asn1:"optional,omitempty,explicit,tag:0"`
+}
+
+type revokedCertificate struct {
+	SerialNumber   *big.Int
+	RevocationTime time.Time
+	Extensions     []pkix.Extension `asn1:"optional,omitempty"`
+}
+
+// CreateRevocationList creates a DER v2 CRL from template, signed by signer
+// on behalf of issuer.
+//
+// The template fields used are Number, ThisUpdate, NextUpdate and
+// RevokedCertificateEntries, of which SerialNumber, RevocationTime and
+// ReasonCode are used. A reason code of 0 (unspecified) is omitted.
+func CreateRevocationList(template *x509.RevocationList, issuer *x509.Certificate, signer Signer) ([]byte, error) {
+	if template.Number == nil || template.Number.Sign() < 0 {
+		return nil, fmt.Errorf("CRL number must be non-negative")
+	}
+	if template.Number.BitLen() > 8*20-1 {
+		return nil, fmt.Errorf("CRL number must be at most 20 bytes")
+	}
+	if !template.NextUpdate.After(template.ThisUpdate) {
+		return nil, fmt.Errorf("CRL nextUpdate must be after thisUpdate")
+	}
+	if issuer.KeyUsage != 0 && issuer.KeyUsage&x509.KeyUsageCRLSign == 0 {
+		return nil, fmt.Errorf("issuer certificate does not allow CRL signing")
+	}
+
+	signerKey := signer.Public()
+	issuerKey, err := ParsePublicKey(issuer.RawSubjectPublicKeyInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse issuer public key: %w", err)
+	}
+	if issuerKey.Algorithm != signerKey.Algorithm || !bytes.Equal(issuerKey.Bytes, signerKey.Bytes) {
+		return nil, fmt.Errorf("signer key does not match the issuer certificate")
+	}
+	signatureAlgorithm, err := lookupSignatureAlgorithm(signerKey.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	var revoked []revokedCertificate
+	for _, entry := range template.RevokedCertificateEntries {
+		var extensions []pkix.Extension
+		if entry.ReasonCode != 0 {
+			reason, err := asn1.Marshal(asn1.Enumerated(entry.ReasonCode))
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal reason code: %w", err)
+			}
+			extensions = append(extensions, pkix.Extension{Id: oidExtensionReasonCode, Value: reason})
+		}
+		revoked = append(revoked, revokedCertificate{
+			SerialNumber:   entry.SerialNumber,
+			RevocationTime: entry.RevocationTime.UTC(),
+			Extensions:     extensions,
+		})
+	}
+
+	authorityKey := issuer.SubjectKeyId
+	if len(authorityKey) == 0 {
+		authorityKey = keyIdentifier(issuerKey.Bytes)
+	}
+	authorityKeyExtension, err := asn1.Marshal(authorityKeyId{Id: authorityKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal authority key identifier: %w", err)
+	}
+	numberExtension, err := asn1.Marshal(template.Number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal CRL number: %w", err)
+	}
+
+	algorithmIdentifier := pkix.AlgorithmIdentifier{Algorithm: signatureAlgorithm.oid}
+	tbs, err := asn1.Marshal(tbsCertList{
+		Version:             1,
+		Signature:           algorithmIdentifier,
+		Issuer:              asn1.RawValue{FullBytes: issuer.RawSubject},
+		ThisUpdate:          template.ThisUpdate.UTC(),
+		NextUpdate:          template.NextUpdate.UTC(),
+		RevokedCertificates: revoked,
+		Extensions: []pkix.Extension{
+			{Id: oidExtensionAuthorityKeyId, Value: authorityKeyExtension},
+			{Id: oidExtensionCRLNumber, Value: numberExtension},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal CRL: %w", err)
+	}
+
+	signature, err := signer.Sign(tbs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign CRL: %w", err)
+	}
+
+	// A CRL has the same outer structure as a certificate
+	return asn1.Marshal(signedCertificate{
+		TBSCertificate:     asn1.RawValue{FullBytes: tbs},
+		SignatureAlgorithm: algorithmIdentifier,
+		SignatureValue:     asn1.BitString{Bytes: signature, BitLength: 8 * len(signature)},
+	})
+}
+
+// CheckRevocationListSignature verifies the signature of crl with the
+// issuer's public key.
+func CheckRevocationListSignature(provider string, crl *x509.RevocationList, issuerKey PublicKey, ctx context.Context) error {
+	var signed signedCertificate
+	if _, err := asn1.Unmarshal(crl.Raw, &signed); err != nil {
+		return fmt.Errorf("failed to parse CRL: %w", err)
+	}
+	a, err := lookupSignatureOID(signed.SignatureAlgorithm.Algorithm)
+	if err != nil {
+		return err
+	}
+	if a.name != issuerKey.Algorithm {
+		return fmt.Errorf("CRL is signed with %s, the issuer key is %s", a.name, issuerKey.Algorithm)
+	}
+	return CheckSignature(provider, issuerKey, crl.RawTBSRevocationList, crl.Signature, ctx)
+}
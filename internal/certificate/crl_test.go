@@ -0,0 +1,149 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
+)
+
+func TestCreateRevocationList(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	for _, algorithm := range []string{"ML-DSA-44", "ML-DSA-87-ECDSA-P384-SHA512"} {
+		t.Run(algorithm, func(t *testing.T) {
+			ca, caSigner := newCA(t, algorithm)
+			entries := []x509.RevocationListEntry{
+				{SerialNumber: big.NewInt(1), RevocationTime: now.Add(-time.Hour), ReasonCode: ReasonCodes["KeyCompromise"]},
+				{SerialNumber: new(big.Int).Lsh(big.NewInt(1), 127), RevocationTime: now.Add(-time.Minute)},
+			}
+			der, err := CreateRevocationList(&x509.RevocationList{
+				Number:                    big.NewInt(42),
+				ThisUpdate:                now,
+				NextUpdate:                now.Add(time.Hour),
+				RevokedCertificateEntries: entries,
+			}, ca, caSigner)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			crl, err := x509.ParseRevocationList(der)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := CheckRevocationListSignature(cryptoprovider.Go, crl, caSigner.Public(), ctx); err != nil {
+				t.Error(err)
+			}
+			other, _, err := GenerateKey(cryptoprovider.Go, algorithm, ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := CheckRevocationListSignature(cryptoprovider.Go, crl, other.Public(), ctx); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("CRL checked with another key: err = %v, want %v", err, ErrInvalidSignature)
+			}
+
+			if crl.Number.Cmp(big.NewInt(42)) != 0 {
+				t.Errorf("number = %s, want 42", crl.Number)
+			}
+			if !bytes.Equal(crl.RawIssuer, ca.RawSubject) {
+				t.Error("CRL issuer does not match the CA subject")
+			}
+			if !bytes.Equal(crl.AuthorityKeyId, ca.SubjectKeyId) {
+				t.Error("CRL authority key identifier does not match the CA subject key identifier")
+			}
+			if !crl.ThisUpdate.Equal(now) || !crl.NextUpdate.Equal(now.Add(time.Hour)) {
+				t.Errorf("thisUpdate = %s, nextUpdate = %s", crl.ThisUpdate, crl.NextUpdate)
+			}
+			if len(crl.RevokedCertificateEntries) != len(entries) {
+				t.Fatalf("%d revoked certificates, want %d", len(crl.RevokedCertificateEntries), len(entries))
+			}
+			for i, got := range crl.RevokedCertificateEntries {
+				want := entries[i]
+				if got.SerialNumber.Cmp(want.SerialNumber) != 0 || !got.RevocationTime.Equal(want.RevocationTime) || got.ReasonCode != want.ReasonCode {
+					t.Errorf("entry %d = %s %s reason %d, want %s %s reason %d", i,
+						got.SerialNumber, got.RevocationTime, got.ReasonCode, want.SerialNumber, want.RevocationTime, want.ReasonCode)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateRevocationListErrors(t *testing.T) {
+	ca, caSigner := newCA(t, "ML-DSA-44")
+	leaf, leafSigner, _ := newLeaf(t, "ML-DSA-44", ca, caSigner)
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		template x509.RevocationList
+		issuer   *x509.Certificate
+		signer   Signer
+	}{
+		{
+			name:     "no number",
+			template: x509.RevocationList{ThisUpdate: now, NextUpdate: now.Add(time.Hour)},
+			issuer:   ca,
+			signer:   caSigner,
+		},
+		{
+			name:     "negative number",
+			template: x509.RevocationList{Number: big.NewInt(-1), ThisUpdate: now, NextUpdate: now.Add(time.Hour)},
+			issuer:   ca,
+			signer:   caSigner,
+		},
+		{
+			name:     "number over 20 bytes",
+			template: x509.RevocationList{Number: new(big.Int).Lsh(big.NewInt(1), 159), ThisUpdate: now, NextUpdate: now.Add(time.Hour)},
+			issuer:   ca,
+			signer:   caSigner,
+		},
+		{
+			name:     "nextUpdate before thisUpdate",
+			template: x509.RevocationList{Number: big.NewInt(1), ThisUpdate: now, NextUpdate: now.Add(-time.Hour)},
+			issuer:   ca,
+			signer:   caSigner,
+		},
+		{
+			name:     "issuer without CRL sign",
+			template: x509.RevocationList{Number: big.NewInt(1), ThisUpdate: now, NextUpdate: now.Add(time.Hour)},
+			issuer:   leaf,
+			signer:   leafSigner,
+		},
+		{
+			name:     "signer does not match the issuer",
+			template: x509.RevocationList{Number: big.NewInt(1), ThisUpdate: now, NextUpdate: now.Add(time.Hour)},
+			issuer:   ca,
+			signer:   leafSigner,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CreateRevocationList(&tt.template, tt.issuer, tt.signer); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/bits"
+	"net"
+)
+
+var (
+	oidExtensionSubjectKeyId          = asn1.ObjectIdentifier{2, 5, 29, 14}
+	oidExtensionKeyUsage              = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtensionSubjectAltName        = asn1.ObjectIdentifier{2, 5, 29, 17}
+	oidExtensionBasicConstraints      = asn1.ObjectIdentifier{2, 5, 29, 19}
+	oidExtensionCRLDistributionPoints = asn1.ObjectIdentifier{2, 5, 29, 31}
+	oidExtensionAuthorityKeyId        = asn1.ObjectIdentifier{2, 5, 29, 35}
+	oidExtensionExtendedKeyUsage      = asn1.ObjectIdentifier{2, 5, 29, 37}
+	oidExtensionAuthorityInfoAccess   = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 1}
+
+	oidAuthorityInfoAccessOCSP    = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1}
+	oidAuthorityInfoAccessIssuers = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 2}
+)
+
+// extKeyUsageOIDs maps the extended key usages of crypto/x509 to their OIDs
+var extKeyUsageOIDs = map[x509.ExtKeyUsage]asn1.ObjectIdentifier{
+	x509.ExtKeyUsageAny:             {2, 5, 29, 37, 0},
+	x509.ExtKeyUsageServerAuth:      {1, 3, 6, 1, 5, 5, 7, 3, 1},
+	x509.ExtKeyUsageClientAuth:      {1, 3, 6, 1, 5, 5, 7, 3, 2},
+	x509.ExtKeyUsageCodeSigning:     {1, 3, 6, 1, 5, 5, 7, 3, 3},
+	x509.ExtKeyUsageEmailProtection: {1, 3, 6, 1, 5, 5, 7, 3, 4},
+	x509.ExtKeyUsageTimeStamping:    {1, 3, 6, 1, 5, 5, 7, 3, 8},
+	x509.ExtKeyUsageOCSPSigning:     {1, 3, 6, 1, 5, 5, 7, 3, 9},
+}
+
+// GeneralName tags (RFC 5280 section 4.2.1.6)
+const (
+	nameTypeEmail = 1
+	nameTypeDNS   = 2
+	nameTypeURI   = 6
+	nameTypeIP    = 7
+)
+
+// basicConstraints is the RFC 5280 BasicConstraints structure
+type basicConstraints struct {
+	IsCA       bool `asn1:"optional"`
+	MaxPathLen int  `asn1:"optional,default:-1"`
+}
+
+// authorityKeyId is the RFC 5280 AuthorityKeyIdentifier structure
+type authorityKeyId struct {
+	Id []byte `asn1:"optional,tag:0"`
+}
+
+// accessDescription is an entry of the AuthorityInfoAccess extension
+type accessDescription struct {
+	Method   asn1.ObjectIdentifier
+	Location asn1.RawValue
+}
+
+// distributionPoint is an entry of the CRLDistributionPoints extension
+type distributionPoint struct {
+	DistributionPoint distributionPointName `asn1:"optional,tag:0"`
+}
+
+type distributionPointName struct {
+	FullName []asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+// buildExtensions returns the extensions for template, in the order
+// crypto/x509 writes them. Extensions in template.ExtraExtensions replace
+// generated extensions with the same OID.
+func buildExtensions(template *x509.Certificate, subjectKeyId, authorityKey []byte, subjectIsEmpty bool) ([]pkix.Extension, error) {
+	var extensions []pkix.Extension
+	add := func(id asn1.ObjectIdentifier, critical bool, value any) error {
+		for _, extra := range template.ExtraExtensions {
+			if extra.Id.Equal(id) {
+				return nil
+			}
+		}
+		der, err := asn1.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal extension %v: %w", id, err)
+		}
+		extensions = append(extensions, pkix.Extension{Id: id, Critical: critical, Value: der})
+		return nil
+	}
+
+	if template.KeyUsage != 0 {
+		if err := add(oidExtensionKeyUsage, true, keyUsageBitString(template.KeyUsage)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(template.ExtKeyUsage) > 0 || len(template.UnknownExtKeyUsage) > 0 {
+		var oids []asn1.ObjectIdentifier
+		for _, usage := range template.ExtKeyUsage {
+			oid, ok := extKeyUsageOIDs[usage]
+			if !ok {
+				return nil, fmt.Errorf("unsupported extended key usage %d", usage)
+			}
+			oids = append(oids, oid)
+		}
+		oids = append(oids, template.UnknownExtKeyUsage...)
+		if err := add(oidExtensionExtendedKeyUsage, false, oids); err != nil {
+			return nil, err
+		}
+	}
+
+	if template.BasicConstraintsValid {
+		maxPathLen := template.MaxPathLen
+		if maxPathLen == 0 && !template.MaxPathLenZero {
+			maxPathLen = -1
+		}
+		if err := add(oidExtensionBasicConstraints, true, basicConstraints{IsCA: template.IsCA, MaxPathLen: maxPathLen}); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(subjectKeyId) > 0 {
+		if err := add(oidExtensionSubjectKeyId, false, subjectKeyId); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(authorityKey) > 0 {
+		if err := add(oidExtensionAuthorityKeyId, false, authorityKeyId{Id: authorityKey}); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(template.OCSPServer) > 0 || len(template.IssuingCertificateURL) > 0 {
+		var access []accessDescription
+		for _, url := range template.OCSPServer {
+			access = append(access, accessDescription{Method: oidAuthorityInfoAccessOCSP, Location: uriName(url)})
+		}
+		for _, url := range template.IssuingCertificateURL {
+			access = append(access, accessDescription{Method: oidAuthorityInfoAccessIssuers, Location: uriName(url)})
+		}
+		if err := add(oidExtensionAuthorityInfoAccess, false, access); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(template.DNSNames) > 0 || len(template.EmailAddresses) > 0 || len(template.IPAddresses) > 0 || len(template.URIs) > 0 {
+		// The SAN extension is critical when it is the only identity (RFC 5280 4.2.1.6)
+		if err := add(oidExtensionSubjectAltName, subjectIsEmpty, subjectAltNames(template)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(template.CRLDistributionPoints) > 0 {
+		var points []distributionPoint
+		for _, url := range template.CRLDistributionPoints {
+			points = append(points, distributionPoint{
+				DistributionPoint: distributionPointName{FullName: []asn1.RawValue{uriName(url)}},
+			})
+		}
+		if err := add(oidExtensionCRLDistributionPoints, false, points); err != nil {
+			return nil, err
+		}
+	}
+
+	return append(extensions, template.ExtraExtensions...), nil
+}
+
+// keyUsageBitString encodes key usage bits, most significant bit first and
+// without trailing zero bits
+func keyUsageBitString(usage x509.KeyUsage) asn1.BitString {
+	b := []byte{bits.Reverse8(byte(usage)), bits.Reverse8(byte(usage >> 8))}
+	if b[1] == 0 {
+		b = b[:1]
+	}
+	return asn1.BitString{Bytes: b, BitLength: 8*len(b) - bits.TrailingZeros8(b[len(b)-1])}
+}
+
+// subjectAltNames returns the GeneralNames of the template's SANs
+func subjectAltNames(template *x509.Certificate) []asn1.RawValue {
+	var names []asn1.RawValue
+	for _, name := range template.DNSNames {
+		names = append(names, asn1.RawValue{Tag: nameTypeDNS, Class: asn1.ClassContextSpecific, Bytes: []byte(name)})
+	}
+	for _, email := range template.EmailAddresses {
+		names = append(names, asn1.RawValue{Tag: nameTypeEmail, Class: asn1.ClassContextSpecific, Bytes: []byte(email)})
+	}
+	for _, ip := range template.IPAddresses {
+		names = append(names, asn1.RawValue{Tag: nameTypeIP, Class: asn1.ClassContextSpecific, Bytes: normalizeIP(ip)})
+	}
+	for _, uri := range template.URIs {
+		names = append(names, uriName(uri.String()))
+	}
+	return names
+}
+
+// uriName returns a uniformResourceIdentifier GeneralName
+func uriName(uri string) asn1.RawValue {
+	return asn1.RawValue{Tag: nameTypeURI, Class: asn1.ClassContextSpecific, Bytes: []byte(uri)}
+}
+
+// normalizeIP returns the 4-byte form of IPv4 addresses
+func normalizeIP(ip net.IP) []byte {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4
+	}
+	return ip
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JKS format constants, as in sun.security.provider.JavaKeyStore
+const (
+	jksMagic             = 0xfeedfeed
+	jksVersion           = 2
+	jksPrivateKeyTag     = 1
+	jksTrustedCertTag    = 2
+	jksCertificateType   = "X.509"
+	jksIntegrityWhitener = "Mighty Aphrodite"
+)
+
+// oidJKSKeyProtector identifies the proprietary key protection of JKS
+var oidJKSKeyProtector = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 42, 2, 17, 1, 1}
+
+// EncodeJKS returns a Java keystore holding the DER PKCS#8 private key and its
+// DER certificate chain, leaf first, under alias. The key is protected with
+// password, which also protects the integrity of the keystore. JKS only
+// offers its original SHA-1 based protection.
+func EncodeJKS(privateKey []byte, chain [][]byte, alias, password string) ([]byte, error) {
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("keystore needs a certificate")
+	}
+
+	protected, err := protectJKSKey(privateKey, password)
+	if err != nil {
+		return nil, err
+	}
+	keyInfo, err := asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidJKSKeyProtector, Parameters: asn1NULL},
+		EncryptedData: protected,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	w := newJKSWriter(1)
+	w.writeUint32(jksPrivateKeyTag)
+	if err := w.writeUTF(strings.ToLower(alias)); err != nil {
+		return nil, err
+	}
+	w.writeDate(time.Now())
+	w.writeBytes(keyInfo)
+	w.writeUint32(uint32(len(chain)))
+	for _, der := range chain {
+		if err := w.writeCertificate(der); err != nil {
+			return nil, err
+		}
+	}
+	return w.sum(password), nil
+}
+
+// EncodeJKSTrustStore returns a Java keystore holding DER certificates as
+// trusted certificate entries under the aliases of TrustStoreAlias. Password
+// protects the integrity of the keystore.
+func EncodeJKSTrustStore(certificates [][]byte, password string) ([]byte, error) {
+	w := newJKSWriter(len(certificates))
+	now := time.Now()
+	for i, der := range certificates {
+		w.writeUint32(jksTrustedCertTag)
+		if err := w.writeUTF(TrustStoreAlias(i)); err != nil {
+			return nil, err
+		}
+		w.writeDate(now)
+		if err := w.writeCertificate(der); err != nil {
+			return nil, err
+		}
+	}
+	return w.sum(password), nil
+}
+
+// jksWriter writes the big-endian encoding of a Java keystore
+type jksWriter struct {
+	bytes.Buffer
+}
+
+// newJKSWriter returns a writer that has written the header of a keystore
+// with entries entries
+func newJKSWriter(entries int) *jksWriter {
+	w := &jksWriter{}
+	w.writeUint32(jksMagic)
+	w.writeUint32(jksVersion)
+	w.writeUint32(uint32(entries))
+	return w
+}
+
+func (w *jksWriter) writeUint32(v uint32) {
+	_ = binary.Write(w, binary.BigEndian, v)
+}
+
+// writeDate writes a time in milliseconds since the epoch
+func (w *jksWriter) writeDate(t time.Time) {
+	_ = binary.Write(w, binary.BigEndian, t.UnixMilli())
+}
+
+// writeBytes writes a length-prefixed byte string
+func (w *jksWriter) writeBytes(b []byte) {
+	w.writeUint32(uint32(len(b)))
+	w.Write(b)
+}
+
+// writeUTF writes a string as Java's DataOutput.writeUTF does. Modified UTF-8
+// only differs from UTF-8 for NUL and supplementary characters, which are
+// rejected.
+func (w *jksWriter) writeUTF(s string) error {
+	if len(s) > 0xffff {
+		return fmt.Errorf("keystore string is too long")
+	}
+	for _, r := range s {
+		if r == 0 || r > 0xffff {
+			return fmt.Errorf("keystore string %q has an unsupported character", s)
+		}
+	}
+	_ = binary.Write(w, binary.BigEndian, uint16(len(s)))
+	w.WriteString(s)
+	return nil
+}
+
+// writeCertificate writes the type and encoding of a DER X.509 certificate
+func (w *jksWriter) writeCertificate(der []byte) error {
+	if err := w.writeUTF(jksCertificateType); err != nil {
+		return err
+	}
+	w.writeBytes(der)
+	return nil
+}
+
+// sum returns the keystore followed by its integrity check: the SHA-1 digest
+// of the UTF-16 password, the whitener and the keystore
+func (w *jksWriter) sum(password string) []byte {
+	h := sha1.New()
+	h.Write(bmpString(password))
+	h.Write([]byte(jksIntegrityWhitener))
+	h.Write(w.Bytes())
+	return h.Sum(w.Bytes())
+}
+
+// protectJKSKey encrypts a key as sun.security.provider.KeyProtector does: the
+// key is XORed with a SHA-1 chain over the UTF-16 password seeded with a
+// random salt, and followed by the SHA-1 digest of the password and the key
+func protectJKSKey(key []byte, password string) ([]byte, error) {
+	passwd := bmpString(password)
+	salt := make([]byte, sha1.Size)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, 2*sha1.Size+len(key))
+	out = append(out, salt...)
+	digest := salt
+	for i := 0; i < len(key); i += sha1.Size {
+		h := sha1.New()
+		h.Write(passwd)
+		h.Write(digest)
+		digest = h.Sum(nil)
+		for j := 0; j < sha1.Size && i+j < len(key); j++ {
+			out = append(out, key[i+j]^digest[j])
+		}
+	}
+
+	h := sha1.New()
+	h.Write(passwd)
+	h.Write(key)
+	return h.Sum(out), nil
+}
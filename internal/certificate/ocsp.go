@@ -0,0 +1,350 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	// Hashes an OCSP CertID may use for the issuer of a certificate
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+var (
+	oidOCSPBasic   = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 1}
+	oidOCSPNonce   = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 2}
+	oidOCSPNoCheck = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 5}
+)
+
+// ocspHashes maps the hash algorithm OIDs of an OCSP CertID to their hashes
+var ocspHashes = []struct {
+	oid  asn1.ObjectIdentifier
+	hash crypto.Hash
+}{
+	{asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}, crypto.SHA1},
+	{asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}, crypto.SHA256},
+	{asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}, crypto.SHA384},
+	{asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}, crypto.SHA512},
+}
+
+// OCSP response statuses (RFC 6960 section 4.2.1)
+const (
+	OCSPMalformedRequest = 1
+	OCSPInternalError    = 2
+	OCSPTryLater         = 3
+	OCSPUnauthorized     = 6
+)
+
+// OCSP certificate statuses (RFC 6960 section 4.2.1)
+const (
+	OCSPGood    = 0
+	OCSPRevoked = 1
+	OCSPUnknown = 2
+)
+
+// OCSPNoCheckExtension marks a delegated OCSP responder certificate as not
+// needing a revocation check itself (RFC 6960 section 4.2.2.2.1).
+var OCSPNoCheckExtension = pkix.Extension{Id: oidOCSPNoCheck, Value: []byte{0x05, 0x00}}
+
+// certID is the RFC 6960 CertID structure
+type certID struct {
+	HashAlgorithm  pkix.AlgorithmIdentifier
+	IssuerNameHash []byte
+	IssuerKeyHash  []byte
+	SerialNumber   *big.Int
+}
+
+type ocspRequest struct {
+	TBSRequest tbsRequest
+	Signature  asn1.RawValue `asn1:"optional,explicit,tag:0"`
+}
+
+type tbsRequest struct {
+	Version       int           `asn1:"optional,explicit,default:0,tag:0"`
+	RequestorName asn1.RawValue `asn1:"optional,explicit,tag:1"`
+	RequestList   []singleRequest
+	Extensions    []pkix.Extension `asn1:"optional,explicit,tag:2"`
+}
+
+type singleRequest struct {
+	CertID     asn1.RawValue
+	Extensions []pkix.Extension `asn1:"optional,explicit,tag:0"`
+}
+
+type ocspResponse struct {
+	Status   asn1.Enumerated
+	Response responseBytes `asn1:"optional,explicit,tag:0"`
+}
+
+type responseBytes struct {
+	ResponseType asn1.ObjectIdentifier
+	Response     []byte
+}
+
+type basicOCSPResponse struct {
+	TBSResponseData    asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          asn1.BitString
+	Certificates       []asn1.RawValue `asn1:"optional,omitempty,explicit,tag:0"`
+}
+
+type responseData struct {
+	Version     int `asn1:"optional,explicit,default:0,tag:0"`
+	ResponderID asn1.RawValue
+	ProducedAt  time.Time `asn1:"generalized"`
+	Responses   []singleResponse
+	Extensions  []pkix.Extension `asn1:"optional,omitempty,explicit,tag:1"`
+}
+
+type singleResponse struct {
+	CertID     asn1.RawValue
+	CertStatus asn1.RawValue
+	ThisUpdate time.Time `asn1:"generalized"`
+	NextUpdate time.Time `asn1:"generalized,explicit,tag:0,optional"`
+}
+
+type revokedInfo struct {
+	RevocationTime time.Time       `asn1:"generalized"`
+	Reason         asn1.Enumerated `asn1:"explicit,tag:0,optional"`
+}
+
+// OCSPCertID identifies a certificate in an OCSP request
+type OCSPCertID struct {
+	Hash           crypto.Hash
+	IssuerNameHash []byte
+	IssuerKeyHash  []byte
+	SerialNumber   *big.Int
+
+	// raw is the DER CertID, which the response repeats verbatim
+	raw []byte
+}
+
+// IssuedBy reports whether the certificate was issued by issuer, by comparing
+// the hashes of the issuer name and public key.
+func (id *OCSPCertID) IssuedBy(issuer *x509.Certificate) bool {
+	nameHash, keyHash, err := issuerHashes(issuer, id.Hash)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(id.IssuerNameHash, nameHash) && bytes.Equal(id.IssuerKeyHash, keyHash)
+}
+
+// OCSPRequest is a parsed OCSP request
+type OCSPRequest struct {
+	CertIDs []OCSPCertID
+	// Nonce is the value of the nonce extension, echoed in the response
+	Nonce []byte
+}
+
+// ParseOCSPRequest parses a DER OCSP request. Request signatures are not
+// checked.
+func ParseOCSPRequest(der []byte) (*OCSPRequest, error) {
+	var request ocspRequest
+	rest, err := asn1.Unmarshal(der, &request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OCSP request: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("trailing data after OCSP request")
+	}
+	if len(request.TBSRequest.RequestList) == 0 {
+		return nil, errors.New("OCSP request lists no certificates")
+	}
+
+	parsed := &OCSPRequest{}
+	for _, extension := range request.TBSRequest.Extensions {
+		if extension.Id.Equal(oidOCSPNonce) {
+			parsed.Nonce = extension.Value
+		}
+	}
+	for _, single := range request.TBSRequest.RequestList {
+		var id certID
+		if _, err := asn1.Unmarshal(single.CertID.FullBytes, &id); err != nil {
+			return nil, fmt.Errorf("failed to parse OCSP CertID: %w", err)
+		}
+		hash, err := lookupOCSPHash(id.HashAlgorithm.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		parsed.CertIDs = append(parsed.CertIDs, OCSPCertID{
+			Hash:           hash,
+			IssuerNameHash: id.IssuerNameHash,
+			IssuerKeyHash:  id.IssuerKeyHash,
+			SerialNumber:   id.SerialNumber,
+			raw:            single.CertID.FullBytes,
+		})
+	}
+	return parsed, nil
+}
+
+// OCSPSingleResponse is the status of one certificate in an OCSP response
+type OCSPSingleResponse struct {
+	CertID OCSPCertID
+	// Status is OCSPGood, OCSPRevoked or OCSPUnknown
+	Status int
+	// RevokedAt and RevocationReason are set for revoked certificates
+	RevokedAt        time.Time
+	RevocationReason int
+	ThisUpdate       time.Time
+	NextUpdate       time.Time
+}
+
+// CreateOCSPResponse creates a successful DER OCSP response, signed by signer
+// on behalf of responder. The responder certificate is included in the
+// response and identified by its key hash.
+func CreateOCSPResponse(responses []OCSPSingleResponse, nonce []byte, producedAt time.Time, responder *x509.Certificate, signer Signer) ([]byte, error) {
+	signerKey := signer.Public()
+	responderKey, err := ParsePublicKey(responder.RawSubjectPublicKeyInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse responder public key: %w", err)
+	}
+	if responderKey.Algorithm != signerKey.Algorithm || !bytes.Equal(responderKey.Bytes, signerKey.Bytes) {
+		return nil, fmt.Errorf("signer key does not match the responder certificate")
+	}
+	signatureAlgorithm, err := lookupSignatureAlgorithm(signerKey.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	var singles []singleResponse
+	for _, response := range responses {
+		status, err := certStatus(response)
+		if err != nil {
+			return nil, err
+		}
+		singles = append(singles, singleResponse{
+			CertID:     asn1.RawValue{FullBytes: response.CertID.raw},
+			CertStatus: status,
+			ThisUpdate: response.ThisUpdate.UTC(),
+			NextUpdate: response.NextUpdate.UTC(),
+		})
+	}
+
+	// The responder is identified by the SHA-1 hash of its public key
+	_, keyHash, err := issuerHashes(responder, crypto.SHA1)
+	if err != nil {
+		return nil, err
+	}
+	keyHashDER, err := asn1.Marshal(keyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var extensions []pkix.Extension
+	if len(nonce) > 0 {
+		extensions = append(extensions, pkix.Extension{Id: oidOCSPNonce, Value: nonce})
+	}
+
+	tbs, err := asn1.Marshal(responseData{
+		ResponderID: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 2, IsCompound: true, Bytes: keyHashDER},
+		ProducedAt:  producedAt.UTC(),
+		Responses:   singles,
+		Extensions:  extensions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal OCSP response: %w", err)
+	}
+
+	signature, err := signer.Sign(tbs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign OCSP response: %w", err)
+	}
+
+	basic, err := asn1.Marshal(basicOCSPResponse{
+		TBSResponseData:    asn1.RawValue{FullBytes: tbs},
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: signatureAlgorithm.oid},
+		Signature:          asn1.BitString{Bytes: signature, BitLength: 8 * len(signature)},
+		Certificates:       []asn1.RawValue{{FullBytes: responder.Raw}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal OCSP response: %w", err)
+	}
+
+	return asn1.Marshal(ocspResponse{
+		Response: responseBytes{ResponseType: oidOCSPBasic, Response: basic},
+	})
+}
+
+// OCSPErrorResponse returns an unsuccessful DER OCSP response with status.
+func OCSPErrorResponse(status int) []byte {
+	der, _ := asn1.Marshal(ocspResponse{Status: asn1.Enumerated(status)})
+	return der
+}
+
+// certStatus encodes the CertStatus choice of a response
+func certStatus(response OCSPSingleResponse) (asn1.RawValue, error) {
+	switch response.Status {
+	case OCSPGood, OCSPUnknown:
+		return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: response.Status}, nil
+	case OCSPRevoked:
+		info := revokedInfo{RevocationTime: response.RevokedAt.UTC()}
+		if response.RevocationReason != 0 {
+			info.Reason = asn1.Enumerated(response.RevocationReason)
+		}
+		der, err := asn1.Marshal(info)
+		if err != nil {
+			return asn1.RawValue{}, fmt.Errorf("failed to marshal revocation: %w", err)
+		}
+		// Replace the SEQUENCE tag with the implicit [1] tag
+		var sequence asn1.RawValue
+		if _, err := asn1.Unmarshal(der, &sequence); err != nil {
+			return asn1.RawValue{}, err
+		}
+		return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: OCSPRevoked, IsCompound: true, Bytes: sequence.Bytes}, nil
+	default:
+		return asn1.RawValue{}, fmt.Errorf("invalid OCSP certificate status %d", response.Status)
+	}
+}
+
+// issuerHashes returns the hashes of the subject name and of the public key
+// bits of a certificate, as used in a CertID
+func issuerHashes(cert *x509.Certificate, hash crypto.Hash) ([]byte, []byte, error) {
+	var publicKeyInfo struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(cert.RawSubjectPublicKeyInfo, &publicKeyInfo); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	if !hash.Available() {
+		return nil, nil, fmt.Errorf("hash %v is not available", hash)
+	}
+	nameHash := hash.New()
+	nameHash.Write(cert.RawSubject)
+	keyHash := hash.New()
+	keyHash.Write(publicKeyInfo.PublicKey.RightAlign())
+	return nameHash.Sum(nil), keyHash.Sum(nil), nil
+}
+
+// lookupOCSPHash returns the hash with the given OID
+func lookupOCSPHash(oid asn1.ObjectIdentifier) (crypto.Hash, error) {
+	for _, h := range ocspHashes {
+		if h.oid.Equal(oid) {
+			return h.hash, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported OCSP hash algorithm %v", oid)
+}
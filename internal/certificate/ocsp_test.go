@@ -0,0 +1,187 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha1"
+	"encoding/asn1"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
+)
+
+// parseOCSPResponse parses a response with golang.org/x/crypto/ocsp. The
+// package cannot check post-quantum signatures and checks the signature of
+// an embedded responder certificate, so the certificates are dropped first
+// and the signature is checked with the responder key.
+func parseOCSPResponse(t *testing.T, der []byte, responderKey PublicKey) *ocsp.Response {
+	t.Helper()
+	var outer ocspResponse
+	if _, err := asn1.Unmarshal(der, &outer); err != nil {
+		t.Fatal(err)
+	}
+	var basic basicOCSPResponse
+	if _, err := asn1.Unmarshal(outer.Response.Response, &basic); err != nil {
+		t.Fatal(err)
+	}
+	if len(basic.Certificates) != 1 {
+		t.Fatalf("response holds %d certificates, want the responder certificate", len(basic.Certificates))
+	}
+	basic.Certificates = nil
+	stripped, err := asn1.Marshal(basic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outer.Response.Response = stripped
+	if der, err = asn1.Marshal(outer); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := ocsp.ParseResponse(der, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckSignature(cryptoprovider.Go, responderKey, response.TBSResponseData, response.Signature, context.Background()); err != nil {
+		t.Fatalf("OCSP response signature: %v", err)
+	}
+	return response
+}
+
+func TestCreateOCSPResponse(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name      string
+		algorithm string
+		status    int
+		reason    int
+	}{
+		{name: "good", algorithm: "ML-DSA-65", status: OCSPGood},
+		{name: "revoked", algorithm: "ML-DSA-65", status: OCSPRevoked, reason: ReasonCodes["KeyCompromise"]},
+		{name: "unknown", algorithm: "ML-DSA-65", status: OCSPUnknown},
+		{name: "superseded", algorithm: "ML-DSA-44", status: OCSPRevoked, reason: ReasonCodes["Superseded"]},
+		{name: "composite", algorithm: "ML-DSA-44-ECDSA-P256-SHA256", status: OCSPRevoked, reason: ReasonCodes["CessationOfOperation"]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ca, caSigner := newCA(t, tt.algorithm)
+			leaf, _, _ := newLeaf(t, tt.algorithm, ca, caSigner)
+
+			requestDER, err := ocsp.CreateRequest(leaf, ca, &ocsp.RequestOptions{Hash: crypto.SHA256})
+			if err != nil {
+				t.Fatal(err)
+			}
+			request, err := ParseOCSPRequest(requestDER)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(request.CertIDs) != 1 {
+				t.Fatalf("%d CertIDs, want 1", len(request.CertIDs))
+			}
+			id := request.CertIDs[0]
+			if id.Hash != crypto.SHA256 || id.SerialNumber.Cmp(leaf.SerialNumber) != 0 {
+				t.Errorf("CertID = %v %s, want SHA-256 %s", id.Hash, id.SerialNumber, leaf.SerialNumber)
+			}
+			if !id.IssuedBy(ca) {
+				t.Error("CertID does not match the issuer")
+			}
+			if id.IssuedBy(leaf) {
+				t.Error("CertID matches the leaf as its issuer")
+			}
+
+			der, err := CreateOCSPResponse([]OCSPSingleResponse{{
+				CertID:           id,
+				Status:           tt.status,
+				RevokedAt:        now.Add(-time.Hour),
+				RevocationReason: tt.reason,
+				ThisUpdate:       now,
+				NextUpdate:       now.Add(time.Hour),
+			}}, nil, now, ca, caSigner)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			response := parseOCSPResponse(t, der, caSigner.Public())
+			if response.Status != tt.status {
+				t.Errorf("status = %d, want %d", response.Status, tt.status)
+			}
+			if response.SerialNumber.Cmp(leaf.SerialNumber) != 0 {
+				t.Errorf("serial number = %s, want %s", response.SerialNumber, leaf.SerialNumber)
+			}
+			if !response.ProducedAt.Equal(now) || !response.ThisUpdate.Equal(now) || !response.NextUpdate.Equal(now.Add(time.Hour)) {
+				t.Errorf("producedAt = %s, thisUpdate = %s, nextUpdate = %s", response.ProducedAt, response.ThisUpdate, response.NextUpdate)
+			}
+			if tt.status == OCSPRevoked && (!response.RevokedAt.Equal(now.Add(-time.Hour)) || response.RevocationReason != tt.reason) {
+				t.Errorf("revoked at %s for reason %d, want %s for reason %d", response.RevokedAt, response.RevocationReason, now.Add(-time.Hour), tt.reason)
+			}
+			keyHash := sha1.Sum(caSigner.Public().Bytes)
+			if !bytes.Equal(response.ResponderKeyHash, keyHash[:]) {
+				t.Errorf("responder key hash = %x, want %x", response.ResponderKeyHash, keyHash)
+			}
+		})
+	}
+}
+
+func TestCreateOCSPResponseSignerMismatch(t *testing.T) {
+	ca, caSigner := newCA(t, "ML-DSA-44")
+	_, leafSigner, _ := newLeaf(t, "ML-DSA-44", ca, caSigner)
+
+	if _, err := CreateOCSPResponse(nil, nil, time.Now(), ca, leafSigner); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestParseOCSPRequestErrors(t *testing.T) {
+	empty, err := asn1.Marshal(ocspRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		der  []byte
+	}{
+		{name: "garbage", der: []byte("not a request")},
+		{name: "no certificates", der: empty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseOCSPRequest(tt.der); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestOCSPErrorResponse(t *testing.T) {
+	for _, status := range []int{OCSPMalformedRequest, OCSPInternalError, OCSPTryLater, OCSPUnauthorized} {
+		_, err := ocsp.ParseResponse(OCSPErrorResponse(status), nil)
+		var responseError ocsp.ResponseError
+		if !errors.As(err, &responseError) || int(responseError.Status) != status {
+			t.Errorf("status %d: err = %v", status, err)
+		}
+	}
+}
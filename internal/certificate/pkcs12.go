@@ -0,0 +1,441 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"crypto"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"unicode/utf16"
+)
+
+// PKCS#12 profiles, named as in cert-manager
+const (
+	// PKCS12Modern2023 encrypts with PBES2, PBKDF2-HMAC-SHA256 and AES-256-CBC,
+	// and authenticates with HMAC-SHA256
+	PKCS12Modern2023 = "Modern2023"
+	// PKCS12LegacyDES encrypts with pbeWithSHAAnd3-KeyTripleDES-CBC and
+	// authenticates with HMAC-SHA1, for older Java and Windows versions
+	PKCS12LegacyDES = "LegacyDES"
+)
+
+// pkcs12Iterations is the iteration count of the key derivations
+const pkcs12Iterations = 2048
+
+var (
+	oidDataContentType          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedDataContentType = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+	oidPKCS8ShroudedKeyBag      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidCertBag                  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidCertTypeX509             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidFriendlyName             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	oidLocalKeyID               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+	// oidJavaTrustStore marks a certificate bag as a trusted certificate entry in Java
+	oidJavaTrustStore      = asn1.ObjectIdentifier{2, 16, 840, 1, 113894, 746875, 1, 1}
+	oidAnyExtendedKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37, 0}
+
+	oidPBES2                         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2                        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA256                = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES256CBC                     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidPBEWithSHAAnd3KeyTripleDESCBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+	oidSHA1                          = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256                        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+)
+
+// asn1NULL is the DER encoding of an ASN.1 NULL
+var asn1NULL = asn1.RawValue{FullBytes: []byte{asn1.TagNull, 0}}
+
+type pfxPDU struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  macData
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo encryptedContentInfo
+}
+
+type encryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           []byte `asn1:"tag:0"`
+}
+
+type macData struct {
+	Mac        digestInfo
+	MacSalt    []byte
+	Iterations int
+}
+
+type digestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+type safeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue
+	Attributes []pkcs12Attribute `asn1:"set,omitempty"`
+}
+
+type pkcs12Attribute struct {
+	ID     asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+type certBag struct {
+	ID   asn1.ObjectIdentifier
+	Data asn1.RawValue
+}
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt       []byte
+	Iterations int
+	KeyLength  int                      `asn1:"optional"`
+	PRF        pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+type pbeParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+// pkcs12Profile holds the algorithms of a PKCS#12 profile
+type pkcs12Profile struct {
+	encrypt func(password string, plaintext []byte) (pkix.AlgorithmIdentifier, []byte, error)
+	macHash crypto.Hash
+	macOID  asn1.ObjectIdentifier
+}
+
+var pkcs12Profiles = map[string]pkcs12Profile{
+	PKCS12Modern2023: {encrypt: encryptPBES2, macHash: crypto.SHA256, macOID: oidSHA256},
+	PKCS12LegacyDES:  {encrypt: encryptPBEWithSHAAnd3KeyTripleDES, macHash: crypto.SHA1, macOID: oidSHA1},
+}
+
+// EncodePKCS12 returns a PKCS#12 keystore holding the DER PKCS#8 private key
+// and its DER certificate chain, leaf first. The key and the leaf share the
+// friendly name alias; the key and all certificates are encrypted with
+// password as the profile specifies.
+func EncodePKCS12(privateKey []byte, chain [][]byte, alias, password, profile string) ([]byte, error) {
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("keystore needs a certificate")
+	}
+	p, ok := pkcs12Profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("unsupported PKCS#12 profile %q", profile)
+	}
+
+	// The local key ID ties the key to its certificate
+	localKeyID := sha1.Sum(chain[0])
+	attributes := []pkcs12Attribute{
+		friendlyNameAttribute(alias),
+		pkcs12AttributeOf(oidLocalKeyID, asn1.RawValue{Tag: asn1.TagOctetString, Bytes: localKeyID[:]}),
+	}
+
+	var certBags []safeBag
+	for i, der := range chain {
+		bag, err := newCertBag(der)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			bag.Attributes = attributes
+		}
+		certBags = append(certBags, bag)
+	}
+
+	algorithm, encrypted, err := p.encrypt(password, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	keyInfo, err := asn1.Marshal(encryptedPrivateKeyInfo{Algorithm: algorithm, EncryptedData: encrypted})
+	if err != nil {
+		return nil, err
+	}
+	keyBag := safeBag{ID: oidPKCS8ShroudedKeyBag, Value: explicitTag(keyInfo), Attributes: attributes}
+
+	return encodePFX(p, password, certBags, []safeBag{keyBag})
+}
+
+// EncodePKCS12TrustStore returns a PKCS#12 trust store holding DER
+// certificates as trusted certificate entries, encrypted with password as the
+// profile specifies. The first certificate has the friendly name ca, the
+// following ones ca-1, ca-2 and so on.
+func EncodePKCS12TrustStore(certificates [][]byte, password, profile string) ([]byte, error) {
+	p, ok := pkcs12Profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("unsupported PKCS#12 profile %q", profile)
+	}
+
+	trusted, err := asn1.Marshal(oidAnyExtendedKeyUsage)
+	if err != nil {
+		return nil, err
+	}
+	var certBags []safeBag
+	for i, der := range certificates {
+		bag, err := newCertBag(der)
+		if err != nil {
+			return nil, err
+		}
+		bag.Attributes = []pkcs12Attribute{
+			friendlyNameAttribute(TrustStoreAlias(i)),
+			pkcs12AttributeOf(oidJavaTrustStore, asn1.RawValue{FullBytes: trusted}),
+		}
+		certBags = append(certBags, bag)
+	}
+
+	return encodePFX(p, password, certBags, nil)
+}
+
+// TrustStoreAlias returns the alias of the i-th certificate of a trust store
+func TrustStoreAlias(i int) string {
+	if i == 0 {
+		return "ca"
+	}
+	return fmt.Sprintf("ca-%d", i)
+}
+
+// encodePFX returns a PFX with the certificate bags in an encrypted safe and
+// the key bags, which are shrouded already, in a plain one
+func encodePFX(p pkcs12Profile, password string, certBags, keyBags []safeBag) ([]byte, error) {
+	var authenticatedSafe []contentInfo
+
+	if len(certBags) > 0 {
+		contents, err := asn1.Marshal(certBags)
+		if err != nil {
+			return nil, err
+		}
+		algorithm, encrypted, err := p.encrypt(password, contents)
+		if err != nil {
+			return nil, err
+		}
+		data, err := asn1.Marshal(encryptedData{
+			EncryptedContentInfo: encryptedContentInfo{
+				ContentType:                oidDataContentType,
+				ContentEncryptionAlgorithm: algorithm,
+				EncryptedContent:           encrypted,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		authenticatedSafe = append(authenticatedSafe, contentInfo{ContentType: oidEncryptedDataContentType, Content: explicitTag(data)})
+	}
+
+	if len(keyBags) > 0 {
+		contents, err := asn1.Marshal(keyBags)
+		if err != nil {
+			return nil, err
+		}
+		data, err := asn1.Marshal(contents)
+		if err != nil {
+			return nil, err
+		}
+		authenticatedSafe = append(authenticatedSafe, contentInfo{ContentType: oidDataContentType, Content: explicitTag(data)})
+	}
+
+	authSafe, err := asn1.Marshal(authenticatedSafe)
+	if err != nil {
+		return nil, err
+	}
+
+	// The MAC key is derived from the BMPString password with the PKCS#12 KDF
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	macKey := pkcs12KDF(p.macHash, bmpPassword(password), salt, 3, pkcs12Iterations, p.macHash.Size())
+	mac := hmac.New(p.macHash.New, macKey)
+	mac.Write(authSafe)
+
+	content, err := asn1.Marshal(authSafe)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pfxPDU{
+		Version:  3,
+		AuthSafe: contentInfo{ContentType: oidDataContentType, Content: explicitTag(content)},
+		MacData: macData{
+			Mac: digestInfo{
+				Algorithm: pkix.AlgorithmIdentifier{Algorithm: p.macOID, Parameters: asn1NULL},
+				Digest:    mac.Sum(nil),
+			},
+			MacSalt:    salt,
+			Iterations: pkcs12Iterations,
+		},
+	})
+}
+
+// newCertBag returns a safe bag holding a DER X.509 certificate
+func newCertBag(der []byte) (safeBag, error) {
+	octets, err := asn1.Marshal(der)
+	if err != nil {
+		return safeBag{}, err
+	}
+	bag, err := asn1.Marshal(certBag{ID: oidCertTypeX509, Data: explicitTag(octets)})
+	if err != nil {
+		return safeBag{}, err
+	}
+	return safeBag{ID: oidCertBag, Value: explicitTag(bag)}, nil
+}
+
+// friendlyNameAttribute returns the friendly name attribute, a BMPString
+func friendlyNameAttribute(name string) pkcs12Attribute {
+	return pkcs12AttributeOf(oidFriendlyName, asn1.RawValue{Tag: asn1.TagBMPString, Bytes: bmpString(name)})
+}
+
+// pkcs12AttributeOf returns an attribute with a single value
+func pkcs12AttributeOf(id asn1.ObjectIdentifier, value asn1.RawValue) pkcs12Attribute {
+	encoded, _ := asn1.Marshal(value)
+	return pkcs12Attribute{ID: id, Values: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: encoded}}
+}
+
+// explicitTag wraps DER in a [0] EXPLICIT tag
+func explicitTag(der []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: der}
+}
+
+// encryptPBES2 encrypts with PBES2, using PBKDF2-HMAC-SHA256 and AES-256-CBC.
+// The password is used as UTF-8, as OpenSSL and Java do for PBES2.
+func encryptPBES2(password string, plaintext []byte) (pkix.AlgorithmIdentifier, []byte, error) {
+	return pbes2Encrypt([]byte(password), plaintext, CipherAES256CBC, KDFPBKDF2, pkcs12Iterations)
+}
+
+// encryptPBEWithSHAAnd3KeyTripleDES encrypts with the legacy PKCS#12 scheme,
+// deriving the 3DES key and IV from the BMPString password with SHA-1
+func encryptPBEWithSHAAnd3KeyTripleDES(password string, plaintext []byte) (pkix.AlgorithmIdentifier, []byte, error) {
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+
+	bmp := bmpPassword(password)
+	key := pkcs12KDF(crypto.SHA1, bmp, salt, 1, pkcs12Iterations, 24)
+	iv := pkcs12KDF(crypto.SHA1, bmp, salt, 2, pkcs12Iterations, des.BlockSize)
+	block, err := des.NewTripleDESCipher(key)
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+	ciphertext := pkcs7Pad(plaintext, block.BlockSize())
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+
+	params, err := asn1.Marshal(pbeParams{Salt: salt, Iterations: pkcs12Iterations})
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+	return pkix.AlgorithmIdentifier{Algorithm: oidPBEWithSHAAnd3KeyTripleDESCBC, Parameters: asn1.RawValue{FullBytes: params}}, ciphertext, nil
+}
+
+// pkcs12KDF derives size bytes of key material for purpose id (1 for keys,
+// 2 for IVs, 3 for MAC keys) as RFC 7292 appendix B.2 describes
+func pkcs12KDF(h crypto.Hash, password, salt []byte, id byte, iterations, size int) []byte {
+	u := h.Size()
+	const v = 64
+
+	// I is the salt and the password, each repeated to a multiple of v bytes
+	fill := func(b []byte) []byte {
+		if len(b) == 0 {
+			return nil
+		}
+		out := make([]byte, v*((len(b)+v-1)/v))
+		for i := range out {
+			out[i] = b[i%len(b)]
+		}
+		return out
+	}
+	I := append(fill(salt), fill(password)...)
+
+	D := make([]byte, v)
+	for i := range D {
+		D[i] = id
+	}
+
+	var out []byte
+	for len(out) < size {
+		hash := h.New()
+		hash.Write(D)
+		hash.Write(I)
+		A := hash.Sum(nil)
+		for r := 1; r < iterations; r++ {
+			hash.Reset()
+			hash.Write(A)
+			A = hash.Sum(A[:0])
+		}
+		out = append(out, A...)
+
+		// Every v byte block of I becomes I_j + B + 1, with B A repeated to v bytes
+		for j := 0; j < len(I); j += v {
+			carry := 1
+			for k := v - 1; k >= 0; k-- {
+				sum := int(I[j+k]) + int(A[k%u]) + carry
+				I[j+k] = byte(sum)
+				carry = sum >> 8
+			}
+		}
+	}
+	return out[:size]
+}
+
+// bmpPassword returns a password as the null-terminated BMPString the PKCS#12
+// KDF expects
+func bmpPassword(password string) []byte {
+	return append(bmpString(password), 0, 0)
+}
+
+// bmpString returns s encoded as UTF-16BE
+func bmpString(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	out := make([]byte, 0, 2*len(units))
+	for _, unit := range units {
+		out = append(out, byte(unit>>8), byte(unit))
+	}
+	return out
+}
+
+// pkcs7Pad returns a copy of data padded to a multiple of the block size
+func pkcs7Pad(data []byte, blockSize int) []byte {
+	padding := blockSize - len(data)%blockSize
+	out := make([]byte, len(data), len(data)+padding)
+	copy(out, data)
+	for i := 0; i < padding; i++ {
+		out = append(out, byte(padding))
+	}
+	return out
+}
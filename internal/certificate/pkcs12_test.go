@@ -0,0 +1,339 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+// pfxBag is a safe bag whose attributes are optional, as they are on all but
+// the first certificate of a keystore chain
+type pfxBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+// decodePFX verifies the MAC of a PFX and returns its safe bags, with the
+// encrypted safes decrypted. Shrouded key bags are returned as they are.
+func decodePFX(der []byte, password string) ([]pfxBag, error) {
+	var pfx pfxPDU
+	if _, err := asn1.Unmarshal(der, &pfx); err != nil {
+		return nil, err
+	}
+	var authSafe []byte
+	if _, err := asn1.Unmarshal(pfx.AuthSafe.Content.Bytes, &authSafe); err != nil {
+		return nil, err
+	}
+
+	var h crypto.Hash
+	switch algorithm := pfx.MacData.Mac.Algorithm.Algorithm; {
+	case algorithm.Equal(oidSHA1):
+		h = crypto.SHA1
+	case algorithm.Equal(oidSHA256):
+		h = crypto.SHA256
+	default:
+		return nil, fmt.Errorf("unexpected MAC algorithm %v", algorithm)
+	}
+	macKey := pkcs12KDF(h, bmpPassword(password), pfx.MacData.MacSalt, 3, pfx.MacData.Iterations, h.Size())
+	mac := hmac.New(h.New, macKey)
+	mac.Write(authSafe)
+	if !hmac.Equal(mac.Sum(nil), pfx.MacData.Mac.Digest) {
+		return nil, errors.New("MAC mismatch")
+	}
+
+	var safes []contentInfo
+	if _, err := asn1.Unmarshal(authSafe, &safes); err != nil {
+		return nil, err
+	}
+	var bags []pfxBag
+	for _, safe := range safes {
+		var contents []byte
+		switch {
+		case safe.ContentType.Equal(oidDataContentType):
+			if _, err := asn1.Unmarshal(safe.Content.Bytes, &contents); err != nil {
+				return nil, err
+			}
+		case safe.ContentType.Equal(oidEncryptedDataContentType):
+			var data encryptedData
+			if _, err := asn1.Unmarshal(safe.Content.Bytes, &data); err != nil {
+				return nil, err
+			}
+			var err error
+			info := data.EncryptedContentInfo
+			if contents, err = decryptPKCS12(password, info.ContentEncryptionAlgorithm, info.EncryptedContent); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unexpected content type %v", safe.ContentType)
+		}
+
+		var safeBags []pfxBag
+		if _, err := asn1.Unmarshal(contents, &safeBags); err != nil {
+			return nil, err
+		}
+		bags = append(bags, safeBags...)
+	}
+	return bags, nil
+}
+
+// decryptPKCS12 decrypts data encrypted with PBES2 or with the legacy
+// pbeWithSHAAnd3-KeyTripleDES-CBC scheme
+func decryptPKCS12(password string, algorithm pkix.AlgorithmIdentifier, ciphertext []byte) ([]byte, error) {
+	if !algorithm.Algorithm.Equal(oidPBEWithSHAAnd3KeyTripleDESCBC) {
+		return pbes2Decrypt([]byte(password), algorithm, ciphertext)
+	}
+
+	var params pbeParams
+	if _, err := asn1.Unmarshal(algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, err
+	}
+	bmp := bmpPassword(password)
+	block, err := des.NewTripleDESCipher(pkcs12KDF(crypto.SHA1, bmp, params.Salt, 1, params.Iterations, 24))
+	if err != nil {
+		return nil, err
+	}
+	iv := pkcs12KDF(crypto.SHA1, bmp, params.Salt, 2, params.Iterations, des.BlockSize)
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+	return plaintext[:len(plaintext)-int(plaintext[len(plaintext)-1])], nil
+}
+
+// bagAttribute returns the single value of a bag attribute
+func bagAttribute(bag pfxBag, id asn1.ObjectIdentifier) (asn1.RawValue, bool) {
+	for _, attribute := range bag.Attributes {
+		if attribute.ID.Equal(id) {
+			var value asn1.RawValue
+			if _, err := asn1.Unmarshal(attribute.Values.Bytes, &value); err != nil {
+				return asn1.RawValue{}, false
+			}
+			return value, true
+		}
+	}
+	return asn1.RawValue{}, false
+}
+
+// bagCertificate returns the DER certificate of a certificate bag
+func bagCertificate(t *testing.T, bag pfxBag) []byte {
+	t.Helper()
+	var cert certBag
+	if _, err := asn1.Unmarshal(bag.Value.Bytes, &cert); err != nil {
+		t.Fatal(err)
+	}
+	var der []byte
+	if _, err := asn1.Unmarshal(cert.Data.Bytes, &der); err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+// TestPKCS12KDF checks the PKCS#12 key derivation against the vectors of
+// golang.org/x/crypto/pkcs12.
+func TestPKCS12KDF(t *testing.T) {
+	tests := []struct {
+		name     string
+		password []byte
+		salt     string
+		want     string
+	}{
+		{
+			name:     "long key",
+			password: bmpPassword("sesame"),
+			salt:     "ffffffffffffffff",
+			want:     "7cd9fd3e2b3be7691a44e3bef0f9ea0fb9b897d4e325d9d1",
+		},
+		{
+			name:     "leading zeros",
+			password: []byte{0, 0},
+			salt:     "f37e05b518324b4b",
+			want:     "00f759ff47d14dd03665d5943cb3c4a39a2555c02aed66e1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			salt, _ := hex.DecodeString(tt.salt)
+			if got := hex.EncodeToString(pkcs12KDF(crypto.SHA1, tt.password, salt, 1, 2048, 24)); got != tt.want {
+				t.Errorf("key = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodePKCS12(t *testing.T) {
+	for _, profile := range []string{PKCS12Modern2023, PKCS12LegacyDES} {
+		for _, algorithm := range []string{"ML-DSA-65", "ML-DSA-65-ECDSA-P384-SHA512"} {
+			t.Run(profile+"/"+algorithm, func(t *testing.T) {
+				ca, caSigner := newCA(t, algorithm)
+				leaf, _, privateKey := newLeaf(t, algorithm, ca, caSigner)
+				keyDER, err := MarshalPrivateKey(algorithm, privateKey)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				pfx, err := EncodePKCS12(keyDER, [][]byte{leaf.Raw, ca.Raw}, "tls", "changeit", profile)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := decodePFX(pfx, "wrong"); err == nil {
+					t.Error("wrong password was accepted")
+				}
+				bags, err := decodePFX(pfx, "changeit")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(bags) != 3 {
+					t.Fatalf("%d bags, want 2 certificates and a key", len(bags))
+				}
+
+				for i, want := range [][]byte{leaf.Raw, ca.Raw} {
+					if !bags[i].ID.Equal(oidCertBag) || !bytes.Equal(bagCertificate(t, bags[i]), want) {
+						t.Errorf("bag %d is not certificate %d of the chain", i, i)
+					}
+				}
+
+				keyBag := bags[2]
+				if !keyBag.ID.Equal(oidPKCS8ShroudedKeyBag) {
+					t.Fatalf("bag 2 is %v, want a shrouded key bag", keyBag.ID)
+				}
+				var info encryptedPrivateKeyInfo
+				if _, err := asn1.Unmarshal(keyBag.Value.Bytes, &info); err != nil {
+					t.Fatal(err)
+				}
+				decrypted, err := decryptPKCS12("changeit", info.Algorithm, info.EncryptedData)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if name, parsed, err := ParsePrivateKey(decrypted); err != nil || name != algorithm || !bytes.Equal(parsed, privateKey) {
+					t.Errorf("decrypted key = %s, err %v, want the %s key", name, err, algorithm)
+				}
+
+				// The key and the leaf share the alias and the local key ID
+				for _, bag := range []pfxBag{bags[0], keyBag} {
+					if name, ok := bagAttribute(bag, oidFriendlyName); !ok || !bytes.Equal(name.Bytes, bmpString("tls")) {
+						t.Errorf("bag %v has no friendly name tls", bag.ID)
+					}
+				}
+				leafID, _ := bagAttribute(bags[0], oidLocalKeyID)
+				keyID, _ := bagAttribute(keyBag, oidLocalKeyID)
+				if len(leafID.Bytes) == 0 || !bytes.Equal(leafID.Bytes, keyID.Bytes) {
+					t.Error("the key and the leaf do not share a local key ID")
+				}
+			})
+		}
+	}
+}
+
+// TestEncodePKCS12Interop decodes a LegacyDES keystore with a classical key
+// with golang.org/x/crypto/pkcs12, which supports that profile only.
+func TestEncodePKCS12Interop(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "interop"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pfx, err := EncodePKCS12(keyDER, [][]byte{certDER}, "interop", "changeit", PKCS12LegacyDES)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodedKey, cert, err := pkcs12.Decode(pfx, "changeit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.Equal(decodedKey) {
+		t.Error("decoded key does not match")
+	}
+	if !bytes.Equal(cert.Raw, certDER) {
+		t.Error("decoded certificate does not match")
+	}
+}
+
+func TestEncodePKCS12TrustStore(t *testing.T) {
+	first, _ := newCA(t, "ML-DSA-44")
+	second, _ := newCA(t, "ML-DSA-87")
+
+	for _, profile := range []string{PKCS12Modern2023, PKCS12LegacyDES} {
+		t.Run(profile, func(t *testing.T) {
+			pfx, err := EncodePKCS12TrustStore([][]byte{first.Raw, second.Raw}, "changeit", profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bags, err := decodePFX(pfx, "changeit")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(bags) != 2 {
+				t.Fatalf("%d bags, want 2 certificates", len(bags))
+			}
+			for i, want := range [][]byte{first.Raw, second.Raw} {
+				if !bytes.Equal(bagCertificate(t, bags[i]), want) {
+					t.Errorf("bag %d is not certificate %d", i, i)
+				}
+				if name, ok := bagAttribute(bags[i], oidFriendlyName); !ok || !bytes.Equal(name.Bytes, bmpString(TrustStoreAlias(i))) {
+					t.Errorf("bag %d has no friendly name %s", i, TrustStoreAlias(i))
+				}
+				if _, ok := bagAttribute(bags[i], oidJavaTrustStore); !ok {
+					t.Errorf("bag %d is not a trusted certificate entry", i)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodePKCS12Errors(t *testing.T) {
+	if _, err := EncodePKCS12([]byte{0x30, 0x00}, nil, "tls", "changeit", PKCS12Modern2023); err == nil {
+		t.Error("keystore without a certificate was accepted")
+	}
+	if _, err := EncodePKCS12([]byte{0x30, 0x00}, [][]byte{{0x30, 0x00}}, "tls", "changeit", "Modern2000"); err == nil {
+		t.Error("unknown profile was accepted")
+	}
+	if _, err := EncodePKCS12TrustStore(nil, "changeit", "Modern2000"); err == nil {
+		t.Error("unknown trust store profile was accepted")
+	}
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Ciphers and key derivation functions of encrypted PKCS#8 private keys
+const (
+	CipherAES256CBC = "AES-256-CBC"
+	CipherAES256GCM = "AES-256-GCM"
+	KDFPBKDF2       = "PBKDF2"
+	KDFScrypt       = "Scrypt"
+)
+
+// Cost parameters of the key derivations of encrypted PKCS#8 private keys:
+// the OWASP recommendation for PBKDF2-HMAC-SHA256, and the scrypt parameters
+// OpenSSL uses, which stay within its default memory limit
+const (
+	pkcs8Iterations = 600000
+	scryptCost      = 1 << 14
+	scryptBlockSize = 8
+	scryptParallel  = 1
+)
+
+var (
+	oidScrypt         = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11591, 4, 11}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES256GCM      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 46}
+)
+
+type scryptParams struct {
+	Salt                     []byte
+	CostParameter            int
+	BlockSize                int
+	ParallelizationParameter int
+	KeyLength                int `asn1:"optional"`
+}
+
+// gcmParams are the AES-GCM parameters of RFC 5084
+type gcmParams struct {
+	Nonce  []byte
+	ICVLen int `asn1:"optional,default:12"`
+}
+
+// IsEncryptedPrivateKeyPEM reports whether data holds an encrypted PKCS#8
+// private key.
+func IsEncryptedPrivateKeyPEM(data []byte) bool {
+	block, _ := pem.Decode(data)
+	return block != nil && block.Type == "ENCRYPTED PRIVATE KEY"
+}
+
+// EncryptPrivateKeyPEM encrypts a PEM encoded PKCS#8 private key with
+// passphrase and returns it as a PEM encoded EncryptedPrivateKeyInfo. The key
+// is encrypted with PBES2, using the cipher and the key derivation function.
+func EncryptPrivateKeyPEM(keyPEM []byte, passphrase []byte, cipherName, kdf string) (string, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return "", fmt.Errorf("private key is not a PKCS#8 private key")
+	}
+
+	algorithm, ciphertext, err := pbes2Encrypt(passphrase, block.Bytes, cipherName, kdf, pkcs8Iterations)
+	if err != nil {
+		return "", err
+	}
+	der, err := asn1.Marshal(encryptedPrivateKeyInfo{Algorithm: algorithm, EncryptedData: ciphertext})
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: der})), nil
+}
+
+// DecryptPrivateKeyPEM decrypts a PEM encoded encrypted PKCS#8 private key
+// with passphrase and returns it as a PEM encoded PKCS#8 private key.
+func DecryptPrivateKeyPEM(data []byte, passphrase []byte) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "ENCRYPTED PRIVATE KEY" {
+		return nil, fmt.Errorf("private key is not an encrypted PKCS#8 private key")
+	}
+	var info encryptedPrivateKeyInfo
+	if rest, err := asn1.Unmarshal(block.Bytes, &info); err != nil || len(rest) != 0 {
+		return nil, fmt.Errorf("failed to parse encrypted private key")
+	}
+
+	plaintext, err := pbes2Decrypt(passphrase, info.Algorithm, info.EncryptedData)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: plaintext}), nil
+}
+
+// pbes2Encrypt encrypts plaintext with PBES2 and returns the algorithm
+// identifier and the ciphertext. PBKDF2 uses HMAC-SHA256 and iterations.
+func pbes2Encrypt(password, plaintext []byte, cipherName, kdf string, iterations int) (pkix.AlgorithmIdentifier, []byte, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+
+	// Derive the key
+	var key []byte
+	var kdfAlgorithm pkix.AlgorithmIdentifier
+	switch kdf {
+	case KDFPBKDF2:
+		key = pbkdf2.Key(password, salt, iterations, 32, sha256.New)
+		params, err := asn1.Marshal(pbkdf2Params{
+			Salt:       salt,
+			Iterations: iterations,
+			PRF:        pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1NULL},
+		})
+		if err != nil {
+			return pkix.AlgorithmIdentifier{}, nil, err
+		}
+		kdfAlgorithm = pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: params}}
+	case KDFScrypt:
+		var err error
+		if key, err = scrypt.Key(password, salt, scryptCost, scryptBlockSize, scryptParallel, 32); err != nil {
+			return pkix.AlgorithmIdentifier{}, nil, err
+		}
+		params, err := asn1.Marshal(scryptParams{
+			Salt:                     salt,
+			CostParameter:            scryptCost,
+			BlockSize:                scryptBlockSize,
+			ParallelizationParameter: scryptParallel,
+		})
+		if err != nil {
+			return pkix.AlgorithmIdentifier{}, nil, err
+		}
+		kdfAlgorithm = pkix.AlgorithmIdentifier{Algorithm: oidScrypt, Parameters: asn1.RawValue{FullBytes: params}}
+	default:
+		return pkix.AlgorithmIdentifier{}, nil, fmt.Errorf("unsupported key derivation function %q", kdf)
+	}
+
+	// Encrypt
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+	var ciphertext []byte
+	var encryptionScheme pkix.AlgorithmIdentifier
+	switch cipherName {
+	case CipherAES256CBC:
+		iv := make([]byte, aes.BlockSize)
+		if _, err := rand.Read(iv); err != nil {
+			return pkix.AlgorithmIdentifier{}, nil, err
+		}
+		ciphertext = pkcs7Pad(plaintext, block.BlockSize())
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+		encryptionScheme = pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{Tag: asn1.TagOctetString, Bytes: iv}}
+	case CipherAES256GCM:
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return pkix.AlgorithmIdentifier{}, nil, err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return pkix.AlgorithmIdentifier{}, nil, err
+		}
+		ciphertext = aead.Seal(nil, nonce, plaintext, nil)
+		params, err := asn1.Marshal(gcmParams{Nonce: nonce, ICVLen: aead.Overhead()})
+		if err != nil {
+			return pkix.AlgorithmIdentifier{}, nil, err
+		}
+		encryptionScheme = pkix.AlgorithmIdentifier{Algorithm: oidAES256GCM, Parameters: asn1.RawValue{FullBytes: params}}
+	default:
+		return pkix.AlgorithmIdentifier{}, nil, fmt.Errorf("unsupported cipher %q", cipherName)
+	}
+
+	params, err := asn1.Marshal(pbes2Params{KeyDerivationFunc: kdfAlgorithm, EncryptionScheme: encryptionScheme})
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+	return pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}}, ciphertext, nil
+}
+
+// pbes2Decrypt decrypts ciphertext encrypted with PBES2, using PBKDF2 or
+// scrypt and AES-256-CBC or AES-256-GCM
+func pbes2Decrypt(password []byte, algorithm pkix.AlgorithmIdentifier, ciphertext []byte) ([]byte, error) {
+	if !algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported private key encryption %v, expected PBES2", algorithm.Algorithm)
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse PBES2 parameters: %w", err)
+	}
+
+	// Derive the key
+	var key []byte
+	kdf := params.KeyDerivationFunc
+	switch {
+	case kdf.Algorithm.Equal(oidPBKDF2):
+		var p pbkdf2Params
+		if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &p); err != nil {
+			return nil, fmt.Errorf("failed to parse PBKDF2 parameters: %w", err)
+		}
+		var h func() hash.Hash
+		switch prf := p.PRF.Algorithm; {
+		case len(prf) == 0 || prf.Equal(oidHMACWithSHA1):
+			h = sha1.New
+		case prf.Equal(oidHMACWithSHA256):
+			h = sha256.New
+		case prf.Equal(oidHMACWithSHA512):
+			h = sha512.New
+		default:
+			return nil, fmt.Errorf("unsupported PBKDF2 pseudorandom function %v", prf)
+		}
+		key = pbkdf2.Key(password, p.Salt, p.Iterations, 32, h)
+	case kdf.Algorithm.Equal(oidScrypt):
+		var p scryptParams
+		if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &p); err != nil {
+			return nil, fmt.Errorf("failed to parse scrypt parameters: %w", err)
+		}
+		var err error
+		if key, err = scrypt.Key(password, p.Salt, p.CostParameter, p.BlockSize, p.ParallelizationParameter, 32); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key derivation function %v", kdf.Algorithm)
+	}
+
+	// Decrypt
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	scheme := params.EncryptionScheme
+	switch {
+	case scheme.Algorithm.Equal(oidAES256CBC):
+		iv := scheme.Parameters.Bytes
+		if len(iv) != aes.BlockSize || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+			return nil, fmt.Errorf("invalid AES-256-CBC encrypted private key")
+		}
+		plaintext := make([]byte, len(ciphertext))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+		padding := int(plaintext[len(plaintext)-1])
+		if padding == 0 || padding > aes.BlockSize ||
+			!bytes.Equal(plaintext[len(plaintext)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+			return nil, fmt.Errorf("failed to decrypt private key: wrong passphrase")
+		}
+		return plaintext[:len(plaintext)-padding], nil
+	case scheme.Algorithm.Equal(oidAES256GCM):
+		var p gcmParams
+		if _, err := asn1.Unmarshal(scheme.Parameters.FullBytes, &p); err != nil {
+			return nil, fmt.Errorf("failed to parse AES-GCM parameters: %w", err)
+		}
+		aead, err := cipher.NewGCMWithNonceSize(block, len(p.Nonce))
+		if err != nil {
+			return nil, err
+		}
+		if p.ICVLen != aead.Overhead() {
+			return nil, fmt.Errorf("unsupported AES-GCM tag length %d", p.ICVLen)
+		}
+		plaintext, err := aead.Open(nil, p.Nonce, ciphertext, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt private key: wrong passphrase")
+		}
+		return plaintext, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key cipher %v", scheme.Algorithm)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	"context"
+	"encoding/asn1"
+	"encoding/pem"
+	"testing"
+
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
+)
+
+func TestEncryptPrivateKeyPEM(t *testing.T) {
+	_, privateKey, err := GenerateKey(cryptoprovider.Go, "ML-DSA-44", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM, err := EncodePrivateKeyPEM("ML-DSA-44", privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	passphrase := []byte("correct horse battery staple")
+
+	tests := []struct {
+		cipher string
+		kdf    string
+		oid    asn1.ObjectIdentifier
+	}{
+		{cipher: CipherAES256CBC, kdf: KDFPBKDF2, oid: oidPBKDF2},
+		{cipher: CipherAES256GCM, kdf: KDFPBKDF2, oid: oidPBKDF2},
+		{cipher: CipherAES256CBC, kdf: KDFScrypt, oid: oidScrypt},
+		{cipher: CipherAES256GCM, kdf: KDFScrypt, oid: oidScrypt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cipher+"/"+tt.kdf, func(t *testing.T) {
+			encrypted, err := EncryptPrivateKeyPEM([]byte(keyPEM), passphrase, tt.cipher, tt.kdf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !IsEncryptedPrivateKeyPEM([]byte(encrypted)) {
+				t.Fatal("encrypted key is not an ENCRYPTED PRIVATE KEY")
+			}
+
+			// The key is encrypted with PBES2 and the requested KDF
+			block, _ := pem.Decode([]byte(encrypted))
+			var info encryptedPrivateKeyInfo
+			if _, err := asn1.Unmarshal(block.Bytes, &info); err != nil {
+				t.Fatal(err)
+			}
+			var params pbes2Params
+			if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+				t.Fatal(err)
+			}
+			if !info.Algorithm.Algorithm.Equal(oidPBES2) || !params.KeyDerivationFunc.Algorithm.Equal(tt.oid) {
+				t.Errorf("encryption = %v with %v, want PBES2 with %v", info.Algorithm.Algorithm, params.KeyDerivationFunc.Algorithm, tt.oid)
+			}
+
+			decrypted, err := DecryptPrivateKeyPEM([]byte(encrypted), passphrase)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, []byte(keyPEM)) {
+				t.Error("decrypted key does not match")
+			}
+
+			if _, err := DecryptPrivateKeyPEM([]byte(encrypted), []byte("wrong")); err == nil {
+				t.Error("wrong passphrase was accepted")
+			}
+		})
+	}
+}
+
+func TestEncryptPrivateKeyPEMErrors(t *testing.T) {
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{0x30, 0x00}})
+	rsaPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: []byte{0x30, 0x00}})
+
+	tests := []struct {
+		name   string
+		key    []byte
+		cipher string
+		kdf    string
+	}{
+		{name: "not PKCS#8", key: rsaPEM, cipher: CipherAES256CBC, kdf: KDFPBKDF2},
+		{name: "unknown cipher", key: keyPEM, cipher: "DES-CBC", kdf: KDFPBKDF2},
+		{name: "unknown KDF", key: keyPEM, cipher: CipherAES256CBC, kdf: "Argon2id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EncryptPrivateKeyPEM(tt.key, []byte("passphrase"), tt.cipher, tt.kdf); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	if _, err := DecryptPrivateKeyPEM(keyPEM, []byte("passphrase")); err == nil {
+		t.Error("unencrypted key was decrypted")
+	}
+}
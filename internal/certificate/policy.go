@@ -0,0 +1,164 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Policy restricts the certificates an issuer signs. An empty name list
+// allows any name of that type.
+type Policy struct {
+	// DNSNames are allowed DNS names. A leading "*." matches exactly one label.
+	DNSNames []string
+	// IPRanges are allowed IP ranges in CIDR notation.
+	IPRanges []string
+	// URIs are allowed URI patterns, where "*" matches within a path segment.
+	URIs []string
+	// EmailAddresses are allowed email patterns, e.g. "*@example.com".
+	EmailAddresses []string
+	// MaxDays is the longest validity in days; 0 means no limit.
+	MaxDays int
+	// AllowCA allows CA certificates.
+	AllowCA bool
+	// RestrictedUsages are the allowed usages among cert sign, crl sign, ocsp
+	// signing and any, which let a certificate act for the issuer or for any
+	// purpose. A CA certificate may always have cert sign and crl sign.
+	RestrictedUsages []string
+}
+
+// Apply checks the subject alternative names, basic constraints and usages of
+// template against the policy and shortens its validity to the maximum duration.
+func (p Policy) Apply(template *x509.Certificate) error {
+	if template.IsCA && !p.AllowCA {
+		return fmt.Errorf("CA certificates are not allowed by the issuer policy")
+	}
+	for _, usage := range restrictedUsages(template) {
+		if !slices.Contains(p.RestrictedUsages, usage) {
+			return fmt.Errorf("usage %q is not allowed by the issuer policy", usage)
+		}
+	}
+
+	if len(p.DNSNames) > 0 {
+		for _, name := range template.DNSNames {
+			if !matchesAny(p.DNSNames, name, matchDNSName) {
+				return fmt.Errorf("DNS name %q is not allowed by the issuer policy", name)
+			}
+		}
+	}
+
+	if len(p.IPRanges) > 0 {
+		var ranges []*net.IPNet
+		for _, cidr := range p.IPRanges {
+			_, ipRange, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return fmt.Errorf("invalid issuer policy IP range %q: %w", cidr, err)
+			}
+			ranges = append(ranges, ipRange)
+		}
+		for _, ip := range template.IPAddresses {
+			if !containsIP(ranges, ip) {
+				return fmt.Errorf("IP address %s is not allowed by the issuer policy", ip)
+			}
+		}
+	}
+
+	if len(p.URIs) > 0 {
+		for _, uri := range template.URIs {
+			if !matchesAny(p.URIs, uri.String(), matchPattern) {
+				return fmt.Errorf("URI %q is not allowed by the issuer policy", uri)
+			}
+		}
+	}
+
+	if len(p.EmailAddresses) > 0 {
+		for _, email := range template.EmailAddresses {
+			if !matchesAny(p.EmailAddresses, email, matchPattern) {
+				return fmt.Errorf("email address %q is not allowed by the issuer policy", email)
+			}
+		}
+	}
+
+	if p.MaxDays > 0 {
+		if maxNotAfter := template.NotBefore.AddDate(0, 0, p.MaxDays); template.NotAfter.After(maxNotAfter) {
+			template.NotAfter = maxNotAfter
+		}
+	}
+	return nil
+}
+
+// restrictedUsages returns the restricted usages of template, leaving out
+// the signing usages of a CA
+func restrictedUsages(template *x509.Certificate) []string {
+	var usages []string
+	if !template.IsCA && template.KeyUsage&x509.KeyUsageCertSign != 0 {
+		usages = append(usages, UsageCertSign)
+	}
+	if !template.IsCA && template.KeyUsage&x509.KeyUsageCRLSign != 0 {
+		usages = append(usages, UsageCRLSign)
+	}
+	for _, usage := range template.ExtKeyUsage {
+		switch usage {
+		case x509.ExtKeyUsageOCSPSigning:
+			usages = append(usages, UsageOCSPSigning)
+		case x509.ExtKeyUsageAny:
+			usages = append(usages, UsageAny)
+		}
+	}
+	return usages
+}
+
+func matchesAny(patterns []string, name string, match func(pattern, name string) bool) bool {
+	for _, pattern := range patterns {
+		if match(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchDNSName matches a DNS name case-insensitively. A "*." prefix matches
+// exactly one label, so "*.example.com" does not match "a.b.example.com".
+func matchDNSName(pattern, name string) bool {
+	pattern, name = strings.ToLower(pattern), strings.ToLower(name)
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		label, rest, found := strings.Cut(name, ".")
+		return found && label != "" && rest == suffix
+	}
+	return pattern == name
+}
+
+// matchPattern matches a name against a shell pattern in which "*" does not
+// cross a "/"
+func matchPattern(pattern, name string) bool {
+	matched, err := path.Match(pattern, name)
+	return err == nil && matched
+}
+
+func containsIP(ranges []*net.IPNet, ip net.IP) bool {
+	for _, ipRange := range ranges {
+		if ipRange.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"crypto/x509"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestPolicyApply(t *testing.T) {
+	mustURL := func(s string) *url.URL {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		template x509.Certificate
+		wantErr  bool
+	}{
+		{
+			name:     "empty policy allows any name",
+			template: x509.Certificate{DNSNames: []string{"anything.example.org"}, IPAddresses: []net.IP{net.ParseIP("192.0.2.1")}},
+		},
+		{
+			name:     "exact DNS name",
+			policy:   Policy{DNSNames: []string{"api.example.com"}},
+			template: x509.Certificate{DNSNames: []string{"API.example.com"}},
+		},
+		{
+			name:     "wildcard matches one label",
+			policy:   Policy{DNSNames: []string{"*.example.com"}},
+			template: x509.Certificate{DNSNames: []string{"api.example.com"}},
+		},
+		{
+			name:     "wildcard does not match two labels",
+			policy:   Policy{DNSNames: []string{"*.example.com"}},
+			template: x509.Certificate{DNSNames: []string{"a.b.example.com"}},
+			wantErr:  true,
+		},
+		{
+			name:     "wildcard does not match the parent",
+			policy:   Policy{DNSNames: []string{"*.example.com"}},
+			template: x509.Certificate{DNSNames: []string{"example.com"}},
+			wantErr:  true,
+		},
+		{
+			name:     "wildcard does not match a suffix",
+			policy:   Policy{DNSNames: []string{"*.example.com"}},
+			template: x509.Certificate{DNSNames: []string{"evilexample.com"}},
+			wantErr:  true,
+		},
+		{
+			name:     "one disallowed DNS name",
+			policy:   Policy{DNSNames: []string{"*.example.com"}},
+			template: x509.Certificate{DNSNames: []string{"api.example.com", "api.example.org"}},
+			wantErr:  true,
+		},
+		{
+			name:     "IP in range",
+			policy:   Policy{IPRanges: []string{"10.0.0.0/8", "2001:db8::/32"}},
+			template: x509.Certificate{IPAddresses: []net.IP{net.ParseIP("10.1.2.3"), net.ParseIP("2001:db8::1")}},
+		},
+		{
+			name:     "IP out of range",
+			policy:   Policy{IPRanges: []string{"10.0.0.0/8"}},
+			template: x509.Certificate{IPAddresses: []net.IP{net.ParseIP("192.0.2.1")}},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid IP range",
+			policy:   Policy{IPRanges: []string{"10.0.0.0"}},
+			template: x509.Certificate{IPAddresses: []net.IP{net.ParseIP("10.0.0.1")}},
+			wantErr:  true,
+		},
+		{
+			name:     "URI within a path segment",
+			policy:   Policy{URIs: []string{"spiffe://cluster.local/ns/*/sa/*"}},
+			template: x509.Certificate{URIs: []*url.URL{mustURL("spiffe://cluster.local/ns/default/sa/api")}},
+		},
+		{
+			name:     "URI across path segments",
+			policy:   Policy{URIs: []string{"spiffe://cluster.local/ns/*"}},
+			template: x509.Certificate{URIs: []*url.URL{mustURL("spiffe://cluster.local/ns/default/sa/api")}},
+			wantErr:  true,
+		},
+		{
+			name:     "email address",
+			policy:   Policy{EmailAddresses: []string{"*@example.com"}},
+			template: x509.Certificate{EmailAddresses: []string{"admin@example.com"}},
+		},
+		{
+			name:     "email address in another domain",
+			policy:   Policy{EmailAddresses: []string{"*@example.com"}},
+			template: x509.Certificate{EmailAddresses: []string{"admin@example.org"}},
+			wantErr:  true,
+		},
+		{
+			name:     "CA refused by default",
+			template: x509.Certificate{BasicConstraintsValid: true, IsCA: true},
+			wantErr:  true,
+		},
+		{
+			name:     "CA allowed",
+			policy:   Policy{AllowCA: true},
+			template: x509.Certificate{BasicConstraintsValid: true, IsCA: true},
+		},
+		{
+			name:     "CA signing usages allowed with the CA",
+			policy:   Policy{AllowCA: true},
+			template: x509.Certificate{BasicConstraintsValid: true, IsCA: true, KeyUsage: x509.KeyUsageCertSign | x509.KeyUsageCRLSign},
+		},
+		{
+			name:     "ordinary usages",
+			template: x509.Certificate{KeyUsage: x509.KeyUsageDigitalSignature, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}},
+		},
+		{
+			name:     "crl sign refused by default",
+			template: x509.Certificate{KeyUsage: x509.KeyUsageDigitalSignature | x509.KeyUsageCRLSign},
+			wantErr:  true,
+		},
+		{
+			name:     "cert sign refused on a leaf",
+			policy:   Policy{AllowCA: true},
+			template: x509.Certificate{KeyUsage: x509.KeyUsageCertSign},
+			wantErr:  true,
+		},
+		{
+			name:     "ocsp signing refused by default",
+			template: x509.Certificate{ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning}},
+			wantErr:  true,
+		},
+		{
+			name:     "any refused by default",
+			template: x509.Certificate{ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageAny}},
+			wantErr:  true,
+		},
+		{
+			name:     "ocsp signing refused on a CA",
+			policy:   Policy{AllowCA: true},
+			template: x509.Certificate{BasicConstraintsValid: true, IsCA: true, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning}},
+			wantErr:  true,
+		},
+		{
+			name:     "restricted usages allowed",
+			policy:   Policy{RestrictedUsages: []string{UsageCRLSign, UsageOCSPSigning}},
+			template: x509.Certificate{KeyUsage: x509.KeyUsageCRLSign, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning}},
+		},
+		{
+			name:     "one restricted usage not allowed",
+			policy:   Policy{RestrictedUsages: []string{UsageOCSPSigning}},
+			template: x509.Certificate{ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning, x509.ExtKeyUsageAny}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := tt.template
+			err := tt.policy.Apply(&template)
+			if tt.wantErr && err == nil {
+				t.Error("expected an error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestPolicyMaxDays(t *testing.T) {
+	notBefore := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		maxDays      int
+		notAfter     time.Time
+		wantNotAfter time.Time
+	}{
+		{name: "no limit", notAfter: notBefore.AddDate(1, 0, 0), wantNotAfter: notBefore.AddDate(1, 0, 0)},
+		{name: "within the limit", maxDays: 90, notAfter: notBefore.AddDate(0, 0, 30), wantNotAfter: notBefore.AddDate(0, 0, 30)},
+		{name: "shortened", maxDays: 90, notAfter: notBefore.AddDate(1, 0, 0), wantNotAfter: notBefore.AddDate(0, 0, 90)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := &x509.Certificate{NotBefore: notBefore, NotAfter: tt.notAfter}
+			if err := (Policy{MaxDays: tt.maxDays}).Apply(template); err != nil {
+				t.Fatal(err)
+			}
+			if !template.NotAfter.Equal(tt.wantNotAfter) {
+				t.Errorf("notAfter = %s, want %s", template.NotAfter, tt.wantNotAfter)
+			}
+		})
+	}
+}
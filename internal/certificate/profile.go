@@ -0,0 +1,166 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Usage names, as used by cert-manager
+const (
+	UsageDigitalSignature  = "digital signature"
+	UsageContentCommitment = "content commitment"
+	UsageCertSign          = "cert sign"
+	UsageCRLSign           = "crl sign"
+	UsageKeyEncipherment   = "key encipherment"
+	UsageServerAuth        = "server auth"
+	UsageClientAuth        = "client auth"
+	UsageCodeSigning       = "code signing"
+	UsageEmailProtection   = "email protection"
+	UsageTimestamping      = "timestamping"
+	UsageOCSPSigning       = "ocsp signing"
+	UsageAny               = "any"
+)
+
+var keyUsages = map[string]x509.KeyUsage{
+	UsageDigitalSignature:  x509.KeyUsageDigitalSignature,
+	UsageContentCommitment: x509.KeyUsageContentCommitment,
+	UsageCertSign:          x509.KeyUsageCertSign,
+	UsageCRLSign:           x509.KeyUsageCRLSign,
+	UsageKeyEncipherment:   x509.KeyUsageKeyEncipherment,
+}
+
+var extKeyUsages = map[string]x509.ExtKeyUsage{
+	UsageServerAuth:      x509.ExtKeyUsageServerAuth,
+	UsageClientAuth:      x509.ExtKeyUsageClientAuth,
+	UsageCodeSigning:     x509.ExtKeyUsageCodeSigning,
+	UsageEmailProtection: x509.ExtKeyUsageEmailProtection,
+	UsageTimestamping:    x509.ExtKeyUsageTimeStamping,
+	UsageOCSPSigning:     x509.ExtKeyUsageOCSPSigning,
+	UsageAny:             x509.ExtKeyUsageAny,
+}
+
+// SetUsages sets the key usages and extended key usages of template from
+// usage names. Cert sign is only allowed on CA certificates. Whether the usages
+// fit the key is checked when the certificate is created: signature keys only
+// support signing usages, ML-KEM keys only key encipherment.
+func SetUsages(template *x509.Certificate, usages []string) error {
+	template.KeyUsage = 0
+	template.ExtKeyUsage = nil
+
+	for _, usage := range usages {
+		if keyUsage, ok := keyUsages[usage]; ok {
+			template.KeyUsage |= keyUsage
+			continue
+		}
+		if extKeyUsage, ok := extKeyUsages[usage]; ok {
+			template.ExtKeyUsage = append(template.ExtKeyUsage, extKeyUsage)
+			continue
+		}
+		return fmt.Errorf("unsupported usage %q", usage)
+	}
+
+	if template.KeyUsage&x509.KeyUsageCertSign != 0 && !template.IsCA {
+		return fmt.Errorf("usage %q requires a CA certificate", UsageCertSign)
+	}
+	return nil
+}
+
+// SetSubjectAltNames validates the subject alternative names and sets them
+// on template.
+func SetSubjectAltNames(template *x509.Certificate, dnsNames, ipAddresses, uris, emailAddresses []string) error {
+	for _, name := range dnsNames {
+		// A wildcard covers one label on the left
+		if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(strings.ToLower(name), "*.")); len(errs) > 0 {
+			return fmt.Errorf("invalid DNS name %q: %s", name, strings.Join(errs, ", "))
+		}
+	}
+	template.DNSNames = dnsNames
+
+	template.IPAddresses = nil
+	for _, address := range ipAddresses {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			return fmt.Errorf("invalid IP address %q", address)
+		}
+		template.IPAddresses = append(template.IPAddresses, ip)
+	}
+
+	template.URIs = nil
+	for _, uri := range uris {
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			return fmt.Errorf("invalid URI %q: %w", uri, err)
+		}
+		if !parsed.IsAbs() {
+			return fmt.Errorf("invalid URI %q: a scheme is required", uri)
+		}
+		template.URIs = append(template.URIs, parsed)
+	}
+
+	for _, email := range emailAddresses {
+		address, err := mail.ParseAddress(email)
+		if err != nil || address.Address != email {
+			return fmt.Errorf("invalid email address %q", email)
+		}
+	}
+	template.EmailAddresses = emailAddresses
+
+	return nil
+}
+
+// ApplyIssuerConstraints checks that parent may sign template and adjusts
+// template to fit below it: the validity ends no later than the parent's,
+// and an intermediate CA inherits the remaining path length when it sets
+// none.
+func ApplyIssuerConstraints(parent, template *x509.Certificate) error {
+	if !parent.IsCA || parent.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return fmt.Errorf("issuer certificate %q is not a CA", parent.Subject.CommonName)
+	}
+	if !template.NotBefore.Before(parent.NotAfter) {
+		return fmt.Errorf("issuer certificate %q expired at %s", parent.Subject.CommonName, parent.NotAfter)
+	}
+	if template.NotAfter.After(parent.NotAfter) {
+		template.NotAfter = parent.NotAfter
+	}
+
+	if !template.IsCA || parent.MaxPathLen < 0 || (parent.MaxPathLen == 0 && !parent.MaxPathLenZero) {
+		return nil
+	}
+	if parent.MaxPathLen == 0 {
+		return fmt.Errorf("issuer certificate %q has a path length of 0 and cannot sign CA certificates", parent.Subject.CommonName)
+	}
+
+	limit := parent.MaxPathLen - 1
+	unlimited := template.MaxPathLen < 0 || (template.MaxPathLen == 0 && !template.MaxPathLenZero)
+	if unlimited {
+		template.MaxPathLen = limit
+		template.MaxPathLenZero = limit == 0
+		return nil
+	}
+	if template.MaxPathLen > limit {
+		return fmt.Errorf("path length %d exceeds the limit of %d set by issuer certificate %q", template.MaxPathLen, limit, parent.Subject.CommonName)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"crypto/x509"
+	"slices"
+	"testing"
+)
+
+func TestSetUsages(t *testing.T) {
+	tests := []struct {
+		name        string
+		usages      []string
+		isCA        bool
+		keyUsage    x509.KeyUsage
+		extKeyUsage []x509.ExtKeyUsage
+		valid       bool
+	}{
+		{
+			name:        "TLS server",
+			usages:      []string{UsageDigitalSignature, UsageServerAuth},
+			keyUsage:    x509.KeyUsageDigitalSignature,
+			extKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			valid:       true,
+		},
+		{
+			name:        "mTLS client and server",
+			usages:      []string{UsageDigitalSignature, UsageKeyEncipherment, UsageServerAuth, UsageClientAuth},
+			keyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+			extKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+			valid:       true,
+		},
+		{
+			name:     "CA",
+			usages:   []string{UsageCertSign, UsageCRLSign},
+			isCA:     true,
+			keyUsage: x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+			valid:    true,
+		},
+		{
+			name:   "cert sign without CA",
+			usages: []string{UsageCertSign},
+		},
+		{
+			name:   "unknown usage",
+			usages: []string{UsageDigitalSignature, "decipher only"},
+		},
+		{
+			name:  "no usages",
+			valid: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Previous usages are replaced
+			template := &x509.Certificate{
+				IsCA:        tt.isCA,
+				KeyUsage:    x509.KeyUsageDataEncipherment,
+				ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+			}
+			err := SetUsages(template, tt.usages)
+			if (err == nil) != tt.valid {
+				t.Fatalf("SetUsages = %v, want valid %t", err, tt.valid)
+			}
+			if !tt.valid {
+				return
+			}
+			if template.KeyUsage != tt.keyUsage {
+				t.Errorf("KeyUsage = %b, want %b", template.KeyUsage, tt.keyUsage)
+			}
+			if !slices.Equal(template.ExtKeyUsage, tt.extKeyUsage) {
+				t.Errorf("ExtKeyUsage = %v, want %v", template.ExtKeyUsage, tt.extKeyUsage)
+			}
+		})
+	}
+}
+
+func TestSetSubjectAltNames(t *testing.T) {
+	tests := []struct {
+		name        string
+		dnsNames    []string
+		ipAddresses []string
+		uris        []string
+		emails      []string
+		valid       bool
+	}{
+		{
+			name:        "all types",
+			dnsNames:    []string{"example.com", "*.example.com"},
+			ipAddresses: []string{"192.0.2.1", "2001:db8::1"},
+			uris:        []string{"spiffe://cluster.local/ns/default/sa/app"},
+			emails:      []string{"admin@example.com"},
+			valid:       true,
+		},
+		{name: "none", valid: true},
+		{name: "uppercase DNS name", dnsNames: []string{"WWW.Example.com"}, valid: true},
+		{name: "invalid DNS name", dnsNames: []string{"exa mple.com"}},
+		{name: "wildcard not on the left", dnsNames: []string{"www.*.example.com"}},
+		{name: "double wildcard", dnsNames: []string{"*.*.example.com"}},
+		{name: "invalid IP address", ipAddresses: []string{"192.0.2.256"}},
+		{name: "relative URI", uris: []string{"/path"}},
+		{name: "unparsable URI", uris: []string{"http://[::1"}},
+		{name: "invalid email address", emails: []string{"admin"}},
+		{name: "email address with a display name", emails: []string{"Admin <admin@example.com>"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := &x509.Certificate{}
+			err := SetSubjectAltNames(template, tt.dnsNames, tt.ipAddresses, tt.uris, tt.emails)
+			if (err == nil) != tt.valid {
+				t.Fatalf("SetSubjectAltNames = %v, want valid %t", err, tt.valid)
+			}
+			if !tt.valid {
+				return
+			}
+
+			if !slices.Equal(template.DNSNames, tt.dnsNames) {
+				t.Errorf("DNSNames = %v, want %v", template.DNSNames, tt.dnsNames)
+			}
+			if len(template.IPAddresses) != len(tt.ipAddresses) {
+				t.Fatalf("IPAddresses = %v, want %v", template.IPAddresses, tt.ipAddresses)
+			}
+			for i, ip := range template.IPAddresses {
+				if ip.String() != tt.ipAddresses[i] {
+					t.Errorf("IPAddresses[%d] = %s, want %s", i, ip, tt.ipAddresses[i])
+				}
+			}
+			if len(template.URIs) != len(tt.uris) {
+				t.Fatalf("URIs = %v, want %v", template.URIs, tt.uris)
+			}
+			for i, uri := range template.URIs {
+				if uri.String() != tt.uris[i] {
+					t.Errorf("URIs[%d] = %s, want %s", i, uri, tt.uris[i])
+				}
+			}
+			if !slices.Equal(template.EmailAddresses, tt.emails) {
+				t.Errorf("EmailAddresses = %v, want %v", template.EmailAddresses, tt.emails)
+			}
+		})
+	}
+}
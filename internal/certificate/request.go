@@ -0,0 +1,104 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+)
+
+// signedCertificateRequest is the PKCS#10 CertificationRequest structure
+type signedCertificateRequest struct {
+	CertificationRequestInfo asn1.RawValue
+	SignatureAlgorithm       pkix.AlgorithmIdentifier
+	Signature                asn1.BitString
+}
+
+// ParseCertificateRequest decodes a PEM or DER PKCS#10 certificate signing
+// request with a post-quantum public key. The signature is not checked.
+func ParseCertificateRequest(data []byte) (*x509.CertificateRequest, PublicKey, error) {
+	csr, err := decodeCertificateRequest(data)
+	if err != nil {
+		return nil, PublicKey{}, err
+	}
+
+	publicKey, err := ParsePublicKey(csr.RawSubjectPublicKeyInfo)
+	if err != nil {
+		return nil, PublicKey{}, err
+	}
+	return csr, publicKey, nil
+}
+
+// CheckCertificateRequestSignature verifies the proof of possession of a
+// certificate signing request: it must be signed by the key it contains, with
+// the algorithm of that key.
+func CheckCertificateRequestSignature(provider string, csr *x509.CertificateRequest, publicKey PublicKey, ctx context.Context) error {
+	var signed signedCertificateRequest
+	if _, err := asn1.Unmarshal(csr.Raw, &signed); err != nil {
+		return fmt.Errorf("failed to parse certificate request: %w", err)
+	}
+	if len(signed.SignatureAlgorithm.Parameters.FullBytes) != 0 {
+		return fmt.Errorf("signature algorithm parameters must be absent")
+	}
+	a, err := lookupSignatureOID(signed.SignatureAlgorithm.Algorithm)
+	if err != nil {
+		return err
+	}
+	if a.name != publicKey.Algorithm {
+		return fmt.Errorf("certificate request is signed with %s, its key is %s", a.name, publicKey.Algorithm)
+	}
+	return CheckSignature(provider, publicKey, csr.RawTBSCertificateRequest, csr.Signature, ctx)
+}
+
+// ParseClassicalCertificateRequest decodes a PEM or DER PKCS#10 certificate
+// signing request with an RSA, ECDSA or Ed25519 public key, as generated by
+// cert-manager, and verifies that it is signed by that key.
+func ParseClassicalCertificateRequest(data []byte) (*x509.CertificateRequest, error) {
+	csr, err := decodeCertificateRequest(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkClassicalPublicKey(csr.PublicKey); err != nil {
+		return nil, err
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid certificate request signature: %w", err)
+	}
+	return csr, nil
+}
+
+// decodeCertificateRequest decodes a PEM or DER PKCS#10 certificate signing
+// request
+func decodeCertificateRequest(data []byte) (*x509.CertificateRequest, error) {
+	der := data
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "CERTIFICATE REQUEST" && block.Type != "NEW CERTIFICATE REQUEST" {
+			return nil, fmt.Errorf("unexpected PEM block %q, want CERTIFICATE REQUEST", block.Type)
+		}
+		der = block.Bytes
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate request: %w", err)
+	}
+	return csr, nil
+}
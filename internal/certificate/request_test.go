@@ -0,0 +1,217 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
+)
+
+type certificationRequestInfo struct {
+	Version    int
+	Subject    asn1.RawValue
+	PublicKey  asn1.RawValue
+	Attributes asn1.RawValue
+}
+
+// newCertificateRequest returns a PEM CSR for publicKey, signed by signer
+// with the signature algorithm OID of algorithm
+func newCertificateRequest(t *testing.T, publicKey PublicKey, algorithm string, signer Signer, parameters asn1.RawValue) []byte {
+	t.Helper()
+	publicKeyDER, err := MarshalPublicKey(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := asn1.Marshal(pkix.Name{CommonName: "request"}.ToRDNSequence())
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := asn1.Marshal(certificationRequestInfo{
+		Subject:    asn1.RawValue{FullBytes: subject},
+		PublicKey:  asn1.RawValue{FullBytes: publicKeyDER},
+		Attributes: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := signer.Sign(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := lookupAlgorithm(algorithm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := asn1.Marshal(signedCertificateRequest{
+		CertificationRequestInfo: asn1.RawValue{FullBytes: info},
+		SignatureAlgorithm:       pkix.AlgorithmIdentifier{Algorithm: a.oid, Parameters: parameters},
+		Signature:                asn1.BitString{Bytes: signature, BitLength: 8 * len(signature)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+func TestCheckCertificateRequestSignature(t *testing.T) {
+	ctx := context.Background()
+	generate := func(algorithm string) Signer {
+		signer, _, err := GenerateKey(cryptoprovider.Go, algorithm, ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signer
+	}
+	key := generate("ML-DSA-65")
+	otherKey := generate("ML-DSA-65")
+	mldsa87 := generate("ML-DSA-87")
+	composite := generate("ML-DSA-65-ECDSA-P256-SHA512")
+
+	tests := []struct {
+		name       string
+		publicKey  PublicKey
+		algorithm  string
+		signer     Signer
+		parameters asn1.RawValue
+		wantErr    error
+	}{
+		{
+			name:      "ML-DSA",
+			publicKey: key.Public(),
+			algorithm: "ML-DSA-65",
+			signer:    key,
+		},
+		{
+			name:      "composite",
+			publicKey: composite.Public(),
+			algorithm: "ML-DSA-65-ECDSA-P256-SHA512",
+			signer:    composite,
+		},
+		{
+			name:      "composite signed with ML-DSA only",
+			publicKey: composite.Public(),
+			algorithm: "ML-DSA-65",
+			signer:    key,
+			wantErr:   errAny,
+		},
+		{
+			name:      "signed by another key",
+			publicKey: key.Public(),
+			algorithm: "ML-DSA-65",
+			signer:    otherKey,
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "signed with another algorithm",
+			publicKey: key.Public(),
+			algorithm: "ML-DSA-87",
+			signer:    mldsa87,
+			wantErr:   errAny,
+		},
+		{
+			name:       "signature algorithm parameters",
+			publicKey:  key.Public(),
+			algorithm:  "ML-DSA-65",
+			signer:     key,
+			parameters: asn1.NullRawValue,
+			wantErr:    errAny,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := newCertificateRequest(t, tt.publicKey, tt.algorithm, tt.signer, tt.parameters)
+			csr, publicKey, err := ParseCertificateRequest(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if publicKey.Algorithm != tt.publicKey.Algorithm {
+				t.Errorf("public key algorithm = %s, want %s", publicKey.Algorithm, tt.publicKey.Algorithm)
+			}
+
+			err = CheckCertificateRequestSignature(cryptoprovider.Go, csr, publicKey, ctx)
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr == errAny && err == nil:
+				t.Error("expected an error")
+			case tt.wantErr != nil && tt.wantErr != errAny && !errors.Is(err, tt.wantErr):
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseCertificateRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "wrong PEM block", data: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{0x30, 0x00}})},
+		{name: "garbage", data: []byte("not a request")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ParseCertificateRequest(tt.data); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseClassicalCertificateRequest(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "classical"},
+		DNSNames: []string{"classical.example.com"},
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	csr, err := ParseClassicalCertificateRequest(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if csr.Subject.CommonName != "classical" {
+		t.Errorf("common name = %q", csr.Subject.CommonName)
+	}
+
+	// Flip a bit of the signature
+	tampered := append([]byte(nil), der...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := ParseClassicalCertificateRequest(tampered); err == nil {
+		t.Error("tampered request was accepted")
+	}
+}
+
+// errAny marks test cases that expect any error
+var errAny = errors.New("any error")
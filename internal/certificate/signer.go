@@ -0,0 +1,83 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"context"
+
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
+)
+
+// Signer signs certificates with a post-quantum private key.
+type Signer interface {
+	// Public returns the public key matching the private key.
+	Public() PublicKey
+	// Sign signs the message with the pure (not pre-hashed) algorithm.
+	Sign(message []byte) ([]byte, error)
+}
+
+// providerSigner signs with a private key held in memory through a crypto
+// provider.
+type providerSigner struct {
+	provider   cryptoprovider.Provider
+	publicKey  PublicKey
+	privateKey []byte
+}
+
+// NewSigner returns a Signer for a raw key pair, backed by the named crypto
+// provider. An empty provider falls back to the operator-wide default.
+func NewSigner(provider string, algorithm string, publicKey []byte, privateKey []byte, ctx context.Context) (Signer, error) {
+	if _, err := lookupSignatureAlgorithm(algorithm); err != nil {
+		return nil, err
+	}
+
+	cryptoProvider, err := cryptoprovider.ForSignature(provider, algorithm, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &providerSigner{
+		provider:   cryptoProvider,
+		publicKey:  PublicKey{Algorithm: algorithm, Bytes: publicKey},
+		privateKey: privateKey,
+	}, nil
+}
+
+func (s *providerSigner) Public() PublicKey {
+	return s.publicKey
+}
+
+func (s *providerSigner) Sign(message []byte) ([]byte, error) {
+	return s.provider.Sign(s.publicKey.Algorithm, s.privateKey, message)
+}
+
+// CheckSignature verifies signature over signed with the public key.
+func CheckSignature(provider string, publicKey PublicKey, signed []byte, signature []byte, ctx context.Context) error {
+	cryptoProvider, err := cryptoprovider.ForSignature(provider, publicKey.Algorithm, ctx)
+	if err != nil {
+		return err
+	}
+
+	valid, err := cryptoProvider.Verify(publicKey.Algorithm, publicKey.Bytes, signed, signature)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
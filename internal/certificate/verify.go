@@ -0,0 +1,135 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// maxChainLength bounds the number of certificates in a verified chain
+const maxChainLength = 10
+
+// VerifyOptions holds the certificates Verify builds a chain from.
+type VerifyOptions struct {
+	// Roots are the trust anchors. A trust anchor may be an intermediate CA.
+	Roots []*x509.Certificate
+	// Intermediates are the candidate certificates between a leaf and a root.
+	Intermediates []*x509.Certificate
+	// CurrentTime is when the chain must be valid.
+	CurrentTime time.Time
+}
+
+// Verify builds a chain from cert to one of the roots and returns it, leaf
+// first. crypto/x509 cannot check post-quantum signatures, so the chain is
+// built and checked here: every certificate must be valid at the current time
+// and have no unhandled critical extensions, every issuer must be a CA with
+// the cert sign usage and room in its path length, and every signature must
+// verify with the key of its issuer. Name constraints and revocation are not
+// checked.
+func Verify(provider string, cert *x509.Certificate, opts VerifyOptions, ctx context.Context) ([]*x509.Certificate, error) {
+	if err := checkValidity(cert, opts.CurrentTime); err != nil {
+		return nil, err
+	}
+	return buildChain(provider, []*x509.Certificate{cert}, opts, ctx)
+}
+
+// buildChain extends chain by an issuer of its last certificate until it
+// reaches a root
+func buildChain(provider string, chain []*x509.Certificate, opts VerifyOptions, ctx context.Context) ([]*x509.Certificate, error) {
+	if len(chain) >= maxChainLength {
+		return nil, fmt.Errorf("certificate chain is longer than %d certificates", maxChainLength)
+	}
+
+	cert := chain[len(chain)-1]
+	lastErr := fmt.Errorf("certificate %q is not issued by a trusted CA", cert.Subject.String())
+	candidates := append(slices.Clone(opts.Roots), opts.Intermediates...)
+	for i, candidate := range candidates {
+		if !bytes.Equal(candidate.RawSubject, cert.RawIssuer) {
+			continue
+		}
+		if len(cert.AuthorityKeyId) > 0 && len(candidate.SubjectKeyId) > 0 && !bytes.Equal(cert.AuthorityKeyId, candidate.SubjectKeyId) {
+			continue
+		}
+		if slices.ContainsFunc(chain, candidate.Equal) {
+			continue
+		}
+		// Every certificate below the issuer except the leaf is an intermediate
+		if err := checkIssuer(provider, candidate, cert, len(chain)-1, opts.CurrentTime, ctx); err != nil {
+			lastErr = err
+			continue
+		}
+
+		extended := append(slices.Clone(chain), candidate)
+		if i < len(opts.Roots) {
+			return extended, nil
+		}
+		verified, err := buildChain(provider, extended, opts, ctx)
+		if err == nil {
+			return verified, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// checkIssuer checks that issuer may sign cert with intermediates CA
+// certificates below it, and that it did
+func checkIssuer(provider string, issuer, cert *x509.Certificate, intermediates int, now time.Time, ctx context.Context) error {
+	name := issuer.Subject.String()
+	if !issuer.BasicConstraintsValid || !issuer.IsCA {
+		return fmt.Errorf("issuer certificate %q is not a CA", name)
+	}
+	if issuer.KeyUsage != 0 && issuer.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return fmt.Errorf("issuer certificate %q lacks the cert sign usage", name)
+	}
+	if (issuer.MaxPathLen > 0 || issuer.MaxPathLenZero) && intermediates > issuer.MaxPathLen {
+		return fmt.Errorf("issuer certificate %q allows %d intermediate CAs below it", name, issuer.MaxPathLen)
+	}
+	if err := checkValidity(issuer, now); err != nil {
+		return err
+	}
+
+	issuerKey, err := ParsePublicKey(issuer.RawSubjectPublicKeyInfo)
+	if err != nil {
+		return fmt.Errorf("issuer certificate %q: %w", name, err)
+	}
+	if err := CheckCertificateSignature(provider, cert, issuerKey, ctx); err != nil {
+		return fmt.Errorf("certificate %q is not signed by %q: %w", cert.Subject.String(), name, err)
+	}
+	return nil
+}
+
+// checkValidity checks that cert is valid at now and has no critical
+// extensions it cannot be checked against
+func checkValidity(cert *x509.Certificate, now time.Time) error {
+	name := cert.Subject.String()
+	if now.Before(cert.NotBefore) {
+		return fmt.Errorf("certificate %q is not valid before %s", name, cert.NotBefore)
+	}
+	if now.After(cert.NotAfter) {
+		return fmt.Errorf("certificate %q expired at %s", name, cert.NotAfter)
+	}
+	if len(cert.UnhandledCriticalExtensions) > 0 {
+		return fmt.Errorf("certificate %q has unhandled critical extension %v", name, cert.UnhandledCriticalExtensions[0])
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/certificate"
+)
+
+// certManagerCertificateRequest is the cert-manager CertificateRequest kind.
+// It is handled as unstructured data, so the operator does not depend on the
+// cert-manager API module.
+var certManagerCertificateRequest = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "CertificateRequest"}
+
+// Condition types and reasons of the cert-manager external issuer contract
+const (
+	certManagerConditionReady          = "Ready"
+	certManagerConditionApproved       = "Approved"
+	certManagerConditionDenied         = "Denied"
+	certManagerConditionInvalidRequest = "InvalidRequest"
+
+	certManagerReasonPending = "Pending"
+	certManagerReasonIssued  = "Issued"
+	certManagerReasonFailed  = "Failed"
+	certManagerReasonDenied  = "Denied"
+)
+
+// certManagerDefaultDuration is the validity cert-manager requests when a
+// Certificate sets none
+const certManagerDefaultDuration = 90 * 24 * time.Hour
+
+// certManagerRequest holds the fields of a cert-manager CertificateRequest
+// that the external issuer reads and writes
+type certManagerRequest struct {
+	Spec struct {
+		Request   []byte `json:"request"`
+		IssuerRef struct {
+			Name  string `json:"name"`
+			Kind  string `json:"kind,omitempty"`
+			Group string `json:"group,omitempty"`
+		} `json:"issuerRef"`
+		Duration *metav1.Duration `json:"duration,omitempty"`
+		IsCA     bool             `json:"isCA,omitempty"`
+		Usages   []string         `json:"usages,omitempty"`
+	} `json:"spec"`
+	Status certManagerRequestStatus `json:"status,omitempty"`
+}
+
+type certManagerRequestStatus struct {
+	Conditions  []certManagerCondition `json:"conditions,omitempty"`
+	Certificate []byte                 `json:"certificate,omitempty"`
+	CA          []byte                 `json:"ca,omitempty"`
+	FailureTime *metav1.Time           `json:"failureTime,omitempty"`
+}
+
+type certManagerCondition struct {
+	Type               string       `json:"type"`
+	Status             string       `json:"status"`
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+	Reason             string       `json:"reason,omitempty"`
+	Message            string       `json:"message,omitempty"`
+	ObservedGeneration int64        `json:"observedGeneration,omitempty"`
+}
+
+// condition returns the condition of type conditionType, or nil
+func (s *certManagerRequestStatus) condition(conditionType string) *certManagerCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == conditionType {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// setReady sets the Ready condition, keeping its transition time while its
+// status does not change
+func (s *certManagerRequestStatus) setReady(status metav1.ConditionStatus, reason, message string, generation int64) {
+	ready := s.condition(certManagerConditionReady)
+	if ready == nil {
+		s.Conditions = append(s.Conditions, certManagerCondition{Type: certManagerConditionReady})
+		ready = &s.Conditions[len(s.Conditions)-1]
+	}
+	if ready.Status != string(status) || ready.LastTransitionTime == nil {
+		now := metav1.Now()
+		ready.LastTransitionTime = &now
+	}
+	ready.Status = string(status)
+	ready.Reason = reason
+	ready.Message = message
+	ready.ObservedGeneration = generation
+}
+
+// CertManagerCertificateRequestReconciler signs cert-manager CertificateRequests
+// that reference a QuantumIssuer or QuantumClusterIssuer, implementing the
+// cert-manager external issuer contract
+type CertManagerCertificateRequestReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificaterequests,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificaterequests/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumissuers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumclusterissuers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumsignaturekeypairs,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile signs an approved CertificateRequest with the referenced issuer
+// and publishes the certificate chain in its status. Denied, failed and
+// issued requests are left alone, as cert-manager creates a new request to
+// retry.
+func (r *CertManagerCertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	// Fetch the CertificateRequest
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(certManagerCertificateRequest)
+	if err := r.Get(ctx, req.NamespacedName, object); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !referencesQubeSecIssuer(object) {
+		return ctrl.Result{}, nil
+	}
+	request := &certManagerRequest{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, request); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to read CertificateRequest: %w", err)
+	}
+	status := &request.Status
+	generation := object.GetGeneration()
+
+	// If already issued, failed or denied, no need to reconcile again
+	if ready := status.condition(certManagerConditionReady); ready != nil &&
+		(ready.Status == string(metav1.ConditionTrue) || ready.Reason == certManagerReasonFailed || ready.Reason == certManagerReasonDenied) {
+		return ctrl.Result{}, nil
+	}
+	if invalid := status.condition(certManagerConditionInvalidRequest); invalid != nil && invalid.Status == string(metav1.ConditionTrue) {
+		return ctrl.Result{}, nil
+	}
+
+	// Wait for an approver to approve or deny the request
+	if denied := status.condition(certManagerConditionDenied); denied != nil && denied.Status == string(metav1.ConditionTrue) {
+		now := metav1.Now()
+		status.FailureTime = &now
+		status.setReady(metav1.ConditionFalse, certManagerReasonDenied, "The CertificateRequest was denied by an approval controller", generation)
+		return ctrl.Result{}, r.updateStatus(object, status, ctx)
+	}
+	if approved := status.condition(certManagerConditionApproved); approved == nil || approved.Status != string(metav1.ConditionTrue) {
+		if ready := status.condition(certManagerConditionReady); ready == nil || ready.Reason != certManagerReasonPending {
+			status.setReady(metav1.ConditionFalse, certManagerReasonPending, "Waiting for the CertificateRequest to be approved", generation)
+			return ctrl.Result{}, r.updateStatus(object, status, ctx)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Parse the request and verify that it is signed by its key. RSA, ECDSA and
+	// Ed25519 keys generated by cert-manager are certified by the post-quantum
+	// CA like post-quantum keys.
+	csr, publicKey, err := certificate.ParseCertificateRequest(request.Spec.Request)
+	classical := err != nil
+	if classical {
+		csr, err = certificate.ParseClassicalCertificateRequest(request.Spec.Request)
+	} else {
+		err = certificate.CheckCertificateRequestSignature("", csr, publicKey, ctx)
+	}
+	if err != nil {
+		log.Error(err, "Invalid certificate request")
+		return ctrl.Result{}, r.fail(object, status, fmt.Sprintf("Invalid certificate request: %v", err), ctx)
+	}
+
+	// Build the certificate template from the request
+	template, err := certManagerTemplate(request, csr)
+	if err != nil {
+		log.Error(err, "Invalid certificate request")
+		return ctrl.Result{}, r.fail(object, status, err.Error(), ctx)
+	}
+
+	// Get the issuing CA
+	ref := qubeseciov1.IssuerReference{Name: request.Spec.IssuerRef.Name, Kind: request.Spec.IssuerRef.Kind}
+	issuer, err := getIssuingCA(r.Client, ref, object.GetNamespace(), ctx)
+	if err != nil {
+		log.Error(err, "Failed to get issuer")
+		if errors.Is(err, errInvalidIssuer) {
+			return ctrl.Result{}, r.fail(object, status, err.Error(), ctx)
+		}
+		status.setReady(metav1.ConditionFalse, certManagerReasonPending, err.Error(), generation)
+		_ = r.updateStatus(object, status, ctx)
+		return ctrl.Result{}, err
+	}
+
+	// Apply the issuer policy and sign
+	var der []byte
+	keyAlgorithm := publicKey.Algorithm
+	if classical {
+		keyAlgorithm = csr.PublicKeyAlgorithm.String()
+		der, err = issuer.signClassicalKey(template, csr.PublicKey)
+	} else {
+		der, err = issuer.sign(template, publicKey)
+	}
+	if err != nil {
+		log.Error(err, "Certificate request denied")
+		return ctrl.Result{}, r.fail(object, status, fmt.Sprintf("Certificate request denied: %v", err), ctx)
+	}
+	log.Info("Signed cert-manager certificate request", "serialNumber", hex.EncodeToString(template.SerialNumber.Bytes()), "publicKeyAlgorithm", keyAlgorithm)
+
+	// Update status to Issued
+	status.Certificate = []byte(certificate.EncodeCertificatePEM(append([][]byte{der}, issuer.chain...)...))
+	status.CA = []byte(certificate.EncodeCertificatePEM(issuer.root))
+	status.setReady(metav1.ConditionTrue, certManagerReasonIssued, fmt.Sprintf("Certificate issued by %s %s", issuerKind(&ref), ref.Name), generation)
+	if err := r.updateStatus(object, status, ctx); err != nil {
+		log.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// fail marks a request as failed for good; cert-manager retries with a new
+// request
+func (r *CertManagerCertificateRequestReconciler) fail(object *unstructured.Unstructured, status *certManagerRequestStatus, message string, ctx context.Context) error {
+	now := metav1.Now()
+	status.FailureTime = &now
+	status.setReady(metav1.ConditionFalse, certManagerReasonFailed, message, object.GetGeneration())
+	return r.updateStatus(object, status, ctx)
+}
+
+// updateStatus writes status to the CertificateRequest
+func (r *CertManagerCertificateRequestReconciler) updateStatus(object *unstructured.Unstructured, status *certManagerRequestStatus, ctx context.Context) error {
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(status)
+	if err != nil {
+		return err
+	}
+	object.Object["status"] = data
+	return r.Status().Update(ctx, object)
+}
+
+// certManagerTemplate returns the certificate template for a CertificateRequest.
+// The subject and subject alternative names come from the CSR; usages and
+// validity from the spec. CA certificates cannot be requested.
+func certManagerTemplate(request *certManagerRequest, csr *x509.CertificateRequest) (*x509.Certificate, error) {
+	if request.Spec.IsCA {
+		return nil, fmt.Errorf("CA certificates cannot be requested from a QubeSec issuer")
+	}
+
+	duration := certManagerDefaultDuration
+	if request.Spec.Duration != nil && request.Spec.Duration.Duration > 0 {
+		duration = request.Spec.Duration.Duration
+	}
+
+	usages := request.Spec.Usages
+	if len(usages) == 0 {
+		usages = []string{certificate.UsageDigitalSignature, certificate.UsageKeyEncipherment}
+	}
+
+	now := time.Now().Truncate(time.Second)
+	return signingRequestTemplate(csr, now, now.Add(duration), usages)
+}
+
+// referencesQubeSecIssuer reports whether a CertificateRequest references an
+// issuer of the qubesec.io group
+func referencesQubeSecIssuer(object client.Object) bool {
+	request, ok := object.(*unstructured.Unstructured)
+	if !ok {
+		return false
+	}
+	group, _, _ := unstructured.NestedString(request.Object, "spec", "issuerRef", "group")
+	kind, _, _ := unstructured.NestedString(request.Object, "spec", "issuerRef", "kind")
+	return group == qubeseciov1.GroupVersion.Group && (kind == kindQuantumIssuer || kind == kindQuantumClusterIssuer)
+}
+
+// CertManagerInstalled reports whether the cert-manager CertificateRequest API
+// is served by the cluster.
+func CertManagerInstalled(mapper meta.RESTMapper) (bool, error) {
+	_, err := mapper.RESTMapping(certManagerCertificateRequest.GroupKind(), certManagerCertificateRequest.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CertManagerCertificateRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	request := &unstructured.Unstructured{}
+	request.SetGroupVersionKind(certManagerCertificateRequest)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(request, builder.WithPredicates(predicate.NewPredicateFuncs(referencesQubeSecIssuer))).
+		Named("certmanager-certificaterequest").
+		Complete(r)
+}
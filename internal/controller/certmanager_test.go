@@ -0,0 +1,314 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/certificate"
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
+)
+
+var _ = Describe("CertManagerCertificateRequest Controller", Ordered, func() {
+	const (
+		namespace  = "default"
+		issuerName = "certmanager-ca"
+		keyName    = "certmanager-ca-key"
+	)
+	ctx := context.Background()
+
+	var reconciler *CertManagerCertificateRequestReconciler
+	var ca *issuingCA
+
+	BeforeAll(func() {
+		reconciler = &CertManagerCertificateRequestReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+
+		By("creating the key pair of the issuer")
+		keyPair := &qubeseciov1.QuantumSignatureKeyPair{
+			ObjectMeta: metav1.ObjectMeta{Name: keyName, Namespace: namespace},
+			Spec: qubeseciov1.QuantumSignatureKeyPairSpec{
+				Algorithm:      "ML-DSA-65",
+				CryptoProvider: cryptoprovider.Go,
+			},
+		}
+		Expect(k8sClient.Create(ctx, keyPair)).To(Succeed())
+		keyPairReconciler := &QuantumSignatureKeyPairReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+		Eventually(func(g Gomega) {
+			_, _ = keyPairReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(keyPair)})
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(keyPair), keyPair)).To(Succeed())
+			g.Expect(keyPair.Status.Status).To(Equal("Success"))
+		}).Should(Succeed())
+
+		By("creating the issuer")
+		issuer := &qubeseciov1.QuantumIssuer{
+			ObjectMeta: metav1.ObjectMeta{Name: issuerName, Namespace: namespace},
+			Spec: qubeseciov1.IssuerSpec{
+				KeyPairRef: qubeseciov1.ObjectReference{Name: keyName},
+			},
+		}
+		Expect(k8sClient.Create(ctx, issuer)).To(Succeed())
+		issuerReconciler := &QuantumIssuerReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+		Eventually(func(g Gomega) {
+			_, _ = issuerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(issuer)})
+			var err error
+			ca, err = getIssuingCA(k8sClient, qubeseciov1.IssuerReference{Name: issuerName}, namespace, ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+		}).Should(Succeed())
+	})
+
+	AfterAll(func() {
+		By("deleting the issuer and its key pair")
+		issuer := &qubeseciov1.QuantumIssuer{ObjectMeta: metav1.ObjectMeta{Name: issuerName, Namespace: namespace}}
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, issuer))).To(Succeed())
+		keyPair := &qubeseciov1.QuantumSignatureKeyPair{ObjectMeta: metav1.ObjectMeta{Name: keyName, Namespace: namespace}}
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, keyPair))).To(Succeed())
+	})
+
+	// createRequest creates a CertificateRequest for csrPEM with the issuer,
+	// approved, denied or neither
+	createRequest := func(name string, csrPEM []byte, usages []string, approval string) types.NamespacedName {
+		object := &unstructured.Unstructured{}
+		object.SetGroupVersionKind(certManagerCertificateRequest)
+		object.SetName(name)
+		object.SetNamespace(namespace)
+		usageList := make([]any, 0, len(usages))
+		for _, usage := range usages {
+			usageList = append(usageList, usage)
+		}
+		object.Object["spec"] = map[string]any{
+			"request": base64.StdEncoding.EncodeToString(csrPEM),
+			"issuerRef": map[string]any{
+				"name":  issuerName,
+				"kind":  kindQuantumIssuer,
+				"group": qubeseciov1.GroupVersion.Group,
+			},
+			"usages": usageList,
+		}
+		Expect(k8sClient.Create(ctx, object)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, object))).To(Succeed())
+		})
+
+		// cert-manager's approver sets Approved or Denied on the status
+		if approval != "" {
+			object.Object["status"] = map[string]any{
+				"conditions": []any{map[string]any{
+					"type":               approval,
+					"status":             string(metav1.ConditionTrue),
+					"reason":             "test",
+					"lastTransitionTime": metav1.Now().UTC().Format("2006-01-02T15:04:05Z"),
+				}},
+			}
+			Expect(k8sClient.Status().Update(ctx, object)).To(Succeed())
+		}
+		return client.ObjectKeyFromObject(object)
+	}
+
+	// reconcileRequest reconciles a CertificateRequest and returns its status
+	reconcileRequest := func(key types.NamespacedName) *certManagerRequest {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		object := &unstructured.Unstructured{}
+		object.SetGroupVersionKind(certManagerCertificateRequest)
+		Expect(k8sClient.Get(ctx, key, object)).To(Succeed())
+		request := &certManagerRequest{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, request)).To(Succeed())
+		return request
+	}
+
+	// issuedCertificate checks that an issued request holds a certificate
+	// signed by the issuer and returns it
+	issuedCertificate := func(request *certManagerRequest) *x509.Certificate {
+		ready := request.Status.condition(certManagerConditionReady)
+		Expect(ready).NotTo(BeNil())
+		Expect(ready.Status).To(Equal(string(metav1.ConditionTrue)), ready.Message)
+		Expect(ready.Reason).To(Equal(certManagerReasonIssued))
+
+		chain := decodeCertificates(request.Status.Certificate)
+		Expect(chain).NotTo(BeEmpty())
+		leaf, err := x509.ParseCertificate(chain[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(certificate.CheckCertificateSignature(cryptoprovider.Go, leaf, ca.signer.Public(), ctx)).To(Succeed())
+		Expect(decodeCertificates(request.Status.CA)).To(Equal([][]byte{ca.root}))
+		return leaf
+	}
+
+	Context("When a request is not approved", func() {
+		It("should wait for approval", func() {
+			key := createRequest("certmanager-pending", newPostQuantumCSR("pending.example.com"), []string{"digital signature", "server auth"}, "")
+			request := reconcileRequest(key)
+
+			ready := request.Status.condition(certManagerConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(string(metav1.ConditionFalse)))
+			Expect(ready.Reason).To(Equal(certManagerReasonPending))
+			Expect(request.Status.Certificate).To(BeEmpty())
+		})
+	})
+
+	Context("When a request is denied", func() {
+		It("should mark it denied without signing", func() {
+			key := createRequest("certmanager-denied", newPostQuantumCSR("denied.example.com"), []string{"digital signature", "server auth"}, certManagerConditionDenied)
+			request := reconcileRequest(key)
+
+			ready := request.Status.condition(certManagerConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(string(metav1.ConditionFalse)))
+			Expect(ready.Reason).To(Equal(certManagerReasonDenied))
+			Expect(request.Status.FailureTime).NotTo(BeNil())
+			Expect(request.Status.Certificate).To(BeEmpty())
+		})
+	})
+
+	Context("When a post-quantum request is approved", func() {
+		It("should issue a certificate signed by the issuer", func() {
+			key := createRequest("certmanager-issued", newPostQuantumCSR("issued.example.com"), []string{"digital signature", "server auth"}, certManagerConditionApproved)
+			request := reconcileRequest(key)
+			leaf := issuedCertificate(request)
+
+			Expect(leaf.DNSNames).To(Equal([]string{"issued.example.com"}))
+			Expect(leaf.ExtKeyUsage).To(Equal([]x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}))
+			Expect(leaf.IsCA).To(BeFalse())
+			publicKey, err := certificate.ParsePublicKey(leaf.RawSubjectPublicKeyInfo)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(publicKey.Algorithm).To(Equal("ML-DSA-44"))
+
+			By("leaving the issued request alone")
+			Expect(reconcileRequest(key).Status.Certificate).To(Equal(request.Status.Certificate))
+		})
+
+		It("should refuse usages the issuer policy does not allow", func() {
+			key := createRequest("certmanager-ocsp-signing", newPostQuantumCSR("ocsp.example.com"), []string{"digital signature", "ocsp signing"}, certManagerConditionApproved)
+			request := reconcileRequest(key)
+
+			ready := request.Status.condition(certManagerConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Reason).To(Equal(certManagerReasonFailed))
+			Expect(request.Status.Certificate).To(BeEmpty())
+		})
+	})
+
+	Context("When a classical request is approved", func() {
+		It("should certify the classical key with the post-quantum CA", func() {
+			key := createRequest("certmanager-classical", newClassicalCSR("classical.example.com"), []string{"digital signature", "key encipherment", "server auth"}, certManagerConditionApproved)
+			leaf := issuedCertificate(reconcileRequest(key))
+
+			Expect(leaf.DNSNames).To(Equal([]string{"classical.example.com"}))
+			Expect(leaf.PublicKey).To(BeAssignableToTypeOf(&ecdsa.PublicKey{}))
+		})
+	})
+
+	It("should ignore requests deleted before they are reconciled", func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "certmanager-missing", Namespace: namespace}})
+		Expect(err).NotTo(HaveOccurred())
+
+		object := &unstructured.Unstructured{}
+		object.SetGroupVersionKind(certManagerCertificateRequest)
+		err = k8sClient.Get(ctx, types.NamespacedName{Name: "certmanager-missing", Namespace: namespace}, object)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
+
+// newPostQuantumCSR returns a PEM ML-DSA-44 certificate request for dnsName
+func newPostQuantumCSR(dnsName string) []byte {
+	signer, _, err := certificate.GenerateKey(cryptoprovider.Go, "ML-DSA-44", context.Background())
+	Expect(err).NotTo(HaveOccurred())
+	spki, err := certificate.MarshalPublicKey(signer.Public())
+	Expect(err).NotTo(HaveOccurred())
+
+	// The signature algorithm has the OID of the key, without parameters
+	var publicKeyInfo struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	_, err = asn1.Unmarshal(spki, &publicKeyInfo)
+	Expect(err).NotTo(HaveOccurred())
+
+	subject, err := asn1.Marshal(pkix.Name{CommonName: dnsName}.ToRDNSequence())
+	Expect(err).NotTo(HaveOccurred())
+	sanExtension, err := asn1.Marshal([]asn1.RawValue{{Class: asn1.ClassContextSpecific, Tag: 2, Bytes: []byte(dnsName)}})
+	Expect(err).NotTo(HaveOccurred())
+	extensions, err := asn1.Marshal([]pkix.Extension{{Id: asn1.ObjectIdentifier{2, 5, 29, 17}, Value: sanExtension}})
+	Expect(err).NotTo(HaveOccurred())
+	extensionRequest, err := asn1.Marshal(struct {
+		Type   asn1.ObjectIdentifier
+		Values []asn1.RawValue `asn1:"set"`
+	}{
+		Type:   asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 14},
+		Values: []asn1.RawValue{{FullBytes: extensions}},
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	info, err := asn1.Marshal(struct {
+		Version    int
+		Subject    asn1.RawValue
+		PublicKey  asn1.RawValue
+		Attributes []asn1.RawValue `asn1:"tag:0"`
+	}{
+		Subject:    asn1.RawValue{FullBytes: subject},
+		PublicKey:  asn1.RawValue{FullBytes: spki},
+		Attributes: []asn1.RawValue{{FullBytes: extensionRequest}},
+	})
+	Expect(err).NotTo(HaveOccurred())
+	signature, err := signer.Sign(info)
+	Expect(err).NotTo(HaveOccurred())
+
+	der, err := asn1.Marshal(struct {
+		Info               asn1.RawValue
+		SignatureAlgorithm pkix.AlgorithmIdentifier
+		Signature          asn1.BitString
+	}{
+		Info:               asn1.RawValue{FullBytes: info},
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: publicKeyInfo.Algorithm.Algorithm},
+		Signature:          asn1.BitString{Bytes: signature, BitLength: 8 * len(signature)},
+	})
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+// newClassicalCSR returns a PEM ECDSA P-256 certificate request for dnsName
+func newClassicalCSR(dnsName string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: dnsName},
+		DNSNames: []string{dnsName},
+	}, key)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
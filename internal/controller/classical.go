@@ -0,0 +1,357 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/certificate"
+)
+
+// classicalCA is the classical CA of an issuer that is ready to sign the
+// classical companions of certificates
+type classicalCA struct {
+	certificate *x509.Certificate
+	key         crypto.Signer
+	// chain is the DER CA certificate and its intermediates, without the root
+	chain [][]byte
+	// root is the DER root certificate that anchors the chain
+	root []byte
+}
+
+// classicalSecretName returns the name of the Secret holding the classical
+// companion of the certificate stored in secretName
+func classicalSecretName(secretName string, classical *qubeseciov1.ClassicalCertificate) string {
+	if classical.SecretName != "" {
+		return classical.SecretName
+	}
+	return secretName + "-classical"
+}
+
+// classicalAlgorithm returns the algorithm of a classical companion certificate
+func classicalAlgorithm(classical *qubeseciov1.ClassicalCertificate) string {
+	if classical.Algorithm != "" {
+		return classical.Algorithm
+	}
+	return "ECDSA-P256"
+}
+
+// issueClassicalCA creates the Secret holding the classical CA of an issuer
+// unless it exists. Like the post-quantum CA, it is never reissued.
+func issueClassicalCA(c client.Client, scheme *runtime.Scheme, issuer client.Object, spec *qubeseciov1.IssuerSpec, status *qubeseciov1.IssuerStatus, ca *issuingCA, ctx context.Context) error {
+	log := logf.FromContext(ctx)
+
+	if spec.Classical == nil {
+		if status.Classical != nil {
+			status.Classical = nil
+			_ = c.Status().Update(ctx, issuer)
+		}
+		return nil
+	}
+
+	namespace := status.CertificateReference.Namespace
+	secretName := classicalSecretName(status.CertificateReference.Name, spec.Classical)
+	reference := &qubeseciov1.ObjectReference{Name: secretName, Namespace: namespace}
+
+	// If the Secret already exists, restore the status from it
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, client.ObjectKey{Name: secretName, Namespace: namespace}, secret)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if err == nil {
+		if !metav1.IsControlledBy(secret, issuer) {
+			return fmt.Errorf("%w: secret %s exists and is not owned by the issuer", errInvalidIssuer, secretName)
+		}
+		current := secretCertificate(secret)
+		if current == nil {
+			return fmt.Errorf("existing secret %s has no classical CA certificate", secretName)
+		}
+		if status.Classical == nil || status.Classical.SerialNumber != hex.EncodeToString(current.SerialNumber.Bytes()) {
+			status.Classical = classicalStatus(current, reference)
+			_ = c.Status().Update(ctx, issuer)
+		}
+		return nil
+	}
+
+	key, err := certificate.GenerateClassicalKey(classicalAlgorithm(spec.Classical))
+	if err != nil {
+		return err
+	}
+
+	// The classical CA has the subject and validity of the post-quantum CA
+	template := certificate.ClassicalTemplate(ca.certificate)
+	var der []byte
+	var chain [][]byte
+	var root []byte
+	if ref := spec.IssuerRef; ref == nil {
+		if der, err = certificate.SignClassical(template, key, nil, nil); err != nil {
+			return err
+		}
+		root = der
+	} else {
+		parent, err := getIssuingCA(c, *ref, issuer.GetNamespace(), ctx)
+		if err != nil {
+			return err
+		}
+		parentCA, err := parent.classicalCA(ref)
+		if err != nil {
+			return err
+		}
+		if der, err = certificate.SignClassical(template, key, parentCA.certificate, parentCA.key); err != nil {
+			return fmt.Errorf("%w: %v", errInvalidIssuer, err)
+		}
+		chain = parentCA.chain
+		root = parentCA.root
+	}
+	issued, err := x509.ParseCertificate(der)
+	if err != nil {
+		return err
+	}
+	keyPEM, err := certificate.EncodeClassicalKeyPEM(key)
+	if err != nil {
+		return err
+	}
+
+	newSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"tls.crt": []byte(certificate.EncodeCertificatePEM(append([][]byte{der}, chain...)...)),
+			"tls.key": []byte(keyPEM),
+			"ca.crt":  []byte(certificate.EncodeCertificatePEM(root)),
+		},
+	}
+
+	// Set owner reference to the issuer for Secret
+	if err := ctrl.SetControllerReference(issuer, newSecret, scheme); err != nil {
+		return err
+	}
+	if err := c.Create(ctx, newSecret); err != nil {
+		return err
+	}
+	log.Info("Created classical CA certificate", "secret", secretName, "serialNumber", hex.EncodeToString(issued.SerialNumber.Bytes()))
+
+	status.Classical = classicalStatus(issued, reference)
+	_ = c.Status().Update(ctx, issuer)
+
+	return nil
+}
+
+// classicalCA returns the classical CA of the issuer ref refers to, which
+// must have one
+func (ca *issuingCA) classicalCA(ref *qubeseciov1.IssuerReference) (*classicalCA, error) {
+	if !ca.hasClassical {
+		return nil, fmt.Errorf("%w: %s %s has no classical CA", errInvalidIssuer, issuerKind(ref), ref.Name)
+	}
+	if ca.classical == nil {
+		return nil, fmt.Errorf("classical CA of %s %s %w", issuerKind(ref), ref.Name, errIssuerNotReady)
+	}
+	return ca.classical, nil
+}
+
+// loadClassicalCA returns the classical CA certificate and key stored in the
+// referenced Secret
+func loadClassicalCA(c client.Reader, ref *qubeseciov1.ObjectReference, ctx context.Context) (*classicalCA, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get classical CA secret %s: %w", ref.Name, err)
+	}
+
+	chain := decodeCertificates(secret.Data["tls.crt"])
+	roots := decodeCertificates(secret.Data["ca.crt"])
+	if len(chain) == 0 || len(roots) == 0 {
+		return nil, fmt.Errorf("classical CA secret %s is missing tls.crt or ca.crt", ref.Name)
+	}
+	caCertificate, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid classical CA certificate in secret %s: %w", ref.Name, err)
+	}
+	key, err := certificate.ParseClassicalKeyPEM(secret.Data["tls.key"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid classical CA key in secret %s: %w", ref.Name, err)
+	}
+
+	// The certificate of a root CA is only published in ca.crt
+	chain = slices.DeleteFunc(chain, func(der []byte) bool {
+		return bytes.Equal(der, roots[0])
+	})
+
+	return &classicalCA{
+		certificate: caCertificate,
+		key:         key,
+		chain:       chain,
+		root:        roots[0],
+	}, nil
+}
+
+// issueClassicalCertificate issues the classical companion of the certificate
+// cert of a QuantumCertificate, unless the current companion was issued with
+// it. It reports whether the status changed.
+func (r *QuantumCertificateReconciler) issueClassicalCertificate(QuantumCertificate *qubeseciov1.QuantumCertificate, secretName string, cert *x509.Certificate, ctx context.Context) (bool, error) {
+	log := logf.FromContext(ctx)
+
+	classical := QuantumCertificate.Spec.Classical
+	if classical == nil {
+		changed := QuantumCertificate.Status.Classical != nil
+		QuantumCertificate.Status.Classical = nil
+		return changed, nil
+	}
+
+	algorithm := classicalAlgorithm(classical)
+	name := classicalSecretName(secretName, classical)
+	if name == secretName {
+		return false, fmt.Errorf("classical.secretName must differ from the certificate Secret %s", secretName)
+	}
+	reference := &qubeseciov1.ObjectReference{Name: name, Namespace: QuantumCertificate.Namespace}
+
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: QuantumCertificate.Namespace}, secret)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return false, err
+	}
+	exists := err == nil
+	if exists && !metav1.IsControlledBy(secret, QuantumCertificate) {
+		return false, fmt.Errorf("secret %s exists and is not owned by QuantumCertificate %s", name, QuantumCertificate.Name)
+	}
+
+	// Keep a companion that was issued together with the certificate
+	var key crypto.Signer
+	if exists {
+		current := secretCertificate(secret)
+		currentKey, keyErr := certificate.ParseClassicalKeyPEM(secret.Data["tls.key"])
+		if current != nil && keyErr == nil && classicalCertificateMatches(current, cert, algorithm) {
+			if QuantumCertificate.Status.Classical == nil ||
+				QuantumCertificate.Status.Classical.SerialNumber != hex.EncodeToString(current.SerialNumber.Bytes()) {
+				QuantumCertificate.Status.Classical = classicalStatus(current, reference)
+				return true, nil
+			}
+			return false, nil
+		}
+		if keyErr == nil && QuantumCertificate.Spec.RotationPolicy == rotationPolicyNever && certificate.ClassicalAlgorithm(currentKey.Public()) == algorithm {
+			key = currentKey
+		}
+	}
+	if key == nil {
+		if key, err = certificate.GenerateClassicalKey(algorithm); err != nil {
+			return false, err
+		}
+	}
+
+	// Get the classical CA of the issuer; without one the companion is self-signed
+	var issuer *classicalCA
+	if ref := QuantumCertificate.Spec.IssuerRef; ref != nil {
+		ca, err := getIssuingCA(r.Client, *ref, QuantumCertificate.Namespace, ctx)
+		if err != nil {
+			return false, err
+		}
+		if issuer, err = ca.classicalCA(ref); err != nil {
+			return false, err
+		}
+	}
+
+	var parent *x509.Certificate
+	var parentKey crypto.Signer
+	if issuer != nil {
+		parent, parentKey = issuer.certificate, issuer.key
+	}
+	der, err := certificate.SignClassical(certificate.ClassicalTemplate(cert), key, parent, parentKey)
+	if err != nil {
+		return false, fmt.Errorf("classical certificate generation failed: %w", err)
+	}
+	issued, err := x509.ParseCertificate(der)
+	if err != nil {
+		return false, err
+	}
+	keyPEM, err := certificate.EncodeClassicalKeyPEM(key)
+	if err != nil {
+		return false, err
+	}
+
+	// tls.crt holds the certificate and its intermediates, ca.crt the root
+	certificatePEM := certificate.EncodeCertificatePEM(der)
+	caPEM := certificatePEM
+	if issuer != nil {
+		certificatePEM += certificate.EncodeCertificatePEM(issuer.chain...)
+		caPEM = certificate.EncodeCertificatePEM(issuer.root)
+	}
+	data := map[string][]byte{
+		"tls.crt": []byte(certificatePEM),
+		"tls.key": []byte(keyPEM),
+		"ca.crt":  []byte(caPEM),
+	}
+
+	if exists {
+		secret.Data = data
+		if err := r.Update(ctx, secret); err != nil {
+			return false, err
+		}
+	} else {
+		newSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: QuantumCertificate.Namespace,
+			},
+			Data: data,
+		}
+
+		// Set owner reference to QuantumCertificate for Secret
+		if err := ctrl.SetControllerReference(QuantumCertificate, newSecret, r.Scheme); err != nil {
+			return false, err
+		}
+		if err := r.Create(ctx, newSecret); err != nil {
+			return false, err
+		}
+	}
+	log.Info("Issued classical certificate", "secret", name, "serialNumber", hex.EncodeToString(issued.SerialNumber.Bytes()))
+
+	QuantumCertificate.Status.Classical = classicalStatus(issued, reference)
+	return true, nil
+}
+
+// classicalCertificateMatches reports whether current is a classical companion
+// of cert with the given algorithm, issued together with it
+func classicalCertificateMatches(current, cert *x509.Certificate, algorithm string) bool {
+	return certificate.ClassicalAlgorithm(current.PublicKey) == algorithm &&
+		bytes.Equal(current.RawSubject, cert.RawSubject) && bytes.Equal(current.RawIssuer, cert.RawIssuer) &&
+		current.NotBefore.Equal(cert.NotBefore) && current.NotAfter.Equal(cert.NotAfter)
+}
+
+// classicalStatus describes a classical certificate stored in reference
+func classicalStatus(cert *x509.Certificate, reference *qubeseciov1.ObjectReference) *qubeseciov1.ClassicalCertificateStatus {
+	return &qubeseciov1.ClassicalCertificateStatus{
+		CertificateReference: reference,
+		Algorithm:            certificate.ClassicalAlgorithm(cert.PublicKey),
+		SerialNumber:         hex.EncodeToString(cert.SerialNumber.Bytes()),
+	}
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/certificate"
+)
+
+const (
+	// crlDataKey is the key of the PEM-encoded CRL in its ConfigMap or Secret
+	crlDataKey = "ca.crl"
+
+	crlTargetConfigMap = "ConfigMap"
+	crlTargetSecret    = "Secret"
+)
+
+// crlConfig is the CRL configuration of an issuer with defaults applied
+type crlConfig struct {
+	target   string
+	name     string
+	validity time.Duration
+}
+
+// issuerCRLConfig returns the CRL configuration of an issuer spec
+func issuerCRLConfig(issuerName string, spec *qubeseciov1.IssuerSpec) crlConfig {
+	config := crlConfig{target: crlTargetConfigMap, name: issuerName + "-crl", validity: 24 * time.Hour}
+	if crl := spec.CRL; crl != nil {
+		if crl.Target != "" {
+			config.target = crl.Target
+		}
+		if crl.Name != "" {
+			config.name = crl.Name
+		}
+		if crl.ValidityHours > 0 {
+			config.validity = time.Duration(crl.ValidityHours) * time.Hour
+		}
+	}
+	return config
+}
+
+// newObject returns an empty object of the CRL target kind
+func (config crlConfig) newObject() client.Object {
+	if config.target == crlTargetSecret {
+		return &corev1.Secret{}
+	}
+	return &corev1.ConfigMap{}
+}
+
+// publishCRL signs a CRL listing the revoked certificates of an issuer and
+// publishes it, unless the published CRL is current. It returns when the CRL
+// must be regenerated.
+func publishCRL(c client.Client, scheme *runtime.Scheme, issuer client.Object, kind string, spec *qubeseciov1.IssuerSpec, status *qubeseciov1.IssuerStatus, ca *issuingCA, ctx context.Context) (time.Time, error) {
+	log := logf.FromContext(ctx)
+
+	config := issuerCRLConfig(issuer.GetName(), spec)
+	namespace := status.CertificateReference.Namespace
+	reference := &qubeseciov1.ObjectReference{Name: config.name, Namespace: namespace}
+
+	entries, err := revokedCertificateEntries(c, kind, issuer, ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Get the published CRL
+	object := config.newObject()
+	err = c.Get(ctx, client.ObjectKey{Name: config.name, Namespace: namespace}, object)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return time.Time{}, err
+	}
+	exists := err == nil
+	if exists && !metav1.IsControlledBy(object, issuer) {
+		return time.Time{}, fmt.Errorf("%w: %s %s exists and is not owned by the issuer", errInvalidIssuer, config.target, config.name)
+	}
+	current := readCRL(object)
+
+	// Keep a current CRL, restoring the status from it
+	now := time.Now().Truncate(time.Second)
+	if current != nil && crlIsCurrent(current, ca.certificate, entries, config.validity, now) {
+		if status.CRL == nil || current.Number == nil || status.CRL.Number != current.Number.Int64() {
+			setCRLStatus(status, current, reference)
+			_ = c.Status().Update(ctx, issuer)
+		}
+		return crlRefreshTime(current), nil
+	}
+
+	// CRL numbers increase monotonically, even if the published CRL was deleted
+	number := big.NewInt(1)
+	if current != nil && current.Number != nil {
+		number.Add(current.Number, big.NewInt(1))
+	}
+	if status.CRL != nil && status.CRL.Number >= number.Int64() {
+		number.SetInt64(status.CRL.Number + 1)
+	}
+
+	der, err := certificate.CreateRevocationList(&x509.RevocationList{
+		Number:                    number,
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(config.validity),
+		RevokedCertificateEntries: entries,
+	}, ca.certificate, ca.signer)
+	if err != nil {
+		return time.Time{}, err
+	}
+	crl, err := x509.ParseRevocationList(der)
+	if err != nil {
+		return time.Time{}, err
+	}
+	crlPEM := pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+
+	switch o := object.(type) {
+	case *corev1.Secret:
+		if o.Data == nil {
+			o.Data = map[string][]byte{}
+		}
+		o.Data[crlDataKey] = crlPEM
+	case *corev1.ConfigMap:
+		if o.Data == nil {
+			o.Data = map[string]string{}
+		}
+		o.Data[crlDataKey] = string(crlPEM)
+	}
+
+	if exists {
+		if err := c.Update(ctx, object); err != nil {
+			return time.Time{}, err
+		}
+	} else {
+		object.SetName(config.name)
+		object.SetNamespace(namespace)
+
+		// Set owner reference to the issuer for the CRL
+		if err := ctrl.SetControllerReference(issuer, object, scheme); err != nil {
+			return time.Time{}, err
+		}
+		if err := c.Create(ctx, object); err != nil {
+			return time.Time{}, err
+		}
+	}
+	log.Info("Published CRL", "name", config.name, "number", number, "revokedCertificates", len(entries))
+
+	setCRLStatus(status, crl, reference)
+	_ = c.Status().Update(ctx, issuer)
+
+	return crlRefreshTime(crl), nil
+}
+
+// revokedCertificateEntries returns the CRL entries of the revocations that
+// name an issuer, ordered by serial number
+func revokedCertificateEntries(c client.Reader, kind string, issuer client.Object, ctx context.Context) ([]x509.RevocationListEntry, error) {
+	// A QuantumIssuer only revokes certificates in its namespace. Revocations
+	// of a QuantumClusterIssuer come from every namespace, and are only marked
+	// Revoked for a certificate held by a resource in their own namespace.
+	var options []client.ListOption
+	if kind == kindQuantumIssuer {
+		options = append(options, client.InNamespace(issuer.GetNamespace()))
+	}
+	revocations := &qubeseciov1.QuantumCertificateRevocationList{}
+	if err := c.List(ctx, revocations, options...); err != nil {
+		return nil, fmt.Errorf("failed to list QuantumCertificateRevocations: %w", err)
+	}
+
+	var entries []x509.RevocationListEntry
+	for _, revocation := range revocations.Items {
+		ref := revocation.Status.IssuerRef
+		if revocation.Status.Status != "Revoked" || ref == nil || ref.Name != issuer.GetName() || issuerKind(ref) != kind {
+			continue
+		}
+		serialNumber, err := parseSerialNumber(revocation.Status.SerialNumber)
+		if err != nil {
+			continue
+		}
+		var revocationTime time.Time
+		if revocation.Status.RevocationTime != nil {
+			revocationTime = revocation.Status.RevocationTime.Truncate(time.Second)
+		}
+		entries = append(entries, x509.RevocationListEntry{
+			SerialNumber:   serialNumber,
+			RevocationTime: revocationTime,
+			ReasonCode:     certificate.ReasonCodes[revocation.Spec.Reason],
+		})
+	}
+
+	// A certificate revoked twice is listed once, with its first revocation
+	slices.SortFunc(entries, func(a, b x509.RevocationListEntry) int {
+		if n := a.SerialNumber.Cmp(b.SerialNumber); n != 0 {
+			return n
+		}
+		return a.RevocationTime.Compare(b.RevocationTime)
+	})
+	return slices.CompactFunc(entries, func(a, b x509.RevocationListEntry) bool {
+		return a.SerialNumber.Cmp(b.SerialNumber) == 0
+	}), nil
+}
+
+// readCRL returns the CRL published in a ConfigMap or Secret, or nil
+func readCRL(object client.Object) *x509.RevocationList {
+	var data []byte
+	switch o := object.(type) {
+	case *corev1.Secret:
+		data = o.Data[crlDataKey]
+	case *corev1.ConfigMap:
+		data = []byte(o.Data[crlDataKey])
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "X509 CRL" {
+		return nil
+	}
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	if err != nil {
+		return nil
+	}
+	return crl
+}
+
+// crlIsCurrent reports whether crl was issued by the CA certificate with the
+// configured validity, lists exactly entries and is not due for refresh
+func crlIsCurrent(crl *x509.RevocationList, caCertificate *x509.Certificate, entries []x509.RevocationListEntry, validity time.Duration, now time.Time) bool {
+	if !bytes.Equal(crl.RawIssuer, caCertificate.RawSubject) || !bytes.Equal(crl.AuthorityKeyId, caCertificate.SubjectKeyId) {
+		return false
+	}
+	if crl.NextUpdate.Sub(crl.ThisUpdate) != validity || !now.Before(crlRefreshTime(crl)) {
+		return false
+	}
+	return slices.EqualFunc(crl.RevokedCertificateEntries, entries, func(a, b x509.RevocationListEntry) bool {
+		return a.SerialNumber.Cmp(b.SerialNumber) == 0 && a.RevocationTime.Equal(b.RevocationTime) && a.ReasonCode == b.ReasonCode
+	})
+}
+
+// crlRefreshTime returns when a CRL is replaced, halfway through its validity
+func crlRefreshTime(crl *x509.RevocationList) time.Time {
+	return crl.ThisUpdate.Add(crl.NextUpdate.Sub(crl.ThisUpdate) / 2)
+}
+
+// setCRLStatus records a published CRL in the issuer status
+func setCRLStatus(status *qubeseciov1.IssuerStatus, crl *x509.RevocationList, reference *qubeseciov1.ObjectReference) {
+	thisUpdate := metav1.NewTime(crl.ThisUpdate)
+	nextUpdate := metav1.NewTime(crl.NextUpdate)
+	status.CRL = &qubeseciov1.IssuerCRLStatus{
+		Reference:           reference,
+		RevokedCertificates: len(crl.RevokedCertificateEntries),
+		ThisUpdate:          &thisUpdate,
+		NextUpdate:          &nextUpdate,
+	}
+	if crl.Number != nil {
+		status.CRL.Number = crl.Number.Int64()
+	}
+}
+
+// revocationIssuer maps a QuantumCertificateRevocation to the issuer of kind
+// whose CRL lists it
+func revocationIssuer(kind string) handler.MapFunc {
+	return func(ctx context.Context, object client.Object) []reconcile.Request {
+		revocation, ok := object.(*qubeseciov1.QuantumCertificateRevocation)
+		if !ok || revocation.Status.IssuerRef == nil || issuerKind(revocation.Status.IssuerRef) != kind {
+			return nil
+		}
+		key := types.NamespacedName{Name: revocation.Status.IssuerRef.Name}
+		if kind == kindQuantumIssuer {
+			key.Namespace = revocation.Namespace
+		}
+		return []reconcile.Request{{NamespacedName: key}}
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+)
+
+// CRLServer serves the published CRLs of all issuers over HTTP, DER-encoded
+// as application/pkix-crl. The CRL of a QuantumIssuer is served at
+// /crl/<namespace>/<name>.crl and that of a QuantumClusterIssuer at
+// /crl/<name>.crl. It runs on every replica, not only the leader.
+type CRLServer struct {
+	client.Reader
+
+	// BindAddress is the address the server listens on
+	BindAddress string
+}
+
+// Start serves CRLs until ctx is done.
+func (s *CRLServer) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("crl-server")
+
+	server := &http.Server{
+		Addr:              s.BindAddress,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Info("Serving CRLs", "address", s.BindAddress)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection returns false so that all replicas serve CRLs.
+func (s *CRLServer) NeedLeaderElection() bool {
+	return false
+}
+
+// ServeHTTP serves the CRL of the issuer named by the request path.
+func (s *CRLServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path, ok := strings.CutPrefix(r.URL.Path, "/crl/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	parts := strings.Split(strings.TrimSuffix(path, ".crl"), "/")
+
+	var issuer client.Object
+	var spec *qubeseciov1.IssuerSpec
+	var status *qubeseciov1.IssuerStatus
+	switch len(parts) {
+	case 1:
+		clusterIssuer := &qubeseciov1.QuantumClusterIssuer{}
+		issuer, spec, status = clusterIssuer, &clusterIssuer.Spec, &clusterIssuer.Status
+		if err := s.Get(r.Context(), client.ObjectKey{Name: parts[0]}, issuer); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+	case 2:
+		namespacedIssuer := &qubeseciov1.QuantumIssuer{}
+		issuer, spec, status = namespacedIssuer, &namespacedIssuer.Spec, &namespacedIssuer.Status
+		if err := s.Get(r.Context(), client.ObjectKey{Name: parts[1], Namespace: parts[0]}, issuer); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	// Read the CRL the issuer published
+	if status.CRL == nil || status.CRL.Reference == nil {
+		http.NotFound(w, r)
+		return
+	}
+	object := issuerCRLConfig(issuer.GetName(), spec).newObject()
+	if err := s.Get(r.Context(), client.ObjectKey{Name: status.CRL.Reference.Name, Namespace: status.CRL.Reference.Namespace}, object); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	crl := readCRL(object)
+	if crl == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Relying parties may cache the CRL until it is replaced
+	w.Header().Set("Content-Type", "application/pkix-crl")
+	w.Header().Set("Last-Modified", crl.ThisUpdate.UTC().Format(http.TimeFormat))
+	w.Header().Set("Expires", crlRefreshTime(crl).UTC().Format(http.TimeFormat))
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(crl.Raw)
+	}
+}
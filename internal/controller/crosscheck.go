@@ -0,0 +1,67 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
+)
+
+// crossCheckAnnotation on a Namespace names a second crypto provider that
+// independently re-checks every KEM and signature operation in it.
+const crossCheckAnnotation = "qubesec.io/cross-check"
+
+// statusImplementationMismatch is reported when the cross-check provider
+// disagrees with the primary provider.
+const statusImplementationMismatch = "ImplementationMismatch"
+
+// statusCrossCheckUnavailable is reported when the namespace asks for a
+// cross-check that cannot be performed, for example because the provider is
+// not in this build or the algorithm cannot be reproduced by it.
+const statusCrossCheckUnavailable = "CrossCheckUnavailable"
+
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// withCrossCheck returns a context that enables cross-checking when the
+// namespace carries the cross-check annotation.
+func withCrossCheck(ctx context.Context, c client.Client, namespace string) (context.Context, error) {
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		return ctx, err
+	}
+
+	if provider := ns.Annotations[crossCheckAnnotation]; provider != "" {
+		return cryptoprovider.WithCrossCheck(ctx, provider), nil
+	}
+	return ctx, nil
+}
+
+// cryptoFailureStatus returns the status to report for a failed crypto operation.
+func cryptoFailureStatus(err error) string {
+	if cryptoprovider.IsMismatch(err) {
+		return statusImplementationMismatch
+	}
+	if errors.Is(err, cryptoprovider.ErrCrossCheckUnavailable) {
+		return statusCrossCheckUnavailable
+	}
+	return "Failed"
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/certificate"
+	"github.com/QubeSec/QubeSec/internal/keypair"
+)
+
+// Keys of the encrypted private key written next to the plaintext key
+const (
+	encryptedPrivateKeyKey = "encrypted-private-key"
+	encryptedTLSKeyKey     = "tls-encrypted.key"
+)
+
+// encryptionModeOnly replaces the plaintext private key with the encrypted key
+const encryptionModeOnly = "Only"
+
+// keyEncryptionAnnotation records a digest of the settings, inputs and outputs
+// of the private key encryption of a Secret, so the key is only encrypted again
+// when one of them changes. The passphrase is not part of the digest, which is
+// readable by anyone who can read the Secret metadata; whether it changed is
+// checked by decrypting the encrypted key.
+const keyEncryptionAnnotation = "qubesec.io/key-encryption-digest"
+
+// setPrivateKeyEncryption encrypts the private key under keyKey of a Secret as
+// encryption configures: into encryptedKey next to the plaintext key, or in
+// place of it. Without encryption, the encrypted key is removed. It reports
+// whether the Secret changed.
+func setPrivateKeyEncryption(c client.Reader, encryption *qubeseciov1.PrivateKeyEncryption, namespace string, secret *corev1.Secret, keyKey, encryptedKey string, ctx context.Context) (bool, error) {
+	if encryption == nil {
+		if certificate.IsEncryptedPrivateKeyPEM(secret.Data[keyKey]) {
+			return false, fmt.Errorf("%s in secret %s is encrypted and privateKeyEncryption is not set", keyKey, secret.Name)
+		}
+		_, hasEncrypted := secret.Data[encryptedKey]
+		_, hasDigest := secret.Annotations[keyEncryptionAnnotation]
+		delete(secret.Data, encryptedKey)
+		delete(secret.Annotations, keyEncryptionAnnotation)
+		return hasEncrypted || hasDigest, nil
+	}
+
+	passphrase, err := privateKeyPassphrase(c, encryption, namespace, ctx)
+	if err != nil {
+		return false, err
+	}
+	spec, err := json.Marshal(encryption)
+	if err != nil {
+		return false, err
+	}
+	digest := func() string {
+		h := sha256.New()
+		for _, input := range [][]byte{spec, secret.Data[keyKey], secret.Data[encryptedKey]} {
+			writeDigestInput(h, input)
+		}
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	// Keep a key encrypted from the same inputs with the current passphrase
+	if secret.Annotations[keyEncryptionAnnotation] == digest() {
+		encrypted := secret.Data[encryptedKey]
+		if encryption.Mode == encryptionModeOnly {
+			encrypted = secret.Data[keyKey]
+		}
+		if _, err := certificate.DecryptPrivateKeyPEM(encrypted, passphrase); err == nil {
+			return false, nil
+		}
+	}
+
+	keyPEM := secret.Data[keyKey]
+	if certificate.IsEncryptedPrivateKeyPEM(keyPEM) {
+		if keyPEM, err = certificate.DecryptPrivateKeyPEM(keyPEM, passphrase); err != nil {
+			return false, fmt.Errorf("failed to decrypt %s in secret %s, restore the passphrase it was encrypted with: %w", keyKey, secret.Name, err)
+		}
+	}
+	encrypted, err := certificate.EncryptPrivateKeyPEM(keyPEM, passphrase, privateKeyCipher(encryption), privateKeyKDF(encryption))
+	if err != nil {
+		return false, fmt.Errorf("failed to encrypt %s in secret %s: %w", keyKey, secret.Name, err)
+	}
+
+	if encryption.Mode == encryptionModeOnly {
+		secret.Data[keyKey] = []byte(encrypted)
+		delete(secret.Data, encryptedKey)
+	} else {
+		secret.Data[keyKey] = keyPEM
+		secret.Data[encryptedKey] = []byte(encrypted)
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[keyEncryptionAnnotation] = digest()
+
+	logf.FromContext(ctx).Info("Encrypted private key", "secret", secret.Name, "mode", encryption.Mode)
+	return true, nil
+}
+
+// encryptKeyPairSecret encrypts the private key of a key pair Secret as
+// encryption configures. Only keys in the PKCS8 format can be encrypted.
+func encryptKeyPairSecret(c client.Reader, encryption *qubeseciov1.PrivateKeyEncryption, namespace string, secret *corev1.Secret, ctx context.Context) (bool, error) {
+	if encryption != nil && keypair.Format(secret.Data["public-key"]) != keypair.FormatPKCS8 {
+		return false, fmt.Errorf("privateKeyEncryption needs the %s key format", keypair.FormatPKCS8)
+	}
+	return setPrivateKeyEncryption(c, encryption, namespace, secret, "private-key", encryptedPrivateKeyKey, ctx)
+}
+
+// plaintextPrivateKey returns a PEM private key in plaintext, decrypting an
+// encrypted PKCS#8 key with the passphrase of encryption
+func plaintextPrivateKey(c client.Reader, encryption *qubeseciov1.PrivateKeyEncryption, namespace string, data []byte, ctx context.Context) ([]byte, error) {
+	if !certificate.IsEncryptedPrivateKeyPEM(data) {
+		return data, nil
+	}
+	if encryption == nil {
+		return nil, fmt.Errorf("private key is encrypted and privateKeyEncryption is not set")
+	}
+	passphrase, err := privateKeyPassphrase(c, encryption, namespace, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return certificate.DecryptPrivateKeyPEM(data, passphrase)
+}
+
+// privateKeyPassphrase returns the passphrase of a private key from its Secret
+// in namespace
+func privateKeyPassphrase(c client.Reader, encryption *qubeseciov1.PrivateKeyEncryption, namespace string, ctx context.Context) ([]byte, error) {
+	ref := encryption.PassphraseSecretRef
+	key := encryption.PassphraseKey
+	if key == "" {
+		key = "passphrase"
+	}
+
+	// A passphrase Secret in another namespace would be read for anyone who
+	// can create a resource that references it
+	secretNamespace, err := localReferenceNamespace(&ref, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("passphraseSecretRef: %w", err)
+	}
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: secretNamespace}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get private key passphrase secret: %w", err)
+	}
+	passphrase, ok := secret.Data[key]
+	if !ok || len(passphrase) == 0 {
+		return nil, fmt.Errorf("key %q not found in private key passphrase secret %s", key, ref.Name)
+	}
+	return passphrase, nil
+}
+
+// privateKeyCipher returns the cipher of an encrypted private key
+func privateKeyCipher(encryption *qubeseciov1.PrivateKeyEncryption) string {
+	if encryption.Cipher != "" {
+		return encryption.Cipher
+	}
+	return certificate.CipherAES256CBC
+}
+
+// privateKeyKDF returns the key derivation function of an encrypted private key
+func privateKeyKDF(encryption *qubeseciov1.PrivateKeyEncryption) string {
+	if encryption.KDF != "" {
+		return encryption.KDF
+	}
+	return certificate.KDFPBKDF2
+}
+
+// usesPassphraseSecret reports whether the passphrase of encryption, set on an
+// object in namespace, is in the Secret
+func usesPassphraseSecret(encryption *qubeseciov1.PrivateKeyEncryption, namespace string, secret client.Object) bool {
+	return encryption != nil && encryption.PassphraseSecretRef.Name == secret.GetName() &&
+		referenceNamespace(&encryption.PassphraseSecretRef, namespace) == secret.GetNamespace()
+}
+
+// secretKEMKeyPairs returns the QuantumKEMKeyPairs whose private key
+// passphrase or imported keys are in the Secret, so a changed passphrase
+// encrypts the key again and changed keys are imported again
+func (r *QuantumKEMKeyPairReconciler) secretKEMKeyPairs(ctx context.Context, object client.Object) []reconcile.Request {
+	keyPairs := &qubeseciov1.QuantumKEMKeyPairList{}
+	if err := r.List(ctx, keyPairs); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list QuantumKEMKeyPairs")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, keyPair := range keyPairs.Items {
+		if usesPassphraseSecret(keyPair.Spec.PrivateKeyEncryption, keyPair.Namespace, object) ||
+			usesImportSecret(keyPair.Spec.ImportFrom, keyPair.Namespace, object) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: keyPair.Name, Namespace: keyPair.Namespace}})
+		}
+	}
+	return requests
+}
+
+// secretSignatureKeyPairs returns the QuantumSignatureKeyPairs whose private
+// key passphrase or imported keys are in the Secret, so a changed passphrase
+// encrypts the key again and changed keys are imported again
+func (r *QuantumSignatureKeyPairReconciler) secretSignatureKeyPairs(ctx context.Context, object client.Object) []reconcile.Request {
+	keyPairs := &qubeseciov1.QuantumSignatureKeyPairList{}
+	if err := r.List(ctx, keyPairs); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list QuantumSignatureKeyPairs")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, keyPair := range keyPairs.Items {
+		if usesPassphraseSecret(keyPair.Spec.PrivateKeyEncryption, keyPair.Namespace, object) ||
+			usesImportSecret(keyPair.Spec.ImportFrom, keyPair.Namespace, object) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: keyPair.Name, Namespace: keyPair.Namespace}})
+		}
+	}
+	return requests
+}
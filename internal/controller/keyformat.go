@@ -0,0 +1,61 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/QubeSec/QubeSec/internal/certificate"
+	"github.com/QubeSec/QubeSec/internal/keypair"
+)
+
+// reencodeKeyPairSecret re-encodes the keys in the Secret of a key pair in
+// format, unless format is empty or the keys are stored in it already, and
+// returns the format of the stored keys. The keys themselves do not change.
+func reencodeKeyPairSecret(c client.Client, secret *corev1.Secret, algorithm string, format string, ctx context.Context) (string, error) {
+	current := keypair.Format(secret.Data["public-key"])
+	if current == "" {
+		return "", fmt.Errorf("existing secret %s has no PEM public-key", secret.Name)
+	}
+	if format == "" || format == current {
+		return current, nil
+	}
+	if _, err := keypair.ResolveFormat(format, algorithm); err != nil {
+		return "", err
+	}
+	if certificate.IsEncryptedPrivateKeyPEM(secret.Data["private-key"]) {
+		return "", fmt.Errorf("existing secret %s holds an encrypted private key, which needs the %s key format", secret.Name, keypair.FormatPKCS8)
+	}
+
+	publicKey, privateKey, err := keypair.Reencode(algorithm, secret.Data["public-key"], secret.Data["private-key"], format)
+	if err != nil {
+		return "", fmt.Errorf("failed to re-encode keys in secret %s: %w", secret.Name, err)
+	}
+	secret.Data["public-key"] = []byte(publicKey)
+	secret.Data["private-key"] = []byte(privateKey)
+	if err := c.Update(ctx, secret); err != nil {
+		return "", err
+	}
+	logf.FromContext(ctx).Info("Re-encoded key pair", "secret", secret.Name, "from", current, "to", format)
+
+	return format, nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/certificate"
+	"github.com/QubeSec/QubeSec/internal/keypair"
+)
+
+// keyImportAnnotation records a digest of the imported keys of a key pair
+// Secret, so the keys are only imported again when they change
+const keyImportAnnotation = "qubesec.io/key-import-digest"
+
+// importKeys checks imported keys and returns them PEM encoded in format
+type importKeys func(publicKey []byte, privateKey []byte, format string) (string, string, error)
+
+// importKeyPairSecret imports the keys referenced by keyImport into a key pair
+// Secret, unless it holds the same keys already or their source was deleted
+// after they were imported. Replaced keys keep their format unless format is
+// set. It reports whether the Secret changed.
+func importKeyPairSecret(c client.Reader, keyImport *qubeseciov1.KeyImport, namespace string, algorithm string, format string, secret *corev1.Secret, importKeys importKeys, ctx context.Context) (bool, error) {
+	ref := keyImport.SecretRef
+	// Keys in another namespace would be copied for anyone who can create a
+	// key pair that references them
+	sourceNamespace, err := localReferenceNamespace(&ref, namespace)
+	if err != nil {
+		return false, fmt.Errorf("importFrom: %w", err)
+	}
+	source := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: sourceNamespace}, source); err != nil {
+		// Keys already imported are kept once their source is deleted
+		if apierrors.IsNotFound(err) && secret.Annotations[keyImportAnnotation] != "" {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get key import secret: %w", err)
+	}
+
+	publicKeyKey := keyImport.PublicKeyKey
+	if publicKeyKey == "" {
+		publicKeyKey = "public-key"
+	}
+	privateKeyKey := keyImport.PrivateKeyKey
+	if privateKeyKey == "" {
+		privateKeyKey = "private-key"
+	}
+	publicKey, ok := source.Data[publicKeyKey]
+	if !ok || len(publicKey) == 0 {
+		return false, fmt.Errorf("key %q not found in key import secret %s", publicKeyKey, ref.Name)
+	}
+	privateKey, ok := source.Data[privateKeyKey]
+	if !ok || len(privateKey) == 0 {
+		return false, fmt.Errorf("key %q not found in key import secret %s", privateKeyKey, ref.Name)
+	}
+
+	h := sha256.New()
+	for _, input := range [][]byte{[]byte(algorithm), publicKey, privateKey} {
+		writeDigestInput(h, input)
+	}
+	digest := hex.EncodeToString(h.Sum(nil))
+
+	// Keep keys imported from the same source keys
+	if secret.Annotations[keyImportAnnotation] == digest {
+		return false, nil
+	}
+
+	if certificate.IsEncryptedPrivateKeyPEM(privateKey) {
+		passphraseKey := keyImport.PassphraseKey
+		if passphraseKey == "" {
+			passphraseKey = "passphrase"
+		}
+		passphrase, ok := source.Data[passphraseKey]
+		if !ok || len(passphrase) == 0 {
+			return false, fmt.Errorf("%s in key import secret %s is encrypted and key %q is not set", privateKeyKey, ref.Name, passphraseKey)
+		}
+		if privateKey, err = certificate.DecryptPrivateKeyPEM(privateKey, passphrase); err != nil {
+			return false, fmt.Errorf("failed to decrypt %s in key import secret %s: %w", privateKeyKey, ref.Name, err)
+		}
+	}
+
+	if format == "" {
+		format = keypair.Format(secret.Data["public-key"])
+	}
+	format, err = keypair.ResolveFormat(format, algorithm)
+	if err != nil {
+		return false, err
+	}
+	publicKeyPEM, privateKeyPEM, err := importKeys(publicKey, privateKey, format)
+	if err != nil {
+		return false, fmt.Errorf("failed to import key pair from secret %s: %w", ref.Name, err)
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data["public-key"] = []byte(publicKeyPEM)
+	secret.Data["private-key"] = []byte(privateKeyPEM)
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[keyImportAnnotation] = digest
+
+	logf.FromContext(ctx).Info("Imported key pair", "secret", secret.Name, "from", ref.Name)
+	return true, nil
+}
+
+// usesImportSecret reports whether the keys of keyImport, set on an object in
+// namespace, are in the Secret
+func usesImportSecret(keyImport *qubeseciov1.KeyImport, namespace string, secret client.Object) bool {
+	return keyImport != nil && keyImport.SecretRef.Name == secret.GetName() &&
+		referenceNamespace(&keyImport.SecretRef, namespace) == secret.GetNamespace()
+}
+
+// importKeyPair imports the keys of spec.importFrom into the Secret of a
+// QuantumKEMKeyPair
+func (r *QuantumKEMKeyPairReconciler) importKeyPair(keyPair *qubeseciov1.QuantumKEMKeyPair, secret *corev1.Secret, ctx context.Context) (bool, error) {
+	spec := keyPair.Spec
+	if spec.ImportFrom == nil {
+		return false, nil
+	}
+	return importKeyPairSecret(r.Client, spec.ImportFrom, keyPair.Namespace, spec.Algorithm, spec.KeyFormat, secret, func(publicKey []byte, privateKey []byte, format string) (string, string, error) {
+		return keypair.ImportKEMKeyPair(spec.CryptoProvider, spec.Algorithm, publicKey, privateKey, format, ctx)
+	}, ctx)
+}
+
+// importKeyPair imports the keys of spec.importFrom into the Secret of a
+// QuantumSignatureKeyPair
+func (r *QuantumSignatureKeyPairReconciler) importKeyPair(keyPair *qubeseciov1.QuantumSignatureKeyPair, secret *corev1.Secret, ctx context.Context) (bool, error) {
+	spec := keyPair.Spec
+	if spec.ImportFrom == nil {
+		return false, nil
+	}
+	return importKeyPairSecret(r.Client, spec.ImportFrom, keyPair.Namespace, spec.Algorithm, spec.KeyFormat, secret, func(publicKey []byte, privateKey []byte, format string) (string, string, error) {
+		return keypair.ImportSIGKeyPair(spec.CryptoProvider, spec.Algorithm, publicKey, privateKey, format, ctx)
+	}, ctx)
+}
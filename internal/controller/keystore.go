@@ -0,0 +1,262 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/certificate"
+)
+
+// Keys of the keystores in a certificate Secret, named as in cert-manager
+const (
+	keystorePKCS12Key   = "keystore.p12"
+	truststorePKCS12Key = "truststore.p12"
+	keystoreJKSKey      = "keystore.jks"
+	truststoreJKSKey    = "truststore.jks"
+)
+
+// keystoresAnnotation records a digest of the inputs of the keystores in a
+// certificate Secret, so they are only rebuilt when one of them changes
+const keystoresAnnotation = "qubesec.io/keystores-digest"
+
+// setKeystores adds the keystores configured for a certificate to its Secret
+// and removes the others. Keystores are rebuilt when the certificate, its key,
+// the configuration or a password changes. It reports whether the Secret
+// changed.
+func (r *QuantumCertificateReconciler) setKeystores(QuantumCertificate *qubeseciov1.QuantumCertificate, secret *corev1.Secret, ctx context.Context) (bool, error) {
+	keystores := QuantumCertificate.Spec.Keystores
+	if keystores == nil {
+		keystores = &qubeseciov1.CertificateKeystores{}
+	}
+
+	// Get the passwords. The digest is readable with the Secret metadata, so
+	// it covers the versions of the password Secrets, not the passwords.
+	var pkcs12Password, jksPassword []byte
+	var pkcs12PasswordVersion, jksPasswordVersion string
+	var err error
+	if keystores.PKCS12 != nil {
+		if pkcs12Password, pkcs12PasswordVersion, err = r.keystorePassword(QuantumCertificate, &keystores.PKCS12.KeystoreOptions, ctx); err != nil {
+			return false, err
+		}
+	}
+	if keystores.JKS != nil {
+		if jksPassword, jksPasswordVersion, err = r.keystorePassword(QuantumCertificate, &keystores.JKS.KeystoreOptions, ctx); err != nil {
+			return false, err
+		}
+	}
+
+	// Keep keystores built from the same inputs
+	digest := ""
+	if keystores.PKCS12 != nil || keystores.JKS != nil {
+		h := sha256.New()
+		spec, err := json.Marshal(keystores)
+		if err != nil {
+			return false, err
+		}
+		for _, input := range [][]byte{spec, []byte(pkcs12PasswordVersion), []byte(jksPasswordVersion), secret.Data["tls.crt"], secret.Data["tls.key"], secret.Data["ca.crt"]} {
+			writeDigestInput(h, input)
+		}
+		digest = hex.EncodeToString(h.Sum(nil))
+	}
+	if secret.Annotations[keystoresAnnotation] == digest && hasKeystores(secret, keystores) {
+		return false, nil
+	}
+
+	data := map[string][]byte{}
+	if digest != "" {
+		keyPEM, err := plaintextPrivateKey(r.Client, QuantumCertificate.Spec.PrivateKeyEncryption, QuantumCertificate.Namespace, secret.Data["tls.key"], ctx)
+		if err != nil {
+			return false, err
+		}
+		chain, roots, privateKey, err := keystoreContents(secret, keyPEM)
+		if err != nil {
+			return false, err
+		}
+		if p := keystores.PKCS12; p != nil {
+			profile := p.Profile
+			if profile == "" {
+				profile = certificate.PKCS12Modern2023
+			}
+			if data[keystorePKCS12Key], err = certificate.EncodePKCS12(privateKey, chain, keystoreAlias(&p.KeystoreOptions), string(pkcs12Password), profile); err != nil {
+				return false, fmt.Errorf("failed to create PKCS#12 keystore: %w", err)
+			}
+			if data[truststorePKCS12Key], err = certificate.EncodePKCS12TrustStore(roots, string(pkcs12Password), profile); err != nil {
+				return false, fmt.Errorf("failed to create PKCS#12 trust store: %w", err)
+			}
+		}
+		if p := keystores.JKS; p != nil {
+			if data[keystoreJKSKey], err = certificate.EncodeJKS(privateKey, chain, keystoreAlias(&p.KeystoreOptions), string(jksPassword)); err != nil {
+				return false, fmt.Errorf("failed to create JKS keystore: %w", err)
+			}
+			if data[truststoreJKSKey], err = certificate.EncodeJKSTrustStore(roots, string(jksPassword)); err != nil {
+				return false, fmt.Errorf("failed to create JKS trust store: %w", err)
+			}
+		}
+	}
+
+	for _, key := range []string{keystorePKCS12Key, truststorePKCS12Key, keystoreJKSKey, truststoreJKSKey} {
+		if value, ok := data[key]; ok {
+			secret.Data[key] = value
+		} else {
+			delete(secret.Data, key)
+		}
+	}
+	if digest != "" {
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Annotations[keystoresAnnotation] = digest
+	} else {
+		delete(secret.Annotations, keystoresAnnotation)
+	}
+
+	logf.FromContext(ctx).Info("Updated certificate keystores", "secret", secret.Name)
+	return true, nil
+}
+
+// keystorePassword returns the password of a keystore from its Secret in the
+// namespace of the certificate, and the UID and resource version of the Secret
+func (r *QuantumCertificateReconciler) keystorePassword(QuantumCertificate *qubeseciov1.QuantumCertificate, options *qubeseciov1.KeystoreOptions, ctx context.Context) ([]byte, string, error) {
+	ref := options.PasswordSecretRef
+	key := keystorePasswordKey(options)
+
+	namespace, err := localReferenceNamespace(&ref, QuantumCertificate.Namespace)
+	if err != nil {
+		return nil, "", fmt.Errorf("passwordSecretRef: %w", err)
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, secret); err != nil {
+		return nil, "", fmt.Errorf("failed to get keystore password secret: %w", err)
+	}
+	password, ok := secret.Data[key]
+	if !ok || len(password) == 0 {
+		return nil, "", fmt.Errorf("key %q not found in keystore password secret %s", key, ref.Name)
+	}
+	return password, string(secret.UID) + "/" + secret.ResourceVersion, nil
+}
+
+// keystorePasswordKey returns the key of the password in its Secret
+func keystorePasswordKey(options *qubeseciov1.KeystoreOptions) string {
+	if options.PasswordKey != "" {
+		return options.PasswordKey
+	}
+	return "password"
+}
+
+// keystoreAlias returns the alias of the key entry of a keystore
+func keystoreAlias(options *qubeseciov1.KeystoreOptions) string {
+	if options.Alias != "" {
+		return options.Alias
+	}
+	return "certificate"
+}
+
+// hasKeystores reports whether a Secret holds exactly the configured keystores
+func hasKeystores(secret *corev1.Secret, keystores *qubeseciov1.CertificateKeystores) bool {
+	has := func(key string) bool {
+		_, ok := secret.Data[key]
+		return ok
+	}
+	return has(keystorePKCS12Key) == (keystores.PKCS12 != nil) && has(truststorePKCS12Key) == (keystores.PKCS12 != nil) &&
+		has(keystoreJKSKey) == (keystores.JKS != nil) && has(truststoreJKSKey) == (keystores.JKS != nil)
+}
+
+// keystoreContents returns what the keystores of a certificate Secret hold:
+// the certificate chain up to and including the root, the roots of ca.crt,
+// and the DER PKCS#8 private key of the plaintext keyPEM
+func keystoreContents(secret *corev1.Secret, keyPEM []byte) ([][]byte, [][]byte, []byte, error) {
+	chain := decodeCertificates(secret.Data["tls.crt"])
+	roots := decodeCertificates(secret.Data["ca.crt"])
+	if len(chain) == 0 || len(roots) == 0 {
+		return nil, nil, nil, fmt.Errorf("secret %s is missing tls.crt or ca.crt", secret.Name)
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, nil, nil, fmt.Errorf("secret %s has no PKCS#8 private key in tls.key", secret.Name)
+	}
+
+	// The root of a self-signed certificate is the certificate itself
+	for _, root := range roots {
+		if !slices.ContainsFunc(chain, func(der []byte) bool { return bytes.Equal(der, root) }) {
+			chain = append(chain, root)
+		}
+	}
+	return chain, roots, block.Bytes, nil
+}
+
+// writeDigestInput writes a length-prefixed input to a digest
+func writeDigestInput(h hash.Hash, input []byte) {
+	_ = binary.Write(h, binary.BigEndian, uint64(len(input)))
+	h.Write(input)
+}
+
+// passwordSecretCertificates returns the QuantumCertificates whose keystore
+// passwords or private key passphrase are in the Secret, so a changed password
+// rebuilds the keystores and a changed passphrase encrypts the key again
+func (r *QuantumCertificateReconciler) passwordSecretCertificates(ctx context.Context, object client.Object) []reconcile.Request {
+	certificates := &qubeseciov1.QuantumCertificateList{}
+	if err := r.List(ctx, certificates); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list QuantumCertificates")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, qc := range certificates.Items {
+		if usesKeystorePassword(qc.Spec.Keystores, qc.Namespace, object) || usesPassphraseSecret(qc.Spec.PrivateKeyEncryption, qc.Namespace, object) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: qc.Name, Namespace: qc.Namespace}})
+		}
+	}
+	return requests
+}
+
+// usesKeystorePassword reports whether a password of keystores, set on an
+// object in namespace, is in the Secret
+func usesKeystorePassword(keystores *qubeseciov1.CertificateKeystores, namespace string, secret client.Object) bool {
+	if keystores == nil {
+		return false
+	}
+	var options []*qubeseciov1.KeystoreOptions
+	if keystores.PKCS12 != nil {
+		options = append(options, &keystores.PKCS12.KeystoreOptions)
+	}
+	if keystores.JKS != nil {
+		options = append(options, &keystores.JKS.KeystoreOptions)
+	}
+	for _, o := range options {
+		if o.PasswordSecretRef.Name == secret.GetName() && referenceNamespace(&o.PasswordSecretRef, namespace) == secret.GetNamespace() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/certificate"
+)
+
+// ocspResponderSecretName returns the name of the Secret holding the OCSP
+// responder certificate of an issuer
+func ocspResponderSecretName(issuerName string, ocsp *qubeseciov1.IssuerOCSP) string {
+	if ocsp.SecretName != "" {
+		return ocsp.SecretName
+	}
+	return issuerName + "-ocsp"
+}
+
+// ocspResponderKeyPair returns the reference to the responder key pair of an
+// issuer in namespace, which is empty for a QuantumClusterIssuer, with its
+// namespace resolved. Only a QuantumClusterIssuer may name the namespace.
+func ocspResponderKeyPair(ocsp *qubeseciov1.IssuerOCSP, status *qubeseciov1.IssuerStatus, namespace string) (qubeseciov1.ObjectReference, error) {
+	ref := ocsp.KeyPairRef
+	if namespace != "" {
+		if _, err := localReferenceNamespace(&ref, namespace); err != nil {
+			return ref, fmt.Errorf("%w: ocsp.keyPairRef: %v", errInvalidIssuer, err)
+		}
+	}
+	ref.Namespace = referenceNamespace(&ref, status.CertificateReference.Namespace)
+	return ref, nil
+}
+
+// issueOCSPResponder issues the certificate of the delegated OCSP responder
+// of an issuer unless the current one is valid for the responder key. It
+// returns when the certificate must be renewed, or zero without OCSP.
+func issueOCSPResponder(c client.Client, scheme *runtime.Scheme, issuer client.Object, spec *qubeseciov1.IssuerSpec, status *qubeseciov1.IssuerStatus, ca *issuingCA, ctx context.Context) (time.Time, error) {
+	log := logf.FromContext(ctx)
+
+	if spec.OCSP == nil {
+		if status.OCSP != nil {
+			status.OCSP = nil
+			_ = c.Status().Update(ctx, issuer)
+		}
+		return time.Time{}, nil
+	}
+
+	// The responder must have its own key so that the CA key is only used to sign certificates and CRLs
+	keyPairRef, err := ocspResponderKeyPair(spec.OCSP, status, issuer.GetNamespace())
+	if err != nil {
+		return time.Time{}, err
+	}
+	if keyPairRef.Name == spec.KeyPairRef.Name && keyPairRef.Namespace == referenceNamespace(&spec.KeyPairRef, issuer.GetNamespace()) {
+		return time.Time{}, fmt.Errorf("%w: the OCSP responder key must differ from the CA key", errInvalidIssuer)
+	}
+	keyPair, publicKey, privateKey, err := getSignatureKeyPair(c, keyPairRef, "", ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	signer, err := certificate.NewSigner(keyPair.Spec.CryptoProvider, keyPair.Spec.Algorithm, publicKey, privateKey, ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	namespace := status.CertificateReference.Namespace
+	secretName := ocspResponderSecretName(issuer.GetName(), spec.OCSP)
+	reference := &qubeseciov1.ObjectReference{Name: secretName, Namespace: namespace}
+
+	// Get the current responder certificate
+	secret := &corev1.Secret{}
+	err = c.Get(ctx, client.ObjectKey{Name: secretName, Namespace: namespace}, secret)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return time.Time{}, err
+	}
+	exists := err == nil
+	if exists && !metav1.IsControlledBy(secret, issuer) {
+		return time.Time{}, fmt.Errorf("%w: secret %s exists and is not owned by the issuer", errInvalidIssuer, secretName)
+	}
+
+	// Keep a current certificate for the responder key, restoring the status from it
+	now := time.Now().Truncate(time.Second)
+	if current := secretCertificate(secret); current != nil && bytes.Equal(current.RawIssuer, ca.certificate.RawSubject) &&
+		bytes.Equal(current.AuthorityKeyId, ca.certificate.SubjectKeyId) && certificateHasKey(current, signer.Public().Bytes) &&
+		now.Before(certificateRenewalTime(current, 0)) {
+		if status.OCSP == nil || status.OCSP.SerialNumber != hex.EncodeToString(current.SerialNumber.Bytes()) {
+			setOCSPStatus(status, current, reference)
+			_ = c.Status().Update(ctx, issuer)
+		}
+		return certificateRenewalTime(current, 0), nil
+	}
+
+	days := spec.OCSP.ResponderDays
+	if days <= 0 {
+		days = 30
+	}
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: issuer.GetName() + " OCSP Responder"},
+		NotBefore:             now,
+		NotAfter:              now.AddDate(0, 0, days),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning},
+		BasicConstraintsValid: true,
+		ExtraExtensions:       []pkix.Extension{certificate.OCSPNoCheckExtension},
+	}
+	der, err := certificate.Sign(template, signer, ca.certificate, ca.signer)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %v", errInvalidIssuer, err)
+	}
+	issued, err := x509.ParseCertificate(der)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	data := map[string][]byte{
+		"tls.crt": []byte(certificate.EncodeCertificatePEM(der)),
+	}
+	if exists {
+		secret.Data = data
+		if err := c.Update(ctx, secret); err != nil {
+			return time.Time{}, err
+		}
+	} else {
+		newSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: namespace,
+			},
+			Data: data,
+		}
+
+		// Set owner reference to the issuer for Secret
+		if err := ctrl.SetControllerReference(issuer, newSecret, scheme); err != nil {
+			return time.Time{}, err
+		}
+		if err := c.Create(ctx, newSecret); err != nil {
+			return time.Time{}, err
+		}
+	}
+	log.Info("Issued OCSP responder certificate", "secret", secretName, "serialNumber", hex.EncodeToString(issued.SerialNumber.Bytes()))
+
+	setOCSPStatus(status, issued, reference)
+	_ = c.Status().Update(ctx, issuer)
+
+	return certificateRenewalTime(issued, 0), nil
+}
+
+// secretCertificate returns the first certificate in tls.crt of a Secret, or nil
+func secretCertificate(secret *corev1.Secret) *x509.Certificate {
+	certificates := decodeCertificates(secret.Data["tls.crt"])
+	if len(certificates) == 0 {
+		return nil
+	}
+	cert, err := x509.ParseCertificate(certificates[0])
+	if err != nil {
+		return nil
+	}
+	return cert
+}
+
+// setOCSPStatus records the responder certificate in the issuer status
+func setOCSPStatus(status *qubeseciov1.IssuerStatus, responder *x509.Certificate, reference *qubeseciov1.ObjectReference) {
+	notAfter := metav1.NewTime(responder.NotAfter)
+	status.OCSP = &qubeseciov1.IssuerOCSPStatus{
+		CertificateReference: reference,
+		SerialNumber:         hex.EncodeToString(responder.SerialNumber.Bytes()),
+		NotAfter:             &notAfter,
+	}
+	if publicKey, err := certificate.ParsePublicKey(responder.RawSubjectPublicKeyInfo); err == nil {
+		status.OCSP.Algorithm = publicKey.Algorithm
+	}
+}
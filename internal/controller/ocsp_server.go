@@ -0,0 +1,422 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/certificate"
+)
+
+// maxOCSPRequestSize limits the body of POST requests
+const maxOCSPRequestSize = 64 << 10
+
+// maxCachedOCSPResponses limits the responses kept for requests without a nonce
+const maxCachedOCSPResponses = 4096
+
+// errNoResponder is returned when no issuer answers for a certificate
+var errNoResponder = errors.New("no OCSP responder for the issuer")
+
+// OCSPServer is an RFC 6960 OCSP responder for the certificates signed by
+// issuers with OCSP configured. Requests are POSTed to any path, or sent with
+// GET as the URL-encoded base64 request in the last path segment. The issuer
+// is found from the issuer hashes in the request, and responses are signed by
+// the delegated responder key of that issuer. It runs on every replica, not
+// only the leader.
+//
+// Loading a responder key may decrypt it with a slow KDF, and signing is
+// slow for large post-quantum keys, so the signer of each issuer is kept
+// until its key pair changes, and responses to requests without a nonce are
+// served again until the status of a certificate changes or half of their
+// validity has passed.
+type OCSPServer struct {
+	client.Reader
+
+	// BindAddress is the address the server listens on
+	BindAddress string
+
+	mu sync.Mutex
+	// signers are the responder signers by issuer
+	signers map[types.UID]*cachedOCSPSigner
+	// responses are the signed responses by request digest
+	responses map[[sha256.Size]byte]*cachedOCSPResponse
+}
+
+// cachedOCSPSigner is a responder signer loaded from a key pair
+type cachedOCSPSigner struct {
+	// version is the resource version of the key pair and its Secret
+	version string
+	signer  certificate.Signer
+}
+
+// cachedOCSPResponse is a signed response to a request without a nonce
+type cachedOCSPResponse struct {
+	der []byte
+	// responder is the DER responder certificate that signed the response
+	responder  []byte
+	responses  []certificate.OCSPSingleResponse
+	producedAt time.Time
+	nextUpdate time.Time
+}
+
+// Start serves OCSP requests until ctx is done.
+func (s *OCSPServer) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("ocsp-server")
+
+	server := &http.Server{
+		Addr:              s.BindAddress,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Info("Serving OCSP", "address", s.BindAddress)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection returns false so that all replicas answer OCSP requests.
+func (s *OCSPServer) NeedLeaderElection() bool {
+	return false
+}
+
+// ServeHTTP answers an OCSP request.
+func (s *OCSPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log := logf.FromContext(r.Context()).WithName("ocsp-server")
+
+	var der []byte
+	switch r.Method {
+	case http.MethodGet:
+		path := r.URL.EscapedPath()
+		encoded, err := url.PathUnescape(path[strings.LastIndex(path, "/")+1:])
+		if err == nil {
+			der, err = base64.StdEncoding.DecodeString(encoded)
+		}
+		if err != nil {
+			writeOCSPResponse(w, certificate.OCSPErrorResponse(certificate.OCSPMalformedRequest), 0)
+			return
+		}
+	case http.MethodPost:
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxOCSPRequestSize+1))
+		if err != nil || len(body) > maxOCSPRequestSize {
+			writeOCSPResponse(w, certificate.OCSPErrorResponse(certificate.OCSPMalformedRequest), 0)
+			return
+		}
+		der = body
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	request, err := certificate.ParseOCSPRequest(der)
+	if err != nil {
+		writeOCSPResponse(w, certificate.OCSPErrorResponse(certificate.OCSPMalformedRequest), 0)
+		return
+	}
+
+	response, validity, err := s.respond(request, der, r.Context())
+	switch {
+	case errors.Is(err, errNoResponder):
+		writeOCSPResponse(w, certificate.OCSPErrorResponse(certificate.OCSPUnauthorized), 0)
+	case errors.Is(err, errIssuerNotReady):
+		writeOCSPResponse(w, certificate.OCSPErrorResponse(certificate.OCSPTryLater), 0)
+	case err != nil:
+		log.Error(err, "Failed to answer OCSP request")
+		writeOCSPResponse(w, certificate.OCSPErrorResponse(certificate.OCSPInternalError), 0)
+	default:
+		// Responses to GET requests may be cached until they are replaced
+		if r.Method != http.MethodGet || len(request.Nonce) > 0 {
+			validity = 0
+		}
+		writeOCSPResponse(w, response, validity)
+	}
+}
+
+// respond returns the signed response to a DER request and how long it is valid
+func (s *OCSPServer) respond(request *certificate.OCSPRequest, der []byte, ctx context.Context) ([]byte, time.Duration, error) {
+	responder, err := s.findResponder(&request.CertIDs[0], ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries, err := revokedCertificateEntries(s.Reader, responder.kind, responder.issuer, ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	revoked := make(map[string]x509.RevocationListEntry, len(entries))
+	for _, entry := range entries {
+		revoked[entry.SerialNumber.String()] = entry
+	}
+
+	// Certificates that were not revoked are reported as good
+	now := time.Now().Truncate(time.Second)
+	var responses []certificate.OCSPSingleResponse
+	for _, id := range request.CertIDs {
+		// One response has one signer, so all certificates must have the same issuer
+		if !id.IssuedBy(responder.caCertificate) {
+			return nil, 0, fmt.Errorf("%w: request lists certificates of several issuers", errNoResponder)
+		}
+		response := certificate.OCSPSingleResponse{
+			CertID:     id,
+			Status:     certificate.OCSPGood,
+			ThisUpdate: now,
+			NextUpdate: now.Add(responder.validity),
+		}
+		if entry, ok := revoked[id.SerialNumber.String()]; ok {
+			response.Status = certificate.OCSPRevoked
+			response.RevokedAt = entry.RevocationTime
+			response.RevocationReason = entry.ReasonCode
+		}
+		responses = append(responses, response)
+	}
+
+	// A response echoes the nonce, so only requests without one are cached
+	if len(request.Nonce) > 0 {
+		response, err := certificate.CreateOCSPResponse(responses, request.Nonce, now, responder.certificate, responder.signer)
+		if err != nil {
+			return nil, 0, err
+		}
+		return response, responder.validity, nil
+	}
+
+	key := sha256.Sum256(der)
+	s.mu.Lock()
+	cached := s.responses[key]
+	s.mu.Unlock()
+	if cached != nil && bytes.Equal(cached.responder, responder.certificate.Raw) &&
+		cached.nextUpdate.Sub(cached.producedAt) == responder.validity &&
+		now.Before(cached.producedAt.Add(responder.validity/2)) && sameOCSPStatus(cached.responses, responses) {
+		return cached.der, cached.nextUpdate.Sub(now), nil
+	}
+
+	response, err := certificate.CreateOCSPResponse(responses, nil, now, responder.certificate, responder.signer)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.responses == nil {
+		s.responses = make(map[[sha256.Size]byte]*cachedOCSPResponse)
+	}
+	if len(s.responses) >= maxCachedOCSPResponses {
+		for k, c := range s.responses {
+			if !now.Before(c.producedAt.Add(c.nextUpdate.Sub(c.producedAt) / 2)) {
+				delete(s.responses, k)
+			}
+		}
+		// Requests are chosen by clients, so start over rather than grow
+		if len(s.responses) >= maxCachedOCSPResponses {
+			clear(s.responses)
+		}
+	}
+	s.responses[key] = &cachedOCSPResponse{
+		der:        response,
+		responder:  responder.certificate.Raw,
+		responses:  responses,
+		producedAt: now,
+		nextUpdate: now.Add(responder.validity),
+	}
+	return response, responder.validity, nil
+}
+
+// sameOCSPStatus reports whether two responses give the same status for the
+// same certificates
+func sameOCSPStatus(a, b []certificate.OCSPSingleResponse) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].CertID.SerialNumber.Cmp(b[i].CertID.SerialNumber) != 0 || a[i].Status != b[i].Status ||
+			!a[i].RevokedAt.Equal(b[i].RevokedAt) || a[i].RevocationReason != b[i].RevocationReason {
+			return false
+		}
+	}
+	return true
+}
+
+// ocspResponder is an issuer that answers OCSP requests
+type ocspResponder struct {
+	issuer        client.Object
+	kind          string
+	caCertificate *x509.Certificate
+	// certificate is the delegated responder certificate and signer its key
+	certificate *x509.Certificate
+	signer      certificate.Signer
+	validity    time.Duration
+}
+
+// findResponder returns the responder of the issuer of a certificate
+func (s *OCSPServer) findResponder(id *certificate.OCSPCertID, ctx context.Context) (*ocspResponder, error) {
+	type candidate struct {
+		issuer client.Object
+		kind   string
+		spec   *qubeseciov1.IssuerSpec
+		status *qubeseciov1.IssuerStatus
+	}
+	var candidates []candidate
+
+	issuers := &qubeseciov1.QuantumIssuerList{}
+	if err := s.List(ctx, issuers); err != nil {
+		return nil, fmt.Errorf("failed to list QuantumIssuers: %w", err)
+	}
+	for i := range issuers.Items {
+		issuer := &issuers.Items[i]
+		candidates = append(candidates, candidate{issuer, kindQuantumIssuer, &issuer.Spec, &issuer.Status})
+	}
+	clusterIssuers := &qubeseciov1.QuantumClusterIssuerList{}
+	if err := s.List(ctx, clusterIssuers); err != nil {
+		return nil, fmt.Errorf("failed to list QuantumClusterIssuers: %w", err)
+	}
+	for i := range clusterIssuers.Items {
+		issuer := &clusterIssuers.Items[i]
+		candidates = append(candidates, candidate{issuer, kindQuantumClusterIssuer, &issuer.Spec, &issuer.Status})
+	}
+
+	for _, c := range candidates {
+		if c.spec.OCSP == nil || c.status.Status != "Success" || c.status.CertificateReference == nil {
+			continue
+		}
+		caCertificate, err := s.readCertificate(c.status.CertificateReference, ctx)
+		if err != nil || !id.IssuedBy(caCertificate) {
+			continue
+		}
+
+		// The responder certificate is issued once the issuer has reconciled
+		if c.status.OCSP == nil || c.status.OCSP.CertificateReference == nil {
+			return nil, fmt.Errorf("OCSP responder of %s %s %w", c.kind, c.issuer.GetName(), errIssuerNotReady)
+		}
+		responderCertificate, err := s.readCertificate(c.status.OCSP.CertificateReference, ctx)
+		if err != nil {
+			return nil, err
+		}
+		keyPairRef, err := ocspResponderKeyPair(c.spec.OCSP, c.status, c.issuer.GetNamespace())
+		if err != nil {
+			return nil, err
+		}
+		signer, err := s.responderSigner(c.issuer.GetUID(), keyPairRef, ctx)
+		if err != nil {
+			return nil, err
+		}
+		// A rotated responder key is only used once its certificate is reissued
+		if !certificateHasKey(responderCertificate, signer.Public().Bytes) {
+			return nil, fmt.Errorf("OCSP responder certificate of %s %s %w", c.kind, c.issuer.GetName(), errIssuerNotReady)
+		}
+
+		validity := time.Hour
+		if c.spec.OCSP.ValidityHours > 0 {
+			validity = time.Duration(c.spec.OCSP.ValidityHours) * time.Hour
+		}
+		return &ocspResponder{
+			issuer:        c.issuer,
+			kind:          c.kind,
+			caCertificate: caCertificate,
+			certificate:   responderCertificate,
+			signer:        signer,
+			validity:      validity,
+		}, nil
+	}
+	return nil, errNoResponder
+}
+
+// responderSigner returns the signer of the responder key pair of an issuer,
+// loading it again only when the key pair or its Secret has changed
+func (s *OCSPServer) responderSigner(issuer types.UID, ref qubeseciov1.ObjectReference, ctx context.Context) (certificate.Signer, error) {
+	var version string
+	keyPair := &qubeseciov1.QuantumSignatureKeyPair{}
+	secret := &corev1.Secret{}
+	if err := s.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}, keyPair); err == nil {
+		if err := s.Get(ctx, client.ObjectKey{Name: signatureKeyPairSecretName(keyPair), Namespace: ref.Namespace}, secret); err == nil {
+			version = keyPair.ResourceVersion + "/" + secret.ResourceVersion
+		}
+	}
+
+	s.mu.Lock()
+	cached := s.signers[issuer]
+	s.mu.Unlock()
+	if cached != nil && version != "" && cached.version == version {
+		return cached.signer, nil
+	}
+
+	keyPair, publicKey, privateKey, err := getSignatureKeyPair(s.Reader, ref, "", ctx)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := certificate.NewSigner(keyPair.Spec.CryptoProvider, keyPair.Spec.Algorithm, publicKey, privateKey, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version != "" {
+		s.mu.Lock()
+		if s.signers == nil {
+			s.signers = make(map[types.UID]*cachedOCSPSigner)
+		}
+		s.signers[issuer] = &cachedOCSPSigner{version: version, signer: signer}
+		s.mu.Unlock()
+	}
+	return signer, nil
+}
+
+// readCertificate returns the first certificate in tls.crt of a Secret
+func (s *OCSPServer) readCertificate(ref *qubeseciov1.ObjectReference, ctx context.Context) (*x509.Certificate, error) {
+	secret := &corev1.Secret{}
+	if err := s.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get secret %s: %w", ref.Name, err)
+	}
+	cert := secretCertificate(secret)
+	if cert == nil {
+		return nil, fmt.Errorf("secret %s has no certificate", ref.Name)
+	}
+	return cert, nil
+}
+
+// writeOCSPResponse writes a DER OCSP response, cacheable for maxAge
+func writeOCSPResponse(w http.ResponseWriter, response []byte, maxAge time.Duration) {
+	w.Header().Set("Content-Type", "application/ocsp-response")
+	if maxAge > 0 {
+		w.Header().Set("Cache-Control", "max-age="+strconv.Itoa(int(maxAge.Seconds()))+", public, no-transform, must-revalidate")
+	} else {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	_, _ = w.Write(response)
+}
@@ -17,18 +17,36 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
 	"github.com/QubeSec/QubeSec/internal/certificate"
+	"github.com/QubeSec/QubeSec/internal/keypair"
+)
+
+// Rotation policies of certificate keys
+const (
+	rotationPolicyNever  = "Never"
+	rotationPolicyAlways = "Always"
 )
 
 // QuantumCertificateReconciler reconciles a QuantumCertificate object
@@ -40,6 +58,11 @@ type QuantumCertificateReconciler struct {
 //+kubebuilder:rbac:groups=qubesec.io,resources=quantumcertificates,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=qubesec.io,resources=quantumcertificates/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=qubesec.io,resources=quantumcertificates/finalizers,verbs=update
+//+kubebuilder:rbac:groups=qubesec.io,resources=quantumcertificateprofiles,verbs=get;list;watch
+//+kubebuilder:rbac:groups=qubesec.io,resources=quantumissuers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=qubesec.io,resources=quantumclusterissuers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=qubesec.io,resources=quantumsignaturekeypairs,verbs=get;list;watch
+//+kubebuilder:rbac:groups=qubesec.io,resources=quantumkemkeypairs,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -69,17 +92,26 @@ func (r *QuantumCertificateReconciler) Reconcile(ctx context.Context, req ctrl.R
 	if err != nil {
 		log.Error(err, "Failed to Create or Update Secret")
 		quantumCertificate.Status.Status = "Failed"
+		if errors.Is(err, errIssuerNotReady) {
+			quantumCertificate.Status.Status = "Pending"
+		}
 		quantumCertificate.Status.Error = err.Error()
 		_ = r.Status().Update(ctx, quantumCertificate)
 		return ctrl.Result{}, err
 	}
 
+	// Come back when the certificate is due for renewal
+	if renewalTime := quantumCertificate.Status.RenewalTime; renewalTime != nil {
+		return ctrl.Result{RequeueAfter: max(time.Until(renewalTime.Time), time.Second)}, nil
+	}
 	return ctrl.Result{}, nil
 } // SetupWithManager sets up the controller with the Manager.
 func (r *QuantumCertificateReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&qubeseciov1.QuantumCertificate{}).
 		Owns(&corev1.Secret{}). // Watch Secret objects owned by QuantumCertificate
+		// Rebuild keystores and encrypt the key again when a password changes
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.passwordSecretCertificates)).
 		Complete(r)
 }
 
@@ -105,63 +137,181 @@ func (r *QuantumCertificateReconciler) CreateOrUpdateSecret(QuantumCertificate *
 	if err != nil && client.IgnoreNotFound(err) != nil {
 		return err
 	}
+	exists := err == nil
 
-	// If Secret already exists, update status to Success
-	if err == nil {
-		if QuantumCertificate.Status.Status != "Success" {
-			now := metav1.Now()
-			QuantumCertificate.Status.Status = "Success"
-			QuantumCertificate.Status.CertificateReference = &qubeseciov1.ObjectReference{
-				Name:      secretName,
-				Namespace: QuantumCertificate.Namespace,
+	// If Secret already exists, keep the certificate until it is due for renewal
+	var current *x509.Certificate
+	if exists {
+		certificates := decodeCertificates(secret.Data["tls.crt"])
+		if len(certificates) == 0 {
+			return fmt.Errorf("existing secret %s has no certificate", secretName)
+		}
+		if current, err = x509.ParseCertificate(certificates[0]); err != nil {
+			return fmt.Errorf("existing secret %s has an invalid certificate: %w", secretName, err)
+		}
+
+		renewalTime := certificateRenewalTime(current, QuantumCertificate.Spec.RenewBeforeDays)
+		if time.Now().Before(renewalTime) {
+			// The classical certificate follows the certificate it accompanies
+			classicalChanged, err := r.issueClassicalCertificate(QuantumCertificate, secretName, current, ctx)
+			if err != nil {
+				log.Error(err, "Failed to issue classical certificate")
+				return err
+			}
+			encryptionChanged, err := setPrivateKeyEncryption(r.Client, QuantumCertificate.Spec.PrivateKeyEncryption, QuantumCertificate.Namespace, secret, "tls.key", encryptedTLSKeyKey, ctx)
+			if err != nil {
+				log.Error(err, "Failed to encrypt private key")
+				return err
+			}
+			keystoresChanged, err := r.setKeystores(QuantumCertificate, secret, ctx)
+			if err != nil {
+				log.Error(err, "Failed to create keystores")
+				return err
 			}
-			QuantumCertificate.Status.LastUpdateTime = &now
-			QuantumCertificate.Status.Error = ""
-			_ = r.Status().Update(ctx, QuantumCertificate)
+			if encryptionChanged || keystoresChanged {
+				if err := r.Update(ctx, secret); err != nil {
+					log.Error(err, "Failed to Update Secret")
+					return err
+				}
+			}
+			if classicalChanged || QuantumCertificate.Status.Status != "Success" || QuantumCertificate.Status.RenewalTime == nil ||
+				!QuantumCertificate.Status.RenewalTime.Time.Equal(renewalTime) {
+				now := metav1.Now()
+				QuantumCertificate.Status.Status = "Success"
+				QuantumCertificate.Status.CertificateReference = &qubeseciov1.ObjectReference{
+					Name:      secretName,
+					Namespace: QuantumCertificate.Namespace,
+				}
+				QuantumCertificate.Status.CertificateFingerprint = certificateFingerprint(secret.Data["tls.crt"])
+				setCertificateValidity(&QuantumCertificate.Status, current, renewalTime)
+				QuantumCertificate.Status.Revision = max(QuantumCertificate.Status.Revision, 1)
+				QuantumCertificate.Status.LastUpdateTime = &now
+				QuantumCertificate.Status.Error = ""
+				_ = r.Status().Update(ctx, QuantumCertificate)
+			}
+			return nil
 		}
-		return nil
+		log.Info("Renewing certificate", "notAfter", current.NotAfter)
 	}
 
-	// If Secret doesn't exist, create it
-	// Generate key pair
-	publicKey, privateKey := certificate.Certificate(
-		QuantumCertificate.Spec.Algorithm,
-		QuantumCertificate.Spec.Domain,
-		QuantumCertificate.Spec.Days,
-	)
+	// Build the certificate template from the spec and its profile
+	template, algorithm, err := r.certificateTemplate(QuantumCertificate, ctx)
+	if err != nil {
+		log.Error(err, "Invalid certificate profile")
+		return err
+	}
 
-	// Check if certificate generation failed
-	if publicKey == "" || privateKey == "" {
-		log.Error(nil, "Certificate generation failed - empty keys returned")
-		return fmt.Errorf("certificate generation failed")
+	// Get the key the certificate binds. A KEM key cannot sign, it is only certified.
+	var key certificate.Signer
+	var publicKey certificate.PublicKey
+	var privateKey []byte
+	if QuantumCertificate.Spec.KEMKeyPairRef != nil {
+		publicKey, privateKey, err = r.kemCertificateKey(QuantumCertificate, current, ctx)
+	} else if key, privateKey, err = r.certificateKey(QuantumCertificate, algorithm, current, secret.Data["tls.key"], ctx); err == nil {
+		publicKey = key.Public()
+	}
+	if err != nil {
+		log.Error(err, "Failed to get certificate key")
+		return err
 	}
 
-	// Create Secret object
-	newSecret := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
-			Namespace: QuantumCertificate.Namespace,
-		},
-		Data: map[string][]byte{
-			"tls.crt": []byte(publicKey),
-			"tls.key": []byte(privateKey),
-		},
+	// Get the issuing CA; without one the certificate is self-signed
+	var issuer *issuingCA
+	if ref := QuantumCertificate.Spec.IssuerRef; ref != nil {
+		if issuer, err = getIssuingCA(r.Client, *ref, QuantumCertificate.Namespace, ctx); err != nil {
+			log.Error(err, "Failed to get issuer")
+			return err
+		}
+		if err := issuer.applyPolicy(template); err != nil {
+			log.Error(err, "Certificate denied by issuer policy")
+			return err
+		}
 	}
 
-	// Set owner reference to QuantumCertificate for Secret
-	err = ctrl.SetControllerReference(QuantumCertificate, newSecret, r.Scheme)
+	// Issue the certificate
+	var der []byte
+	if issuer != nil {
+		der, err = certificate.SignPublicKey(template, publicKey, issuer.certificate, issuer.signer)
+	} else {
+		der, err = certificate.Sign(template, key, nil, nil)
+	}
+	if err != nil {
+		log.Error(err, "Certificate generation failed")
+		return fmt.Errorf("certificate generation failed: %w", err)
+	}
+	privateKeyPEM, err := certificate.EncodePrivateKeyPEM(publicKey.Algorithm, privateKey)
 	if err != nil {
-		log.Error(err, "Failed to Set Controller Reference")
+		log.Error(err, "Failed to encode private key")
 		return err
 	}
 
-	// Create Secret
-	err = r.Create(ctx, newSecret)
+	// tls.crt holds the certificate and its intermediates, ca.crt the root
+	certificatePEM := certificate.EncodeCertificatePEM(der)
+	caPEM := certificatePEM
+	if issuer != nil {
+		certificatePEM += certificate.EncodeCertificatePEM(issuer.chain...)
+		caPEM = certificate.EncodeCertificatePEM(issuer.root)
+	}
+	data := map[string][]byte{
+		"tls.crt": []byte(certificatePEM),
+		"tls.key": []byte(privateKeyPEM),
+		"ca.crt":  []byte(caPEM),
+	}
+
+	if exists {
+		// Replace the renewed certificate
+		secret.Data = data
+		if _, err := setPrivateKeyEncryption(r.Client, QuantumCertificate.Spec.PrivateKeyEncryption, QuantumCertificate.Namespace, secret, "tls.key", encryptedTLSKeyKey, ctx); err != nil {
+			log.Error(err, "Failed to encrypt private key")
+			return err
+		}
+		if _, err := r.setKeystores(QuantumCertificate, secret, ctx); err != nil {
+			log.Error(err, "Failed to create keystores")
+			return err
+		}
+		if err := r.Update(ctx, secret); err != nil {
+			log.Error(err, "Failed to Update Secret")
+			return err
+		}
+		log.Info("Renewed certificate")
+	} else {
+		// Create Secret object
+		newSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: QuantumCertificate.Namespace,
+			},
+			Data: data,
+		}
+		if _, err := setPrivateKeyEncryption(r.Client, QuantumCertificate.Spec.PrivateKeyEncryption, QuantumCertificate.Namespace, newSecret, "tls.key", encryptedTLSKeyKey, ctx); err != nil {
+			log.Error(err, "Failed to encrypt private key")
+			return err
+		}
+		if _, err := r.setKeystores(QuantumCertificate, newSecret, ctx); err != nil {
+			log.Error(err, "Failed to create keystores")
+			return err
+		}
+
+		// Set owner reference to QuantumCertificate for Secret
+		err = ctrl.SetControllerReference(QuantumCertificate, newSecret, r.Scheme)
+		if err != nil {
+			log.Error(err, "Failed to Set Controller Reference")
+			return err
+		}
+
+		// Create Secret
+		err = r.Create(ctx, newSecret)
+		if err != nil {
+			log.Error(err, "Failed to Create Secret")
+			return err
+		}
+		log.Info("Created Secret")
+	}
+
+	issued, err := x509.ParseCertificate(der)
 	if err != nil {
-		log.Error(err, "Failed to Create Secret")
 		return err
 	}
-	log.Info("Created Secret")
 
 	// Update status to Success
 	now := metav1.Now()
@@ -170,9 +320,385 @@ func (r *QuantumCertificateReconciler) CreateOrUpdateSecret(QuantumCertificate *
 		Name:      secretName,
 		Namespace: QuantumCertificate.Namespace,
 	}
+	QuantumCertificate.Status.CertificateFingerprint = certificateFingerprint([]byte(certificatePEM))
+	setCertificateValidity(&QuantumCertificate.Status, issued, certificateRenewalTime(issued, QuantumCertificate.Spec.RenewBeforeDays))
+	QuantumCertificate.Status.Revision++
 	QuantumCertificate.Status.LastUpdateTime = &now
 	QuantumCertificate.Status.Error = ""
+
+	// Renew the classical certificate with the certificate it accompanies
+	if _, err := r.issueClassicalCertificate(QuantumCertificate, secretName, issued, ctx); err != nil {
+		log.Error(err, "Failed to issue classical certificate")
+		return err
+	}
 	_ = r.Status().Update(ctx, QuantumCertificate)
 
 	return nil
 }
+
+// certificateKey returns a signer for the key the certificate binds and the raw
+// private key: the key of the referenced key pair, the current key when the
+// rotation policy keeps it, or a new key
+func (r *QuantumCertificateReconciler) certificateKey(QuantumCertificate *qubeseciov1.QuantumCertificate, algorithm string, current *x509.Certificate, currentKeyPEM []byte, ctx context.Context) (certificate.Signer, []byte, error) {
+	spec := QuantumCertificate.Spec
+
+	if ref := spec.PrivateKeyRef; ref != nil {
+		// The key pair must be in the certificate's namespace, or its key
+		// would be handed to whoever can create certificates here
+		if _, err := localReferenceNamespace(ref, QuantumCertificate.Namespace); err != nil {
+			return nil, nil, fmt.Errorf("privateKeyRef: %w", err)
+		}
+		keyPair, publicKey, privateKey, err := getSignatureKeyPair(r.Client, *ref, QuantumCertificate.Namespace, ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if spec.Algorithm != "" && spec.Algorithm != keyPair.Spec.Algorithm {
+			return nil, nil, fmt.Errorf("spec.algorithm %s does not match the %s key of QuantumSignatureKeyPair %s", spec.Algorithm, keyPair.Spec.Algorithm, ref.Name)
+		}
+
+		// Rotating regenerates the key of the key pair once per renewal, an
+		// imported key is rotated by updating the imported keys
+		if spec.RotationPolicy == rotationPolicyAlways && current != nil && certificateHasKey(current, publicKey) {
+			if keyPair.Spec.ImportFrom != nil {
+				return nil, nil, fmt.Errorf("QuantumSignatureKeyPair %s imports its key from secret %s, which must be updated to rotate it, and is %w", ref.Name, keyPair.Spec.ImportFrom.SecretRef.Name, errIssuerNotReady)
+			}
+			if err := r.rotateKeyPair("QuantumSignatureKeyPair", keyPair, signatureKeyPairSecretName(keyPair), ctx); err != nil {
+				return nil, nil, err
+			}
+			return nil, nil, fmt.Errorf("QuantumSignatureKeyPair %s is rotating its key and is %w", ref.Name, errIssuerNotReady)
+		}
+
+		signer, err := certificate.NewSigner(keyPair.Spec.CryptoProvider, keyPair.Spec.Algorithm, publicKey, privateKey, ctx)
+		return signer, privateKey, err
+	}
+
+	if spec.RotationPolicy == rotationPolicyNever && current != nil {
+		keyPEM, err := plaintextPrivateKey(r.Client, spec.PrivateKeyEncryption, QuantumCertificate.Namespace, currentKeyPEM, ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read existing tls.key: %w", err)
+		}
+		block, _ := pem.Decode(keyPEM)
+		if block == nil {
+			return nil, nil, fmt.Errorf("existing secret has no tls.key to keep")
+		}
+		keyAlgorithm, privateKey, err := certificate.ParsePrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		publicKey, err := certificate.ParsePublicKey(current.RawSubjectPublicKeyInfo)
+		if err != nil {
+			return nil, nil, err
+		}
+		if publicKey.Algorithm != keyAlgorithm {
+			return nil, nil, fmt.Errorf("existing tls.key is a %s key, the certificate has a %s key", keyAlgorithm, publicKey.Algorithm)
+		}
+		signer, err := certificate.NewSigner(spec.CryptoProvider, keyAlgorithm, publicKey.Bytes, privateKey, ctx)
+		return signer, privateKey, err
+	}
+
+	return certificate.GenerateKey(spec.CryptoProvider, algorithm, ctx)
+}
+
+// kemCertificateKey returns the ML-KEM public key of the referenced
+// QuantumKEMKeyPair and its raw private key
+func (r *QuantumCertificateReconciler) kemCertificateKey(QuantumCertificate *qubeseciov1.QuantumCertificate, current *x509.Certificate, ctx context.Context) (certificate.PublicKey, []byte, error) {
+	spec := QuantumCertificate.Spec
+	ref := spec.KEMKeyPairRef
+
+	if _, err := localReferenceNamespace(ref, QuantumCertificate.Namespace); err != nil {
+		return certificate.PublicKey{}, nil, fmt.Errorf("kemKeyPairRef: %w", err)
+	}
+	keyPair, publicKey, privateKey, err := getKEMKeyPair(r.Client, *ref, QuantumCertificate.Namespace, ctx)
+	if err != nil {
+		return certificate.PublicKey{}, nil, err
+	}
+	if spec.Algorithm != "" && spec.Algorithm != keyPair.Spec.Algorithm {
+		return certificate.PublicKey{}, nil, fmt.Errorf("spec.algorithm %s does not match the %s key of QuantumKEMKeyPair %s", spec.Algorithm, keyPair.Spec.Algorithm, ref.Name)
+	}
+
+	// Rotating regenerates the key of the key pair once per renewal, an
+	// imported key is rotated by updating the imported keys
+	if spec.RotationPolicy == rotationPolicyAlways && current != nil && certificateHasKey(current, publicKey) {
+		if keyPair.Spec.ImportFrom != nil {
+			return certificate.PublicKey{}, nil, fmt.Errorf("QuantumKEMKeyPair %s imports its key from secret %s, which must be updated to rotate it, and is %w", ref.Name, keyPair.Spec.ImportFrom.SecretRef.Name, errIssuerNotReady)
+		}
+		if err := r.rotateKeyPair("QuantumKEMKeyPair", keyPair, kemKeyPairSecretName(keyPair), ctx); err != nil {
+			return certificate.PublicKey{}, nil, err
+		}
+		return certificate.PublicKey{}, nil, fmt.Errorf("QuantumKEMKeyPair %s is rotating its key and is %w", ref.Name, errIssuerNotReady)
+	}
+
+	return certificate.PublicKey{Algorithm: keyPair.Spec.Algorithm, Bytes: publicKey}, privateKey, nil
+}
+
+// getKEMKeyPair returns a QuantumKEMKeyPair with an ML-KEM key together with
+// its raw public and private keys
+func getKEMKeyPair(c client.Reader, ref qubeseciov1.ObjectReference, namespace string, ctx context.Context) (*qubeseciov1.QuantumKEMKeyPair, []byte, []byte, error) {
+	namespace = referenceNamespace(&ref, namespace)
+
+	keyPair := &qubeseciov1.QuantumKEMKeyPair{}
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, keyPair); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get QuantumKEMKeyPair %s: %w", ref.Name, err)
+	}
+	if !certificate.SupportedKEM(keyPair.Spec.Algorithm) {
+		return nil, nil, nil, fmt.Errorf("QuantumKEMKeyPair %s uses %s, which cannot be certified; use ML-KEM-512, ML-KEM-768 or ML-KEM-1024", ref.Name, keyPair.Spec.Algorithm)
+	}
+	if keyPair.Status.Status != "Success" {
+		return nil, nil, nil, fmt.Errorf("QuantumKEMKeyPair %s %w", ref.Name, errIssuerNotReady)
+	}
+
+	// The Secret is missing while the key pair generates a new key
+	secretName := kemKeyPairSecretName(keyPair)
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Name: secretName, Namespace: namespace}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, nil, fmt.Errorf("key pair secret %s %w", secretName, errIssuerNotReady)
+		}
+		return nil, nil, nil, fmt.Errorf("failed to get key pair secret %s: %w", secretName, err)
+	}
+
+	publicKey, err := keypair.DecodePublicKey(keyPair.Spec.Algorithm, secret.Data["public-key"])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("key pair secret %s has no valid public-key: %w", secretName, err)
+	}
+	privateKeyData, err := plaintextPrivateKey(c, keyPair.Spec.PrivateKeyEncryption, namespace, secret.Data["private-key"], ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("key pair secret %s has no readable private-key: %w", secretName, err)
+	}
+	privateKey, err := keypair.DecodePrivateKey(keyPair.Spec.Algorithm, privateKeyData)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("key pair secret %s has no valid private-key: %w", secretName, err)
+	}
+	return keyPair, publicKey, privateKey, nil
+}
+
+// kemKeyPairSecretName returns the name of the Secret holding the key of a
+// QuantumKEMKeyPair
+func kemKeyPairSecretName(keyPair *qubeseciov1.QuantumKEMKeyPair) string {
+	if keyPair.Spec.SecretName != "" {
+		return keyPair.Spec.SecretName
+	}
+	return keyPair.Name
+}
+
+// rotateKeyPair deletes the key Secret of a key pair, so its controller
+// generates a new key
+func (r *QuantumCertificateReconciler) rotateKeyPair(kind string, keyPair client.Object, secretName string, ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Name: secretName, Namespace: keyPair.GetNamespace()}, secret); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(secret, keyPair) {
+		return fmt.Errorf("secret %s is not managed by %s %s and cannot be rotated", secret.Name, kind, keyPair.GetName())
+	}
+	if err := r.Delete(ctx, secret); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	log.Info("Rotating key of "+kind, "keyPair", keyPair.GetName())
+	return nil
+}
+
+// certificateHasKey reports whether the certificate is issued for publicKey
+func certificateHasKey(cert *x509.Certificate, publicKey []byte) bool {
+	certificateKey, err := certificate.ParsePublicKey(cert.RawSubjectPublicKeyInfo)
+	return err == nil && bytes.Equal(certificateKey.Bytes, publicKey)
+}
+
+// certificateRenewalTime returns when a certificate is due for renewal:
+// renewBeforeDays before it expires, or after two thirds of its lifetime
+func certificateRenewalTime(cert *x509.Certificate, renewBeforeDays int) time.Time {
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	renewBefore := lifetime / 3
+	if window := time.Duration(renewBeforeDays) * 24 * time.Hour; window > 0 && window < lifetime {
+		renewBefore = window
+	}
+	// Status times are stored with second precision
+	return cert.NotAfter.Add(-renewBefore).Truncate(time.Second)
+}
+
+// certificateTemplate merges the certificate spec over its profile and returns
+// the X.509 template and the key algorithm
+func (r *QuantumCertificateReconciler) certificateTemplate(QuantumCertificate *qubeseciov1.QuantumCertificate, ctx context.Context) (*x509.Certificate, string, error) {
+	spec := QuantumCertificate.Spec
+	algorithm := spec.Algorithm
+	days := spec.Days
+	profile := spec.CertificateProfile
+
+	if ref := spec.ProfileRef; ref != nil {
+		certificateProfile := &qubeseciov1.QuantumCertificateProfile{}
+		if err := r.Get(ctx, client.ObjectKey{
+			Name:      ref.Name,
+			Namespace: referenceNamespace(ref, QuantumCertificate.Namespace),
+		}, certificateProfile); err != nil {
+			return nil, "", fmt.Errorf("failed to get QuantumCertificateProfile %s: %w", ref.Name, err)
+		}
+
+		if algorithm == "" {
+			algorithm = certificateProfile.Spec.Algorithm
+		}
+		if days == 0 {
+			days = certificateProfile.Spec.Days
+		}
+		profile = mergeCertificateProfile(certificateProfile.Spec.CertificateProfile, profile)
+	}
+
+	if algorithm == "" {
+		algorithm = "ML-DSA-87"
+	}
+	if days <= 0 {
+		days = 365
+	}
+
+	// A KEM key cannot sign its own certificate, nor a classical companion
+	if spec.KEMKeyPairRef != nil {
+		switch {
+		case spec.IssuerRef == nil:
+			return nil, "", fmt.Errorf("kemKeyPairRef requires issuerRef, as a KEM key cannot sign its own certificate")
+		case spec.PrivateKeyRef != nil:
+			return nil, "", fmt.Errorf("kemKeyPairRef and privateKeyRef are mutually exclusive")
+		case spec.Classical != nil:
+			return nil, "", fmt.Errorf("kemKeyPairRef cannot be combined with classical")
+		}
+	}
+
+	now := time.Now().Truncate(time.Second)
+	template := &x509.Certificate{
+		Subject:               certificateSubject(profile.Subject, spec.Domain),
+		NotBefore:             now,
+		NotAfter:              now.AddDate(0, 0, days),
+		BasicConstraintsValid: true,
+		IsCA:                  profile.IsCA,
+	}
+
+	if profile.MaxPathLen != nil {
+		if !profile.IsCA {
+			return nil, "", fmt.Errorf("maxPathLen requires isCA")
+		}
+		template.MaxPathLen = int(*profile.MaxPathLen)
+		template.MaxPathLenZero = template.MaxPathLen == 0
+	}
+
+	// A CA always needs cert sign
+	usages := make([]string, 0, len(profile.Usages)+1)
+	for _, usage := range profile.Usages {
+		usages = append(usages, string(usage))
+	}
+	if len(usages) == 0 {
+		switch {
+		case spec.KEMKeyPairRef != nil:
+			usages = []string{certificate.UsageKeyEncipherment}
+		case profile.IsCA:
+			usages = []string{certificate.UsageDigitalSignature, certificate.UsageCRLSign}
+		default:
+			usages = []string{certificate.UsageDigitalSignature, certificate.UsageServerAuth}
+		}
+	}
+	if profile.IsCA && !slices.Contains(usages, certificate.UsageCertSign) {
+		usages = append(usages, certificate.UsageCertSign)
+	}
+	if err := certificate.SetUsages(template, usages); err != nil {
+		return nil, "", err
+	}
+
+	// The domain doubles as DNS name, as TLS clients ignore the common name
+	dnsNames := spec.DNSNames
+	if len(dnsNames) == 0 && spec.Domain != "" {
+		dnsNames = []string{spec.Domain}
+	}
+	if err := certificate.SetSubjectAltNames(template, dnsNames, spec.IPAddresses, spec.URIs, spec.EmailAddresses); err != nil {
+		return nil, "", err
+	}
+
+	if template.Subject.String() == "" && len(template.DNSNames)+len(template.IPAddresses)+len(template.URIs)+len(template.EmailAddresses) == 0 {
+		return nil, "", fmt.Errorf("a domain, subject or subject alternative name is required")
+	}
+
+	return template, algorithm, nil
+}
+
+// mergeCertificateProfile returns base with the fields set in override applied
+func mergeCertificateProfile(base, override qubeseciov1.CertificateProfile) qubeseciov1.CertificateProfile {
+	merged := base
+	if override.Subject != nil {
+		if base.Subject == nil {
+			merged.Subject = override.Subject
+		} else {
+			subject := *base.Subject
+			if override.Subject.CommonName != "" {
+				subject.CommonName = override.Subject.CommonName
+			}
+			if override.Subject.SerialNumber != "" {
+				subject.SerialNumber = override.Subject.SerialNumber
+			}
+			for _, field := range []struct{ dst, src *[]string }{
+				{&subject.Organizations, &override.Subject.Organizations},
+				{&subject.OrganizationalUnits, &override.Subject.OrganizationalUnits},
+				{&subject.Countries, &override.Subject.Countries},
+				{&subject.Provinces, &override.Subject.Provinces},
+				{&subject.Localities, &override.Subject.Localities},
+				{&subject.StreetAddresses, &override.Subject.StreetAddresses},
+				{&subject.PostalCodes, &override.Subject.PostalCodes},
+			} {
+				if len(*field.src) > 0 {
+					*field.dst = *field.src
+				}
+			}
+			merged.Subject = &subject
+		}
+	}
+	if len(override.Usages) > 0 {
+		merged.Usages = override.Usages
+	}
+	merged.IsCA = base.IsCA || override.IsCA
+	if override.MaxPathLen != nil {
+		merged.MaxPathLen = override.MaxPathLen
+	}
+	return merged
+}
+
+// certificateSubject returns the subject distinguished name. The domain is the
+// common name unless the subject sets one.
+func certificateSubject(subject *qubeseciov1.CertificateSubject, domain string) pkix.Name {
+	if subject == nil {
+		return pkix.Name{CommonName: domain}
+	}
+
+	commonName := subject.CommonName
+	if commonName == "" {
+		commonName = domain
+	}
+	return pkix.Name{
+		CommonName:         commonName,
+		Organization:       subject.Organizations,
+		OrganizationalUnit: subject.OrganizationalUnits,
+		Country:            subject.Countries,
+		Province:           subject.Provinces,
+		Locality:           subject.Localities,
+		StreetAddress:      subject.StreetAddresses,
+		PostalCode:         subject.PostalCodes,
+		SerialNumber:       subject.SerialNumber,
+	}
+}
+
+// certificateFingerprint returns the SHA256 fingerprint of the DER encoding of
+// the first certificate in a PEM bundle
+func certificateFingerprint(certificatePEM []byte) string {
+	block, _ := pem.Decode(certificatePEM)
+	if block == nil {
+		return ""
+	}
+	hash := sha256.Sum256(block.Bytes)
+	return hex.EncodeToString(hash[:])
+}
+
+// setCertificateValidity records the serial number, expiry and renewal time
+// of a certificate
+func setCertificateValidity(status *qubeseciov1.QuantumCertificateStatus, cert *x509.Certificate, renewalTime time.Time) {
+	notAfter := metav1.NewTime(cert.NotAfter)
+	renewal := metav1.NewTime(renewalTime)
+	status.SerialNumber = hex.EncodeToString(cert.SerialNumber.Bytes())
+	status.NotAfter = &notAfter
+	status.RenewalTime = &renewal
+}
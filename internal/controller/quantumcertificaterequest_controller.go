@@ -0,0 +1,206 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/certificate"
+)
+
+// QuantumCertificateRequestReconciler reconciles a QuantumCertificateRequest object
+type QuantumCertificateRequestReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumcertificaterequests,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumcertificaterequests/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumcertificaterequests/finalizers,verbs=update
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumissuers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumclusterissuers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumsignaturekeypairs,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile verifies the certificate signing request, checks it against the
+// issuer policy and publishes the signed certificate chain in the status. The
+// private key never reaches the operator. A request is signed once.
+func (r *QuantumCertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	// Fetch the QuantumCertificateRequest resource
+	request := &qubeseciov1.QuantumCertificateRequest{}
+	if err := r.Get(ctx, req.NamespacedName, request); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// If already signed, no need to reconcile again
+	if request.Status.Status == "Success" && request.Status.Certificate != "" {
+		return ctrl.Result{}, nil
+	}
+
+	// Parse the request and verify that it is signed by its key
+	csr, publicKey, err := certificate.ParseCertificateRequest([]byte(request.Spec.Request))
+	if err == nil {
+		err = certificate.CheckCertificateRequestSignature(request.Spec.CryptoProvider, csr, publicKey, ctx)
+	}
+	if err != nil {
+		log.Error(err, "Invalid certificate request")
+		request.Status.Status = "Failed"
+		request.Status.Error = fmt.Sprintf("Invalid certificate request: %v", err)
+		_ = r.Status().Update(ctx, request)
+		return ctrl.Result{}, nil
+	}
+
+	// Build the certificate template from the request
+	template, err := certificateRequestTemplate(request, csr)
+	if err != nil {
+		log.Error(err, "Invalid certificate request")
+		request.Status.Status = "Failed"
+		request.Status.Error = err.Error()
+		_ = r.Status().Update(ctx, request)
+		return ctrl.Result{}, nil
+	}
+
+	// Get the issuing CA
+	issuer, err := getIssuingCA(r.Client, request.Spec.IssuerRef, request.Namespace, ctx)
+	if err != nil {
+		log.Error(err, "Failed to get issuer")
+		request.Status.Status = "Failed"
+		if errors.Is(err, errIssuerNotReady) {
+			request.Status.Status = "Pending"
+		}
+		request.Status.Error = err.Error()
+		_ = r.Status().Update(ctx, request)
+		if errors.Is(err, errInvalidIssuer) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Apply the issuer policy and sign
+	der, err := issuer.sign(template, publicKey)
+	if err != nil {
+		log.Error(err, "Certificate request denied")
+		request.Status.Status = "Failed"
+		request.Status.Error = fmt.Sprintf("Certificate request denied: %v", err)
+		_ = r.Status().Update(ctx, request)
+		return ctrl.Result{}, nil
+	}
+	log.Info("Signed certificate request", "serialNumber", hex.EncodeToString(template.SerialNumber.Bytes()))
+
+	// Update status to Success
+	now := metav1.Now()
+	notAfter := metav1.NewTime(template.NotAfter)
+	certificatePEM := certificate.EncodeCertificatePEM(append([][]byte{der}, issuer.chain...)...)
+	request.Status.Status = "Success"
+	request.Status.Certificate = certificatePEM
+	request.Status.CA = certificate.EncodeCertificatePEM(issuer.root)
+	request.Status.PublicKeyAlgorithm = publicKey.Algorithm
+	request.Status.SerialNumber = hex.EncodeToString(template.SerialNumber.Bytes())
+	request.Status.NotAfter = &notAfter
+	request.Status.CertificateFingerprint = certificateFingerprint([]byte(certificatePEM))
+	request.Status.LastUpdateTime = &now
+	request.Status.Error = ""
+	if err := r.Status().Update(ctx, request); err != nil {
+		log.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// certificateRequestTemplate returns the certificate template for a request.
+// The subject and subject alternative names come from the CSR; usages and
+// validity from the spec. Other extensions in the CSR are ignored.
+func certificateRequestTemplate(request *qubeseciov1.QuantumCertificateRequest, csr *x509.CertificateRequest) (*x509.Certificate, error) {
+	days := request.Spec.Days
+	if days <= 0 {
+		days = 365
+	}
+
+	usages := []string{certificate.UsageDigitalSignature, certificate.UsageServerAuth}
+	if len(request.Spec.Usages) > 0 {
+		usages = usages[:0]
+		for _, usage := range request.Spec.Usages {
+			usages = append(usages, string(usage))
+		}
+	}
+
+	now := time.Now().Truncate(time.Second)
+	return signingRequestTemplate(csr, now, now.AddDate(0, 0, days), usages)
+}
+
+// signingRequestTemplate returns the template of a certificate for a CSR with
+// the subject and subject alternative names of the CSR
+func signingRequestTemplate(csr *x509.CertificateRequest, notBefore, notAfter time.Time, usages []string) (*x509.Certificate, error) {
+	serialNumber, err := certificate.NewSerialNumber(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		RawSubject:            csr.RawSubject,
+		Subject:               csr.Subject,
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		BasicConstraintsValid: true,
+	}
+	if err := certificate.SetUsages(template, usages); err != nil {
+		return nil, err
+	}
+
+	ipAddresses := make([]string, 0, len(csr.IPAddresses))
+	for _, ip := range csr.IPAddresses {
+		ipAddresses = append(ipAddresses, ip.String())
+	}
+	uris := make([]string, 0, len(csr.URIs))
+	for _, uri := range csr.URIs {
+		uris = append(uris, uri.String())
+	}
+	if err := certificate.SetSubjectAltNames(template, csr.DNSNames, ipAddresses, uris, csr.EmailAddresses); err != nil {
+		return nil, err
+	}
+
+	if csr.Subject.String() == "" && len(template.DNSNames)+len(template.IPAddresses)+len(template.URIs)+len(template.EmailAddresses) == 0 {
+		return nil, fmt.Errorf("certificate request has neither a subject nor a subject alternative name")
+	}
+
+	return template, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *QuantumCertificateRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&qubeseciov1.QuantumCertificateRequest{}).
+		Named("quantumcertificaterequest").
+		Complete(r)
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+)
+
+const (
+	kindQuantumCertificate        = "QuantumCertificate"
+	kindQuantumCertificateRequest = "QuantumCertificateRequest"
+)
+
+// errNotIssued is returned while the certificate to revoke has not been issued
+var errNotIssued = errors.New("has not issued a certificate yet")
+
+// QuantumCertificateRevocationReconciler reconciles a QuantumCertificateRevocation object
+type QuantumCertificateRevocationReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumcertificaterevocations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumcertificaterevocations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumcertificaterevocations/finalizers,verbs=update
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumcertificates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumcertificaterequests,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumissuers,verbs=get;list;watch
+
+// Reconcile resolves the serial number and issuer of the certificate a
+// QuantumCertificateRevocation revokes. The issuer lists it in its next CRL.
+// Once revoked, the serial number is kept even if the certificate is renewed.
+func (r *QuantumCertificateRevocationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	// Fetch the QuantumCertificateRevocation resource
+	revocation := &qubeseciov1.QuantumCertificateRevocation{}
+	if err := r.Get(ctx, req.NamespacedName, revocation); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// If already revoked, no need to reconcile again
+	if revocation.Status.Status == "Revoked" {
+		return ctrl.Result{}, nil
+	}
+
+	serialNumber, issuerRef, err := r.revokedCertificate(revocation, ctx)
+	if err != nil {
+		log.Error(err, "Failed to resolve revoked certificate")
+
+		// Retry until the certificate exists and is issued
+		pending := errors.Is(err, errNotIssued) || apierrors.IsNotFound(err)
+		revocation.Status.Status = "Failed"
+		if pending {
+			revocation.Status.Status = "Pending"
+		}
+		revocation.Status.Error = err.Error()
+		_ = r.Status().Update(ctx, revocation)
+		if pending {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Update status to Revoked
+	now := metav1.Now()
+	revocation.Status.Status = "Revoked"
+	revocation.Status.SerialNumber = serialNumber
+	revocation.Status.IssuerRef = &qubeseciov1.IssuerReference{Name: issuerRef.Name, Kind: issuerKind(issuerRef)}
+	revocation.Status.RevocationTime = &now
+	revocation.Status.Error = ""
+	if err := r.Status().Update(ctx, revocation); err != nil {
+		log.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+	log.Info("Revoked certificate", "serialNumber", serialNumber, "issuer", issuerRef.Name, "reason", revocation.Spec.Reason)
+
+	return ctrl.Result{}, nil
+}
+
+// revokedCertificate returns the hex-encoded serial number and the issuer of
+// the certificate a revocation refers to
+func (r *QuantumCertificateRevocationReconciler) revokedCertificate(revocation *qubeseciov1.QuantumCertificateRevocation, ctx context.Context) (string, *qubeseciov1.IssuerReference, error) {
+	ref := revocation.Spec.CertificateRef
+	if ref == nil {
+		if revocation.Spec.IssuerRef == nil {
+			return "", nil, fmt.Errorf("issuerRef is required with serialNumber")
+		}
+		serialNumber, err := parseSerialNumber(revocation.Spec.SerialNumber)
+		if err != nil {
+			return "", nil, err
+		}
+		// A QuantumIssuer is in the namespace of the revocation, but a
+		// QuantumClusterIssuer signs for every namespace, so only its
+		// certificates held by a resource in this namespace may be revoked
+		if issuerKind(revocation.Spec.IssuerRef) != kindQuantumIssuer {
+			if err := r.checkSerialNumberInNamespace(serialNumber, revocation.Spec.IssuerRef, revocation.Namespace, ctx); err != nil {
+				return "", nil, err
+			}
+		}
+		return hex.EncodeToString(serialNumber.Bytes()), revocation.Spec.IssuerRef, nil
+	}
+
+	key := client.ObjectKey{Name: ref.Name, Namespace: revocation.Namespace}
+	var serialNumber string
+	var issuerRef *qubeseciov1.IssuerReference
+	kind := ref.Kind
+	if kind == "" {
+		kind = kindQuantumCertificate
+	}
+	switch kind {
+	case kindQuantumCertificateRequest:
+		request := &qubeseciov1.QuantumCertificateRequest{}
+		if err := r.Get(ctx, key, request); err != nil {
+			return "", nil, fmt.Errorf("failed to get QuantumCertificateRequest %s: %w", ref.Name, err)
+		}
+		if request.Status.Status != "Success" {
+			return "", nil, fmt.Errorf("QuantumCertificateRequest %s %w", ref.Name, errNotIssued)
+		}
+		serialNumber, issuerRef = request.Status.SerialNumber, &request.Spec.IssuerRef
+	case kindQuantumIssuer:
+		issuer := &qubeseciov1.QuantumIssuer{}
+		if err := r.Get(ctx, key, issuer); err != nil {
+			return "", nil, fmt.Errorf("failed to get QuantumIssuer %s: %w", ref.Name, err)
+		}
+		if issuer.Status.Status != "Success" {
+			return "", nil, fmt.Errorf("QuantumIssuer %s %w", ref.Name, errNotIssued)
+		}
+		serialNumber, issuerRef = issuer.Status.SerialNumber, issuer.Spec.IssuerRef
+	default:
+		cert := &qubeseciov1.QuantumCertificate{}
+		if err := r.Get(ctx, key, cert); err != nil {
+			return "", nil, fmt.Errorf("failed to get QuantumCertificate %s: %w", ref.Name, err)
+		}
+		if cert.Status.Status != "Success" || cert.Status.SerialNumber == "" {
+			return "", nil, fmt.Errorf("QuantumCertificate %s %w", ref.Name, errNotIssued)
+		}
+		serialNumber, issuerRef = cert.Status.SerialNumber, cert.Spec.IssuerRef
+	}
+
+	// Only certificates signed by an issuer appear on a CRL
+	if issuerRef == nil {
+		return "", nil, fmt.Errorf("%s %s is self-signed and has no issuer to revoke it", kind, ref.Name)
+	}
+	return serialNumber, issuerRef, nil
+}
+
+// checkSerialNumberInNamespace checks that a QuantumCertificate,
+// QuantumCertificateRequest or intermediate QuantumIssuer in the namespace
+// holds the certificate with the serial number signed by issuerRef
+func (r *QuantumCertificateRevocationReconciler) checkSerialNumberInNamespace(serialNumber *big.Int, issuerRef *qubeseciov1.IssuerReference, namespace string, ctx context.Context) error {
+	matches := func(value string, ref *qubeseciov1.IssuerReference) bool {
+		if ref == nil || ref.Name != issuerRef.Name || issuerKind(ref) != issuerKind(issuerRef) {
+			return false
+		}
+		issued, err := parseSerialNumber(value)
+		return err == nil && issued.Cmp(serialNumber) == 0
+	}
+
+	certs := &qubeseciov1.QuantumCertificateList{}
+	if err := r.List(ctx, certs, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list QuantumCertificates: %w", err)
+	}
+	for _, cert := range certs.Items {
+		if matches(cert.Status.SerialNumber, cert.Spec.IssuerRef) {
+			return nil
+		}
+	}
+	requests := &qubeseciov1.QuantumCertificateRequestList{}
+	if err := r.List(ctx, requests, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list QuantumCertificateRequests: %w", err)
+	}
+	for _, request := range requests.Items {
+		if matches(request.Status.SerialNumber, &request.Spec.IssuerRef) {
+			return nil
+		}
+	}
+	issuers := &qubeseciov1.QuantumIssuerList{}
+	if err := r.List(ctx, issuers, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list QuantumIssuers: %w", err)
+	}
+	for _, issuer := range issuers.Items {
+		if matches(issuer.Status.SerialNumber, issuer.Spec.IssuerRef) {
+			return nil
+		}
+	}
+	return fmt.Errorf("serial number %s of %s %s is not held by a certificate in namespace %s",
+		hex.EncodeToString(serialNumber.Bytes()), issuerKind(issuerRef), issuerRef.Name, namespace)
+}
+
+// parseSerialNumber parses a hex serial number, with or without a 0x prefix
+// and colon separators
+func parseSerialNumber(value string) (*big.Int, error) {
+	digits := strings.ReplaceAll(strings.TrimPrefix(value, "0x"), ":", "")
+	serialNumber, ok := new(big.Int).SetString(digits, 16)
+	if !ok || serialNumber.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid serial number %q", value)
+	}
+	return serialNumber, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *QuantumCertificateRevocationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&qubeseciov1.QuantumCertificateRevocation{}).
+		Named("quantumcertificaterevocation").
+		Complete(r)
+}
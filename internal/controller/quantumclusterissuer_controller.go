@@ -0,0 +1,66 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+)
+
+// QuantumClusterIssuerReconciler reconciles a QuantumClusterIssuer object
+type QuantumClusterIssuerReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumclusterissuers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumclusterissuers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumclusterissuers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumsignaturekeypairs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumcertificaterevocations,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile issues the CA certificate of a QuantumClusterIssuer in the
+// namespace of its key pair and keeps its CRL current.
+func (r *QuantumClusterIssuerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// Fetch the QuantumClusterIssuer resource
+	issuer := &qubeseciov1.QuantumClusterIssuer{}
+	if err := r.Get(ctx, req.NamespacedName, issuer); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	return reconcileIssuer(r.Client, r.Scheme, issuer, kindQuantumClusterIssuer, &issuer.Spec, &issuer.Status, ctx)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *QuantumClusterIssuerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&qubeseciov1.QuantumClusterIssuer{}).
+		Owns(&corev1.Secret{}).    // Watch Secret objects owned by QuantumClusterIssuer
+		Owns(&corev1.ConfigMap{}). // Watch the published CRL
+		Watches(&qubeseciov1.QuantumCertificateRevocation{}, handler.EnqueueRequestsFromMapFunc(revocationIssuer(kindQuantumClusterIssuer))).
+		Named("quantumclusterissuer").
+		Complete(r)
+}
@@ -58,9 +58,13 @@ func (r *QuantumDecapsulateSecretReconciler) Reconcile(ctx context.Context, req
 	}
 
 	// Check if secret already exists - if so, skip reconciliation
+	derivation := quantumDecapsulateSecret.Spec.Derive
 	secretName := quantumDecapsulateSecret.Spec.SecretName
 	if secretName == "" {
 		secretName = fmt.Sprintf("%s-shared-secret", quantumDecapsulateSecret.Name)
+		if derivation != nil {
+			secretName = fmt.Sprintf("%s-derived-keys", quantumDecapsulateSecret.Name)
+		}
 	}
 
 	existingSecret := &corev1.Secret{}
@@ -84,13 +88,21 @@ func (r *QuantumDecapsulateSecretReconciler) Reconcile(ctx context.Context, req
 
 			now := metav1.Now()
 			quantumDecapsulateSecret.Status.Status = "Success"
-			quantumDecapsulateSecret.Status.SharedSecretReference = &qubeseciov1.ObjectReference{
+			reference := &qubeseciov1.ObjectReference{
 				Name:      secretName,
 				Namespace: quantumDecapsulateSecret.Namespace,
 			}
-			// Calculate fingerprint from the cached shared secret
-			fingerprint := sha256.Sum256(existingSecret.Data["shared-secret"])
-			quantumDecapsulateSecret.Status.Fingerprint = hex.EncodeToString(fingerprint[:])[:10]
+			if derivation != nil {
+				// Only the derived keys and the shared secret fingerprint were stored
+				quantumDecapsulateSecret.Status.DerivedKeysReference = reference
+				quantumDecapsulateSecret.Status.DerivedKeys = derivedKeyFingerprints(derivation, existingSecret.Data)
+				quantumDecapsulateSecret.Status.Fingerprint = string(existingSecret.Data[sharedSecretFingerprintKey])
+			} else {
+				quantumDecapsulateSecret.Status.SharedSecretReference = reference
+				// Calculate fingerprint from the cached shared secret
+				fingerprint := sha256.Sum256(existingSecret.Data["shared-secret"])
+				quantumDecapsulateSecret.Status.Fingerprint = hex.EncodeToString(fingerprint[:])[:10]
+			}
 			quantumDecapsulateSecret.Status.LastUpdateTime = &now
 			quantumDecapsulateSecret.Status.Error = ""
 
@@ -106,6 +118,16 @@ func (r *QuantumDecapsulateSecretReconciler) Reconcile(ctx context.Context, req
 		return ctrl.Result{}, err
 	}
 
+	if derivation != nil {
+		if err := validateDerivation(derivation); err != nil {
+			log.Error(err, "Invalid derivation")
+			quantumDecapsulateSecret.Status.Status = "Failed"
+			quantumDecapsulateSecret.Status.Error = fmt.Sprintf("Invalid derivation: %v", err)
+			_ = r.Status().Update(ctx, quantumDecapsulateSecret)
+			return ctrl.Result{}, nil
+		}
+	}
+
 	// Get the referenced QuantumKEMKeyPair
 	namespace := quantumDecapsulateSecret.Spec.PrivateKeyRef.Namespace
 	if namespace == "" {
@@ -150,6 +172,14 @@ func (r *QuantumDecapsulateSecretReconciler) Reconcile(ctx context.Context, req
 		_ = r.Status().Update(ctx, quantumDecapsulateSecret)
 		return ctrl.Result{}, fmt.Errorf("private key not found in secret")
 	}
+	privateKeyPEM, err = plaintextPrivateKey(r.Client, kemKeyPair.Spec.PrivateKeyEncryption, namespace, privateKeyPEM, ctx)
+	if err != nil {
+		log.Error(err, "Failed to decrypt private key")
+		quantumDecapsulateSecret.Status.Status = "Failed"
+		quantumDecapsulateSecret.Status.Error = fmt.Sprintf("Failed to decrypt private key: %v", err)
+		_ = r.Status().Update(ctx, quantumDecapsulateSecret)
+		return ctrl.Result{}, err
+	}
 
 	// Resolve ciphertext from spec or referenced QuantumEncapsulateSecret status
 	ciphertextHex := quantumDecapsulateSecret.Spec.Ciphertext
@@ -189,21 +219,36 @@ func (r *QuantumDecapsulateSecretReconciler) Reconcile(ctx context.Context, req
 		return ctrl.Result{}, err
 	}
 
+	// Re-check crypto operations with a second provider in high-assurance namespaces
+	cryptoCtx, err := withCrossCheck(ctx, r.Client, quantumDecapsulateSecret.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to get cross-check settings")
+		quantumDecapsulateSecret.Status.Status = "Failed"
+		quantumDecapsulateSecret.Status.Error = fmt.Sprintf("Failed to get cross-check settings: %v", err)
+		_ = r.Status().Update(ctx, quantumDecapsulateSecret)
+		return ctrl.Result{}, err
+	}
+
 	// Decapsulate to recover shared secret
 	sharedSecret, err := sharedsecret.DecapsulateSharedSecret(
+		quantumDecapsulateSecret.Spec.CryptoProvider,
 		quantumDecapsulateSecret.Spec.Algorithm,
 		privateKeyPEM,
 		ciphertext,
-		ctx,
+		cryptoCtx,
 	)
 	if err != nil {
 		log.Error(err, "Failed to decapsulate shared secret")
-		quantumDecapsulateSecret.Status.Status = "Failed"
+		quantumDecapsulateSecret.Status.Status = cryptoFailureStatus(err)
 		quantumDecapsulateSecret.Status.Error = fmt.Sprintf("Failed to decapsulate shared secret: %v", err)
 		_ = r.Status().Update(ctx, quantumDecapsulateSecret)
 		return ctrl.Result{}, err
 	}
 
+	// Calculate fingerprint from recovered shared secret
+	fingerprint := sha256.Sum256(sharedSecret)
+	sharedSecretFingerprint := hex.EncodeToString(fingerprint[:])[:10]
+
 	// Create secret with shared secret
 	derivedSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -215,6 +260,23 @@ func (r *QuantumDecapsulateSecretReconciler) Reconcile(ctx context.Context, req
 		},
 	}
 
+	// Or, with a derivation, only the derived keys
+	var derivedKeys []qubeseciov1.DerivedKeyFingerprint
+	if derivation != nil {
+		var keys map[string][]byte
+		keys, derivedKeys, err = deriveEphemeralKeys(derivation, sharedSecret, ctx)
+		clear(sharedSecret)
+		if err != nil {
+			log.Error(err, "Failed to derive keys from shared secret")
+			quantumDecapsulateSecret.Status.Status = "Failed"
+			quantumDecapsulateSecret.Status.Error = fmt.Sprintf("Failed to derive keys from shared secret: %v", err)
+			_ = r.Status().Update(ctx, quantumDecapsulateSecret)
+			return ctrl.Result{}, err
+		}
+		keys[sharedSecretFingerprintKey] = []byte(sharedSecretFingerprint)
+		derivedSecret.Data = keys
+	}
+
 	// Set owner reference
 	if err := ctrl.SetControllerReference(quantumDecapsulateSecret, derivedSecret, r.Scheme); err != nil {
 		log.Error(err, "Failed to set owner reference")
@@ -233,13 +295,17 @@ func (r *QuantumDecapsulateSecretReconciler) Reconcile(ctx context.Context, req
 	// Update status with fingerprint
 	now := metav1.Now()
 	quantumDecapsulateSecret.Status.Status = "Success"
-	// Calculate fingerprint from recovered shared secret
-	fingerprint := sha256.Sum256(sharedSecret)
-	quantumDecapsulateSecret.Status.Fingerprint = hex.EncodeToString(fingerprint[:])[:10]
-	quantumDecapsulateSecret.Status.SharedSecretReference = &qubeseciov1.ObjectReference{
+	quantumDecapsulateSecret.Status.Fingerprint = sharedSecretFingerprint
+	reference := &qubeseciov1.ObjectReference{
 		Name:      secretName,
 		Namespace: quantumDecapsulateSecret.Namespace,
 	}
+	if derivation != nil {
+		quantumDecapsulateSecret.Status.DerivedKeysReference = reference
+		quantumDecapsulateSecret.Status.DerivedKeys = derivedKeys
+	} else {
+		quantumDecapsulateSecret.Status.SharedSecretReference = reference
+	}
 	quantumDecapsulateSecret.Status.LastUpdateTime = &now
 	quantumDecapsulateSecret.Status.Error = ""
 
@@ -21,6 +21,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"maps"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -87,7 +88,17 @@ func (r *QuantumDerivedKeyReconciler) Reconcile(ctx context.Context, req ctrl.Re
 			quantumDerivedKey.Status.LastUpdateTime = &now
 			quantumDerivedKey.Status.KeyFingerprint = fingerprint
 			quantumDerivedKey.Status.UsedSalt = quantumDerivedKey.Spec.Salt
+			if salt, ok := existingSecret.Data["salt"]; ok {
+				quantumDerivedKey.Status.UsedSalt = hex.EncodeToString(salt)
+			}
 			quantumDerivedKey.Status.UsedInfo = quantumDerivedKey.Spec.Info
+			// Passphrase keys record their KDF in the Secret
+			quantumDerivedKey.Status.UsedKDF = nil
+			if kdf, ok := existingSecret.Data["kdf"]; ok {
+				params := passphraseParameters(quantumDerivedKey.Spec.KDF)
+				params.Algorithm = string(kdf)
+				quantumDerivedKey.Status.UsedKDF = passphraseKDFStatus(params.WithDefaults())
+			}
 			quantumDerivedKey.Status.Error = ""
 
 			if err := r.Status().Update(ctx, quantumDerivedKey); err != nil {
@@ -102,6 +113,20 @@ func (r *QuantumDerivedKeyReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, err
 	}
 
+	// Exactly one key source must be set
+	if (quantumDerivedKey.Spec.SharedSecretRef == nil) == (quantumDerivedKey.Spec.PassphraseSecretRef == nil) {
+		log.Error(nil, "Invalid key source")
+		quantumDerivedKey.Status.Status = "Failed"
+		quantumDerivedKey.Status.Error = "Exactly one of sharedSecretRef and passphraseSecretRef must be set"
+		_ = r.Status().Update(ctx, quantumDerivedKey)
+		return ctrl.Result{}, nil
+	}
+
+	// Passphrases are derived with a memory-hard KDF instead of HKDF
+	if quantumDerivedKey.Spec.PassphraseSecretRef != nil {
+		return r.reconcilePassphraseKey(quantumDerivedKey, secretName, ctx)
+	}
+
 	// Get the referenced shared secret (could be QuantumEncapsulateSecret or QuantumDecapsulateSecret)
 	namespace := quantumDerivedKey.Spec.SharedSecretRef.Namespace
 	if namespace == "" {
@@ -111,6 +136,7 @@ func (r *QuantumDerivedKeyReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	// Try to get QuantumEncapsulateSecret first
 	sharedSecretRef := &qubeseciov1.ObjectReference{}
 	sharedSecretStatus := ""
+	ephemeral := false
 
 	encapsulateSecret := &qubeseciov1.QuantumEncapsulateSecret{}
 	err = r.Get(ctx, client.ObjectKey{
@@ -122,6 +148,7 @@ func (r *QuantumDerivedKeyReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		// Found QuantumEncapsulateSecret
 		sharedSecretRef = encapsulateSecret.Status.SharedSecretReference
 		sharedSecretStatus = encapsulateSecret.Status.Status
+		ephemeral = encapsulateSecret.Spec.Derive != nil
 	} else {
 		// Try to get QuantumDecapsulateSecret
 		decapsulateSecret := &qubeseciov1.QuantumDecapsulateSecret{}
@@ -137,6 +164,16 @@ func (r *QuantumDerivedKeyReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		}
 		sharedSecretRef = decapsulateSecret.Status.SharedSecretReference
 		sharedSecretStatus = decapsulateSecret.Status.Status
+		ephemeral = decapsulateSecret.Spec.Derive != nil
+	}
+
+	// Ephemeral shared secrets are never stored, so there is nothing to read back
+	if ephemeral {
+		log.Error(nil, "Shared secret is ephemeral")
+		quantumDerivedKey.Status.Status = "Failed"
+		quantumDerivedKey.Status.Error = fmt.Sprintf("Shared secret %s is ephemeral; declare the key in its spec.derive instead", quantumDerivedKey.Spec.SharedSecretRef.Name)
+		_ = r.Status().Update(ctx, quantumDerivedKey)
+		return ctrl.Result{}, nil
 	}
 
 	// Check if shared secret is ready
@@ -217,6 +254,108 @@ func (r *QuantumDerivedKeyReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, err
 	}
 
+	quantumDerivedKey.Status.UsedSalt = quantumDerivedKey.Spec.Salt
+	quantumDerivedKey.Status.UsedInfo = quantumDerivedKey.Spec.Info
+	return r.storeDerivedKey(quantumDerivedKey, secretName, derivedKey, nil, ctx)
+}
+
+// reconcilePassphraseKey derives the key from a passphrase Secret with
+// Argon2id or scrypt. A random salt is generated unless one is given, and it
+// is stored with the key so the derivation can be repeated.
+func (r *QuantumDerivedKeyReconciler) reconcilePassphraseKey(quantumDerivedKey *qubeseciov1.QuantumDerivedKey, secretName string, ctx context.Context) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	// HKDF info has no equivalent in the passphrase KDFs
+	if quantumDerivedKey.Spec.Info != "" {
+		log.Error(nil, "Info is not supported for passphrases")
+		quantumDerivedKey.Status.Status = "Failed"
+		quantumDerivedKey.Status.Error = "info cannot be combined with passphraseSecretRef"
+		_ = r.Status().Update(ctx, quantumDerivedKey)
+		return ctrl.Result{}, nil
+	}
+
+	// Resolve and validate the KDF parameters
+	params := passphraseParameters(quantumDerivedKey.Spec.KDF).WithDefaults()
+	if err := params.Validate(); err != nil {
+		log.Error(err, "Invalid KDF parameters")
+		quantumDerivedKey.Status.Status = "Failed"
+		quantumDerivedKey.Status.Error = fmt.Sprintf("Invalid KDF parameters: %v", err)
+		_ = r.Status().Update(ctx, quantumDerivedKey)
+		return ctrl.Result{}, nil
+	}
+
+	// Get the passphrase. With another namespace's passphrase and salt, a
+	// resource could reproduce that namespace's key, so it must be local.
+	ref := quantumDerivedKey.Spec.PassphraseSecretRef
+	namespace, err := localReferenceNamespace(ref, quantumDerivedKey.Namespace)
+	if err != nil {
+		log.Error(err, "Invalid passphrase secret reference")
+		quantumDerivedKey.Status.Status = "Failed"
+		quantumDerivedKey.Status.Error = err.Error()
+		_ = r.Status().Update(ctx, quantumDerivedKey)
+		return ctrl.Result{}, nil
+	}
+	key := quantumDerivedKey.Spec.PassphraseKey
+	if key == "" {
+		key = "passphrase"
+	}
+
+	passphraseSecret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, passphraseSecret); err != nil {
+		log.Error(err, "Failed to get passphrase secret")
+		quantumDerivedKey.Status.Status = "Failed"
+		quantumDerivedKey.Status.Error = fmt.Sprintf("Failed to get passphrase secret: %v", err)
+		_ = r.Status().Update(ctx, quantumDerivedKey)
+		return ctrl.Result{}, err
+	}
+	passphrase, ok := passphraseSecret.Data[key]
+	if !ok {
+		log.Error(nil, "Passphrase not found in secret")
+		quantumDerivedKey.Status.Status = "Failed"
+		quantumDerivedKey.Status.Error = fmt.Sprintf("Key %q not found in passphrase secret", key)
+		_ = r.Status().Update(ctx, quantumDerivedKey)
+		return ctrl.Result{}, fmt.Errorf("passphrase not found in secret")
+	}
+
+	// Decode the salt, or generate one
+	var salt []byte
+	if quantumDerivedKey.Spec.Salt != "" {
+		salt, err = hex.DecodeString(quantumDerivedKey.Spec.Salt)
+	} else {
+		salt, err = derivedkey.NewSalt()
+	}
+	if err != nil {
+		log.Error(err, "Failed to get salt")
+		quantumDerivedKey.Status.Status = "Failed"
+		quantumDerivedKey.Status.Error = fmt.Sprintf("Failed to get salt: %v", err)
+		_ = r.Status().Update(ctx, quantumDerivedKey)
+		return ctrl.Result{}, err
+	}
+
+	// Derive the key
+	derivedKey, err := derivedkey.DerivePassphraseKey(passphrase, salt, params, ctx)
+	if err != nil {
+		log.Error(err, "Failed to derive key")
+		quantumDerivedKey.Status.Status = "Failed"
+		quantumDerivedKey.Status.Error = fmt.Sprintf("Failed to derive key: %v", err)
+		_ = r.Status().Update(ctx, quantumDerivedKey)
+		return ctrl.Result{}, nil
+	}
+
+	quantumDerivedKey.Status.UsedSalt = hex.EncodeToString(salt)
+	quantumDerivedKey.Status.UsedInfo = ""
+	quantumDerivedKey.Status.UsedKDF = passphraseKDFStatus(params)
+	return r.storeDerivedKey(quantumDerivedKey, secretName, derivedKey, map[string][]byte{
+		"salt": salt,
+		"kdf":  []byte(params.Algorithm),
+	}, ctx)
+}
+
+// storeDerivedKey creates the Secret holding the derived key and any extra
+// data, and marks the QuantumDerivedKey as successful.
+func (r *QuantumDerivedKeyReconciler) storeDerivedKey(quantumDerivedKey *qubeseciov1.QuantumDerivedKey, secretName string, derivedKey []byte, extra map[string][]byte, ctx context.Context) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
 	// Calculate fingerprint of derived key
 	hash := sha256.Sum256(derivedKey)
 	fingerprint := hex.EncodeToString(hash[:])
@@ -233,6 +372,7 @@ func (r *QuantumDerivedKeyReconciler) Reconcile(ctx context.Context, req ctrl.Re
 			"key-type":    []byte(quantumDerivedKey.Spec.KeyType),
 		},
 	}
+	maps.Copy(derivedSecret.Data, extra)
 
 	// Set owner reference
 	if err := ctrl.SetControllerReference(quantumDerivedKey, derivedSecret, r.Scheme); err != nil {
@@ -264,8 +404,6 @@ func (r *QuantumDerivedKeyReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	} else {
 		quantumDerivedKey.Status.Fingerprint = fingerprint
 	}
-	quantumDerivedKey.Status.UsedSalt = quantumDerivedKey.Spec.Salt
-	quantumDerivedKey.Status.UsedInfo = quantumDerivedKey.Spec.Info
 	quantumDerivedKey.Status.Error = ""
 
 	if err := r.Status().Update(ctx, quantumDerivedKey); err != nil {
@@ -277,6 +415,35 @@ func (r *QuantumDerivedKeyReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	return ctrl.Result{}, nil
 }
 
+// passphraseParameters converts the spec KDF settings
+func passphraseParameters(kdf *qubeseciov1.PassphraseKDF) derivedkey.PassphraseParameters {
+	if kdf == nil {
+		return derivedkey.PassphraseParameters{}
+	}
+	return derivedkey.PassphraseParameters{
+		Algorithm:       kdf.Algorithm,
+		Iterations:      uint32(kdf.Iterations),
+		MemoryKiB:       uint32(kdf.MemoryKiB),
+		Parallelism:     uint8(kdf.Parallelism),
+		CostExponent:    int(kdf.CostExponent),
+		BlockSize:       int(kdf.BlockSize),
+		Parallelization: int(kdf.Parallelization),
+	}
+}
+
+// passphraseKDFStatus records the KDF parameters used for a derivation
+func passphraseKDFStatus(params derivedkey.PassphraseParameters) *qubeseciov1.PassphraseKDF {
+	return &qubeseciov1.PassphraseKDF{
+		Algorithm:       params.Algorithm,
+		Iterations:      int32(params.Iterations),
+		MemoryKiB:       int32(params.MemoryKiB),
+		Parallelism:     int32(params.Parallelism),
+		CostExponent:    int32(params.CostExponent),
+		BlockSize:       int32(params.BlockSize),
+		Parallelization: int32(params.Parallelization),
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *QuantumDerivedKeyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
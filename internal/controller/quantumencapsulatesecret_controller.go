@@ -19,8 +19,12 @@ package controller
 import (
 	"context"
 	"crypto/sha256"
+	"crypto/x509"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,9 +34,16 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/certificate"
+	"github.com/QubeSec/QubeSec/internal/derivedkey"
+	"github.com/QubeSec/QubeSec/internal/keypair"
 	"github.com/QubeSec/QubeSec/internal/sharedsecret"
 )
 
+// sharedSecretFingerprintKey records the fingerprint of a shared secret that is
+// only used in memory to derive keys
+const sharedSecretFingerprintKey = "shared-secret-fingerprint"
+
 // QuantumEncapsulateSecretReconciler reconciles a QuantumEncapsulateSecret object
 type QuantumEncapsulateSecretReconciler struct {
 	client.Client
@@ -43,6 +54,8 @@ type QuantumEncapsulateSecretReconciler struct {
 // +kubebuilder:rbac:groups=qubesec.io,resources=quantumencapsulatesecrets/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=qubesec.io,resources=quantumencapsulatesecrets/finalizers,verbs=update
 // +kubebuilder:rbac:groups=qubesec.io,resources=quantumkemkeypairs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumissuers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumclusterissuers,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -57,9 +70,13 @@ func (r *QuantumEncapsulateSecretReconciler) Reconcile(ctx context.Context, req
 	}
 
 	// Check if secret already exists - if so, skip reconciliation
+	derivation := quantumEncapsulatedSecret.Spec.Derive
 	secretName := quantumEncapsulatedSecret.Spec.SecretName
 	if secretName == "" {
 		secretName = fmt.Sprintf("%s-shared-secret", quantumEncapsulatedSecret.Name)
+		if derivation != nil {
+			secretName = fmt.Sprintf("%s-derived-keys", quantumEncapsulatedSecret.Name)
+		}
 	}
 
 	existingSecret := &corev1.Secret{}
@@ -86,13 +103,21 @@ func (r *QuantumEncapsulateSecretReconciler) Reconcile(ctx context.Context, req
 
 			now := metav1.Now()
 			quantumEncapsulatedSecret.Status.Status = "Success"
-			quantumEncapsulatedSecret.Status.SharedSecretReference = &qubeseciov1.ObjectReference{
+			reference := &qubeseciov1.ObjectReference{
 				Name:      secretName,
 				Namespace: quantumEncapsulatedSecret.Namespace,
 			}
-			// Calculate fingerprint from the cached shared secret
-			fingerprint := sha256.Sum256(existingSecret.Data["shared-secret"])
-			quantumEncapsulatedSecret.Status.Fingerprint = hex.EncodeToString(fingerprint[:])[:10]
+			if derivation != nil {
+				// Only the derived keys and the shared secret fingerprint were stored
+				quantumEncapsulatedSecret.Status.DerivedKeysReference = reference
+				quantumEncapsulatedSecret.Status.DerivedKeys = derivedKeyFingerprints(derivation, existingSecret.Data)
+				quantumEncapsulatedSecret.Status.Fingerprint = string(existingSecret.Data[sharedSecretFingerprintKey])
+			} else {
+				quantumEncapsulatedSecret.Status.SharedSecretReference = reference
+				// Calculate fingerprint from the cached shared secret
+				fingerprint := sha256.Sum256(existingSecret.Data["shared-secret"])
+				quantumEncapsulatedSecret.Status.Fingerprint = hex.EncodeToString(fingerprint[:])[:10]
+			}
 			quantumEncapsulatedSecret.Status.LastUpdateTime = &now
 			// Hex-encode the binary ciphertext for status (so decapsulate can decode it)
 			quantumEncapsulatedSecret.Status.Ciphertext = hex.EncodeToString(ciphertextBinary)
@@ -110,65 +135,58 @@ func (r *QuantumEncapsulateSecretReconciler) Reconcile(ctx context.Context, req
 		return ctrl.Result{}, err
 	}
 
-	// Get the referenced QuantumKEMKeyPair
-	namespace := quantumEncapsulatedSecret.Spec.PublicKeyRef.Namespace
-	if namespace == "" {
-		namespace = quantumEncapsulatedSecret.Namespace
+	if derivation != nil {
+		if err := validateDerivation(derivation); err != nil {
+			log.Error(err, "Invalid derivation")
+			quantumEncapsulatedSecret.Status.Status = "Failed"
+			quantumEncapsulatedSecret.Status.Error = fmt.Sprintf("Invalid derivation: %v", err)
+			_ = r.Status().Update(ctx, quantumEncapsulatedSecret)
+			return ctrl.Result{}, nil
+		}
 	}
 
-	kemKeyPair := &qubeseciov1.QuantumKEMKeyPair{}
-	if err := r.Get(ctx, client.ObjectKey{
-		Name:      quantumEncapsulatedSecret.Spec.PublicKeyRef.Name,
-		Namespace: namespace,
-	}, kemKeyPair); err != nil {
-		log.Error(err, "Failed to get referenced QuantumKEMKeyPair")
+	// Re-check crypto operations with a second provider in high-assurance namespaces
+	cryptoCtx, err := withCrossCheck(ctx, r.Client, quantumEncapsulatedSecret.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to get cross-check settings")
 		quantumEncapsulatedSecret.Status.Status = "Failed"
-		quantumEncapsulatedSecret.Status.Error = fmt.Sprintf("Failed to get referenced QuantumKEMKeyPair: %v", err)
+		quantumEncapsulatedSecret.Status.Error = fmt.Sprintf("Failed to get cross-check settings: %v", err)
 		_ = r.Status().Update(ctx, quantumEncapsulatedSecret)
 		return ctrl.Result{}, err
 	}
 
-	// Get the public key from the secret created by QuantumKEMKeyPair
-	kemSecretName := kemKeyPair.Spec.SecretName
-	if kemSecretName == "" {
-		kemSecretName = quantumEncapsulatedSecret.Spec.PublicKeyRef.Name
-	}
-	secret := &corev1.Secret{}
-	if err := r.Get(ctx, client.ObjectKey{
-		Name:      kemSecretName,
-		Namespace: namespace,
-	}, secret); err != nil {
-		log.Error(err, "Failed to get public key secret")
+	// Get the public key from the referenced QuantumKEMKeyPair or validated certificate
+	publicKey, certified, err := r.encapsulationKey(quantumEncapsulatedSecret, cryptoCtx)
+	if err != nil {
+		log.Error(err, "Failed to get public key")
 		quantumEncapsulatedSecret.Status.Status = "Failed"
-		quantumEncapsulatedSecret.Status.Error = fmt.Sprintf("Failed to get public key secret: %v", err)
+		if errors.Is(err, errIssuerNotReady) {
+			quantumEncapsulatedSecret.Status.Status = "Pending"
+		}
+		quantumEncapsulatedSecret.Status.Error = fmt.Sprintf("Failed to get public key: %v", err)
 		_ = r.Status().Update(ctx, quantumEncapsulatedSecret)
 		return ctrl.Result{}, err
 	}
 
-	// Extract public key from secret
-	publicKeyPEM, ok := secret.Data["public-key"]
-	if !ok {
-		log.Error(nil, "Public key not found in secret")
-		quantumEncapsulatedSecret.Status.Status = "Failed"
-		quantumEncapsulatedSecret.Status.Error = "Public key not found in secret"
-		_ = r.Status().Update(ctx, quantumEncapsulatedSecret)
-		return ctrl.Result{}, fmt.Errorf("public key not found in secret")
-	}
-
 	// Derive shared secret
-	ciphertext, sharedSecret, err := sharedsecret.DeriveSharedSecret(
+	ciphertext, sharedSecret, err := sharedsecret.Encapsulate(
+		quantumEncapsulatedSecret.Spec.CryptoProvider,
 		quantumEncapsulatedSecret.Spec.Algorithm,
-		publicKeyPEM,
-		ctx,
+		publicKey,
+		cryptoCtx,
 	)
 	if err != nil {
 		log.Error(err, "Failed to derive shared secret")
-		quantumEncapsulatedSecret.Status.Status = "Failed"
+		quantumEncapsulatedSecret.Status.Status = cryptoFailureStatus(err)
 		quantumEncapsulatedSecret.Status.Error = fmt.Sprintf("Failed to derive shared secret: %v", err)
 		_ = r.Status().Update(ctx, quantumEncapsulatedSecret)
 		return ctrl.Result{}, err
 	}
 
+	// Calculate fingerprint from shared secret
+	fingerprint := sha256.Sum256(sharedSecret)
+	sharedSecretFingerprint := hex.EncodeToString(fingerprint[:])[:10]
+
 	// Create secret with shared secret and ciphertext
 	derivedSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -181,6 +199,24 @@ func (r *QuantumEncapsulateSecretReconciler) Reconcile(ctx context.Context, req
 		},
 	}
 
+	// Or, with a derivation, only the derived keys
+	var derivedKeys []qubeseciov1.DerivedKeyFingerprint
+	if derivation != nil {
+		var keys map[string][]byte
+		keys, derivedKeys, err = deriveEphemeralKeys(derivation, sharedSecret, ctx)
+		clear(sharedSecret)
+		if err != nil {
+			log.Error(err, "Failed to derive keys from shared secret")
+			quantumEncapsulatedSecret.Status.Status = "Failed"
+			quantumEncapsulatedSecret.Status.Error = fmt.Sprintf("Failed to derive keys from shared secret: %v", err)
+			_ = r.Status().Update(ctx, quantumEncapsulatedSecret)
+			return ctrl.Result{}, err
+		}
+		keys["ciphertext"] = ciphertext
+		keys[sharedSecretFingerprintKey] = []byte(sharedSecretFingerprint)
+		derivedSecret.Data = keys
+	}
+
 	// Set owner reference
 	if err := ctrl.SetControllerReference(quantumEncapsulatedSecret, derivedSecret, r.Scheme); err != nil {
 		log.Error(err, "Failed to set owner reference")
@@ -200,13 +236,21 @@ func (r *QuantumEncapsulateSecretReconciler) Reconcile(ctx context.Context, req
 	now := metav1.Now()
 	quantumEncapsulatedSecret.Status.Status = "Success"
 	quantumEncapsulatedSecret.Status.Ciphertext = hex.EncodeToString(ciphertext)
-	// Calculate fingerprint from shared secret
-	fingerprint := sha256.Sum256(sharedSecret)
-	quantumEncapsulatedSecret.Status.Fingerprint = hex.EncodeToString(fingerprint[:])[:10]
-	quantumEncapsulatedSecret.Status.SharedSecretReference = &qubeseciov1.ObjectReference{
+	quantumEncapsulatedSecret.Status.Fingerprint = sharedSecretFingerprint
+	reference := &qubeseciov1.ObjectReference{
 		Name:      secretName,
 		Namespace: quantumEncapsulatedSecret.Namespace,
 	}
+	if derivation != nil {
+		quantumEncapsulatedSecret.Status.DerivedKeysReference = reference
+		quantumEncapsulatedSecret.Status.DerivedKeys = derivedKeys
+	} else {
+		quantumEncapsulatedSecret.Status.SharedSecretReference = reference
+	}
+	if certified != nil {
+		quantumEncapsulatedSecret.Status.CertificateSubject = certified.Subject.String()
+		quantumEncapsulatedSecret.Status.CertificateSerialNumber = hex.EncodeToString(certified.SerialNumber.Bytes())
+	}
 	quantumEncapsulatedSecret.Status.LastUpdateTime = &now
 	quantumEncapsulatedSecret.Status.Error = ""
 
@@ -219,6 +263,230 @@ func (r *QuantumEncapsulateSecretReconciler) Reconcile(ctx context.Context, req
 	return ctrl.Result{}, nil
 }
 
+// encapsulationKey returns the raw public key to encapsulate to: the key of
+// the referenced QuantumKEMKeyPair, or the key of the referenced certificate
+// together with the certificate once its chain is validated
+func (r *QuantumEncapsulateSecretReconciler) encapsulationKey(quantumEncapsulatedSecret *qubeseciov1.QuantumEncapsulateSecret, ctx context.Context) ([]byte, *x509.Certificate, error) {
+	spec := quantumEncapsulatedSecret.Spec
+	if (spec.PublicKeyRef.Name == "") == (spec.CertificateRef == nil) {
+		return nil, nil, fmt.Errorf("exactly one of publicKeyRef and certificateRef must be set")
+	}
+	if spec.CertificateRef != nil {
+		// A ca.crt next to the certificate is written by whoever writes the
+		// certificate, so the trust anchor must be named separately
+		if (spec.IssuerRef == nil) == (spec.TrustBundleRef == nil) {
+			return nil, nil, fmt.Errorf("exactly one of issuerRef and trustBundleRef is required with certificateRef")
+		}
+		return r.certifiedKey(quantumEncapsulatedSecret, ctx)
+	}
+
+	// Get the referenced QuantumKEMKeyPair
+	namespace := referenceNamespace(&spec.PublicKeyRef, quantumEncapsulatedSecret.Namespace)
+	kemKeyPair := &qubeseciov1.QuantumKEMKeyPair{}
+	if err := r.Get(ctx, client.ObjectKey{
+		Name:      spec.PublicKeyRef.Name,
+		Namespace: namespace,
+	}, kemKeyPair); err != nil {
+		return nil, nil, fmt.Errorf("failed to get referenced QuantumKEMKeyPair: %w", err)
+	}
+
+	// Get the public key from the secret created by QuantumKEMKeyPair
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{
+		Name:      kemKeyPairSecretName(kemKeyPair),
+		Namespace: namespace,
+	}, secret); err != nil {
+		return nil, nil, fmt.Errorf("failed to get public key secret: %w", err)
+	}
+
+	// Extract public key from secret
+	publicKeyPEM, ok := secret.Data["public-key"]
+	if !ok {
+		return nil, nil, fmt.Errorf("public key not found in secret")
+	}
+	publicKey, err := keypair.DecodePublicKey(kemKeyPair.Spec.Algorithm, publicKeyPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	return publicKey, nil, nil
+}
+
+// certifiedKey returns the ML-KEM public key of the referenced certificate
+// after validating the certificate chain up to the trust anchor: the CA
+// certificate of issuerRef, or the roots in ca.crt of the trustBundleRef Secret
+func (r *QuantumEncapsulateSecretReconciler) certifiedKey(quantumEncapsulatedSecret *qubeseciov1.QuantumEncapsulateSecret, ctx context.Context) ([]byte, *x509.Certificate, error) {
+	spec := quantumEncapsulatedSecret.Spec
+	ref := spec.CertificateRef
+
+	certificateSecret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{
+		Name:      ref.Name,
+		Namespace: referenceNamespace(ref, quantumEncapsulatedSecret.Namespace),
+	}, certificateSecret); err != nil {
+		return nil, nil, fmt.Errorf("failed to get certificate secret: %w", err)
+	}
+
+	certificateKey := spec.CertificateKey
+	if certificateKey == "" {
+		certificateKey = "tls.crt"
+	}
+	chain, err := parseCertificates(certificateSecret.Data[certificateKey])
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(chain) == 0 {
+		return nil, nil, fmt.Errorf("certificate key '%s' not found in secret", certificateKey)
+	}
+	leaf := chain[0]
+
+	publicKey, err := certificate.ParsePublicKey(leaf.RawSubjectPublicKeyInfo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse certificate public key: %w", err)
+	}
+	if !publicKey.IsKEM() {
+		return nil, nil, fmt.Errorf("certificate holds a %s key, not an ML-KEM key", publicKey.Algorithm)
+	}
+	if publicKey.Algorithm != spec.Algorithm {
+		return nil, nil, fmt.Errorf("certificate holds a %s key, spec.algorithm is %s", publicKey.Algorithm, spec.Algorithm)
+	}
+	if leaf.KeyUsage != 0 && leaf.KeyUsage&x509.KeyUsageKeyEncipherment == 0 {
+		return nil, nil, fmt.Errorf("certificate does not allow key encipherment")
+	}
+
+	var roots []*x509.Certificate
+	if spec.IssuerRef != nil {
+		caCertificate, err := getIssuerCertificate(r.Client, *spec.IssuerRef, quantumEncapsulatedSecret.Namespace, ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		roots = []*x509.Certificate{caCertificate}
+	} else {
+		bundleRef := spec.TrustBundleRef
+		bundleSecret := &corev1.Secret{}
+		if err := r.Get(ctx, client.ObjectKey{
+			Name:      bundleRef.Name,
+			Namespace: referenceNamespace(bundleRef, quantumEncapsulatedSecret.Namespace),
+		}, bundleSecret); err != nil {
+			return nil, nil, fmt.Errorf("failed to get trust bundle secret: %w", err)
+		}
+		if roots, err = parseCertificates(bundleSecret.Data["ca.crt"]); err != nil {
+			return nil, nil, err
+		}
+		if len(roots) == 0 {
+			return nil, nil, fmt.Errorf("trust bundle secret %s has no ca.crt", bundleSecret.Name)
+		}
+	}
+
+	// Signatures in the chain are checked with the operator-wide provider
+	if _, err := certificate.Verify("", leaf, certificate.VerifyOptions{
+		Roots:         roots,
+		Intermediates: chain[1:],
+		CurrentTime:   time.Now(),
+	}, ctx); err != nil {
+		return nil, nil, fmt.Errorf("certificate chain is invalid: %w", err)
+	}
+	return publicKey.Bytes, leaf, nil
+}
+
+// parseCertificates parses the certificates of a PEM bundle
+func parseCertificates(bundle []byte) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	for _, der := range decodeCertificates(bundle) {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certificates = append(certificates, cert)
+	}
+	return certificates, nil
+}
+
+// validateDerivation checks the names, salts and infos of the derived keys
+func validateDerivation(derivation *qubeseciov1.SharedSecretDerivation) error {
+	if len(derivation.Keys) == 0 {
+		return fmt.Errorf("at least one key is required")
+	}
+
+	reserved := []string{"shared-secret", "ciphertext", sharedSecretFingerprintKey}
+	names := make(map[string]bool, len(derivation.Keys))
+	for _, key := range derivation.Keys {
+		if key.Name == "" {
+			return fmt.Errorf("key name is required")
+		}
+		if slices.Contains(reserved, key.Name) {
+			return fmt.Errorf("key name %q is reserved", key.Name)
+		}
+		if names[key.Name] {
+			return fmt.Errorf("duplicate key name %q", key.Name)
+		}
+		names[key.Name] = true
+
+		if _, err := hex.DecodeString(key.Salt); err != nil {
+			return fmt.Errorf("key %s: failed to decode salt: %w", key.Name, err)
+		}
+		if _, err := hex.DecodeString(key.Info); err != nil {
+			return fmt.Errorf("key %s: failed to decode info: %w", key.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// deriveEphemeralKeys derives the declared keys from a shared secret that is
+// never stored. The keys match those of QuantumDerivedKeys with the same salt
+// and info, so a pipeline can move to the ephemeral mode without rekeying.
+func deriveEphemeralKeys(derivation *qubeseciov1.SharedSecretDerivation, sharedSecret []byte, ctx context.Context) (map[string][]byte, []qubeseciov1.DerivedKeyFingerprint, error) {
+	keys := make(map[string][]byte, len(derivation.Keys)+2)
+	fingerprints := make([]qubeseciov1.DerivedKeyFingerprint, 0, len(derivation.Keys))
+
+	for _, key := range derivation.Keys {
+		salt, err := hex.DecodeString(key.Salt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("key %s: failed to decode salt: %w", key.Name, err)
+		}
+		info, err := hex.DecodeString(key.Info)
+		if err != nil {
+			return nil, nil, fmt.Errorf("key %s: failed to decode info: %w", key.Name, err)
+		}
+
+		derivedKey, err := derivedkey.DeriveAES256Key(sharedSecret, salt, info, ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("key %s: %w", key.Name, err)
+		}
+		keys[key.Name] = derivedKey
+		fingerprints = append(fingerprints, qubeseciov1.DerivedKeyFingerprint{
+			Name:        key.Name,
+			KeyType:     derivedKeyType(key),
+			Fingerprint: fingerprintOf(derivedKey),
+		})
+	}
+
+	return keys, fingerprints, nil
+}
+
+// derivedKeyFingerprints rebuilds the derived key status from a stored Secret
+func derivedKeyFingerprints(derivation *qubeseciov1.SharedSecretDerivation, data map[string][]byte) []qubeseciov1.DerivedKeyFingerprint {
+	var fingerprints []qubeseciov1.DerivedKeyFingerprint
+	for _, key := range derivation.Keys {
+		if derivedKey, ok := data[key.Name]; ok {
+			fingerprints = append(fingerprints, qubeseciov1.DerivedKeyFingerprint{
+				Name:        key.Name,
+				KeyType:     derivedKeyType(key),
+				Fingerprint: fingerprintOf(derivedKey),
+			})
+		}
+	}
+	return fingerprints
+}
+
+// derivedKeyType returns the key type of a derived key, defaulting to AES-256
+func derivedKeyType(key qubeseciov1.EphemeralDerivedKey) string {
+	if key.KeyType == "" {
+		return "AES-256"
+	}
+	return key.KeyType
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *QuantumEncapsulateSecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -0,0 +1,596 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/certificate"
+	"github.com/QubeSec/QubeSec/internal/keypair"
+)
+
+const (
+	kindQuantumIssuer        = "QuantumIssuer"
+	kindQuantumClusterIssuer = "QuantumClusterIssuer"
+)
+
+var (
+	// errIssuerNotReady is returned while a CA key or certificate is still being created
+	errIssuerNotReady = errors.New("not ready")
+
+	// errInvalidIssuer is returned when an issuer spec cannot be satisfied
+	errInvalidIssuer = errors.New("invalid issuer")
+)
+
+// QuantumIssuerReconciler reconciles a QuantumIssuer object
+type QuantumIssuerReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumissuers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumissuers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumissuers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumclusterissuers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumsignaturekeypairs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumcertificaterevocations,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile issues the CA certificate of a QuantumIssuer, self-signed or
+// signed by its parent issuer, and stores it in a Secret. It then keeps the
+// CRL of the issuer current.
+func (r *QuantumIssuerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// Fetch the QuantumIssuer resource
+	issuer := &qubeseciov1.QuantumIssuer{}
+	if err := r.Get(ctx, req.NamespacedName, issuer); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	return reconcileIssuer(r.Client, r.Scheme, issuer, kindQuantumIssuer, &issuer.Spec, &issuer.Status, ctx)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *QuantumIssuerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&qubeseciov1.QuantumIssuer{}).
+		Owns(&corev1.Secret{}).    // Watch Secret objects owned by QuantumIssuer
+		Owns(&corev1.ConfigMap{}). // Watch the published CRL
+		Watches(&qubeseciov1.QuantumCertificateRevocation{}, handler.EnqueueRequestsFromMapFunc(revocationIssuer(kindQuantumIssuer))).
+		Named("quantumissuer").
+		Complete(r)
+}
+
+// reconcileIssuer issues the CA certificate of a QuantumIssuer or
+// QuantumClusterIssuer once and records it in the issuer status, together
+// with its classical CA. The CRL is republished whenever a revocation changes
+// and halfway through its validity.
+func reconcileIssuer(c client.Client, scheme *runtime.Scheme, issuer client.Object, kind string, spec *qubeseciov1.IssuerSpec, status *qubeseciov1.IssuerStatus, ctx context.Context) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	err := issueCACertificate(c, scheme, issuer, kind, spec, status, ctx)
+	if err == nil {
+		// A classical CA or revocation information that cannot be published does not stop the CA from issuing
+		refreshTime, err := maintainIssuer(c, scheme, issuer, kind, spec, status, ctx)
+		if err != nil {
+			log.Error(err, "Failed to maintain issuer")
+			status.Error = err.Error()
+			_ = c.Status().Update(ctx, issuer)
+			return ctrl.Result{}, err
+		}
+		if status.Error != "" {
+			status.Error = ""
+			_ = c.Status().Update(ctx, issuer)
+		}
+		return ctrl.Result{RequeueAfter: max(time.Until(refreshTime), time.Second)}, nil
+	}
+
+	log.Error(err, "Failed to issue CA certificate")
+	status.Status = "Failed"
+	if errors.Is(err, errIssuerNotReady) {
+		status.Status = "Pending"
+	}
+	status.Error = err.Error()
+	_ = c.Status().Update(ctx, issuer)
+
+	// A spec that cannot be satisfied only changes with the spec
+	if errors.Is(err, errInvalidIssuer) {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, err
+}
+
+// maintainIssuer issues the classical CA of an issuer and keeps its CRL and
+// OCSP responder certificate current. It returns when they must be refreshed.
+func maintainIssuer(c client.Client, scheme *runtime.Scheme, issuer client.Object, kind string, spec *qubeseciov1.IssuerSpec, status *qubeseciov1.IssuerStatus, ctx context.Context) (time.Time, error) {
+	ca, err := loadIssuingCA(c, kind, issuer.GetName(), spec, status, issuer.GetNamespace(), ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if err := issueClassicalCA(c, scheme, issuer, spec, status, ca, ctx); err != nil {
+		return time.Time{}, fmt.Errorf("failed to issue classical CA certificate: %w", err)
+	}
+
+	refreshTime, err := publishCRL(c, scheme, issuer, kind, spec, status, ca, ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to publish CRL: %w", err)
+	}
+
+	renewalTime, err := issueOCSPResponder(c, scheme, issuer, spec, status, ca, ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to issue OCSP responder certificate: %w", err)
+	}
+	if !renewalTime.IsZero() && renewalTime.Before(refreshTime) {
+		return renewalTime, nil
+	}
+	return refreshTime, nil
+}
+
+// issueCACertificate creates the Secret holding the CA certificate of an issuer
+// unless it exists, and updates the issuer status
+func issueCACertificate(c client.Client, scheme *runtime.Scheme, issuer client.Object, kind string, spec *qubeseciov1.IssuerSpec, status *qubeseciov1.IssuerStatus, ctx context.Context) error {
+	log := logf.FromContext(ctx)
+
+	// A cluster issuer stores its CA certificate next to the key pair
+	if issuer.GetNamespace() == "" && spec.KeyPairRef.Namespace == "" {
+		return fmt.Errorf("%w: keyPairRef.namespace is required for a %s", errInvalidIssuer, kind)
+	}
+	namespace := issuer.GetNamespace()
+	if namespace == "" {
+		namespace = spec.KeyPairRef.Namespace
+	}
+
+	secretName := spec.SecretName
+	if secretName == "" {
+		secretName = issuer.GetName() + "-ca"
+	}
+
+	// If the Secret already exists, restore the status from it
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, client.ObjectKey{Name: secretName, Namespace: namespace}, secret)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if err == nil {
+		if status.Status != "Success" {
+			chain := decodeCertificates(secret.Data["tls.crt"])
+			if len(chain) == 0 {
+				return fmt.Errorf("existing secret %s has no CA certificate", secretName)
+			}
+			caCertificate, err := x509.ParseCertificate(chain[0])
+			if err != nil {
+				return fmt.Errorf("existing secret %s has an invalid CA certificate: %w", secretName, err)
+			}
+			setIssuerStatus(status, caCertificate, secretName, namespace)
+			_ = c.Status().Update(ctx, issuer)
+		}
+		return nil
+	}
+
+	// Get the CA key
+	signer, err := signatureKeyPairSigner(c, spec.KeyPairRef, issuer.GetNamespace(), ctx)
+	if err != nil {
+		return err
+	}
+
+	days := spec.Days
+	if days <= 0 {
+		days = 3650
+	}
+
+	subject := certificateSubject(spec.Subject, issuer.GetName())
+	if subject.CommonName == "" {
+		subject.CommonName = issuer.GetName()
+	}
+
+	serialNumber, err := certificate.NewSerialNumber(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Truncate(time.Second)
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               subject,
+		NotBefore:             now,
+		NotAfter:              now.AddDate(0, 0, days),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	if spec.MaxPathLen != nil {
+		template.MaxPathLen = int(*spec.MaxPathLen)
+		template.MaxPathLenZero = template.MaxPathLen == 0
+	}
+
+	var der []byte
+	var chain [][]byte
+	var root []byte
+	if ref := spec.IssuerRef; ref == nil {
+		// Self-signed root CA
+		if der, err = certificate.CreateCertificate(template, nil, signer.Public(), signer); err != nil {
+			return err
+		}
+		root = der
+	} else {
+		if ref.Name == issuer.GetName() && issuerKind(ref) == kind {
+			return fmt.Errorf("%w: %s %s cannot be its own parent", errInvalidIssuer, kind, ref.Name)
+		}
+		parent, err := getIssuingCA(c, *ref, issuer.GetNamespace(), ctx)
+		if err != nil {
+			return err
+		}
+		if der, err = parent.sign(template, signer.Public()); err != nil {
+			return fmt.Errorf("%w: %v", errInvalidIssuer, err)
+		}
+		chain = parent.chain
+		root = parent.root
+	}
+
+	caCertificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return err
+	}
+
+	// Create Secret object
+	newSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"tls.crt": []byte(certificate.EncodeCertificatePEM(append([][]byte{der}, chain...)...)),
+			"ca.crt":  []byte(certificate.EncodeCertificatePEM(root)),
+		},
+	}
+
+	// Set owner reference to the issuer for Secret
+	if err := ctrl.SetControllerReference(issuer, newSecret, scheme); err != nil {
+		return err
+	}
+
+	// Create Secret
+	if err := c.Create(ctx, newSecret); err != nil {
+		return err
+	}
+	log.Info("Created CA certificate", "secret", secretName, "serialNumber", hex.EncodeToString(serialNumber.Bytes()))
+
+	// Update status to Success
+	setIssuerStatus(status, caCertificate, secretName, namespace)
+	_ = c.Status().Update(ctx, issuer)
+
+	return nil
+}
+
+// setIssuerStatus records a successfully issued CA certificate in the status
+func setIssuerStatus(status *qubeseciov1.IssuerStatus, caCertificate *x509.Certificate, secretName, namespace string) {
+	now := metav1.Now()
+	notAfter := metav1.NewTime(caCertificate.NotAfter)
+	status.Status = "Success"
+	status.CertificateReference = &qubeseciov1.ObjectReference{
+		Name:      secretName,
+		Namespace: namespace,
+	}
+	if publicKey, err := certificate.ParsePublicKey(caCertificate.RawSubjectPublicKeyInfo); err == nil {
+		status.Algorithm = publicKey.Algorithm
+	}
+	status.SerialNumber = hex.EncodeToString(caCertificate.SerialNumber.Bytes())
+	status.NotAfter = &notAfter
+	status.CertificateFingerprint = certificateFingerprint(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCertificate.Raw}))
+	status.LastUpdateTime = &now
+	status.Error = ""
+}
+
+// issuingCA is a CA certificate that is ready to sign, with its chain
+type issuingCA struct {
+	certificate *x509.Certificate
+	signer      certificate.Signer
+	policy      certificate.Policy
+	// chain is the DER CA certificate and its intermediates, without the root
+	chain [][]byte
+	// root is the DER root certificate that anchors the chain
+	root []byte
+	// crlDistributionPoints are the CRL URLs added to issued certificates
+	crlDistributionPoints []string
+	// ocspServers are the OCSP responder URLs added to issued certificates
+	ocspServers []string
+	// classical is the classical CA of the issuer once created, if it has one
+	classical    *classicalCA
+	hasClassical bool
+}
+
+// applyPolicy checks template against the policy of the CA and adds the
+// revocation information of the CA
+func (ca *issuingCA) applyPolicy(template *x509.Certificate) error {
+	if err := ca.policy.Apply(template); err != nil {
+		return err
+	}
+	template.CRLDistributionPoints = ca.crlDistributionPoints
+	template.OCSPServer = ca.ocspServers
+	return nil
+}
+
+// applyConstraints checks template against the policy and the certificate of
+// the CA and adjusts its validity and path length to fit below the CA
+func (ca *issuingCA) applyConstraints(template *x509.Certificate) error {
+	if err := ca.applyPolicy(template); err != nil {
+		return err
+	}
+	return certificate.ApplyIssuerConstraints(ca.certificate, template)
+}
+
+// sign issues a certificate for publicKey from template
+func (ca *issuingCA) sign(template *x509.Certificate, publicKey certificate.PublicKey) ([]byte, error) {
+	if err := ca.applyConstraints(template); err != nil {
+		return nil, err
+	}
+	return certificate.CreateCertificate(template, ca.certificate, publicKey, ca.signer)
+}
+
+// signClassicalKey issues a certificate for an RSA, ECDSA or Ed25519 public
+// key from template
+func (ca *issuingCA) signClassicalKey(template *x509.Certificate, publicKey crypto.PublicKey) ([]byte, error) {
+	if err := ca.applyConstraints(template); err != nil {
+		return nil, err
+	}
+	return certificate.CreateClassicalKeyCertificate(template, ca.certificate, publicKey, ca.signer)
+}
+
+// issuerPolicy converts the policy of an issuer spec
+func issuerPolicy(policy *qubeseciov1.IssuerPolicy) certificate.Policy {
+	if policy == nil {
+		return certificate.Policy{}
+	}
+	return certificate.Policy{
+		DNSNames:         policy.AllowedDNSNames,
+		IPRanges:         policy.AllowedIPRanges,
+		URIs:             policy.AllowedURIs,
+		EmailAddresses:   policy.AllowedEmailAddresses,
+		MaxDays:          policy.MaxDays,
+		AllowCA:          policy.AllowCA,
+		RestrictedUsages: policy.AllowedRestrictedUsages,
+	}
+}
+
+// issuerKind returns the kind of an issuer reference
+func issuerKind(ref *qubeseciov1.IssuerReference) string {
+	if ref.Kind == "" {
+		return kindQuantumIssuer
+	}
+	return ref.Kind
+}
+
+// getIssuingCA returns the CA certificate and key of the referenced issuer. A
+// QuantumIssuer is looked up in namespace, which is empty for cluster-scoped
+// referrers that may only reference a QuantumClusterIssuer.
+func getIssuingCA(c client.Client, ref qubeseciov1.IssuerReference, namespace string, ctx context.Context) (*issuingCA, error) {
+	spec, status, namespace, err := getIssuer(c, ref, namespace, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return loadIssuingCA(c, issuerKind(&ref), ref.Name, spec, status, namespace, ctx)
+}
+
+// getIssuerCertificate returns the CA certificate of the referenced issuer,
+// without its key
+func getIssuerCertificate(c client.Reader, ref qubeseciov1.IssuerReference, namespace string, ctx context.Context) (*x509.Certificate, error) {
+	_, status, _, err := getIssuer(c, ref, namespace, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{
+		Name:      status.CertificateReference.Name,
+		Namespace: status.CertificateReference.Namespace,
+	}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get CA certificate of %s %s: %w", issuerKind(&ref), ref.Name, err)
+	}
+	caCertificate := secretCertificate(secret)
+	if caCertificate == nil {
+		return nil, fmt.Errorf("secret %s of %s %s has no valid tls.crt", secret.Name, issuerKind(&ref), ref.Name)
+	}
+	return caCertificate, nil
+}
+
+// getIssuer returns the spec and status of the referenced issuer once its CA
+// certificate is issued, and the namespace of a QuantumIssuer or an empty
+// namespace for a QuantumClusterIssuer
+func getIssuer(c client.Reader, ref qubeseciov1.IssuerReference, namespace string, ctx context.Context) (*qubeseciov1.IssuerSpec, *qubeseciov1.IssuerStatus, string, error) {
+	var spec qubeseciov1.IssuerSpec
+	var status qubeseciov1.IssuerStatus
+
+	kind := issuerKind(&ref)
+	switch kind {
+	case kindQuantumClusterIssuer:
+		clusterIssuer := &qubeseciov1.QuantumClusterIssuer{}
+		if err := c.Get(ctx, client.ObjectKey{Name: ref.Name}, clusterIssuer); err != nil {
+			return nil, nil, "", fmt.Errorf("failed to get QuantumClusterIssuer %s: %w", ref.Name, err)
+		}
+		spec, status = clusterIssuer.Spec, clusterIssuer.Status
+		namespace = ""
+	default:
+		if namespace == "" {
+			return nil, nil, "", fmt.Errorf("%w: a QuantumClusterIssuer can only reference a QuantumClusterIssuer", errInvalidIssuer)
+		}
+		namespacedIssuer := &qubeseciov1.QuantumIssuer{}
+		if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, namespacedIssuer); err != nil {
+			return nil, nil, "", fmt.Errorf("failed to get QuantumIssuer %s: %w", ref.Name, err)
+		}
+		spec, status = namespacedIssuer.Spec, namespacedIssuer.Status
+	}
+
+	if status.Status != "Success" || status.CertificateReference == nil {
+		return nil, nil, "", fmt.Errorf("%s %s %w", kind, ref.Name, errIssuerNotReady)
+	}
+	return &spec, &status, namespace, nil
+}
+
+// loadIssuingCA returns the CA certificate and key of an issuer whose CA
+// certificate has been issued
+func loadIssuingCA(c client.Reader, kind, name string, spec *qubeseciov1.IssuerSpec, status *qubeseciov1.IssuerStatus, namespace string, ctx context.Context) (*issuingCA, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{
+		Name:      status.CertificateReference.Name,
+		Namespace: status.CertificateReference.Namespace,
+	}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get CA certificate of %s %s: %w", kind, name, err)
+	}
+
+	chain := decodeCertificates(secret.Data["tls.crt"])
+	roots := decodeCertificates(secret.Data["ca.crt"])
+	if len(chain) == 0 || len(roots) == 0 {
+		return nil, fmt.Errorf("secret %s of %s %s is missing tls.crt or ca.crt", secret.Name, kind, name)
+	}
+	caCertificate, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid CA certificate of %s %s: %w", kind, name, err)
+	}
+
+	// The certificate of a root CA is only published in ca.crt
+	chain = slices.DeleteFunc(chain, func(der []byte) bool {
+		return bytes.Equal(der, roots[0])
+	})
+
+	signer, err := signatureKeyPairSigner(c, spec.KeyPairRef, namespace, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ca := &issuingCA{
+		certificate: caCertificate,
+		signer:      signer,
+		policy:      issuerPolicy(spec.Policy),
+		chain:       chain,
+		root:        roots[0],
+	}
+	if spec.CRL != nil {
+		ca.crlDistributionPoints = spec.CRL.DistributionPoints
+	}
+	if spec.OCSP != nil {
+		ca.ocspServers = []string{spec.OCSP.URL}
+	}
+	ca.hasClassical = spec.Classical != nil
+	if ca.hasClassical && status.Classical != nil && status.Classical.CertificateReference != nil {
+		if ca.classical, err = loadClassicalCA(c, status.Classical.CertificateReference, ctx); err != nil {
+			return nil, err
+		}
+	}
+	return ca, nil
+}
+
+// signatureKeyPairSigner returns a certificate signer for the CA key of an
+// issuer in namespace, which is empty for a QuantumClusterIssuer. Only a
+// QuantumClusterIssuer may name the namespace of its key pair.
+func signatureKeyPairSigner(c client.Reader, ref qubeseciov1.ObjectReference, namespace string, ctx context.Context) (certificate.Signer, error) {
+	if namespace != "" {
+		if _, err := localReferenceNamespace(&ref, namespace); err != nil {
+			return nil, fmt.Errorf("%w: keyPairRef: %v", errInvalidIssuer, err)
+		}
+	}
+	keyPair, publicKey, privateKey, err := getSignatureKeyPair(c, ref, namespace, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return certificate.NewSigner(keyPair.Spec.CryptoProvider, keyPair.Spec.Algorithm, publicKey, privateKey, ctx)
+}
+
+// getSignatureKeyPair returns a QuantumSignatureKeyPair that can sign
+// certificates together with its raw public and private key
+func getSignatureKeyPair(c client.Reader, ref qubeseciov1.ObjectReference, namespace string, ctx context.Context) (*qubeseciov1.QuantumSignatureKeyPair, []byte, []byte, error) {
+	namespace = referenceNamespace(&ref, namespace)
+
+	keyPair := &qubeseciov1.QuantumSignatureKeyPair{}
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, keyPair); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get QuantumSignatureKeyPair %s: %w", ref.Name, err)
+	}
+	if !certificate.Supported(keyPair.Spec.Algorithm) {
+		return nil, nil, nil, fmt.Errorf("%w: QuantumSignatureKeyPair %s uses %s, which cannot sign certificates", errInvalidIssuer, ref.Name, keyPair.Spec.Algorithm)
+	}
+	if keyPair.Status.Status != "Success" {
+		return nil, nil, nil, fmt.Errorf("QuantumSignatureKeyPair %s %w", ref.Name, errIssuerNotReady)
+	}
+
+	// The Secret is missing while the key pair generates a new key
+	secretName := signatureKeyPairSecretName(keyPair)
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Name: secretName, Namespace: namespace}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, nil, fmt.Errorf("key pair secret %s %w", secretName, errIssuerNotReady)
+		}
+		return nil, nil, nil, fmt.Errorf("failed to get key pair secret %s: %w", secretName, err)
+	}
+
+	publicKey, err := keypair.DecodePublicKey(keyPair.Spec.Algorithm, secret.Data["public-key"])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("key pair secret %s has no valid public-key: %w", secretName, err)
+	}
+	privateKeyData, err := plaintextPrivateKey(c, keyPair.Spec.PrivateKeyEncryption, namespace, secret.Data["private-key"], ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("key pair secret %s has no readable private-key: %w", secretName, err)
+	}
+	privateKey, err := keypair.DecodePrivateKey(keyPair.Spec.Algorithm, privateKeyData)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("key pair secret %s has no valid private-key: %w", secretName, err)
+	}
+	return keyPair, publicKey, privateKey, nil
+}
+
+// signatureKeyPairSecretName returns the name of the Secret holding the key of
+// a QuantumSignatureKeyPair
+func signatureKeyPairSecretName(keyPair *qubeseciov1.QuantumSignatureKeyPair) string {
+	if keyPair.Spec.SecretName != "" {
+		return keyPair.Spec.SecretName
+	}
+	return keyPair.Name
+}
+
+// decodeCertificates returns the DER certificates of a PEM bundle
+func decodeCertificates(bundle []byte) [][]byte {
+	var certificates [][]byte
+	for {
+		var block *pem.Block
+		block, bundle = pem.Decode(bundle)
+		if block == nil {
+			return certificates
+		}
+		if block.Type == "CERTIFICATE" {
+			certificates = append(certificates, block.Bytes)
+		}
+	}
+}
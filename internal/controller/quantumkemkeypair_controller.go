@@ -18,12 +18,14 @@ package controller
 
 import (
 	"context"
+	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
@@ -63,11 +65,21 @@ func (r *QuantumKEMKeyPairReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	// Re-check crypto operations with a second provider in high-assurance namespaces
+	cryptoCtx, err := withCrossCheck(ctx, r.Client, quantumKEMKeyPair.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to get cross-check settings")
+		quantumKEMKeyPair.Status.Status = "Failed"
+		quantumKEMKeyPair.Status.Error = fmt.Sprintf("Failed to get cross-check settings: %v", err)
+		_ = r.Status().Update(ctx, quantumKEMKeyPair)
+		return ctrl.Result{}, err
+	}
+
 	// Create or Update Secret
-	err = r.CreateOrUpdateSecret(quantumKEMKeyPair, ctx)
+	err = r.CreateOrUpdateSecret(quantumKEMKeyPair, cryptoCtx)
 	if err != nil {
 		log.Error(err, "Failed to Create or Update Secret")
-		quantumKEMKeyPair.Status.Status = "Failed"
+		quantumKEMKeyPair.Status.Status = cryptoFailureStatus(err)
 		quantumKEMKeyPair.Status.Error = err.Error()
 		_ = r.Status().Update(ctx, quantumKEMKeyPair)
 		return ctrl.Result{}, err
@@ -81,6 +93,9 @@ func (r *QuantumKEMKeyPairReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&qubeseciov1.QuantumKEMKeyPair{}).
 		Owns(&corev1.Secret{}). // Watch Secret objects owned by QuantumKEMKeyPair
+		// Encrypt the private key again when its passphrase changes, and import
+		// the keys again when the imported keys change
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.secretKEMKeyPairs)).
 		Complete(r)
 }
 
@@ -109,8 +124,35 @@ func (r *QuantumKEMKeyPairReconciler) CreateOrUpdateSecret(quantumKEMKeyPair *qu
 
 	// If Secret already exists, update status to Success
 	if err == nil {
-		if quantumKEMKeyPair.Status.Status != "Success" {
+		// Import the keys again when the keys of spec.importFrom change
+		importChanged, err := r.importKeyPair(quantumKEMKeyPair, secret, ctx)
+		if err != nil {
+			log.Error(err, "Failed to import KEM keypair")
+			return err
+		}
+
+		// Re-encode the keys when spec.keyFormat asks for another format
+		keyFormat, err := reencodeKeyPairSecret(r.Client, secret, quantumKEMKeyPair.Spec.Algorithm, quantumKEMKeyPair.Spec.KeyFormat, ctx)
+		if err != nil {
+			log.Error(err, "Failed to re-encode key pair")
+			return err
+		}
+
+		// Encrypt the private key as spec.privateKeyEncryption configures
+		encryptionChanged, err := encryptKeyPairSecret(r.Client, quantumKEMKeyPair.Spec.PrivateKeyEncryption, quantumKEMKeyPair.Namespace, secret, ctx)
+		if err != nil {
+			log.Error(err, "Failed to encrypt private key")
+			return err
+		}
+		if importChanged || encryptionChanged {
+			if err := r.Update(ctx, secret); err != nil {
+				log.Error(err, "Failed to Update Secret")
+				return err
+			}
+		}
+		if quantumKEMKeyPair.Status.Status != "Success" || quantumKEMKeyPair.Status.KeyFormat != keyFormat || importChanged {
 			now := metav1.Now()
+			quantumKEMKeyPair.Status.KeyFormat = keyFormat
 			quantumKEMKeyPair.Status.Status = "Success"
 			quantumKEMKeyPair.Status.KeyPairReference = &qubeseciov1.ObjectReference{
 				Name:      secretName,
@@ -124,13 +166,10 @@ func (r *QuantumKEMKeyPairReconciler) CreateOrUpdateSecret(quantumKEMKeyPair *qu
 	}
 
 	// If Secret doesn't exist, create it
-	publicKey, privateKey, genErr := keypair.GenerateKEMKeyPair(quantumKEMKeyPair.Spec.Algorithm, ctx)
-	if genErr != nil {
-		log.Error(genErr, "Failed to generate KEM keypair")
-		quantumKEMKeyPair.Status.Status = "Failed"
-		quantumKEMKeyPair.Status.Error = genErr.Error()
-		_ = r.Status().Update(ctx, quantumKEMKeyPair)
-		return genErr
+	keyFormat, err := keypair.ResolveFormat(quantumKEMKeyPair.Spec.KeyFormat, quantumKEMKeyPair.Spec.Algorithm)
+	if err != nil {
+		log.Error(err, "Invalid key format")
+		return err
 	}
 
 	// Create Secret object
@@ -139,10 +178,30 @@ func (r *QuantumKEMKeyPairReconciler) CreateOrUpdateSecret(quantumKEMKeyPair *qu
 			Name:      secretName,
 			Namespace: quantumKEMKeyPair.Namespace,
 		},
-		Data: map[string][]byte{
+	}
+	if quantumKEMKeyPair.Spec.ImportFrom != nil {
+		// Import the keys of spec.importFrom instead of generating them
+		if _, err := r.importKeyPair(quantumKEMKeyPair, newSecret, ctx); err != nil {
+			log.Error(err, "Failed to import KEM keypair")
+			return err
+		}
+	} else {
+		publicKey, privateKey, genErr := keypair.GenerateKEMKeyPair(quantumKEMKeyPair.Spec.CryptoProvider, quantumKEMKeyPair.Spec.RNGProvider, quantumKEMKeyPair.Spec.Algorithm, keyFormat, ctx)
+		if genErr != nil {
+			log.Error(genErr, "Failed to generate KEM keypair")
+			quantumKEMKeyPair.Status.Status = cryptoFailureStatus(genErr)
+			quantumKEMKeyPair.Status.Error = genErr.Error()
+			_ = r.Status().Update(ctx, quantumKEMKeyPair)
+			return genErr
+		}
+		newSecret.Data = map[string][]byte{
 			"public-key":  []byte(publicKey),
 			"private-key": []byte(privateKey),
-		},
+		}
+	}
+	if _, err := encryptKeyPairSecret(r.Client, quantumKEMKeyPair.Spec.PrivateKeyEncryption, quantumKEMKeyPair.Namespace, newSecret, ctx); err != nil {
+		log.Error(err, "Failed to encrypt private key")
+		return err
 	}
 
 	// Set owner reference to QuantumKEMKeyPair for Secret
@@ -163,6 +222,7 @@ func (r *QuantumKEMKeyPairReconciler) CreateOrUpdateSecret(quantumKEMKeyPair *qu
 	// Update status to Success
 	now := metav1.Now()
 	quantumKEMKeyPair.Status.Status = "Success"
+	quantumKEMKeyPair.Status.KeyFormat = keyFormat
 	quantumKEMKeyPair.Status.KeyPairReference = &qubeseciov1.ObjectReference{
 		Name:      secretName,
 		Namespace: quantumKEMKeyPair.Namespace,
@@ -0,0 +1,364 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/derivedkey"
+	"github.com/QubeSec/QubeSec/internal/randomoutput"
+)
+
+// keyHierarchyLabel marks the Secrets of a key hierarchy so removed nodes can be pruned
+const keyHierarchyLabel = "qubesec.io/key-hierarchy"
+
+// errMasterNotReady is returned while the master secret is still being generated
+var errMasterNotReady = errors.New("not ready")
+
+// QuantumKeyHierarchyReconciler reconciles a QuantumKeyHierarchy object
+type QuantumKeyHierarchyReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumkeyhierarchies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumkeyhierarchies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumkeyhierarchies/finalizers,verbs=update
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumrandomnumbers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumencapsulatesecrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumdecapsulatesecrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile derives every declared node key from the master secret and
+// stores it in a Secret. Derivation is deterministic, so the Secrets are
+// rebuilt with the same keys whenever they are lost.
+func (r *QuantumKeyHierarchyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	// Fetch the QuantumKeyHierarchy resource
+	hierarchy := &qubeseciov1.QuantumKeyHierarchy{}
+	if err := r.Get(ctx, req.NamespacedName, hierarchy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Validate the tree
+	secretNames, err := validateKeyHierarchy(hierarchy)
+	if err != nil {
+		log.Error(err, "QuantumKeyHierarchy validation failed")
+		hierarchy.Status.Status = "Failed"
+		hierarchy.Status.Error = err.Error()
+		_ = r.Status().Update(ctx, hierarchy)
+		return ctrl.Result{}, nil
+	}
+
+	// Get the master secret
+	master, err := r.getMasterSecret(hierarchy, ctx)
+	if err != nil {
+		log.Error(err, "Failed to get master secret")
+		hierarchy.Status.Status = "Failed"
+		if errors.Is(err, errMasterNotReady) {
+			hierarchy.Status.Status = "Pending"
+		}
+		hierarchy.Status.Error = err.Error()
+		_ = r.Status().Update(ctx, hierarchy)
+		return ctrl.Result{}, err
+	}
+
+	// Derive and store every node key
+	nodes := make([]qubeseciov1.KeyHierarchyNodeStatus, 0, len(hierarchy.Spec.Keys))
+	for i, node := range hierarchy.Spec.Keys {
+		nodeKey, err := derivedkey.DeriveHierarchyKey(master, node.Path, node.KeyType, ctx)
+		if err != nil {
+			log.Error(err, "Failed to derive key", "path", node.Path)
+			hierarchy.Status.Status = "Failed"
+			hierarchy.Status.Error = fmt.Sprintf("Failed to derive key %s: %v", node.Path, err)
+			_ = r.Status().Update(ctx, hierarchy)
+			return ctrl.Result{}, nil
+		}
+
+		hash := sha256.Sum256(nodeKey)
+		fingerprint := hex.EncodeToString(hash[:])
+
+		if err := r.storeNodeKey(hierarchy, node, secretNames[i], nodeKey, fingerprint, ctx); err != nil {
+			log.Error(err, "Failed to store key", "path", node.Path)
+			hierarchy.Status.Status = "Failed"
+			hierarchy.Status.Error = fmt.Sprintf("Failed to store key %s: %v", node.Path, err)
+			_ = r.Status().Update(ctx, hierarchy)
+			return ctrl.Result{}, err
+		}
+
+		nodes = append(nodes, qubeseciov1.KeyHierarchyNodeStatus{
+			Path: node.Path,
+			KeyReference: &qubeseciov1.ObjectReference{
+				Name:      secretNames[i],
+				Namespace: hierarchy.Namespace,
+			},
+			Fingerprint: fingerprint[:10],
+		})
+	}
+
+	// Remove the Secrets of nodes that are no longer declared
+	if err := r.pruneNodeKeys(hierarchy, secretNames, ctx); err != nil {
+		log.Error(err, "Failed to prune removed keys")
+		return ctrl.Result{}, err
+	}
+
+	// Update status only when it changed, so status updates do not trigger
+	// another reconcile
+	masterHash := sha256.Sum256(master)
+	status := qubeseciov1.QuantumKeyHierarchyStatus{
+		Status:            "Success",
+		MasterFingerprint: hex.EncodeToString(masterHash[:])[:10],
+		KeyCount:          len(nodes),
+		Keys:              nodes,
+		LastUpdateTime:    hierarchy.Status.LastUpdateTime,
+	}
+	if equality.Semantic.DeepEqual(status, hierarchy.Status) {
+		return ctrl.Result{}, nil
+	}
+	now := metav1.Now()
+	status.LastUpdateTime = &now
+	hierarchy.Status = status
+	if err := r.Status().Update(ctx, hierarchy); err != nil {
+		log.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// validateKeyHierarchy checks the master reference and the node paths, and
+// returns the Secret name of each node
+func validateKeyHierarchy(hierarchy *qubeseciov1.QuantumKeyHierarchy) ([]string, error) {
+	master := hierarchy.Spec.Master
+	if (master.RandomNumberRef == nil) == (master.SharedSecretRef == nil) {
+		return nil, fmt.Errorf("exactly one of master.randomNumberRef and master.sharedSecretRef must be set")
+	}
+
+	secretNames := make([]string, 0, len(hierarchy.Spec.Keys))
+	seenPaths := map[string]bool{}
+	seenNames := map[string]string{}
+	for _, node := range hierarchy.Spec.Keys {
+		labels, err := derivedkey.ParsePath(node.Path)
+		if err != nil {
+			return nil, err
+		}
+		if seenPaths[node.Path+"/"+node.KeyType] {
+			return nil, fmt.Errorf("path %s is declared twice for %s", node.Path, node.KeyType)
+		}
+		seenPaths[node.Path+"/"+node.KeyType] = true
+
+		secretName := node.SecretName
+		if secretName == "" {
+			secretName = strings.Join(append([]string{hierarchy.Name}, labels...), "-")
+			if len(labels) == 0 {
+				secretName = hierarchy.Name + "-m"
+			}
+		}
+		if other, ok := seenNames[secretName]; ok {
+			return nil, fmt.Errorf("paths %s and %s both use secret %s", other, node.Path, secretName)
+		}
+		seenNames[secretName] = node.Path
+		secretNames = append(secretNames, secretName)
+	}
+
+	return secretNames, nil
+}
+
+// getMasterSecret reads the master secret from the referenced QuantumRandomNumber or shared secret
+func (r *QuantumKeyHierarchyReconciler) getMasterSecret(hierarchy *qubeseciov1.QuantumKeyHierarchy, ctx context.Context) ([]byte, error) {
+	var secretRef *qubeseciov1.ObjectReference
+	var key string
+
+	// The node keys are stored in the namespace of the hierarchy, so the
+	// master must come from there as well
+	if ref := hierarchy.Spec.Master.RandomNumberRef; ref != nil {
+		namespace, err := localReferenceNamespace(ref, hierarchy.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		qrn := &qubeseciov1.QuantumRandomNumber{}
+		if err := r.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, qrn); err != nil {
+			return nil, fmt.Errorf("failed to get QuantumRandomNumber: %w", err)
+		}
+
+		// A refreshed or encoded master would silently change every key in the tree
+		if qrn.Spec.RefreshInterval != nil {
+			return nil, fmt.Errorf("master QuantumRandomNumber %s must not have a refreshInterval", ref.Name)
+		}
+		if (qrn.Spec.Format != "" && qrn.Spec.Format != randomoutput.Raw) || qrn.Spec.Count > 1 {
+			return nil, fmt.Errorf("master QuantumRandomNumber %s must produce a single raw value", ref.Name)
+		}
+
+		if qrn.Status.Status != "Success" {
+			return nil, fmt.Errorf("master QuantumRandomNumber %s %w", ref.Name, errMasterNotReady)
+		}
+		secretRef = qrn.Status.RandomNumberReference
+		key = "quantumrandomnumber"
+	} else {
+		ref := hierarchy.Spec.Master.SharedSecretRef
+		namespace, err := localReferenceNamespace(ref, hierarchy.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		status := ""
+		ephemeral := false
+
+		// The shared secret can come from either side of the exchange
+		encapsulateSecret := &qubeseciov1.QuantumEncapsulateSecret{}
+		err = r.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, encapsulateSecret)
+		if err == nil {
+			secretRef = encapsulateSecret.Status.SharedSecretReference
+			status = encapsulateSecret.Status.Status
+			ephemeral = encapsulateSecret.Spec.Derive != nil
+		} else {
+			decapsulateSecret := &qubeseciov1.QuantumDecapsulateSecret{}
+			if err := r.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, decapsulateSecret); err != nil {
+				return nil, fmt.Errorf("failed to get referenced shared secret: %w", err)
+			}
+			secretRef = decapsulateSecret.Status.SharedSecretReference
+			status = decapsulateSecret.Status.Status
+			ephemeral = decapsulateSecret.Spec.Derive != nil
+		}
+
+		if ephemeral {
+			return nil, fmt.Errorf("master shared secret %s is ephemeral and cannot be read", ref.Name)
+		}
+
+		if status != "Success" {
+			return nil, fmt.Errorf("master shared secret %s %w", ref.Name, errMasterNotReady)
+		}
+		key = "shared-secret"
+	}
+
+	if secretRef == nil {
+		return nil, fmt.Errorf("master secret reference not set")
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Name: secretRef.Name, Namespace: secretRef.Namespace}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get master secret data: %w", err)
+	}
+	master, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("key %q not found in master secret %s", key, secretRef.Name)
+	}
+
+	return master, nil
+}
+
+// storeNodeKey creates the Secret of a node, or updates it if its key differs
+func (r *QuantumKeyHierarchyReconciler) storeNodeKey(hierarchy *qubeseciov1.QuantumKeyHierarchy, node qubeseciov1.KeyHierarchyNode, secretName string, nodeKey []byte, fingerprint string, ctx context.Context) error {
+	log := logf.FromContext(ctx)
+
+	data := map[string][]byte{
+		"derived-key": nodeKey,
+		"fingerprint": []byte(fingerprint),
+		"key-type":    []byte(node.KeyType),
+		"path":        []byte(node.Path),
+	}
+
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKey{Name: secretName, Namespace: hierarchy.Namespace}, secret)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+
+	if err == nil {
+		// Never overwrite a Secret this hierarchy does not own
+		if !metav1.IsControlledBy(secret, hierarchy) {
+			return fmt.Errorf("secret %s exists and is not owned by this QuantumKeyHierarchy", secretName)
+		}
+		if bytes.Equal(secret.Data["derived-key"], nodeKey) && string(secret.Data["path"]) == node.Path {
+			return nil
+		}
+		secret.Data = data
+		if err := r.Update(ctx, secret); err != nil {
+			return err
+		}
+		log.Info("Updated node key", "path", node.Path, "secret", secretName)
+		return nil
+	}
+
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: hierarchy.Namespace,
+			Labels: map[string]string{
+				keyHierarchyLabel: hierarchy.Name,
+			},
+		},
+		Data: data,
+	}
+	if err := ctrl.SetControllerReference(hierarchy, secret, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, secret); err != nil {
+		return err
+	}
+	log.Info("Created node key", "path", node.Path, "secret", secretName)
+
+	return nil
+}
+
+// pruneNodeKeys deletes owned Secrets that no declared node uses
+func (r *QuantumKeyHierarchyReconciler) pruneNodeKeys(hierarchy *qubeseciov1.QuantumKeyHierarchy, secretNames []string, ctx context.Context) error {
+	log := logf.FromContext(ctx)
+
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, client.InNamespace(hierarchy.Namespace), client.MatchingLabels{keyHierarchyLabel: hierarchy.Name}); err != nil {
+		return err
+	}
+
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if !metav1.IsControlledBy(secret, hierarchy) || slices.Contains(secretNames, secret.Name) {
+			continue
+		}
+		if err := r.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		log.Info("Deleted removed node key", "secret", secret.Name)
+	}
+
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *QuantumKeyHierarchyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&qubeseciov1.QuantumKeyHierarchy{}).
+		Owns(&corev1.Secret{}). // Watch Secret objects owned by QuantumKeyHierarchy
+		Named("quantumkeyhierarchy").
+		Complete(r)
+}
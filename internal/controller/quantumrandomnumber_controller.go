@@ -17,12 +17,14 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"io"
-	"net/http"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -32,16 +34,33 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
-	"github.com/open-quantum-safe/liboqs-go/oqs"
 
-	"github.com/QubeSec/QubeSec/internal/shannonentropy"
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
+	"github.com/QubeSec/QubeSec/internal/drbg"
+	"github.com/QubeSec/QubeSec/internal/entropy"
+	"github.com/QubeSec/QubeSec/internal/randomness"
+	"github.com/QubeSec/QubeSec/internal/randomoutput"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// defaultEntropyTimeout bounds requests to HTTPS entropy sources
+	defaultEntropyTimeout = 10 * time.Second
+	// previousSuffix marks the values kept during a refresh grace period
+	previousSuffix = ".previous"
+
+	// Encodings of spec.seed
+	seedEncodingHex    = "hex"
+	seedEncodingBase64 = "base64"
+	seedEncodingRaw    = "raw"
+)
+
 // QuantumRandomNumberReconciler reconciles a QuantumRandomNumber object
 type QuantumRandomNumberReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// EntropyPolicy restricts the hosts and files entropy sources may use
+	EntropyPolicy entropy.Policy
 }
 
 //+kubebuilder:rbac:groups=qubesec.io,resources=quantumrandomnumbers,verbs=get;list;watch;create;update;patch;delete
@@ -76,7 +95,7 @@ func (r *QuantumRandomNumberReconciler) Reconcile(ctx context.Context, req ctrl.
 	r.applyDefaults(quantumRandomNumber)
 
 	// Validate the resource
-	if err := r.validateQuantumRandomNumber(quantumRandomNumber); err != nil {
+	if err := r.validateQuantumRandomNumber(quantumRandomNumber, ctx); err != nil {
 		log.Error(err, "QuantumRandomNumber validation failed")
 		quantumRandomNumber.Status.Status = "Failed"
 		quantumRandomNumber.Status.Error = err.Error()
@@ -94,7 +113,8 @@ func (r *QuantumRandomNumberReconciler) Reconcile(ctx context.Context, req ctrl.
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	// Requeue for the next refresh or grace period expiry
+	return ctrl.Result{RequeueAfter: nextScheduledUpdate(quantumRandomNumber)}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -129,10 +149,27 @@ func (r *QuantumRandomNumberReconciler) CreateOrUpdateSecret(quantumRandomNumber
 		return err
 	}
 
-	// If Secret already exists, update status to Success
+	// If Secret already exists, refresh the values when due, drop expired
+	// previous values, and update status to Success
 	if err == nil {
+		now := metav1.Now()
+		if refreshDue(quantumRandomNumber, now) {
+			return r.RefreshSecret(quantumRandomNumber, secret, ctx)
+		}
+		if expiry := quantumRandomNumber.Status.PreviousExpiryTime; expiry != nil && !now.Before(expiry) {
+			for key := range secret.Data {
+				if strings.HasSuffix(key, previousSuffix) {
+					delete(secret.Data, key)
+				}
+			}
+			if err := r.Update(ctx, secret); err != nil {
+				return err
+			}
+			log.Info("Removed previous random values")
+			quantumRandomNumber.Status.PreviousExpiryTime = nil
+			_ = r.Status().Update(ctx, quantumRandomNumber)
+		}
 		if quantumRandomNumber.Status.Status != "Success" {
-			now := metav1.Now()
 			quantumRandomNumber.Status.Status = "Success"
 			quantumRandomNumber.Status.RandomNumberReference = &qubeseciov1.ObjectReference{
 				Name:      secretName,
@@ -146,7 +183,7 @@ func (r *QuantumRandomNumberReconciler) CreateOrUpdateSecret(quantumRandomNumber
 	}
 
 	// If Secret doesn't exist, create it
-	secret, shannonEntropy, genErr := r.GenerateRandomNumberSecret(quantumRandomNumber, secretName, ctx)
+	secret, drawn, assessment, genErr := r.GenerateRandomNumberSecret(quantumRandomNumber, secretName, ctx)
 	if genErr != nil {
 		return genErr
 	}
@@ -158,7 +195,7 @@ func (r *QuantumRandomNumberReconciler) CreateOrUpdateSecret(quantumRandomNumber
 	}
 	log.Info("Created Secret")
 
-	err = r.UpdateStatus(quantumRandomNumber, ctx, shannonEntropy)
+	err = r.UpdateStatus(quantumRandomNumber, ctx, drawn, assessment)
 	if err != nil {
 		log.Error(err, "Create: Failed to Update Status")
 		return err
@@ -178,44 +215,162 @@ func (r *QuantumRandomNumberReconciler) CreateOrUpdateSecret(quantumRandomNumber
 	return nil
 }
 
-// generate random number secret
-func (r *QuantumRandomNumberReconciler) GenerateRandomNumberSecret(quantumRandomNumber *qubeseciov1.QuantumRandomNumber, secretName string, ctx context.Context) (*corev1.Secret, float64, error) {
+// RefreshSecret regenerates the values in an existing Secret. With a grace
+// period the current values are kept under "<key>.previous" until it expires.
+func (r *QuantumRandomNumberReconciler) RefreshSecret(quantumRandomNumber *qubeseciov1.QuantumRandomNumber, secret *corev1.Secret, ctx context.Context) error {
 	// Setup logger
 	log := log.FromContext(ctx)
 
-	// Set provider for quantum random number; fail if switching fails
-	if err := oqs.RandomBytesSwitchAlgorithm(quantumRandomNumber.Spec.Provider); err != nil {
-		return nil, 0, err
+	values, drawn, assessment, err := r.GenerateRandomValues(quantumRandomNumber, ctx)
+	if err != nil {
+		return err
+	}
+
+	quantumRandomNumber.Status.PreviousExpiryTime = nil
+	if grace := quantumRandomNumber.Spec.GracePeriod; grace != nil && grace.Duration > 0 {
+		for key, value := range secret.Data {
+			if !strings.HasSuffix(key, previousSuffix) {
+				values[key+previousSuffix] = value
+			}
+		}
+		expiry := metav1.NewTime(time.Now().Add(grace.Duration))
+		quantumRandomNumber.Status.PreviousExpiryTime = &expiry
+	}
+
+	secret.Data = values
+	if err := r.Update(ctx, secret); err != nil {
+		return err
+	}
+	log.Info("Refreshed Secret")
+
+	if err := r.UpdateStatus(quantumRandomNumber, ctx, drawn, assessment); err != nil {
+		log.Error(err, "Refresh: Failed to Update Status")
+		return err
 	}
 
-	// Generate quantum random number
-	randomNumber := oqs.RandomBytes(quantumRandomNumber.Spec.Bytes)
+	return nil
+}
+
+// generate random number secret
+func (r *QuantumRandomNumberReconciler) GenerateRandomNumberSecret(quantumRandomNumber *qubeseciov1.QuantumRandomNumber, secretName string, ctx context.Context) (*corev1.Secret, int, randomness.Result, error) {
+	// Setup logger
+	log := log.FromContext(ctx)
 
-	// Calculate Shannon Entropy
-	shannonEntropy := shannonentropy.ShannonEntropy(randomNumber)
+	values, drawn, assessment, err := r.GenerateRandomValues(quantumRandomNumber, ctx)
+	if err != nil {
+		return nil, 0, assessment, err
+	}
 
-	// Create Secret object with quantum random number
+	// Create Secret object with quantum random numbers
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      secretName,
 			Namespace: quantumRandomNumber.Namespace,
 		},
-		Data: map[string][]byte{
-			"quantumrandomnumber": randomNumber,
-		},
+		Data: values,
 	}
 
 	// Set owner reference to QuantumRandomNumber for Secret
-	err := ctrl.SetControllerReference(quantumRandomNumber, secret, r.Scheme)
+	err = ctrl.SetControllerReference(quantumRandomNumber, secret, r.Scheme)
 	if err != nil {
 		log.Error(err, "Failed to Set Controller Reference")
 	}
 
-	return secret, shannonEntropy, nil
+	return secret, drawn, assessment, nil
+}
+
+// GenerateRandomValues generates count values in the requested format. It
+// returns the values keyed by Secret key, the number of random bytes drawn
+// and the assessment of those bytes.
+func (r *QuantumRandomNumberReconciler) GenerateRandomValues(quantumRandomNumber *qubeseciov1.QuantumRandomNumber, ctx context.Context) (map[string][]byte, int, randomness.Result, error) {
+	// Resolve the random source
+	rand, err := r.randomSource(quantumRandomNumber, ctx)
+	if err != nil {
+		return nil, 0, randomness.Result{}, err
+	}
+
+	// Record every byte drawn so the assessment covers the raw output,
+	// including bytes rejected while sampling password characters
+	var drawn bytes.Buffer
+	rand = io.TeeReader(rand, &drawn)
+
+	values := make(map[string][]byte, quantumRandomNumber.Spec.Count)
+	for i := range quantumRandomNumber.Spec.Count {
+		value, err := randomoutput.Generate(rand, quantumRandomNumber.Spec.Format, quantumRandomNumber.Spec.Bytes, passwordPolicy(quantumRandomNumber))
+		if err != nil {
+			return nil, 0, randomness.Result{}, err
+		}
+		values[valueKey(quantumRandomNumber.Spec.Count, i)] = value
+	}
+
+	// Assess the output and reject it if a test fails
+	assessment := randomness.Assess(drawn.Bytes(), quantumRandomNumber.Spec.StatisticalTests)
+	quantumRandomNumber.Status.Assessment = assessmentStatus(assessment)
+	if err := assessment.Err(); err != nil {
+		return nil, 0, assessment, err
+	}
+
+	return values, drawn.Len(), assessment, nil
+}
+
+// valueKey returns the Secret key of value i out of count
+func valueKey(count, i int) string {
+	if count == 1 {
+		return "quantumrandomnumber"
+	}
+	return fmt.Sprintf("quantumrandomnumber-%d", i)
+}
+
+// passwordPolicy converts the spec password policy
+func passwordPolicy(qrng *qubeseciov1.QuantumRandomNumber) randomoutput.PasswordPolicy {
+	if qrng.Spec.Password == nil {
+		return randomoutput.PasswordPolicy{}
+	}
+	return randomoutput.PasswordPolicy{
+		Length:       qrng.Spec.Password.Length,
+		Charset:      qrng.Spec.Password.Charset,
+		MinLowercase: qrng.Spec.Password.MinLowercase,
+		MinUppercase: qrng.Spec.Password.MinUppercase,
+		MinDigits:    qrng.Spec.Password.MinDigits,
+		MinSymbols:   qrng.Spec.Password.MinSymbols,
+	}
+}
+
+// refreshDue reports whether the values should be regenerated at now
+func refreshDue(qrng *qubeseciov1.QuantumRandomNumber, now metav1.Time) bool {
+	if qrng.Spec.RefreshInterval == nil {
+		return false
+	}
+	next := qrng.Status.NextRefreshTime
+	if next == nil {
+		// The interval was added after the values were generated
+		if qrng.Status.LastUpdateTime == nil {
+			return true
+		}
+		return !now.Time.Before(qrng.Status.LastUpdateTime.Add(qrng.Spec.RefreshInterval.Duration))
+	}
+	return !now.Before(next)
+}
+
+// nextScheduledUpdate returns the time until the next refresh or grace
+// period expiry, or zero if nothing is scheduled
+func nextScheduledUpdate(qrng *qubeseciov1.QuantumRandomNumber) time.Duration {
+	var next time.Duration
+	for _, t := range []*metav1.Time{qrng.Status.NextRefreshTime, qrng.Status.PreviousExpiryTime} {
+		if t == nil {
+			continue
+		}
+		// Never requeue immediately; due work is picked up on the next reconcile
+		d := max(time.Until(t.Time), time.Second)
+		if next == 0 || d < next {
+			next = d
+		}
+	}
+	return next
 }
 
 // Update Status of QuantumRandomNumber
-func (r *QuantumRandomNumberReconciler) UpdateStatus(quantumrandomnumber *qubeseciov1.QuantumRandomNumber, ctx context.Context, shannonEntropy float64) error {
+func (r *QuantumRandomNumberReconciler) UpdateStatus(quantumrandomnumber *qubeseciov1.QuantumRandomNumber, ctx context.Context, drawn int, assessment randomness.Result) error {
 	// Setup logger
 	log := log.FromContext(ctx)
 
@@ -223,8 +378,23 @@ func (r *QuantumRandomNumberReconciler) UpdateStatus(quantumrandomnumber *qubese
 	now := metav1.Now()
 	quantumrandomnumber.Status.Status = "Success"
 	quantumrandomnumber.Status.Bytes = quantumrandomnumber.Spec.Bytes
+	quantumrandomnumber.Status.SampleBytes = drawn
 	quantumrandomnumber.Status.Provider = quantumrandomnumber.Spec.Provider
-	quantumrandomnumber.Status.Entropy = fmt.Sprintf("%.12f", shannonEntropy)
+	quantumrandomnumber.Status.Format = quantumrandomnumber.Spec.Format
+	quantumrandomnumber.Status.Count = quantumrandomnumber.Spec.Count
+	quantumrandomnumber.Status.NextRefreshTime = nil
+	if quantumrandomnumber.Spec.RefreshInterval != nil {
+		next := metav1.NewTime(now.Add(quantumrandomnumber.Spec.RefreshInterval.Duration))
+		quantumrandomnumber.Status.NextRefreshTime = &next
+	}
+	quantumrandomnumber.Status.Entropy = fmt.Sprintf("%.6f", assessment.MinEntropy())
+	quantumrandomnumber.Status.Deterministic = quantumrandomnumber.Spec.Seed != ""
+	quantumrandomnumber.Status.DRBG = ""
+	if quantumrandomnumber.Status.Deterministic || len(quantumrandomnumber.Spec.EntropySources) > 0 {
+		// The DRBG produced the output, not the provider
+		quantumrandomnumber.Status.DRBG = quantumrandomnumber.Spec.DRBG
+		quantumrandomnumber.Status.Provider = quantumrandomnumber.Spec.DRBG
+	}
 	quantumrandomnumber.Status.LastUpdateTime = &now
 	quantumrandomnumber.Status.Error = ""
 	err := r.Status().Update(ctx, quantumrandomnumber)
@@ -236,6 +406,39 @@ func (r *QuantumRandomNumberReconciler) UpdateStatus(quantumrandomnumber *qubese
 	return nil
 }
 
+// assessmentStatus converts a randomness assessment to its status representation
+func assessmentStatus(assessment randomness.Result) *qubeseciov1.RandomnessAssessment {
+	status := &qubeseciov1.RandomnessAssessment{
+		MostCommonValue: fmt.Sprintf("%.6f", assessment.MostCommonValue),
+		Collision:       fmt.Sprintf("%.6f", assessment.Collision),
+	}
+
+	for _, t := range assessment.HealthTests {
+		status.Tests = append(status.Tests, testResultStatus(t))
+	}
+	for _, t := range assessment.StatisticalTests {
+		testStatus := testResultStatus(t)
+		if !t.Skipped {
+			testStatus.PValue = fmt.Sprintf("%.6f", t.PValue)
+		}
+		status.Tests = append(status.Tests, testStatus)
+	}
+
+	return status
+}
+
+// testResultStatus converts a single test result
+func testResultStatus(t randomness.TestResult) qubeseciov1.RandomnessTestResult {
+	result := "Pass"
+	switch {
+	case t.Skipped:
+		result = "Skipped"
+	case !t.Passed:
+		result = "Fail"
+	}
+	return qubeseciov1.RandomnessTestResult{Name: t.Name, Result: result, Detail: t.Detail}
+}
+
 // applyDefaults sets default values for QuantumRandomNumber
 func (r *QuantumRandomNumberReconciler) applyDefaults(qrng *qubeseciov1.QuantumRandomNumber) {
 	if qrng.Spec.Bytes == 0 {
@@ -244,59 +447,277 @@ func (r *QuantumRandomNumberReconciler) applyDefaults(qrng *qubeseciov1.QuantumR
 	if qrng.Spec.Provider == "" {
 		qrng.Spec.Provider = "system"
 	}
+	if qrng.Spec.Format == "" {
+		qrng.Spec.Format = randomoutput.Raw
+	}
+	if qrng.Spec.Count == 0 {
+		qrng.Spec.Count = 1
+	}
+	if qrng.Spec.SeedEncoding == "" {
+		qrng.Spec.SeedEncoding = seedEncodingRaw
+	}
+	if qrng.Spec.DRBG == "" && (qrng.Spec.Seed != "" || qrng.Spec.SeedURI != "" || len(qrng.Spec.EntropySources) > 0) {
+		qrng.Spec.DRBG = drbg.CTR
+	}
+}
+
+// randomSource returns a DRBG instantiated from the seed or the conditioned
+// entropy sources, or an isolated reader for the requested provider.
+func (r *QuantumRandomNumberReconciler) randomSource(qrng *qubeseciov1.QuantumRandomNumber, ctx context.Context) (io.Reader, error) {
+	if qrng.Spec.Seed != "" {
+		seed, err := decodeSeed(qrng.Spec.Seed, qrng.Spec.SeedEncoding)
+		if err != nil {
+			return nil, err
+		}
+		return drbg.New(qrng.Spec.DRBG, seed, []byte(qrng.Spec.Personalization))
+	}
+	if len(qrng.Spec.EntropySources) > 0 {
+		seed, err := r.gatherEntropy(qrng, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return drbg.New(qrng.Spec.DRBG, seed, []byte(qrng.Spec.Personalization))
+	}
+	return cryptoprovider.NewRandom("", qrng.Spec.Provider)
+}
+
+// decodeSeed decodes a seed in the given encoding
+func decodeSeed(seed string, encoding string) ([]byte, error) {
+	switch encoding {
+	case seedEncodingHex:
+		seedBytes, err := hex.DecodeString(seed)
+		if err != nil {
+			return nil, fmt.Errorf("seed is not valid hex: %w", err)
+		}
+		return seedBytes, nil
+	case seedEncodingBase64:
+		seedBytes, err := base64.StdEncoding.DecodeString(seed)
+		if err != nil {
+			return nil, fmt.Errorf("seed is not valid base64: %w", err)
+		}
+		return seedBytes, nil
+	case seedEncodingRaw:
+		return []byte(seed), nil
+	}
+	return nil, fmt.Errorf("unsupported seed encoding %q", encoding)
 }
 
 // validateQuantumRandomNumber validates the QuantumRandomNumber resource
-func (r *QuantumRandomNumberReconciler) validateQuantumRandomNumber(qrng *qubeseciov1.QuantumRandomNumber) error {
+func (r *QuantumRandomNumberReconciler) validateQuantumRandomNumber(qrng *qubeseciov1.QuantumRandomNumber, ctx context.Context) error {
+	// Entropy sources replace the seed
+	if len(qrng.Spec.EntropySources) > 0 && (qrng.Spec.Seed != "" || qrng.Spec.SeedURI != "") {
+		return fmt.Errorf("entropySources cannot be combined with seed or seedURI")
+	}
+
+	// Validate output format
+	if err := validateOutput(qrng); err != nil {
+		return err
+	}
+
 	// Validate seed
-	if err := r.validateSeed(qrng); err != nil {
+	if err := r.validateSeed(qrng, ctx); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// validateOutput validates the format, password policy and refresh schedule
+func validateOutput(qrng *qubeseciov1.QuantumRandomNumber) error {
+	if qrng.Spec.Password != nil && qrng.Spec.Format != randomoutput.Password {
+		return fmt.Errorf("password can only be set when format is password")
+	}
+	if qrng.Spec.Format == randomoutput.Password {
+		if err := passwordPolicy(qrng).Validate(); err != nil {
+			return err
+		}
+	}
+
+	if interval := qrng.Spec.RefreshInterval; interval != nil {
+		// A fixed seed reproduces the same values on every refresh
+		if qrng.Spec.Seed != "" {
+			return fmt.Errorf("refreshInterval cannot be combined with a fixed seed")
+		}
+		if interval.Duration < time.Minute {
+			return fmt.Errorf("refreshInterval must be at least 1m")
+		}
+	}
+	if grace := qrng.Spec.GracePeriod; grace != nil {
+		if qrng.Spec.RefreshInterval == nil {
+			return fmt.Errorf("gracePeriod requires refreshInterval")
+		}
+		if grace.Duration < 0 || grace.Duration > qrng.Spec.RefreshInterval.Duration {
+			return fmt.Errorf("gracePeriod must be between 0 and refreshInterval")
+		}
+	}
+
+	return nil
+}
+
 // validateSeed validates the seed specification
-func (r *QuantumRandomNumberReconciler) validateSeed(qrng *qubeseciov1.QuantumRandomNumber) error {
+func (r *QuantumRandomNumberReconciler) validateSeed(qrng *qubeseciov1.QuantumRandomNumber, ctx context.Context) error {
 	// If seed is not set but seedURI is provided, fetch it
 	if qrng.Spec.Seed == "" && qrng.Spec.SeedURI != "" {
-		seed, err := r.getSeedFromURI(qrng.Spec.SeedURI)
+		seed, err := r.getSeedFromURI(qrng.Spec.SeedURI, ctx)
 		if err != nil {
 			return fmt.Errorf("failed to get seed from URI: %w", err)
 		}
 		qrng.Spec.Seed = seed
+		qrng.Spec.SeedEncoding = seedEncodingBase64
 	}
 
 	// Validate seed bytes length (if provided)
 	if qrng.Spec.Seed != "" {
-		seedBytes := []byte(qrng.Spec.Seed)
+		seedBytes, err := decodeSeed(qrng.Spec.Seed, qrng.Spec.SeedEncoding)
+		if err != nil {
+			return err
+		}
 		if len(seedBytes) < 48 {
 			return fmt.Errorf("seed length is %d bytes, must be at least 48 bytes", len(seedBytes))
 		}
+
+		// Instantiate once to validate DRBG specific requirements
+		if _, err := drbg.New(qrng.Spec.DRBG, seedBytes, []byte(qrng.Spec.Personalization)); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// getSeedFromURI fetches seed from a URI and converts to base64
-func (r *QuantumRandomNumberReconciler) getSeedFromURI(seedURI string) (string, error) {
-	resp, err := http.Get(seedURI)
+// getSeedFromURI fetches a hex seed over HTTPS and converts it to base64
+func (r *QuantumRandomNumberReconciler) getSeedFromURI(seedURI string, ctx context.Context) (string, error) {
+	source, err := r.EntropyPolicy.HTTPSource(seedURI, nil, defaultEntropyTimeout, "")
+	if err != nil {
+		return "", err
+	}
+
+	seedInBytes, err := source.Fetch(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to get seed from %s: %w", seedURI, err)
 	}
-	defer resp.Body.Close()
 
-	hexSeed, err := io.ReadAll(resp.Body)
+	// Convert hex seed to base64
+	base64Seed := base64.StdEncoding.EncodeToString(seedInBytes)
+	return base64Seed, nil
+}
+
+// gatherEntropy fetches every entropy source, records its health in status
+// and conditions the healthy contributions into a DRBG seed.
+func (r *QuantumRandomNumberReconciler) gatherEntropy(qrng *qubeseciov1.QuantumRandomNumber, ctx context.Context) ([]byte, error) {
+	log := log.FromContext(ctx)
+
+	var inputs [][]byte
+	total := 0
+	statuses := make([]qubeseciov1.EntropySourceStatus, 0, len(qrng.Spec.EntropySources))
+	var failed []string
+
+	for _, spec := range qrng.Spec.EntropySources {
+		sourceStatus := qubeseciov1.EntropySourceStatus{Name: spec.Name, Type: spec.Type}
+
+		data, err := r.fetchEntropySource(qrng.Namespace, spec, ctx)
+		if err == nil {
+			err = entropy.CheckHealth(data)
+		}
+		if err != nil {
+			log.Error(err, "Entropy source failed", "source", spec.Name)
+			sourceStatus.Error = err.Error()
+			if !spec.Optional {
+				failed = append(failed, spec.Name)
+			}
+		} else {
+			sourceStatus.Healthy = true
+			sourceStatus.Bytes = len(data)
+			inputs = append(inputs, data)
+			total += len(data)
+		}
+		statuses = append(statuses, sourceStatus)
+	}
+	qrng.Status.EntropySources = statuses
+
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("required entropy sources failed: %s", strings.Join(failed, ", "))
+	}
+	if total < drbg.CTRSeedSize {
+		return nil, fmt.Errorf("entropy sources contributed %d bytes, need at least %d", total, drbg.CTRSeedSize)
+	}
+
+	return entropy.Condition(inputs, drbg.CTRSeedSize), nil
+}
+
+// fetchEntropySource builds the source described by spec and fetches it
+func (r *QuantumRandomNumberReconciler) fetchEntropySource(namespace string, spec qubeseciov1.EntropySource, ctx context.Context) ([]byte, error) {
+	var source entropy.Source
+	var err error
+
+	switch spec.Type {
+	case "Secret":
+		if spec.SecretRef == nil {
+			return nil, fmt.Errorf("secretRef is required for Secret sources")
+		}
+		key := spec.SecretKey
+		if key == "" {
+			key = "entropy"
+		}
+		data, err := r.getSecretData(namespace, spec.SecretRef, key, ctx)
+		if err != nil {
+			return nil, err
+		}
+		source = entropy.Bytes(data)
+	case "File":
+		n := spec.Bytes
+		if n == 0 {
+			n = drbg.CTRSeedSize
+		}
+		source, err = r.EntropyPolicy.FileSource(spec.Path, n)
+	case "HTTPS", "QRNG":
+		var caPEM []byte
+		if spec.CASecretRef != nil {
+			key := spec.CAKey
+			if key == "" {
+				key = "ca.crt"
+			}
+			if caPEM, err = r.getSecretData(namespace, spec.CASecretRef, key, ctx); err != nil {
+				return nil, err
+			}
+		}
+		timeout := defaultEntropyTimeout
+		if spec.TimeoutSeconds > 0 {
+			timeout = time.Duration(spec.TimeoutSeconds) * time.Second
+		}
+		jsonField := ""
+		if spec.Type == "QRNG" {
+			if spec.JSONField == "" {
+				return nil, fmt.Errorf("jsonField is required for QRNG sources")
+			}
+			jsonField = spec.JSONField
+		}
+		source, err = r.EntropyPolicy.HTTPSource(spec.URL, caPEM, timeout, jsonField)
+	default:
+		return nil, fmt.Errorf("unsupported entropy source type %q", spec.Type)
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to read seed from %s: %w", seedURI, err)
+		return nil, err
 	}
 
-	seedInBytes, err := hex.DecodeString(string(hexSeed))
+	return source.Fetch(ctx)
+}
+
+// getSecretData reads a key from a referenced Secret in namespace
+func (r *QuantumRandomNumberReconciler) getSecretData(namespace string, ref *qubeseciov1.ObjectReference, key string, ctx context.Context) ([]byte, error) {
+	namespace, err := localReferenceNamespace(ref, namespace)
 	if err != nil {
-		return "", fmt.Errorf("failed to decode seed from %s: %w", seedURI, err)
+		return nil, err
 	}
 
-	// Convert hex seed to base64
-	base64Seed := base64.StdEncoding.EncodeToString(seedInBytes)
-	return base64Seed, nil
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get secret %s/%s: %w", namespace, ref.Name, err)
+	}
+
+	data, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("key %q not found in secret %s/%s", key, namespace, ref.Name)
+	}
+	return data, nil
 }
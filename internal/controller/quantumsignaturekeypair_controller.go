@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
@@ -66,11 +67,21 @@ func (r *QuantumSignatureKeyPairReconciler) Reconcile(ctx context.Context, req c
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	// Re-check crypto operations with a second provider in high-assurance namespaces
+	cryptoCtx, err := withCrossCheck(ctx, r.Client, quantumSignatureKeyPair.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to get cross-check settings")
+		quantumSignatureKeyPair.Status.Status = "Failed"
+		quantumSignatureKeyPair.Status.Error = fmt.Sprintf("Failed to get cross-check settings: %v", err)
+		_ = r.Status().Update(ctx, quantumSignatureKeyPair)
+		return ctrl.Result{}, err
+	}
+
 	// Create or Update Secret
-	err = r.CreateOrUpdateSecret(quantumSignatureKeyPair, ctx)
+	err = r.CreateOrUpdateSecret(quantumSignatureKeyPair, cryptoCtx)
 	if err != nil {
 		log.Error(err, "Failed to Create or Update Secret")
-		quantumSignatureKeyPair.Status.Status = "Failed"
+		quantumSignatureKeyPair.Status.Status = cryptoFailureStatus(err)
 		quantumSignatureKeyPair.Status.Error = err.Error()
 		_ = r.Status().Update(ctx, quantumSignatureKeyPair)
 		return ctrl.Result{}, err
@@ -84,6 +95,9 @@ func (r *QuantumSignatureKeyPairReconciler) SetupWithManager(mgr ctrl.Manager) e
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&qubeseciov1.QuantumSignatureKeyPair{}).
 		Owns(&corev1.Secret{}). // Watch Secret objects owned by QuantumKEMKeyPair
+		// Encrypt the private key again when its passphrase changes, and import
+		// the keys again when the imported keys change
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.secretSignatureKeyPairs)).
 		Complete(r)
 }
 
@@ -116,7 +130,17 @@ func (r *QuantumSignatureKeyPairReconciler) CreateOrUpdateSecret(quantumSignatur
 
 	// If Secret already exists, verify contents and update status
 	if err == nil {
-		publicKey, hasPub := secret.Data["public-key"]
+		// Import the keys again when the keys of spec.importFrom change
+		importChanged, err := r.importKeyPair(quantumSignatureKeyPair, secret, ctx)
+		if err != nil {
+			log.Error(err, "Failed to import signature keypair")
+			quantumSignatureKeyPair.Status.Status = cryptoFailureStatus(err)
+			quantumSignatureKeyPair.Status.Error = err.Error()
+			_ = r.Status().Update(ctx, quantumSignatureKeyPair)
+			return err
+		}
+
+		_, hasPub := secret.Data["public-key"]
 		_, hasPriv := secret.Data["private-key"]
 		if !hasPub || !hasPriv {
 			quantumSignatureKeyPair.Status.Status = "Failed"
@@ -125,9 +149,36 @@ func (r *QuantumSignatureKeyPairReconciler) CreateOrUpdateSecret(quantumSignatur
 			return fmt.Errorf("existing secret %s missing public-key/private-key", secretName)
 		}
 
-		fingerprint := sha256.Sum256(publicKey)
+		// Re-encode the keys when spec.keyFormat asks for another format
+		keyFormat, err := reencodeKeyPairSecret(r.Client, secret, quantumSignatureKeyPair.Spec.Algorithm, quantumSignatureKeyPair.Spec.KeyFormat, ctx)
+		if err != nil {
+			log.Error(err, "Failed to re-encode key pair")
+			quantumSignatureKeyPair.Status.Status = "Failed"
+			quantumSignatureKeyPair.Status.Error = err.Error()
+			_ = r.Status().Update(ctx, quantumSignatureKeyPair)
+			return err
+		}
 
-		if quantumSignatureKeyPair.Status.Status != "Success" || quantumSignatureKeyPair.Status.PublicKeyFingerprint == "" {
+		// Encrypt the private key as spec.privateKeyEncryption configures
+		encryptionChanged, err := encryptKeyPairSecret(r.Client, quantumSignatureKeyPair.Spec.PrivateKeyEncryption, quantumSignatureKeyPair.Namespace, secret, ctx)
+		if err != nil {
+			log.Error(err, "Failed to encrypt private key")
+			quantumSignatureKeyPair.Status.Status = "Failed"
+			quantumSignatureKeyPair.Status.Error = err.Error()
+			_ = r.Status().Update(ctx, quantumSignatureKeyPair)
+			return err
+		}
+		if importChanged || encryptionChanged {
+			if err := r.Update(ctx, secret); err != nil {
+				log.Error(err, "Failed to Update Secret")
+				return err
+			}
+		}
+
+		fingerprint := sha256.Sum256(secret.Data["public-key"])
+
+		if quantumSignatureKeyPair.Status.Status != "Success" || quantumSignatureKeyPair.Status.PublicKeyFingerprint != hex.EncodeToString(fingerprint[:])[:10] ||
+			quantumSignatureKeyPair.Status.KeyFormat != keyFormat {
 			if err := r.Get(ctx, client.ObjectKey{Namespace: quantumSignatureKeyPair.Namespace, Name: quantumSignatureKeyPair.Name}, quantumSignatureKeyPair); err != nil {
 				log.Error(err, "Failed to re-fetch QuantumSignatureKeyPair before status update")
 				return client.IgnoreNotFound(err)
@@ -141,6 +192,7 @@ func (r *QuantumSignatureKeyPairReconciler) CreateOrUpdateSecret(quantumSignatur
 			}
 			quantumSignatureKeyPair.Status.LastUpdateTime = &now
 			quantumSignatureKeyPair.Status.PublicKeyFingerprint = hex.EncodeToString(fingerprint[:])[:10]
+			quantumSignatureKeyPair.Status.KeyFormat = keyFormat
 			quantumSignatureKeyPair.Status.Error = ""
 			if statusErr := r.Status().Update(ctx, quantumSignatureKeyPair); statusErr != nil {
 				log.Error(statusErr, "Failed to update status for existing secret")
@@ -151,13 +203,13 @@ func (r *QuantumSignatureKeyPairReconciler) CreateOrUpdateSecret(quantumSignatur
 	}
 
 	// If Secret doesn't exist, create it
-	publicKey, privateKey, genErr := keypair.GenerateSIGKeyPair(quantumSignatureKeyPair.Spec.Algorithm, ctx)
-	if genErr != nil {
-		log.Error(genErr, "Failed to generate signature keypair")
+	keyFormat, err := keypair.ResolveFormat(quantumSignatureKeyPair.Spec.KeyFormat, quantumSignatureKeyPair.Spec.Algorithm)
+	if err != nil {
+		log.Error(err, "Invalid key format")
 		quantumSignatureKeyPair.Status.Status = "Failed"
-		quantumSignatureKeyPair.Status.Error = genErr.Error()
+		quantumSignatureKeyPair.Status.Error = err.Error()
 		_ = r.Status().Update(ctx, quantumSignatureKeyPair)
-		return genErr
+		return err
 	}
 
 	// Create Secret object
@@ -166,10 +218,36 @@ func (r *QuantumSignatureKeyPairReconciler) CreateOrUpdateSecret(quantumSignatur
 			Name:      secretName,
 			Namespace: quantumSignatureKeyPair.Namespace,
 		},
-		Data: map[string][]byte{
+	}
+	if quantumSignatureKeyPair.Spec.ImportFrom != nil {
+		// Import the keys of spec.importFrom instead of generating them
+		if _, err := r.importKeyPair(quantumSignatureKeyPair, newSecret, ctx); err != nil {
+			log.Error(err, "Failed to import signature keypair")
+			quantumSignatureKeyPair.Status.Status = cryptoFailureStatus(err)
+			quantumSignatureKeyPair.Status.Error = err.Error()
+			_ = r.Status().Update(ctx, quantumSignatureKeyPair)
+			return err
+		}
+	} else {
+		publicKey, privateKey, genErr := keypair.GenerateSIGKeyPair(quantumSignatureKeyPair.Spec.CryptoProvider, quantumSignatureKeyPair.Spec.RNGProvider, quantumSignatureKeyPair.Spec.Algorithm, keyFormat, ctx)
+		if genErr != nil {
+			log.Error(genErr, "Failed to generate signature keypair")
+			quantumSignatureKeyPair.Status.Status = cryptoFailureStatus(genErr)
+			quantumSignatureKeyPair.Status.Error = genErr.Error()
+			_ = r.Status().Update(ctx, quantumSignatureKeyPair)
+			return genErr
+		}
+		newSecret.Data = map[string][]byte{
 			"public-key":  []byte(publicKey),
 			"private-key": []byte(privateKey),
-		},
+		}
+	}
+	if _, err := encryptKeyPairSecret(r.Client, quantumSignatureKeyPair.Spec.PrivateKeyEncryption, quantumSignatureKeyPair.Namespace, newSecret, ctx); err != nil {
+		log.Error(err, "Failed to encrypt private key")
+		quantumSignatureKeyPair.Status.Status = "Failed"
+		quantumSignatureKeyPair.Status.Error = err.Error()
+		_ = r.Status().Update(ctx, quantumSignatureKeyPair)
+		return err
 	}
 
 	// Set owner reference to QuantumSignatureKeyPair for Secret
@@ -190,7 +268,7 @@ func (r *QuantumSignatureKeyPairReconciler) CreateOrUpdateSecret(quantumSignatur
 	}
 	log.Info("Created Secret")
 
-	fingerprint := sha256.Sum256([]byte(publicKey))
+	fingerprint := sha256.Sum256(newSecret.Data["public-key"])
 
 	// Update status to Success
 	now := metav1.Now()
@@ -200,6 +278,7 @@ func (r *QuantumSignatureKeyPairReconciler) CreateOrUpdateSecret(quantumSignatur
 		Namespace: quantumSignatureKeyPair.Namespace,
 	}
 	quantumSignatureKeyPair.Status.PublicKeyFingerprint = hex.EncodeToString(fingerprint[:])[:10]
+	quantumSignatureKeyPair.Status.KeyFormat = keyFormat
 	quantumSignatureKeyPair.Status.LastUpdateTime = &now
 	quantumSignatureKeyPair.Status.Error = ""
 	if statusErr := r.Status().Update(ctx, quantumSignatureKeyPair); statusErr != nil {
@@ -130,6 +130,14 @@ func (r *QuantumSignMessageReconciler) Reconcile(ctx context.Context, req ctrl.R
 		_ = r.updateStatus(ctx, quantumSignMessage)
 		return ctrl.Result{}, fmt.Errorf("private key not found in secret")
 	}
+	privateKeyPEM, err := plaintextPrivateKey(r.Client, sigKeyPair.Spec.PrivateKeyEncryption, pkNamespace, privateKeyPEM, ctx)
+	if err != nil {
+		log.Error(err, "Failed to decrypt private key")
+		quantumSignMessage.Status.Status = "Failed"
+		quantumSignMessage.Status.Error = fmt.Sprintf("Failed to decrypt private key: %v", err)
+		_ = r.updateStatus(ctx, quantumSignMessage)
+		return ctrl.Result{}, err
+	}
 
 	// Get the message from the referenced secret
 	msgNamespace := quantumSignMessage.Spec.MessageRef.Namespace
@@ -164,11 +172,21 @@ func (r *QuantumSignMessageReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, fmt.Errorf("message key '%s' not found in secret", messageKey)
 	}
 
+	// Re-check crypto operations with a second provider in high-assurance namespaces
+	cryptoCtx, err := withCrossCheck(ctx, r.Client, quantumSignMessage.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to get cross-check settings")
+		quantumSignMessage.Status.Status = "Failed"
+		quantumSignMessage.Status.Error = fmt.Sprintf("Failed to get cross-check settings: %v", err)
+		_ = r.updateStatus(ctx, quantumSignMessage)
+		return ctrl.Result{}, err
+	}
+
 	// Sign the message
-	sig, err := signature.SignMessage(quantumSignMessage.Spec.Algorithm, privateKeyPEM, messageBytes, ctx)
+	sig, err := signature.SignMessage(quantumSignMessage.Spec.CryptoProvider, quantumSignMessage.Spec.Algorithm, privateKeyPEM, messageBytes, cryptoCtx)
 	if err != nil {
 		log.Error(err, "Failed to sign message")
-		quantumSignMessage.Status.Status = "Failed"
+		quantumSignMessage.Status.Status = cryptoFailureStatus(err)
 		quantumSignMessage.Status.Error = fmt.Sprintf("Failed to sign message: %v", err)
 		_ = r.updateStatus(ctx, quantumSignMessage)
 		return ctrl.Result{}, err
@@ -0,0 +1,235 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/keywrap"
+)
+
+// QuantumUnwrapKeyReconciler reconciles a QuantumUnwrapKey object
+type QuantumUnwrapKeyReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumunwrapkeys,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumunwrapkeys/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumunwrapkeys/finalizers,verbs=update
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumwrapkeys,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumderivedkeys,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *QuantumUnwrapKeyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	// Fetch the QuantumUnwrapKey resource
+	quantumUnwrapKey := &qubeseciov1.QuantumUnwrapKey{}
+	if err := r.Get(ctx, req.NamespacedName, quantumUnwrapKey); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Check if secret already exists - if so, skip reconciliation
+	secretName := quantumUnwrapKey.Spec.SecretName
+	if secretName == "" {
+		secretName = fmt.Sprintf("%s-unwrapped-key", quantumUnwrapKey.Name)
+	}
+	secretKey := quantumUnwrapKey.Spec.SecretKey
+	if secretKey == "" {
+		secretKey = "key"
+	}
+
+	existingSecret := &corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKey{
+		Name:      secretName,
+		Namespace: quantumUnwrapKey.Namespace,
+	}, existingSecret)
+
+	if err == nil {
+		// Update status to reflect existing secret only if not already set
+		if quantumUnwrapKey.Status.Status != "Success" {
+			now := metav1.Now()
+			quantumUnwrapKey.Status.Status = "Success"
+			quantumUnwrapKey.Status.UnwrappedKeyReference = &qubeseciov1.ObjectReference{
+				Name:      secretName,
+				Namespace: quantumUnwrapKey.Namespace,
+			}
+			quantumUnwrapKey.Status.KEKFingerprint = string(existingSecret.Data["kek-fingerprint"])
+			quantumUnwrapKey.Status.KeyFingerprint = fingerprintOf(existingSecret.Data[secretKey])
+			quantumUnwrapKey.Status.LastUpdateTime = &now
+			quantumUnwrapKey.Status.Error = ""
+
+			if err := r.Status().Update(ctx, quantumUnwrapKey); err != nil {
+				log.Error(err, "Failed to update status for existing secret")
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Resolve the wrapped key, from the spec or from a QuantumWrapKey
+	algorithm := quantumUnwrapKey.Spec.Algorithm
+	wrappedKeyHex := quantumUnwrapKey.Spec.WrappedKey
+	if wrappedKeyHex == "" && quantumUnwrapKey.Spec.WrappedKeyRef != nil {
+		ref := quantumUnwrapKey.Spec.WrappedKeyRef
+		namespace, err := localReferenceNamespace(ref, quantumUnwrapKey.Namespace)
+		if err != nil {
+			log.Error(err, "Invalid wrapped key reference")
+			quantumUnwrapKey.Status.Status = "Failed"
+			quantumUnwrapKey.Status.Error = err.Error()
+			_ = r.Status().Update(ctx, quantumUnwrapKey)
+			return ctrl.Result{}, nil
+		}
+		quantumWrapKey := &qubeseciov1.QuantumWrapKey{}
+		if err := r.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, quantumWrapKey); err != nil {
+			log.Error(err, "Failed to get referenced QuantumWrapKey")
+			quantumUnwrapKey.Status.Status = "Failed"
+			quantumUnwrapKey.Status.Error = fmt.Sprintf("Failed to get referenced QuantumWrapKey: %v", err)
+			_ = r.Status().Update(ctx, quantumUnwrapKey)
+			return ctrl.Result{}, err
+		}
+		if quantumWrapKey.Status.Status != "Success" {
+			log.Info("Wrapped key not ready yet, waiting...")
+			quantumUnwrapKey.Status.Status = "Pending"
+			quantumUnwrapKey.Status.Error = "Wrapped key not ready"
+			_ = r.Status().Update(ctx, quantumUnwrapKey)
+			return ctrl.Result{}, fmt.Errorf("wrapped key not ready")
+		}
+		wrappedKeyHex = quantumWrapKey.Status.WrappedKey
+		if algorithm == "" {
+			algorithm = quantumWrapKey.Status.Algorithm
+		}
+	}
+	if wrappedKeyHex == "" {
+		log.Error(nil, "No wrapped key provided")
+		quantumUnwrapKey.Status.Status = "Failed"
+		quantumUnwrapKey.Status.Error = "Either wrappedKey or wrappedKeyRef must be set"
+		_ = r.Status().Update(ctx, quantumUnwrapKey)
+		return ctrl.Result{}, nil
+	}
+	if algorithm == "" {
+		algorithm = keywrap.KWP
+	}
+
+	wrappedKey, err := hex.DecodeString(wrappedKeyHex)
+	if err != nil {
+		log.Error(err, "Failed to decode wrapped key")
+		quantumUnwrapKey.Status.Status = "Failed"
+		quantumUnwrapKey.Status.Error = fmt.Sprintf("Failed to decode wrapped key: %v", err)
+		_ = r.Status().Update(ctx, quantumUnwrapKey)
+		return ctrl.Result{}, nil
+	}
+
+	// Get the key-encryption key
+	kek, err := getKeyEncryptionKey(r.Client, quantumUnwrapKey.Spec.KEKRef, quantumUnwrapKey.Namespace, ctx)
+	if err != nil {
+		log.Error(err, "Failed to get key-encryption key")
+		quantumUnwrapKey.Status.Status = "Failed"
+		if errors.Is(err, errKEKNotReady) {
+			quantumUnwrapKey.Status.Status = "Pending"
+		}
+		quantumUnwrapKey.Status.Error = err.Error()
+		_ = r.Status().Update(ctx, quantumUnwrapKey)
+		return ctrl.Result{}, err
+	}
+
+	// Unwrap the key. A failed integrity check usually means a different KEK.
+	key, err := keywrap.Unwrap(algorithm, kek, wrappedKey)
+	if err != nil {
+		log.Error(err, "Failed to unwrap key")
+		quantumUnwrapKey.Status.Status = "Failed"
+		quantumUnwrapKey.Status.Error = fmt.Sprintf("Failed to unwrap key: %v", err)
+		_ = r.Status().Update(ctx, quantumUnwrapKey)
+		return ctrl.Result{}, nil
+	}
+
+	kekFingerprint := fingerprintOf(kek)
+
+	// Create secret with the unwrapped key
+	unwrappedSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: quantumUnwrapKey.Namespace,
+		},
+		Data: map[string][]byte{
+			secretKey:         key,
+			"kek-fingerprint": []byte(kekFingerprint),
+		},
+	}
+
+	// Set owner reference
+	if err := ctrl.SetControllerReference(quantumUnwrapKey, unwrappedSecret, r.Scheme); err != nil {
+		log.Error(err, "Failed to set owner reference")
+		return ctrl.Result{}, err
+	}
+
+	// Create secret
+	if err := r.Create(ctx, unwrappedSecret); err != nil {
+		log.Error(err, "Failed to create secret")
+		quantumUnwrapKey.Status.Status = "Failed"
+		quantumUnwrapKey.Status.Error = fmt.Sprintf("Failed to create secret: %v", err)
+		_ = r.Status().Update(ctx, quantumUnwrapKey)
+		return ctrl.Result{}, err
+	}
+
+	// Update status
+	now := metav1.Now()
+	quantumUnwrapKey.Status.Status = "Success"
+	quantumUnwrapKey.Status.UnwrappedKeyReference = &qubeseciov1.ObjectReference{
+		Name:      secretName,
+		Namespace: quantumUnwrapKey.Namespace,
+	}
+	quantumUnwrapKey.Status.KEKFingerprint = kekFingerprint
+	quantumUnwrapKey.Status.KeyFingerprint = fingerprintOf(key)
+	quantumUnwrapKey.Status.LastUpdateTime = &now
+	quantumUnwrapKey.Status.Error = ""
+
+	if err := r.Status().Update(ctx, quantumUnwrapKey); err != nil {
+		log.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Successfully unwrapped key", "secret", secretName, "algorithm", algorithm, "kek", kekFingerprint)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *QuantumUnwrapKeyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&qubeseciov1.QuantumUnwrapKey{}).
+		Owns(&corev1.Secret{}). // Watch Secret objects owned by QuantumUnwrapKey
+		Named("quantumunwrapkey").
+		Complete(r)
+}
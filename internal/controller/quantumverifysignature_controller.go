@@ -18,6 +18,8 @@ package controller
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
@@ -28,6 +30,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/certificate"
 	"github.com/QubeSec/QubeSec/internal/signature"
 )
 
@@ -80,52 +83,16 @@ func (r *QuantumVerifySignatureReconciler) Reconcile(ctx context.Context, req ct
 		return ctrl.Result{}, nil
 	}
 
-	// Get the public key from the referenced QuantumSignatureKeyPair
-	pkNamespace := quantumVerifySignature.Spec.PublicKeyRef.Namespace
-	if pkNamespace == "" {
-		pkNamespace = quantumVerifySignature.Namespace
-	}
-
-	sigKeyPair := &qubeseciov1.QuantumSignatureKeyPair{}
-	if err := r.Get(ctx, client.ObjectKey{
-		Name:      quantumVerifySignature.Spec.PublicKeyRef.Name,
-		Namespace: pkNamespace,
-	}, sigKeyPair); err != nil {
-		log.Error(err, "Failed to get referenced QuantumSignatureKeyPair")
-		quantumVerifySignature.Status.Status = "Failed"
-		quantumVerifySignature.Status.Error = fmt.Sprintf("Failed to get referenced QuantumSignatureKeyPair: %v", err)
-		_ = r.updateStatus(ctx, quantumVerifySignature)
-		return ctrl.Result{}, err
-	}
-
-	// Get the secret containing the keys
-	keySecretName := sigKeyPair.Spec.SecretName
-	if keySecretName == "" {
-		keySecretName = sigKeyPair.Name
-	}
-
-	keySecret := &corev1.Secret{}
-	if err := r.Get(ctx, client.ObjectKey{
-		Name:      keySecretName,
-		Namespace: pkNamespace,
-	}, keySecret); err != nil {
-		log.Error(err, "Failed to get key pair secret")
+	// Get the public key from the referenced QuantumSignatureKeyPair or certificate
+	algorithm, publicKeyPEM, err := r.getPublicKey(quantumVerifySignature, ctx)
+	if err != nil {
+		log.Error(err, "Failed to get public key")
 		quantumVerifySignature.Status.Status = "Failed"
-		quantumVerifySignature.Status.Error = fmt.Sprintf("Failed to get key pair secret: %v", err)
+		quantumVerifySignature.Status.Error = err.Error()
 		_ = r.updateStatus(ctx, quantumVerifySignature)
 		return ctrl.Result{}, err
 	}
 
-	// Extract public key from secret
-	publicKeyPEM, ok := keySecret.Data["public-key"]
-	if !ok {
-		log.Error(nil, "Public key not found in secret")
-		quantumVerifySignature.Status.Status = "Failed"
-		quantumVerifySignature.Status.Error = "Public key not found in secret"
-		_ = r.updateStatus(ctx, quantumVerifySignature)
-		return ctrl.Result{}, fmt.Errorf("public key not found in secret")
-	}
-
 	// Get the message from the referenced secret
 	msgNamespace := quantumVerifySignature.Spec.MessageRef.Namespace
 	if msgNamespace == "" {
@@ -192,17 +159,28 @@ func (r *QuantumVerifySignatureReconciler) Reconcile(ctx context.Context, req ct
 		return ctrl.Result{}, fmt.Errorf("signature key '%s' not found in secret", signatureKey)
 	}
 
+	// Re-check crypto operations with a second provider in high-assurance namespaces
+	cryptoCtx, err := withCrossCheck(ctx, r.Client, quantumVerifySignature.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to get cross-check settings")
+		quantumVerifySignature.Status.Status = "Failed"
+		quantumVerifySignature.Status.Error = fmt.Sprintf("Failed to get cross-check settings: %v", err)
+		_ = r.updateStatus(ctx, quantumVerifySignature)
+		return ctrl.Result{}, err
+	}
+
 	// Verify the signature
 	valid, err := signature.VerifySignature(
-		quantumVerifySignature.Spec.Algorithm,
+		quantumVerifySignature.Spec.CryptoProvider,
+		algorithm,
 		publicKeyPEM,
 		messageBytes,
 		signatureBytes,
-		ctx,
+		cryptoCtx,
 	)
 	if err != nil {
 		log.Error(err, "Failed to verify signature")
-		quantumVerifySignature.Status.Status = "Failed"
+		quantumVerifySignature.Status.Status = cryptoFailureStatus(err)
 		quantumVerifySignature.Status.Error = fmt.Sprintf("Failed to verify signature: %v", err)
 		_ = r.updateStatus(ctx, quantumVerifySignature)
 		return ctrl.Result{}, err
@@ -245,6 +223,73 @@ func (r *QuantumVerifySignatureReconciler) Reconcile(ctx context.Context, req ct
 	return ctrl.Result{}, nil
 }
 
+// getPublicKey returns the algorithm and PEM public key to verify with, from
+// either the referenced QuantumSignatureKeyPair or the referenced certificate
+func (r *QuantumVerifySignatureReconciler) getPublicKey(qvs *qubeseciov1.QuantumVerifySignature, ctx context.Context) (string, []byte, error) {
+	spec := qvs.Spec
+	if (spec.PublicKeyRef.Name == "") == (spec.CertificateRef == nil) {
+		return "", nil, fmt.Errorf("exactly one of publicKeyRef and certificateRef must be set")
+	}
+
+	if ref := spec.CertificateRef; ref != nil {
+		certificateSecret := &corev1.Secret{}
+		if err := r.Get(ctx, client.ObjectKey{
+			Name:      ref.Name,
+			Namespace: referenceNamespace(ref, qvs.Namespace),
+		}, certificateSecret); err != nil {
+			return "", nil, fmt.Errorf("failed to get certificate secret: %w", err)
+		}
+
+		certificateKey := spec.CertificateKey
+		if certificateKey == "" {
+			certificateKey = "tls.crt"
+		}
+		certificates := decodeCertificates(certificateSecret.Data[certificateKey])
+		if len(certificates) == 0 {
+			return "", nil, fmt.Errorf("certificate key '%s' not found in secret", certificateKey)
+		}
+		cert, err := x509.ParseCertificate(certificates[0])
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		publicKey, err := certificate.ParsePublicKey(cert.RawSubjectPublicKeyInfo)
+		if err != nil {
+			return "", nil, err
+		}
+
+		// The signature package reads the SubjectPublicKeyInfo as a PEM key
+		return publicKey.Algorithm, pem.EncodeToMemory(&pem.Block{
+			Type:  "PUBLIC KEY",
+			Bytes: cert.RawSubjectPublicKeyInfo,
+		}), nil
+	}
+
+	pkNamespace := referenceNamespace(&spec.PublicKeyRef, qvs.Namespace)
+	sigKeyPair := &qubeseciov1.QuantumSignatureKeyPair{}
+	if err := r.Get(ctx, client.ObjectKey{
+		Name:      spec.PublicKeyRef.Name,
+		Namespace: pkNamespace,
+	}, sigKeyPair); err != nil {
+		return "", nil, fmt.Errorf("failed to get referenced QuantumSignatureKeyPair: %w", err)
+	}
+
+	// Get the secret containing the keys
+	keySecret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{
+		Name:      signatureKeyPairSecretName(sigKeyPair),
+		Namespace: pkNamespace,
+	}, keySecret); err != nil {
+		return "", nil, fmt.Errorf("failed to get key pair secret: %w", err)
+	}
+
+	// Extract public key from secret
+	publicKeyPEM, ok := keySecret.Data["public-key"]
+	if !ok {
+		return "", nil, fmt.Errorf("public key not found in secret")
+	}
+	return spec.Algorithm, publicKeyPEM, nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *QuantumVerifySignatureReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
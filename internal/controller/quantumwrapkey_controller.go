@@ -0,0 +1,266 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+	"github.com/QubeSec/QubeSec/internal/keywrap"
+)
+
+// errKEKNotReady is returned while the key-encryption key is still being derived
+var errKEKNotReady = errors.New("key-encryption key not ready")
+
+// QuantumWrapKeyReconciler reconciles a QuantumWrapKey object
+type QuantumWrapKeyReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumwrapkeys,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumwrapkeys/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumwrapkeys/finalizers,verbs=update
+// +kubebuilder:rbac:groups=qubesec.io,resources=quantumderivedkeys,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *QuantumWrapKeyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	// Fetch the QuantumWrapKey resource
+	quantumWrapKey := &qubeseciov1.QuantumWrapKey{}
+	if err := r.Get(ctx, req.NamespacedName, quantumWrapKey); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Check if secret already exists - if so, skip reconciliation
+	secretName := quantumWrapKey.Spec.SecretName
+	if secretName == "" {
+		secretName = fmt.Sprintf("%s-wrapped-key", quantumWrapKey.Name)
+	}
+
+	existingSecret := &corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKey{
+		Name:      secretName,
+		Namespace: quantumWrapKey.Namespace,
+	}, existingSecret)
+
+	if err == nil {
+		// Update status to reflect existing secret only if not already set
+		if quantumWrapKey.Status.Status != "Success" {
+			now := metav1.Now()
+			quantumWrapKey.Status.Status = "Success"
+			quantumWrapKey.Status.WrappedKeyReference = &qubeseciov1.ObjectReference{
+				Name:      secretName,
+				Namespace: quantumWrapKey.Namespace,
+			}
+			quantumWrapKey.Status.WrappedKey = hex.EncodeToString(existingSecret.Data["wrapped-key"])
+			quantumWrapKey.Status.Algorithm = string(existingSecret.Data["algorithm"])
+			quantumWrapKey.Status.KEKFingerprint = string(existingSecret.Data["kek-fingerprint"])
+			quantumWrapKey.Status.KeyFingerprint = string(existingSecret.Data["key-fingerprint"])
+			quantumWrapKey.Status.LastUpdateTime = &now
+			quantumWrapKey.Status.Error = ""
+
+			if err := r.Status().Update(ctx, quantumWrapKey); err != nil {
+				log.Error(err, "Failed to update status for existing secret")
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Get the key-encryption key
+	kek, err := getKeyEncryptionKey(r.Client, quantumWrapKey.Spec.KEKRef, quantumWrapKey.Namespace, ctx)
+	if err != nil {
+		log.Error(err, "Failed to get key-encryption key")
+		quantumWrapKey.Status.Status = "Failed"
+		if errors.Is(err, errKEKNotReady) {
+			quantumWrapKey.Status.Status = "Pending"
+		}
+		quantumWrapKey.Status.Error = err.Error()
+		_ = r.Status().Update(ctx, quantumWrapKey)
+		return ctrl.Result{}, err
+	}
+
+	// Get the key to wrap, which must be in the namespace of the QuantumWrapKey
+	keyNamespace, err := localReferenceNamespace(&quantumWrapKey.Spec.KeySecretRef, quantumWrapKey.Namespace)
+	if err != nil {
+		log.Error(err, "Invalid key secret reference")
+		quantumWrapKey.Status.Status = "Failed"
+		quantumWrapKey.Status.Error = err.Error()
+		_ = r.Status().Update(ctx, quantumWrapKey)
+		return ctrl.Result{}, nil
+	}
+	keySecretKey := quantumWrapKey.Spec.KeySecretKey
+	if keySecretKey == "" {
+		keySecretKey = "derived-key"
+	}
+
+	keySecret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{
+		Name:      quantumWrapKey.Spec.KeySecretRef.Name,
+		Namespace: keyNamespace,
+	}, keySecret); err != nil {
+		log.Error(err, "Failed to get key secret")
+		quantumWrapKey.Status.Status = "Failed"
+		quantumWrapKey.Status.Error = fmt.Sprintf("Failed to get key secret: %v", err)
+		_ = r.Status().Update(ctx, quantumWrapKey)
+		return ctrl.Result{}, err
+	}
+	key, ok := keySecret.Data[keySecretKey]
+	if !ok {
+		log.Error(nil, "Key not found in secret")
+		quantumWrapKey.Status.Status = "Failed"
+		quantumWrapKey.Status.Error = fmt.Sprintf("Key %q not found in secret %s", keySecretKey, quantumWrapKey.Spec.KeySecretRef.Name)
+		_ = r.Status().Update(ctx, quantumWrapKey)
+		return ctrl.Result{}, fmt.Errorf("key not found in secret")
+	}
+
+	// Wrap the key
+	algorithm := quantumWrapKey.Spec.Algorithm
+	if algorithm == "" {
+		algorithm = keywrap.KWP
+	}
+	wrappedKey, err := keywrap.Wrap(algorithm, kek, key)
+	if err != nil {
+		log.Error(err, "Failed to wrap key")
+		quantumWrapKey.Status.Status = "Failed"
+		quantumWrapKey.Status.Error = fmt.Sprintf("Failed to wrap key: %v", err)
+		_ = r.Status().Update(ctx, quantumWrapKey)
+		return ctrl.Result{}, nil
+	}
+
+	kekFingerprint := fingerprintOf(kek)
+	keyFingerprint := fingerprintOf(key)
+
+	// Create secret with the wrapped key
+	wrappedSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: quantumWrapKey.Namespace,
+		},
+		Data: map[string][]byte{
+			"wrapped-key":     wrappedKey,
+			"algorithm":       []byte(algorithm),
+			"kek-fingerprint": []byte(kekFingerprint),
+			"key-fingerprint": []byte(keyFingerprint),
+		},
+	}
+
+	// Set owner reference
+	if err := ctrl.SetControllerReference(quantumWrapKey, wrappedSecret, r.Scheme); err != nil {
+		log.Error(err, "Failed to set owner reference")
+		return ctrl.Result{}, err
+	}
+
+	// Create secret
+	if err := r.Create(ctx, wrappedSecret); err != nil {
+		log.Error(err, "Failed to create secret")
+		quantumWrapKey.Status.Status = "Failed"
+		quantumWrapKey.Status.Error = fmt.Sprintf("Failed to create secret: %v", err)
+		_ = r.Status().Update(ctx, quantumWrapKey)
+		return ctrl.Result{}, err
+	}
+
+	// Update status
+	now := metav1.Now()
+	quantumWrapKey.Status.Status = "Success"
+	quantumWrapKey.Status.WrappedKeyReference = &qubeseciov1.ObjectReference{
+		Name:      secretName,
+		Namespace: quantumWrapKey.Namespace,
+	}
+	quantumWrapKey.Status.WrappedKey = hex.EncodeToString(wrappedKey)
+	quantumWrapKey.Status.Algorithm = algorithm
+	quantumWrapKey.Status.KEKFingerprint = kekFingerprint
+	quantumWrapKey.Status.KeyFingerprint = keyFingerprint
+	quantumWrapKey.Status.LastUpdateTime = &now
+	quantumWrapKey.Status.Error = ""
+
+	if err := r.Status().Update(ctx, quantumWrapKey); err != nil {
+		log.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Successfully wrapped key", "secret", secretName, "algorithm", algorithm, "kek", kekFingerprint)
+	return ctrl.Result{}, nil
+}
+
+// getKeyEncryptionKey reads the derived key of an AES-256 QuantumDerivedKey in
+// namespace
+func getKeyEncryptionKey(c client.Client, ref qubeseciov1.ObjectReference, namespace string, ctx context.Context) ([]byte, error) {
+	namespace, err := localReferenceNamespace(&ref, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	quantumDerivedKey := &qubeseciov1.QuantumDerivedKey{}
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, quantumDerivedKey); err != nil {
+		return nil, fmt.Errorf("failed to get referenced QuantumDerivedKey: %w", err)
+	}
+
+	// Only AES keys may be used as key-encryption keys
+	if quantumDerivedKey.Spec.KeyType != "AES-256" {
+		return nil, fmt.Errorf("QuantumDerivedKey %s has key type %s, the KEK must be AES-256", ref.Name, quantumDerivedKey.Spec.KeyType)
+	}
+	if quantumDerivedKey.Status.Status != "Success" || quantumDerivedKey.Status.DerivedKeyReference == nil {
+		return nil, fmt.Errorf("QuantumDerivedKey %s: %w", ref.Name, errKEKNotReady)
+	}
+
+	secretRef := quantumDerivedKey.Status.DerivedKeyReference
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Name: secretRef.Name, Namespace: secretRef.Namespace}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get key-encryption key secret: %w", err)
+	}
+	kek, ok := secret.Data["derived-key"]
+	if !ok {
+		return nil, fmt.Errorf("derived key not found in secret %s", secretRef.Name)
+	}
+
+	return kek, nil
+}
+
+// fingerprintOf returns the first 10 hex characters of the SHA256 hash of key
+func fingerprintOf(key []byte) string {
+	hash := sha256.Sum256(key)
+	return hex.EncodeToString(hash[:])[:10]
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *QuantumWrapKeyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&qubeseciov1.QuantumWrapKey{}).
+		Owns(&corev1.Secret{}). // Watch Secret objects owned by QuantumWrapKey
+		Named("quantumwrapkey").
+		Complete(r)
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	qubeseciov1 "github.com/QubeSec/QubeSec/api/v1"
+)
+
+// referenceNamespace returns the namespace of ref, defaulting to namespace
+func referenceNamespace(ref *qubeseciov1.ObjectReference, namespace string) string {
+	if ref.Namespace != "" {
+		return ref.Namespace
+	}
+	return namespace
+}
+
+// localReferenceNamespace returns the namespace of a reference that must stay
+// in namespace. The operator can read Secrets in every namespace, so following
+// such a reference elsewhere would hand another namespace's data to the
+// resource's owner.
+func localReferenceNamespace(ref *qubeseciov1.ObjectReference, namespace string) (string, error) {
+	if ref.Namespace != "" && ref.Namespace != namespace {
+		return "", fmt.Errorf("reference to %s/%s must be in namespace %s", ref.Namespace, ref.Name, namespace)
+	}
+	return namespace, nil
+}
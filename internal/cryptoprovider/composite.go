@@ -0,0 +1,198 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cryptoprovider
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"fmt"
+	"io"
+
+	"github.com/cloudflare/circl/sign"
+)
+
+// compositePrefix starts every message signed by a composite algorithm
+var compositePrefix = []byte("CompositeAlgorithmSignatures2025")
+
+// composite is an ML-DSA + ECDSA composite signature algorithm following
+// draft-ietf-lamps-pq-composite-sigs. A signature is only valid if both
+// component signatures are.
+//
+// Keys and signatures concatenate the components, ML-DSA first: the public
+// key is the ML-DSA public key followed by the uncompressed EC point, the
+// private key is the expanded ML-DSA private key followed by the DER
+// ECPrivateKey, and the signature is the ML-DSA signature followed by the DER
+// ECDSA signature.
+type composite struct {
+	mldsa string
+	curve elliptic.Curve
+	// hash is the ECDSA hash, preHash hashes the message for both components
+	hash    crypto.Hash
+	preHash crypto.Hash
+	// label separates the composite algorithms and is the ML-DSA context
+	label string
+}
+
+var composites = map[string]composite{
+	"ML-DSA-44-ECDSA-P256-SHA256": {mldsa: "ML-DSA-44", curve: elliptic.P256(), hash: crypto.SHA256, preHash: crypto.SHA256, label: "COMPSIG-MLDSA44-ECDSA-P256-SHA256"},
+	"ML-DSA-65-ECDSA-P256-SHA512": {mldsa: "ML-DSA-65", curve: elliptic.P256(), hash: crypto.SHA256, preHash: crypto.SHA512, label: "COMPSIG-MLDSA65-ECDSA-P256-SHA512"},
+	"ML-DSA-65-ECDSA-P384-SHA512": {mldsa: "ML-DSA-65", curve: elliptic.P384(), hash: crypto.SHA384, preHash: crypto.SHA512, label: "COMPSIG-MLDSA65-ECDSA-P384-SHA512"},
+	"ML-DSA-87-ECDSA-P384-SHA512": {mldsa: "ML-DSA-87", curve: elliptic.P384(), hash: crypto.SHA384, preHash: crypto.SHA512, label: "COMPSIG-MLDSA87-ECDSA-P384-SHA512"},
+}
+
+// compositeAlgorithm returns the composite algorithm with the given name
+func compositeAlgorithm(algorithm string) (composite, bool) {
+	c, ok := composites[algorithm]
+	return c, ok
+}
+
+// message returns the message both components sign:
+// prefix || label || len(ctx) || ctx || PH(M), with an empty context
+func (c composite) message(message []byte) []byte {
+	h := c.preHash.New()
+	h.Write(message)
+
+	out := make([]byte, 0, len(compositePrefix)+len(c.label)+1+c.preHash.Size())
+	out = append(out, compositePrefix...)
+	out = append(out, c.label...)
+	out = append(out, 0)
+	return h.Sum(out)
+}
+
+// digest hashes the composite message for the ECDSA component
+func (c composite) digest(message []byte) []byte {
+	h := c.hash.New()
+	h.Write(message)
+	return h.Sum(nil)
+}
+
+func (c composite) generateKeyPair(rand io.Reader) ([]byte, []byte, error) {
+	scheme := mldsaScheme(c.mldsa)
+
+	seed, err := readSeed(rand, scheme.SeedSize())
+	if err != nil {
+		return nil, nil, err
+	}
+	pk, sk := scheme.DeriveKey(seed)
+	mldsaPublicKey, err := pk.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+	mldsaPrivateKey, err := sk.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if rand == nil {
+		rand = crand.Reader
+	}
+	ecKey, err := ecdsa.GenerateKey(c.curve, rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	ecPublicKey, err := ecKey.PublicKey.Bytes()
+	if err != nil {
+		return nil, nil, err
+	}
+	ecPrivateKey, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return append(mldsaPublicKey, ecPublicKey...), append(mldsaPrivateKey, ecPrivateKey...), nil
+}
+
+// publicKey derives the composite public key from a composite private key
+func (c composite) publicKey(privateKey []byte) ([]byte, error) {
+	scheme := mldsaScheme(c.mldsa)
+	if len(privateKey) <= scheme.PrivateKeySize() {
+		return nil, fmt.Errorf("%s private key is too short", c.label)
+	}
+
+	mldsaPublicKey, err := mldsaPublicKey(c.mldsa, privateKey[:scheme.PrivateKeySize()])
+	if err != nil {
+		return nil, err
+	}
+	ecKey, err := x509.ParseECPrivateKey(privateKey[scheme.PrivateKeySize():])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ECDSA component of %s private key: %w", c.label, err)
+	}
+	ecPublicKey, err := ecKey.PublicKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return append(mldsaPublicKey, ecPublicKey...), nil
+}
+
+// sign signs with both components. ECDSA signatures are deterministic
+// (RFC 6979), so the composite signature is deterministic as well.
+func (c composite) sign(privateKey []byte, message []byte) ([]byte, error) {
+	scheme := mldsaScheme(c.mldsa)
+	if len(privateKey) <= scheme.PrivateKeySize() {
+		return nil, fmt.Errorf("%s private key is too short", c.label)
+	}
+
+	sk, err := scheme.UnmarshalBinaryPrivateKey(privateKey[:scheme.PrivateKeySize()])
+	if err != nil {
+		return nil, err
+	}
+	ecKey, err := x509.ParseECPrivateKey(privateKey[scheme.PrivateKeySize():])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ECDSA component of %s private key: %w", c.label, err)
+	}
+	if ecKey.Curve != c.curve {
+		return nil, fmt.Errorf("ECDSA component of %s private key uses %s", c.label, ecKey.Curve.Params().Name)
+	}
+
+	m := c.message(message)
+	signature := scheme.Sign(sk, m, &sign.SignatureOpts{Context: c.label})
+	ecSignature, err := ecKey.Sign(nil, c.digest(m), c.hash)
+	if err != nil {
+		return nil, err
+	}
+	return append(signature, ecSignature...), nil
+}
+
+func (c composite) verify(publicKey []byte, message []byte, signature []byte) (bool, error) {
+	scheme := mldsaScheme(c.mldsa)
+	if len(publicKey) <= scheme.PublicKeySize() {
+		return false, fmt.Errorf("%s public key is too short", c.label)
+	}
+
+	pk, err := scheme.UnmarshalBinaryPublicKey(publicKey[:scheme.PublicKeySize()])
+	if err != nil {
+		return false, err
+	}
+	ecKey, err := ecdsa.ParseUncompressedPublicKey(c.curve, publicKey[scheme.PublicKeySize():])
+	if err != nil {
+		return false, fmt.Errorf("failed to parse ECDSA component of %s public key: %w", c.label, err)
+	}
+	if len(signature) <= scheme.SignatureSize() {
+		return false, nil
+	}
+
+	m := c.message(message)
+	if !scheme.Verify(pk, m, signature[:scheme.SignatureSize()], &sign.SignatureOpts{Context: c.label}) {
+		return false, nil
+	}
+	return ecdsa.VerifyASN1(ecKey, c.digest(m), signature[scheme.SignatureSize():]), nil
+}
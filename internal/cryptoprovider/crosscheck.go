@@ -0,0 +1,350 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cryptoprovider
+
+import (
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// crossCheckMessage is signed during key generation to prove that both
+// implementations agree on a fresh signature key pair.
+var crossCheckMessage = []byte("qubesec cross-check")
+
+var implementationMismatches = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qubesec_crypto_implementation_mismatch_total",
+		Help: "Number of operations where two crypto providers disagreed",
+	},
+	[]string{"operation", "algorithm", "primary", "secondary"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(implementationMismatches)
+}
+
+// MismatchError reports that the secondary provider did not reproduce the
+// result of the primary provider.
+type MismatchError struct {
+	Operation string
+	Algorithm string
+	Primary   string
+	Secondary string
+	Detail    string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("implementation mismatch during %s with %s: %s and %s disagree: %s",
+		e.Operation, e.Algorithm, e.Primary, e.Secondary, e.Detail)
+}
+
+// ErrCrossCheckUnavailable is returned when a cross-check is requested but
+// cannot be performed. The operation is refused rather than run unchecked.
+var ErrCrossCheckUnavailable = errors.New("cross-check unavailable")
+
+// IsMismatch reports whether err was caused by a cross-check disagreement.
+func IsMismatch(err error) bool {
+	var mismatch *MismatchError
+	return errors.As(err, &mismatch)
+}
+
+type crossCheckKey struct{}
+
+// WithCrossCheck returns a context that asks for every KEM and signature
+// operation to be re-checked with the named provider.
+func WithCrossCheck(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, crossCheckKey{}, name)
+}
+
+// crossCheckFrom returns the cross-check provider requested in ctx, if any.
+func crossCheckFrom(ctx context.Context) string {
+	name, _ := ctx.Value(crossCheckKey{}).(string)
+	return name
+}
+
+// withCrossCheck wraps primary with the cross-check provider requested in ctx.
+func withCrossCheck(ctx context.Context, primary Provider, kind, algorithm string, supports func(Provider) bool) (Provider, error) {
+	name := crossCheckFrom(ctx)
+	if name == "" {
+		return primary, nil
+	}
+
+	secondary, err := resolve(name, kind, algorithm, supports)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCrossCheckUnavailable, err)
+	}
+	if secondary.Name() == primary.Name() {
+		return nil, fmt.Errorf("%w: cross-check provider %q must differ from the primary provider %q", ErrCrossCheckUnavailable, name, primary.Name())
+	}
+	if err := crossCheckable(kind, algorithm, primary, secondary); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCrossCheckUnavailable, err)
+	}
+
+	return &crossChecked{primary: primary, secondary: secondary}, nil
+}
+
+// crossCheckable returns why an algorithm cannot be cross-checked between
+// primary and secondary, or nil if it can. Encapsulations can only be
+// reproduced for ML-KEM, and signatures only when the public key can be
+// derived from the private key.
+func crossCheckable(kind, algorithm string, primary, secondary Provider) error {
+	switch kind {
+	case kindKEM:
+		_, primarySeeded := primary.(seededEncapsulator)
+		_, secondarySeeded := secondary.(seededEncapsulator)
+		if mlkemScheme(algorithm) == nil || !primarySeeded || !secondarySeeded {
+			return fmt.Errorf("%s encapsulations cannot be reproduced by %s and %s", algorithm, primary.Name(), secondary.Name())
+		}
+	case kindSignature:
+		if !derivablePublicKey(algorithm) {
+			return fmt.Errorf("%s signatures cannot be cross-checked: the public key cannot be derived from the private key", algorithm)
+		}
+	}
+	return nil
+}
+
+// crossChecked runs every operation on the primary provider and re-checks the
+// result with the secondary provider.
+type crossChecked struct {
+	primary   Provider
+	secondary Provider
+}
+
+func (c *crossChecked) mismatch(operation, algorithm, format string, args ...any) error {
+	implementationMismatches.WithLabelValues(operation, algorithm, c.primary.Name(), c.secondary.Name()).Inc()
+	return &MismatchError{
+		Operation: operation,
+		Algorithm: algorithm,
+		Primary:   c.primary.Name(),
+		Secondary: c.secondary.Name(),
+		Detail:    fmt.Sprintf(format, args...),
+	}
+}
+
+func (c *crossChecked) Name() string {
+	return c.primary.Name()
+}
+
+func (c *crossChecked) SupportsKEM(algorithm string) bool {
+	return c.primary.SupportsKEM(algorithm) && c.secondary.SupportsKEM(algorithm)
+}
+
+// GenerateKEMKeyPair checks the new key pair by encapsulating with the
+// secondary and decapsulating with both providers.
+func (c *crossChecked) GenerateKEMKeyPair(algorithm string, rand io.Reader) ([]byte, []byte, error) {
+	publicKey, privateKey, err := c.primary.GenerateKEMKeyPair(algorithm, rand)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ciphertext, sharedSecret, err := c.secondary.Encapsulate(algorithm, publicKey)
+	if err != nil {
+		return nil, nil, c.mismatch("keygen", algorithm, "secondary rejected public key: %v", err)
+	}
+	for _, p := range []Provider{c.primary, c.secondary} {
+		recovered, err := p.Decapsulate(algorithm, privateKey, ciphertext)
+		if err != nil {
+			return nil, nil, c.mismatch("keygen", algorithm, "%s failed to decapsulate: %v", p.Name(), err)
+		}
+		if !bytes.Equal(recovered, sharedSecret) {
+			return nil, nil, c.mismatch("keygen", algorithm, "%s recovered a different shared secret", p.Name())
+		}
+	}
+
+	return publicKey, privateKey, nil
+}
+
+// seededEncapsulator is implemented by providers that can encapsulate with
+// caller-supplied randomness instead of their RNG.
+type seededEncapsulator interface {
+	encapsulateSeeded(algorithm string, publicKey []byte, seed []byte) ([]byte, []byte, error)
+}
+
+// Encapsulate draws the encapsulation randomness once and has both providers
+// encapsulate with it. ML-KEM encapsulation is deterministic given that
+// randomness, so the ciphertexts and shared secrets must match byte for byte.
+func (c *crossChecked) Encapsulate(algorithm string, publicKey []byte) ([]byte, []byte, error) {
+	primary, ok := c.primary.(seededEncapsulator)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: crypto provider %q cannot reproduce an encapsulation", ErrCrossCheckUnavailable, c.primary.Name())
+	}
+	secondary, ok := c.secondary.(seededEncapsulator)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: crypto provider %q cannot reproduce an encapsulation", ErrCrossCheckUnavailable, c.secondary.Name())
+	}
+
+	seed := make([]byte, mlkemEncapsulationSeedSize)
+	if _, err := crand.Read(seed); err != nil {
+		return nil, nil, err
+	}
+
+	ciphertext, sharedSecret, err := primary.encapsulateSeeded(algorithm, publicKey, seed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	checkCiphertext, checkSharedSecret, err := secondary.encapsulateSeeded(algorithm, publicKey, seed)
+	if err != nil {
+		return nil, nil, c.mismatch("encapsulate", algorithm, "secondary rejected public key: %v", err)
+	}
+	if !bytes.Equal(checkCiphertext, ciphertext) {
+		return nil, nil, c.mismatch("encapsulate", algorithm, "ciphertexts differ")
+	}
+	if !bytes.Equal(checkSharedSecret, sharedSecret) {
+		return nil, nil, c.mismatch("encapsulate", algorithm, "shared secrets differ")
+	}
+
+	return ciphertext, sharedSecret, nil
+}
+
+func (c *crossChecked) Decapsulate(algorithm string, privateKey []byte, ciphertext []byte) ([]byte, error) {
+	sharedSecret, err := c.primary.Decapsulate(algorithm, privateKey, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	checkSharedSecret, err := c.secondary.Decapsulate(algorithm, privateKey, ciphertext)
+	if err != nil {
+		return nil, c.mismatch("decapsulate", algorithm, "secondary failed to decapsulate: %v", err)
+	}
+	if !bytes.Equal(checkSharedSecret, sharedSecret) {
+		return nil, c.mismatch("decapsulate", algorithm, "recovered shared secrets differ")
+	}
+
+	return sharedSecret, nil
+}
+
+func (c *crossChecked) SupportsSignature(algorithm string) bool {
+	return c.primary.SupportsSignature(algorithm) && c.secondary.SupportsSignature(algorithm)
+}
+
+// GenerateSignatureKeyPair checks the new key pair by signing with each
+// provider and verifying with the other one.
+func (c *crossChecked) GenerateSignatureKeyPair(algorithm string, rand io.Reader) ([]byte, []byte, error) {
+	publicKey, privateKey, err := c.primary.GenerateSignatureKeyPair(algorithm, rand)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pairs := [][2]Provider{{c.primary, c.secondary}, {c.secondary, c.primary}}
+	for _, pair := range pairs {
+		signer, verifier := pair[0], pair[1]
+		sig, err := signer.Sign(algorithm, privateKey, crossCheckMessage)
+		if err != nil {
+			return nil, nil, c.mismatch("keygen", algorithm, "%s failed to sign: %v", signer.Name(), err)
+		}
+		valid, err := verifier.Verify(algorithm, publicKey, crossCheckMessage, sig)
+		if err != nil || !valid {
+			return nil, nil, c.mismatch("keygen", algorithm, "%s rejected a signature from %s", verifier.Name(), signer.Name())
+		}
+	}
+
+	return publicKey, privateKey, nil
+}
+
+// Sign has each provider sign the message and the other one verify the
+// signature. Signatures may be randomized, so they are verified rather than
+// compared.
+func (c *crossChecked) Sign(algorithm string, privateKey []byte, message []byte) ([]byte, error) {
+	sig, err := c.primary.Sign(algorithm, privateKey, message)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := signaturePublicKey(algorithm, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := c.secondary.Verify(algorithm, publicKey, message, sig)
+	if err != nil || !valid {
+		return nil, c.mismatch("sign", algorithm, "secondary rejected the signature")
+	}
+
+	checkSig, err := c.secondary.Sign(algorithm, privateKey, message)
+	if err != nil {
+		return nil, c.mismatch("sign", algorithm, "secondary failed to sign: %v", err)
+	}
+	valid, err = c.primary.Verify(algorithm, publicKey, message, checkSig)
+	if err != nil || !valid {
+		return nil, c.mismatch("sign", algorithm, "primary rejected the secondary's signature")
+	}
+
+	return sig, nil
+}
+
+func (c *crossChecked) Verify(algorithm string, publicKey []byte, message []byte, signature []byte) (bool, error) {
+	valid, err := c.primary.Verify(algorithm, publicKey, message, signature)
+	if err != nil {
+		return false, err
+	}
+
+	checkValid, err := c.secondary.Verify(algorithm, publicKey, message, signature)
+	if err != nil {
+		return false, c.mismatch("verify", algorithm, "secondary failed to verify: %v", err)
+	}
+	if checkValid != valid {
+		return false, c.mismatch("verify", algorithm, "primary returned %t, secondary returned %t", valid, checkValid)
+	}
+
+	return valid, nil
+}
+
+func (c *crossChecked) SupportsRandom(source string) bool {
+	return c.primary.SupportsRandom(source)
+}
+
+// Random is not cross-checked; two RNGs are expected to disagree.
+func (c *crossChecked) Random(source string) (io.Reader, error) {
+	return c.primary.Random(source)
+}
+
+// derivablePublicKey reports whether signaturePublicKey supports algorithm
+func derivablePublicKey(algorithm string) bool {
+	_, composite := compositeAlgorithm(algorithm)
+	return composite || mldsaScheme(algorithm) != nil ||
+		strings.HasPrefix(algorithm, "SLH-DSA-") || strings.HasPrefix(algorithm, "SPHINCS+-")
+}
+
+// signaturePublicKey derives the public key from a private key, so that the
+// secondary can verify a signature made by the primary.
+func signaturePublicKey(algorithm string, privateKey []byte) ([]byte, error) {
+	if c, ok := compositeAlgorithm(algorithm); ok {
+		return c.publicKey(privateKey)
+	}
+	if mldsaScheme(algorithm) != nil {
+		return mldsaPublicKey(algorithm, privateKey)
+	}
+
+	// SLH-DSA private keys are SK.seed || SK.prf || PK.seed || PK.root and
+	// end with the public key
+	if strings.HasPrefix(algorithm, "SLH-DSA-") || strings.HasPrefix(algorithm, "SPHINCS+-") {
+		if len(privateKey) == 0 || len(privateKey)%2 != 0 {
+			return nil, fmt.Errorf("invalid %s private key size %d", algorithm, len(privateKey))
+		}
+		return privateKey[len(privateKey)/2:], nil
+	}
+
+	return nil, fmt.Errorf("%s signatures cannot be cross-checked: the public key cannot be derived from the private key", algorithm)
+}
@@ -0,0 +1,302 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cryptoprovider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// disagreeingProvider is the Go provider with the results of one operation
+// corrupted
+type disagreeingProvider struct {
+	goProvider
+	corrupt string
+}
+
+func (disagreeingProvider) Name() string {
+	return "disagreeing"
+}
+
+func (p disagreeingProvider) encapsulateSeeded(algorithm string, publicKey []byte, seed []byte) ([]byte, []byte, error) {
+	ciphertext, sharedSecret, err := p.goProvider.encapsulateSeeded(algorithm, publicKey, seed)
+	if p.corrupt == "encapsulate" {
+		sharedSecret = flipFirstBit(sharedSecret)
+	}
+	return ciphertext, sharedSecret, err
+}
+
+func (p disagreeingProvider) Decapsulate(algorithm string, privateKey []byte, ciphertext []byte) ([]byte, error) {
+	sharedSecret, err := p.goProvider.Decapsulate(algorithm, privateKey, ciphertext)
+	if p.corrupt == "decapsulate" {
+		sharedSecret = flipFirstBit(sharedSecret)
+	}
+	return sharedSecret, err
+}
+
+func (p disagreeingProvider) Sign(algorithm string, privateKey []byte, message []byte) ([]byte, error) {
+	signature, err := p.goProvider.Sign(algorithm, privateKey, message)
+	if p.corrupt == "sign" {
+		signature = flipFirstBit(signature)
+	}
+	return signature, err
+}
+
+func (p disagreeingProvider) Verify(algorithm string, publicKey []byte, message []byte, signature []byte) (bool, error) {
+	valid, err := p.goProvider.Verify(algorithm, publicKey, message, signature)
+	if p.corrupt == "verify" {
+		valid = !valid
+	}
+	return valid, err
+}
+
+// opaqueProvider hides every method of the wrapped provider that is not part
+// of the Provider interface
+type opaqueProvider struct {
+	Provider
+}
+
+func (opaqueProvider) Name() string {
+	return "opaque"
+}
+
+func flipFirstBit(b []byte) []byte {
+	b = bytes.Clone(b)
+	if len(b) > 0 {
+		b[0] ^= 1
+	}
+	return b
+}
+
+func TestCrossCheckAgreement(t *testing.T) {
+	c := &crossChecked{primary: goProvider{}, secondary: disagreeingProvider{}}
+
+	publicKey, privateKey, err := c.GenerateKEMKeyPair("ML-KEM-768", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, sharedSecret, err := c.Encapsulate("ML-KEM-768", publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := c.Decapsulate("ML-KEM-768", privateKey, ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recovered, sharedSecret) {
+		t.Error("shared secrets differ")
+	}
+
+	publicKey, privateKey, err = c.GenerateSignatureKeyPair("ML-DSA-65", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := c.Sign("ML-DSA-65", privateKey, []byte("message"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid, err := c.Verify("ML-DSA-65", publicKey, []byte("message"), signature); err != nil || !valid {
+		t.Errorf("Verify = %t, %v", valid, err)
+	}
+}
+
+func TestCrossCheckMismatch(t *testing.T) {
+	kemPublicKey, kemPrivateKey, err := goProvider{}.GenerateKEMKeyPair("ML-KEM-768", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, _, err := goProvider{}.Encapsulate("ML-KEM-768", kemPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signaturePublicKey, signaturePrivateKey, err := goProvider{}.GenerateSignatureKeyPair("ML-DSA-44", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := goProvider{}.Sign("ML-DSA-44", signaturePrivateKey, []byte("message"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		corrupt   string
+		operation string
+		algorithm string
+		run       func(c *crossChecked) error
+	}{
+		{
+			name: "KEM keygen", corrupt: "decapsulate", operation: "keygen", algorithm: "ML-KEM-768",
+			run: func(c *crossChecked) error {
+				_, _, err := c.GenerateKEMKeyPair("ML-KEM-768", nil)
+				return err
+			},
+		},
+		{
+			name: "encapsulate", corrupt: "encapsulate", operation: "encapsulate", algorithm: "ML-KEM-768",
+			run: func(c *crossChecked) error {
+				_, _, err := c.Encapsulate("ML-KEM-768", kemPublicKey)
+				return err
+			},
+		},
+		{
+			name: "decapsulate", corrupt: "decapsulate", operation: "decapsulate", algorithm: "ML-KEM-768",
+			run: func(c *crossChecked) error {
+				_, err := c.Decapsulate("ML-KEM-768", kemPrivateKey, ciphertext)
+				return err
+			},
+		},
+		{
+			name: "signature keygen", corrupt: "sign", operation: "keygen", algorithm: "ML-DSA-44",
+			run: func(c *crossChecked) error {
+				_, _, err := c.GenerateSignatureKeyPair("ML-DSA-44", nil)
+				return err
+			},
+		},
+		{
+			name: "secondary rejects the signature", corrupt: "verify", operation: "sign", algorithm: "ML-DSA-44",
+			run: func(c *crossChecked) error {
+				_, err := c.Sign("ML-DSA-44", signaturePrivateKey, []byte("message"))
+				return err
+			},
+		},
+		{
+			name: "secondary signs differently", corrupt: "sign", operation: "sign", algorithm: "ML-DSA-44",
+			run: func(c *crossChecked) error {
+				_, err := c.Sign("ML-DSA-44", signaturePrivateKey, []byte("message"))
+				return err
+			},
+		},
+		{
+			name: "verify", corrupt: "verify", operation: "verify", algorithm: "ML-DSA-44",
+			run: func(c *crossChecked) error {
+				_, err := c.Verify("ML-DSA-44", signaturePublicKey, []byte("message"), signature)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &crossChecked{primary: goProvider{}, secondary: disagreeingProvider{corrupt: tt.corrupt}}
+			counter := implementationMismatches.WithLabelValues(tt.operation, tt.algorithm, Go, "disagreeing")
+			before := testutil.ToFloat64(counter)
+
+			err := tt.run(c)
+			if !IsMismatch(err) {
+				t.Fatalf("err = %v, want a mismatch", err)
+			}
+			var mismatch *MismatchError
+			if !errors.As(err, &mismatch) {
+				t.Fatal("error is not a MismatchError")
+			}
+			if mismatch.Operation != tt.operation || mismatch.Algorithm != tt.algorithm || mismatch.Primary != Go || mismatch.Secondary != "disagreeing" {
+				t.Errorf("mismatch = %+v", mismatch)
+			}
+			if !strings.Contains(err.Error(), "implementation mismatch during "+tt.operation) {
+				t.Errorf("error = %q", err)
+			}
+			if after := testutil.ToFloat64(counter); after != before+1 {
+				t.Errorf("qubesec_crypto_implementation_mismatch_total = %v, want %v", after, before+1)
+			}
+		})
+	}
+}
+
+// registerForTest registers p for the duration of the test
+func registerForTest(t *testing.T, p Provider) {
+	register(p)
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(providers, p.Name())
+	})
+}
+
+func TestCrossCheckUnavailable(t *testing.T) {
+	registerForTest(t, disagreeingProvider{})
+	registerForTest(t, opaqueProvider{goProvider{}})
+	always := func(Provider) bool { return true }
+	crossCheck := func(name, kind, algorithm string) error {
+		_, err := withCrossCheck(WithCrossCheck(context.Background(), name), goProvider{}, kind, algorithm, always)
+		return err
+	}
+
+	tests := []struct {
+		name string
+		err  func() error
+	}{
+		{
+			name: "same provider",
+			err: func() error {
+				_, err := ForKEM(Go, "ML-KEM-768", WithCrossCheck(context.Background(), Go))
+				return err
+			},
+		},
+		{
+			name: "provider not in this build",
+			err:  func() error { return crossCheck("missing", kindSignature, "ML-DSA-65") },
+		},
+		{
+			name: "KEM other than ML-KEM",
+			err:  func() error { return crossCheck("disagreeing", kindKEM, "Kyber768") },
+		},
+		{
+			name: "secondary cannot reproduce encapsulations",
+			err:  func() error { return crossCheck("opaque", kindKEM, "ML-KEM-768") },
+		},
+		{
+			name: "signature public key cannot be derived",
+			err:  func() error { return crossCheck("disagreeing", kindSignature, "Falcon-512") },
+		},
+		{
+			name: "encapsulation",
+			err: func() error {
+				c := &crossChecked{primary: goProvider{}, secondary: opaqueProvider{goProvider{}}}
+				_, _, err := c.Encapsulate("ML-KEM-768", nil)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err()
+			if !errors.Is(err, ErrCrossCheckUnavailable) {
+				t.Errorf("err = %v, want %v", err, ErrCrossCheckUnavailable)
+			}
+			if IsMismatch(err) {
+				t.Error("unavailable cross-check reported as a mismatch")
+			}
+		})
+	}
+
+	for _, algorithm := range []string{"ML-KEM-768", "ML-DSA-44", "ML-DSA-44-ECDSA-P256-SHA256"} {
+		kind := kindSignature
+		if mlkemScheme(algorithm) != nil {
+			kind = kindKEM
+		}
+		if err := crossCheck("disagreeing", kind, algorithm); err != nil {
+			t.Errorf("%s: %v", algorithm, err)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+//go:build liboqs
+
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cryptoprovider
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/open-quantum-safe/liboqs-go/oqs"
+)
+
+// liboqs has a single process-wide RNG. rngMu serializes every liboqs call
+// that draws randomness, and each call selects the RNG it needs while holding
+// the lock, so no request observes another request's RNG.
+var rngMu sync.Mutex
+
+// liboqsProvider wraps the cgo liboqs-go bindings.
+type liboqsProvider struct{}
+
+func init() {
+	register(liboqsProvider{})
+}
+
+// oqsSource is a random source implemented by liboqs itself.
+type oqsSource string
+
+// Read draws bytes from the liboqs source without leaving it selected for
+// other callers.
+func (s oqsSource) Read(p []byte) (int, error) {
+	err := withRNG(s, func() error {
+		oqs.RandomBytesInPlace(p, len(p))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// withRNG runs fn with the liboqs RNG set to rand. A nil rand selects the
+// system RNG, other readers are installed as a custom liboqs RNG.
+func withRNG(rand io.Reader, fn func() error) error {
+	rngMu.Lock()
+	defer rngMu.Unlock()
+
+	var readErr error
+	switch r := rand.(type) {
+	case nil:
+		if err := oqs.RandomBytesSwitchAlgorithm("system"); err != nil {
+			return err
+		}
+	case oqsSource:
+		if err := oqs.RandomBytesSwitchAlgorithm(string(r)); err != nil {
+			return err
+		}
+	default:
+		err := oqs.RandomBytesCustomAlgorithm(func(buf []byte, n int) {
+			if _, err := io.ReadFull(r, buf[:n]); err != nil && readErr == nil {
+				readErr = err
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+	return readErr
+}
+
+func (liboqsProvider) Name() string {
+	return LibOQS
+}
+
+func (liboqsProvider) SupportsKEM(algorithm string) bool {
+	return oqs.IsKEMEnabled(algorithm)
+}
+
+func (liboqsProvider) GenerateKEMKeyPair(algorithm string, rand io.Reader) ([]byte, []byte, error) {
+	quantumKeys := oqs.KeyEncapsulation{}
+	defer quantumKeys.Clean()
+
+	if err := quantumKeys.Init(algorithm, nil); err != nil {
+		return nil, nil, err
+	}
+
+	var publicKey []byte
+	err := withRNG(rand, func() error {
+		var err error
+		publicKey, err = quantumKeys.GenerateKeyPair()
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return publicKey, quantumKeys.ExportSecretKey(), nil
+}
+
+func (liboqsProvider) Encapsulate(algorithm string, publicKey []byte) ([]byte, []byte, error) {
+	quantumKEM := oqs.KeyEncapsulation{}
+	defer quantumKEM.Clean()
+
+	if err := quantumKEM.Init(algorithm, nil); err != nil {
+		return nil, nil, err
+	}
+
+	var ciphertext, sharedSecret []byte
+	err := withRNG(nil, func() error {
+		var err error
+		ciphertext, sharedSecret, err = quantumKEM.EncapSecret(publicKey)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ciphertext, sharedSecret, nil
+}
+
+// encapsulateSeeded encapsulates with the liboqs RNG replaced by seed, so the
+// encapsulation draws its randomness from seed only.
+func (liboqsProvider) encapsulateSeeded(algorithm string, publicKey []byte, seed []byte) ([]byte, []byte, error) {
+	quantumKEM := oqs.KeyEncapsulation{}
+	defer quantumKEM.Clean()
+
+	if err := quantumKEM.Init(algorithm, nil); err != nil {
+		return nil, nil, err
+	}
+
+	var ciphertext, sharedSecret []byte
+	err := withRNG(bytes.NewReader(seed), func() error {
+		var err error
+		ciphertext, sharedSecret, err = quantumKEM.EncapSecret(publicKey)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ciphertext, sharedSecret, nil
+}
+
+func (liboqsProvider) Decapsulate(algorithm string, privateKey []byte, ciphertext []byte) ([]byte, error) {
+	// liboqs only loads expanded keys, so seed-form keys from the Go provider are expanded first
+	secretKey, err := ExpandMLKEMKey(algorithm, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	quantumKEM := oqs.KeyEncapsulation{}
+	defer quantumKEM.Clean()
+
+	if err := quantumKEM.Init(algorithm, secretKey); err != nil {
+		return nil, err
+	}
+
+	return quantumKEM.DecapSecret(ciphertext)
+}
+
+func (liboqsProvider) SupportsSignature(algorithm string) bool {
+	return oqs.IsSigEnabled(algorithm)
+}
+
+func (liboqsProvider) GenerateSignatureKeyPair(algorithm string, rand io.Reader) ([]byte, []byte, error) {
+	quantumKeys := oqs.Signature{}
+	defer quantumKeys.Clean()
+
+	if err := quantumKeys.Init(algorithm, nil); err != nil {
+		return nil, nil, err
+	}
+
+	var publicKey []byte
+	err := withRNG(rand, func() error {
+		var err error
+		publicKey, err = quantumKeys.GenerateKeyPair()
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return publicKey, quantumKeys.ExportSecretKey(), nil
+}
+
+func (liboqsProvider) Sign(algorithm string, privateKey []byte, message []byte) ([]byte, error) {
+	signer := oqs.Signature{}
+	defer signer.Clean()
+
+	if err := signer.Init(algorithm, privateKey); err != nil {
+		return nil, err
+	}
+
+	var sig []byte
+	err := withRNG(nil, func() error {
+		var err error
+		sig, err = signer.Sign(message)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sig, nil
+}
+
+func (liboqsProvider) Verify(algorithm string, publicKey []byte, message []byte, signature []byte) (bool, error) {
+	verifier := oqs.Signature{}
+	defer verifier.Clean()
+
+	if err := verifier.Init(algorithm, nil); err != nil {
+		return false, err
+	}
+
+	return verifier.Verify(message, signature, publicKey)
+}
+
+func (liboqsProvider) SupportsRandom(source string) bool {
+	return source == "system" || source == "OpenSSL"
+}
+
+func (liboqsProvider) Random(source string) (io.Reader, error) {
+	if err := withRNG(oqsSource(source), func() error { return nil }); err != nil {
+		return nil, err
+	}
+	return oqsSource(source), nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cryptoprovider
+
+import (
+	"fmt"
+
+	"github.com/cloudflare/circl/kem"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem1024"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem512"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem768"
+)
+
+// mlkemSeedSize is the size of the (d, z) seed that FIPS 203 allows to be
+// stored in place of the expanded decapsulation key.
+const mlkemSeedSize = 64
+
+// mlkemEncapsulationSeedSize is the size of the message m that FIPS 203
+// encapsulation draws from the RNG.
+const mlkemEncapsulationSeedSize = 32
+
+// mlkemScheme returns the circl scheme for an ML-KEM parameter set, or nil
+// if the algorithm is not ML-KEM.
+func mlkemScheme(algorithm string) kem.Scheme {
+	switch algorithm {
+	case "ML-KEM-512":
+		return mlkem512.Scheme()
+	case "ML-KEM-768":
+		return mlkem768.Scheme()
+	case "ML-KEM-1024":
+		return mlkem1024.Scheme()
+	}
+	return nil
+}
+
+// ExpandMLKEMKey returns the expanded FIPS 203 decapsulation key for an
+// ML-KEM private key. Keys that are already expanded are returned unchanged.
+func ExpandMLKEMKey(algorithm string, privateKey []byte) ([]byte, error) {
+	scheme := mlkemScheme(algorithm)
+	if scheme == nil || len(privateKey) != mlkemSeedSize {
+		return privateKey, nil
+	}
+
+	_, sk := scheme.DeriveKeyPair(privateKey)
+	expanded, err := sk.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand %s private key: %w", algorithm, err)
+	}
+	return expanded, nil
+}
+
+// MLKEMPrivateKeySizes returns the sizes of the seed and expanded forms of an
+// ML-KEM private key, both of which every provider accepts, or nil if the
+// algorithm is not ML-KEM.
+func MLKEMPrivateKeySizes(algorithm string) []int {
+	scheme := mlkemScheme(algorithm)
+	if scheme == nil {
+		return nil
+	}
+	return []int{mlkemSeedSize, scheme.PrivateKeySize()}
+}
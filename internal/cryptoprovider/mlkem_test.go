@@ -0,0 +1,103 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cryptoprovider
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+// mlkemKeyGenVectors are the first test case of each parameter set of the
+// NIST ACVP ML-KEM-keyGen-FIPS203 vector set (vsId 42). The expected keys are
+// given as SHA-256 digests to keep the file short.
+var mlkemKeyGenVectors = []struct {
+	algorithm string
+	d         string
+	z         string
+	ek        string
+	dk        string
+}{
+	{
+		algorithm: "ML-KEM-512",
+		d:         "2CB843A02EF02EE109305F39119FABF49AB90A57FFECB3A0E75E179450F52761",
+		z:         "84CC9121AE56FBF39E67ADBD83AD2D3E3BB80843645206BDD9F2F629E3CC49B7",
+		ek:        "f96920dc6766df52dca2428e7e751c0c27d537424c0a3f5c89153c2d5d28f898",
+		dk:        "0c48e1338e5329cb7850f0a8cf7bd6ea3b4071158269fc129afd984b8ee14543",
+	},
+	{
+		algorithm: "ML-KEM-768",
+		d:         "E34A701C4C87582F42264EE422D3C684D97611F2523EFE0C998AF05056D693DC",
+		z:         "A85768F3486BD32A01BF9A8F21EA938E648EAE4E5448C34C3EB88820B159EEDD",
+		ek:        "7799c9d8eef172aa78c073514f2f039c240de8c5cb61bca82ba0bc46041ce279",
+		dk:        "104b3444c3de2b81143788d27e17648f45c80f617f906156db2258da96dead40",
+	},
+	{
+		algorithm: "ML-KEM-1024",
+		d:         "49AC8B99BB1E6A8EA818261F8BE68BDEAA52897E7EC6C40B530BC760AB77DCE3",
+		z:         "99E3246884181F8E1DD44E0C7629093330221FD67D9B7D6E1510B2DBAD8762F7",
+		ek:        "62fccf5fdf805b110670b39cd5e25b1811172961ea4047bfbd589e323ce7cfbc",
+		dk:        "2f8af73000bd5247a74312ac70386444290bc4b80da6fae05aeb1196dbd8912e",
+	},
+}
+
+// TestExpandMLKEMKey checks that a 64-byte (d, z) seed expands to the FIPS 203
+// decapsulation key, so that seed keys interoperate with expanded keys from
+// liboqs.
+func TestExpandMLKEMKey(t *testing.T) {
+	for _, tt := range mlkemKeyGenVectors {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			seed, err := hex.DecodeString(tt.d + tt.z)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expanded, err := ExpandMLKEMKey(tt.algorithm, seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sizes := MLKEMPrivateKeySizes(tt.algorithm); len(expanded) != sizes[1] {
+				t.Errorf("expanded key is %d bytes, want %d", len(expanded), sizes[1])
+			}
+			if digest := sha256.Sum256(expanded); hex.EncodeToString(digest[:]) != tt.dk {
+				t.Errorf("SHA-256(dk) = %x, want %s", digest, tt.dk)
+			}
+
+			// The Go provider derives the same encapsulation key from the seed
+			publicKey, privateKey, err := goProvider{}.GenerateKEMKeyPair(tt.algorithm, bytes.NewReader(seed))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if digest := sha256.Sum256(publicKey); hex.EncodeToString(digest[:]) != tt.ek {
+				t.Errorf("SHA-256(ek) = %x, want %s", digest, tt.ek)
+			}
+			if !bytes.Equal(privateKey, seed) {
+				t.Error("private key is not the seed")
+			}
+
+			// Expanded keys are returned unchanged
+			again, err := ExpandMLKEMKey(tt.algorithm, expanded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(again, expanded) {
+				t.Error("expanded key was changed")
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cryptoprovider abstracts the KEM, signature and RNG primitives used
+// by the controllers so that they can be served by liboqs or by pure Go code.
+package cryptoprovider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+const (
+	// Auto picks the first registered provider that supports the requested algorithm.
+	Auto = "auto"
+	// LibOQS is the cgo liboqs-go implementation, only available in builds with the liboqs tag.
+	LibOQS = "liboqs"
+	// Go is the pure-Go implementation backed by crypto/mlkem and circl.
+	Go = "go"
+)
+
+// Provider implements the post-quantum primitives for one crypto library.
+// Keys, ciphertexts and signatures are raw byte encodings as defined by the
+// respective FIPS standard, so that material produced by one provider can be
+// consumed by another.
+type Provider interface {
+	// Name returns the identifier used to select the provider.
+	Name() string
+
+	// SupportsKEM reports whether the KEM algorithm is available.
+	SupportsKEM(algorithm string) bool
+	// GenerateKEMKeyPair returns a new public/private key pair drawn from
+	// rand, or from the provider's system RNG if rand is nil.
+	GenerateKEMKeyPair(algorithm string, rand io.Reader) ([]byte, []byte, error)
+	// Encapsulate returns a ciphertext and shared secret for the public key.
+	Encapsulate(algorithm string, publicKey []byte) ([]byte, []byte, error)
+	// Decapsulate recovers the shared secret from the ciphertext.
+	Decapsulate(algorithm string, privateKey []byte, ciphertext []byte) ([]byte, error)
+
+	// SupportsSignature reports whether the signature algorithm is available.
+	SupportsSignature(algorithm string) bool
+	// GenerateSignatureKeyPair returns a new public/private key pair drawn
+	// from rand, or from the provider's system RNG if rand is nil.
+	GenerateSignatureKeyPair(algorithm string, rand io.Reader) ([]byte, []byte, error)
+	// Sign signs the message with the private key.
+	Sign(algorithm string, privateKey []byte, message []byte) ([]byte, error)
+	// Verify checks the signature over the message with the public key.
+	Verify(algorithm string, publicKey []byte, message []byte, signature []byte) (bool, error)
+
+	// SupportsRandom reports whether the random number source is available.
+	SupportsRandom(source string) bool
+	// Random returns a reader for the named source. Readers are isolated:
+	// using one never changes the RNG seen by other requests.
+	Random(source string) (io.Reader, error)
+}
+
+var (
+	mu              sync.RWMutex
+	providers       = map[string]Provider{}
+	defaultProvider = Auto
+)
+
+// Kinds of primitives, as used in error messages
+const (
+	kindKEM       = "KEM algorithm"
+	kindSignature = "signature algorithm"
+)
+
+// preference is the order in which providers are tried in auto mode.
+var preference = []string{LibOQS, Go}
+
+// register makes a provider available for selection. It is called from the
+// init functions of the implementations.
+func register(p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[p.Name()] = p
+}
+
+// Available returns the names of the providers compiled into this build.
+func Available() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetDefault sets the operator-wide provider used when a resource does not
+// select one. It accepts "auto" or the name of a registered provider.
+func SetDefault(name string) error {
+	if name == "" {
+		name = Auto
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := providers[name]; !ok && name != Auto {
+		return fmt.Errorf("crypto provider %q is not available in this build", name)
+	}
+	defaultProvider = name
+	return nil
+}
+
+// Default returns the operator-wide provider name.
+func Default() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultProvider
+}
+
+// ForKEM resolves the provider to use for a KEM algorithm.
+// An empty name falls back to the operator-wide default. If ctx requests a
+// cross-check, the returned provider re-checks every operation.
+func ForKEM(name, algorithm string, ctx context.Context) (Provider, error) {
+	supports := func(p Provider) bool {
+		return p.SupportsKEM(algorithm)
+	}
+	p, err := resolve(name, kindKEM, algorithm, supports)
+	if err != nil {
+		return nil, err
+	}
+	return withCrossCheck(ctx, p, kindKEM, algorithm, supports)
+}
+
+// ForSignature resolves the provider to use for a signature algorithm.
+// An empty name falls back to the operator-wide default. If ctx requests a
+// cross-check, the returned provider re-checks every operation.
+func ForSignature(name, algorithm string, ctx context.Context) (Provider, error) {
+	supports := func(p Provider) bool {
+		return p.SupportsSignature(algorithm)
+	}
+	p, err := resolve(name, kindSignature, algorithm, supports)
+	if err != nil {
+		return nil, err
+	}
+	return withCrossCheck(ctx, p, kindSignature, algorithm, supports)
+}
+
+// ForRandom resolves the provider to use for a random number source.
+// An empty name falls back to the operator-wide default.
+func ForRandom(name, source string) (Provider, error) {
+	return resolve(name, "random source", source, func(p Provider) bool {
+		return p.SupportsRandom(source)
+	})
+}
+
+// NewRandom returns an isolated reader for a random number source.
+// An empty provider name falls back to the operator-wide default.
+func NewRandom(name, source string) (io.Reader, error) {
+	p, err := ForRandom(name, source)
+	if err != nil {
+		return nil, err
+	}
+	return p.Random(source)
+}
+
+func resolve(name, kind, algorithm string, supports func(Provider) bool) (Provider, error) {
+	if name == "" {
+		name = Default()
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if name == Auto {
+		for _, candidate := range preference {
+			if p, ok := providers[candidate]; ok && supports(p) {
+				return p, nil
+			}
+		}
+		return nil, fmt.Errorf("no crypto provider in this build supports %s %q", kind, algorithm)
+	}
+
+	p, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("crypto provider %q is not available in this build", name)
+	}
+	if !supports(p) {
+		return nil, fmt.Errorf("crypto provider %q does not support %s %q", name, kind, algorithm)
+	}
+	return p, nil
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cryptoprovider
+
+import (
+	"crypto/mlkem"
+	crand "crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/cloudflare/circl/sign"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa44"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa65"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa87"
+)
+
+// goProvider serves the NIST-standard algorithms without cgo. ML-KEM-768 and
+// ML-KEM-1024 use crypto/mlkem, ML-KEM-512 and ML-DSA use circl. It also
+// serves the ML-DSA + ECDSA composite algorithms.
+//
+// ML-KEM private keys are generated in the 64-byte seed form; expanded keys
+// produced by liboqs are accepted as well.
+type goProvider struct{}
+
+func init() {
+	register(goProvider{})
+}
+
+func (goProvider) Name() string {
+	return Go
+}
+
+func (goProvider) SupportsKEM(algorithm string) bool {
+	return mlkemScheme(algorithm) != nil
+}
+
+func (goProvider) GenerateKEMKeyPair(algorithm string, rand io.Reader) ([]byte, []byte, error) {
+	scheme := mlkemScheme(algorithm)
+	if scheme == nil {
+		return nil, nil, fmt.Errorf("unsupported KEM algorithm %q", algorithm)
+	}
+
+	// Keys are derived from a seed so that any RNG can be used
+	seed, err := readSeed(rand, mlkemSeedSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch algorithm {
+	case "ML-KEM-768":
+		dk, err := mlkem.NewDecapsulationKey768(seed)
+		if err != nil {
+			return nil, nil, err
+		}
+		return dk.EncapsulationKey().Bytes(), seed, nil
+	case "ML-KEM-1024":
+		dk, err := mlkem.NewDecapsulationKey1024(seed)
+		if err != nil {
+			return nil, nil, err
+		}
+		return dk.EncapsulationKey().Bytes(), seed, nil
+	}
+
+	pk, _ := scheme.DeriveKeyPair(seed)
+	publicKey, err := pk.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+	return publicKey, seed, nil
+}
+
+func (goProvider) Encapsulate(algorithm string, publicKey []byte) ([]byte, []byte, error) {
+	switch algorithm {
+	case "ML-KEM-768":
+		ek, err := mlkem.NewEncapsulationKey768(publicKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		sharedSecret, ciphertext := ek.Encapsulate()
+		return ciphertext, sharedSecret, nil
+	case "ML-KEM-1024":
+		ek, err := mlkem.NewEncapsulationKey1024(publicKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		sharedSecret, ciphertext := ek.Encapsulate()
+		return ciphertext, sharedSecret, nil
+	}
+
+	scheme := mlkemScheme(algorithm)
+	if scheme == nil {
+		return nil, nil, fmt.Errorf("unsupported KEM algorithm %q", algorithm)
+	}
+
+	pk, err := scheme.UnmarshalBinaryPublicKey(publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return scheme.Encapsulate(pk)
+}
+
+// encapsulateSeeded encapsulates with the message m taken from seed instead
+// of the system RNG. crypto/mlkem does not accept m, so circl serves all
+// parameter sets here.
+func (goProvider) encapsulateSeeded(algorithm string, publicKey []byte, seed []byte) ([]byte, []byte, error) {
+	scheme := mlkemScheme(algorithm)
+	if scheme == nil {
+		return nil, nil, fmt.Errorf("unsupported KEM algorithm %q", algorithm)
+	}
+
+	pk, err := scheme.UnmarshalBinaryPublicKey(publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return scheme.EncapsulateDeterministically(pk, seed)
+}
+
+func (goProvider) Decapsulate(algorithm string, privateKey []byte, ciphertext []byte) ([]byte, error) {
+	if len(privateKey) == mlkemSeedSize {
+		switch algorithm {
+		case "ML-KEM-768":
+			dk, err := mlkem.NewDecapsulationKey768(privateKey)
+			if err != nil {
+				return nil, err
+			}
+			return dk.Decapsulate(ciphertext)
+		case "ML-KEM-1024":
+			dk, err := mlkem.NewDecapsulationKey1024(privateKey)
+			if err != nil {
+				return nil, err
+			}
+			return dk.Decapsulate(ciphertext)
+		}
+	}
+
+	scheme := mlkemScheme(algorithm)
+	if scheme == nil {
+		return nil, fmt.Errorf("unsupported KEM algorithm %q", algorithm)
+	}
+
+	expanded, err := ExpandMLKEMKey(algorithm, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	sk, err := scheme.UnmarshalBinaryPrivateKey(expanded)
+	if err != nil {
+		return nil, err
+	}
+	return scheme.Decapsulate(sk, ciphertext)
+}
+
+// mldsaScheme returns the circl scheme for an ML-DSA parameter set, or nil
+// if the algorithm is not ML-DSA.
+func mldsaScheme(algorithm string) sign.Scheme {
+	switch algorithm {
+	case "ML-DSA-44":
+		return mldsa44.Scheme()
+	case "ML-DSA-65":
+		return mldsa65.Scheme()
+	case "ML-DSA-87":
+		return mldsa87.Scheme()
+	}
+	return nil
+}
+
+// mldsaPublicKey derives the public key from a FIPS 204 ML-DSA private key.
+func mldsaPublicKey(algorithm string, privateKey []byte) ([]byte, error) {
+	scheme := mldsaScheme(algorithm)
+	if scheme == nil {
+		return nil, fmt.Errorf("unsupported signature algorithm %q", algorithm)
+	}
+
+	sk, err := scheme.UnmarshalBinaryPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	pk, ok := sk.Public().(sign.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("failed to derive %s public key", algorithm)
+	}
+	return pk.MarshalBinary()
+}
+
+func (goProvider) SupportsSignature(algorithm string) bool {
+	_, ok := compositeAlgorithm(algorithm)
+	return ok || mldsaScheme(algorithm) != nil
+}
+
+func (goProvider) GenerateSignatureKeyPair(algorithm string, rand io.Reader) ([]byte, []byte, error) {
+	if c, ok := compositeAlgorithm(algorithm); ok {
+		return c.generateKeyPair(rand)
+	}
+
+	scheme := mldsaScheme(algorithm)
+	if scheme == nil {
+		return nil, nil, fmt.Errorf("unsupported signature algorithm %q", algorithm)
+	}
+
+	seed, err := readSeed(rand, scheme.SeedSize())
+	if err != nil {
+		return nil, nil, err
+	}
+	pk, sk := scheme.DeriveKey(seed)
+	publicKey, err := pk.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKey, err := sk.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+	return publicKey, privateKey, nil
+}
+
+func (goProvider) Sign(algorithm string, privateKey []byte, message []byte) ([]byte, error) {
+	if c, ok := compositeAlgorithm(algorithm); ok {
+		return c.sign(privateKey, message)
+	}
+
+	scheme := mldsaScheme(algorithm)
+	if scheme == nil {
+		return nil, fmt.Errorf("unsupported signature algorithm %q", algorithm)
+	}
+
+	sk, err := scheme.UnmarshalBinaryPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return scheme.Sign(sk, message, nil), nil
+}
+
+func (goProvider) Verify(algorithm string, publicKey []byte, message []byte, signature []byte) (bool, error) {
+	if c, ok := compositeAlgorithm(algorithm); ok {
+		return c.verify(publicKey, message, signature)
+	}
+
+	scheme := mldsaScheme(algorithm)
+	if scheme == nil {
+		return false, fmt.Errorf("unsupported signature algorithm %q", algorithm)
+	}
+
+	pk, err := scheme.UnmarshalBinaryPublicKey(publicKey)
+	if err != nil {
+		return false, err
+	}
+	return scheme.Verify(pk, message, signature, nil), nil
+}
+
+func (goProvider) SupportsRandom(source string) bool {
+	return source == "system"
+}
+
+func (goProvider) Random(source string) (io.Reader, error) {
+	if source != "system" {
+		return nil, fmt.Errorf("unsupported random source %q", source)
+	}
+	return crand.Reader, nil
+}
+
+// readSeed reads a key generation seed from rand, or from the system RNG if
+// rand is nil.
+func readSeed(rand io.Reader, size int) ([]byte, error) {
+	if rand == nil {
+		rand = crand.Reader
+	}
+
+	seed := make([]byte, size)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, fmt.Errorf("failed to read key generation seed: %w", err)
+	}
+	return seed, nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cryptoprovider
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestGoProviderKEM(t *testing.T) {
+	for _, algorithm := range []string{"ML-KEM-512", "ML-KEM-768", "ML-KEM-1024"} {
+		t.Run(algorithm, func(t *testing.T) {
+			p, err := ForKEM(Go, algorithm, context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			publicKey, privateKey, err := p.GenerateKEMKeyPair(algorithm, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ciphertext, sharedSecret, err := p.Encapsulate(algorithm, publicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Both the seed and the expanded private key decapsulate
+			expanded, err := ExpandMLKEMKey(algorithm, privateKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, key := range map[string][]byte{"seed": privateKey, "expanded": expanded} {
+				decapsulated, err := p.Decapsulate(algorithm, key, ciphertext)
+				if err != nil {
+					t.Fatalf("%s key: %v", name, err)
+				}
+				if !bytes.Equal(decapsulated, sharedSecret) {
+					t.Errorf("%s key: shared secrets differ", name)
+				}
+			}
+
+			// ML-KEM rejects implicitly: a changed ciphertext gives another secret
+			tampered := bytes.Clone(ciphertext)
+			tampered[0] ^= 1
+			decapsulated, err := p.Decapsulate(algorithm, privateKey, tampered)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(decapsulated, sharedSecret) {
+				t.Error("tampered ciphertext gave the shared secret")
+			}
+		})
+	}
+}
+
+func TestGoProviderSignature(t *testing.T) {
+	message := []byte("message")
+
+	for _, algorithm := range []string{"ML-DSA-44", "ML-DSA-65", "ML-DSA-87"} {
+		t.Run(algorithm, func(t *testing.T) {
+			p, err := ForSignature(Go, algorithm, context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			publicKey, privateKey, err := p.GenerateSignatureKeyPair(algorithm, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signature, err := p.Sign(algorithm, privateKey, message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok, err := p.Verify(algorithm, publicKey, message, signature); err != nil || !ok {
+				t.Fatalf("Verify = %t, %v", ok, err)
+			}
+
+			derived, err := mldsaPublicKey(algorithm, privateKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(derived, publicKey) {
+				t.Error("public key derived from the private key differs")
+			}
+
+			otherPublicKey, _, err := p.GenerateSignatureKeyPair(algorithm, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tampered := bytes.Clone(signature)
+			tampered[0] ^= 1
+			tests := []struct {
+				name      string
+				publicKey []byte
+				message   []byte
+				signature []byte
+			}{
+				{name: "other key", publicKey: otherPublicKey, message: message, signature: signature},
+				{name: "other message", publicKey: publicKey, message: []byte("other"), signature: signature},
+				{name: "tampered signature", publicKey: publicKey, message: message, signature: tampered},
+			}
+			for _, tt := range tests {
+				if ok, _ := p.Verify(algorithm, tt.publicKey, tt.message, tt.signature); ok {
+					t.Errorf("%s: signature verified", tt.name)
+				}
+			}
+		})
+	}
+}
+
+func TestGoProviderUnsupported(t *testing.T) {
+	if _, err := ForKEM(Go, "Kyber768", context.Background()); err == nil {
+		t.Error("unsupported KEM algorithm was resolved")
+	}
+	if _, err := ForSignature(Go, "Dilithium2", context.Background()); err == nil {
+		t.Error("unsupported signature algorithm was resolved")
+	}
+	if _, err := ForKEM("missing", "ML-KEM-768", context.Background()); err == nil {
+		t.Error("missing provider was resolved")
+	}
+}
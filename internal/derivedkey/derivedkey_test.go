@@ -0,0 +1,71 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package derivedkey
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+)
+
+// TestDeriveAES256Key checks the SHA-256 test cases 1 and 3 of RFC 5869
+// Appendix A, truncated to the 32 bytes of an AES-256 key. HKDF output is a
+// prefix of any longer output for the same inputs.
+func TestDeriveAES256Key(t *testing.T) {
+	tests := []struct {
+		name string
+		ikm  string
+		salt string
+		info string
+		okm  string
+	}{
+		{
+			name: "A.1",
+			ikm:  "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b",
+			salt: "000102030405060708090a0b0c",
+			info: "f0f1f2f3f4f5f6f7f8f9",
+			okm:  "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf",
+		},
+		{
+			name: "A.3",
+			ikm:  "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b",
+			okm:  "8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := DeriveAES256Key(mustDecodeHex(t, tt.ikm), mustDecodeHex(t, tt.salt), mustDecodeHex(t, tt.info), context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(key, mustDecodeHex(t, tt.okm)) {
+				t.Errorf("OKM = %x, want %s", key, tt.okm)
+			}
+		})
+	}
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package derivedkey
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// MinMasterSecretSize is the smallest master secret a hierarchy accepts
+	MinMasterSecretSize = 32
+
+	// hierarchySalt separates hierarchy chain keys from other uses of the master
+	hierarchySalt = "qubesec.io/key-hierarchy/v1"
+	// chainSize is the size of the chain key carried down the tree
+	chainSize = 32
+)
+
+// pathLabel restricts path labels to DNS-1123 labels so node paths map to
+// valid Secret names
+var pathLabel = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// ParsePath splits a path such as m/tenant-a/db/enc into its labels.
+func ParsePath(path string) ([]string, error) {
+	labels := strings.Split(path, "/")
+	if labels[0] != "m" {
+		return nil, fmt.Errorf("path %q must start at the master node m", path)
+	}
+	for _, label := range labels[1:] {
+		if !pathLabel.MatchString(label) {
+			return nil, fmt.Errorf("path %q has an invalid label %q", path, label)
+		}
+	}
+	return labels[1:], nil
+}
+
+// DeriveHierarchyKey derives the key of the node at path for keyType.
+//
+// Every node has a chain key. The master chain key is HKDF-Extract of the
+// master secret, and each child's chain key is HKDF of its parent's chain key
+// with the child label as info. A node's key is expanded from its chain key
+// with the key type as info. A node key never reveals the chain key, so
+// leaking one key does not expose its subtree, while the whole tree can be
+// rebuilt from the master secret alone.
+func DeriveHierarchyKey(master []byte, path string, keyType string, ctx context.Context) ([]byte, error) {
+	log := log.FromContext(ctx)
+
+	if len(master) < MinMasterSecretSize {
+		return nil, fmt.Errorf("master secret is %d bytes, must be at least %d bytes", len(master), MinMasterSecretSize)
+	}
+	labels, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := hkdf.Extract(sha256.New, master, []byte(hierarchySalt))
+	for _, label := range labels {
+		child := make([]byte, chainSize)
+		if _, err := io.ReadFull(hkdf.Expand(sha256.New, chain, []byte("chain:"+label)), child); err != nil {
+			log.Error(err, "Failed to derive chain key using HKDF", "label", label)
+			return nil, err
+		}
+		chain = child
+	}
+
+	nodeKey := make([]byte, KeySize)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, chain, []byte("key:"+keyType)), nodeKey); err != nil {
+		log.Error(err, "Failed to derive node key using HKDF", "path", path)
+		return nil, err
+	}
+
+	return nodeKey, nil
+}
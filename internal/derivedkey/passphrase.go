@@ -0,0 +1,181 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package derivedkey
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// Argon2id is the RFC 9106 memory-hard password hash
+	Argon2id = "Argon2id"
+	// Scrypt is the RFC 7914 memory-hard password-based KDF
+	Scrypt = "scrypt"
+
+	// SaltSize is the size of generated salts, and the minimum for supplied ones
+	SaltSize = 16
+	// KeySize is the size of derived keys
+	KeySize = 32
+
+	// maxMemory bounds the memory a single derivation may use. It is half of
+	// the manager's 128Mi memory limit, and derivations run one at a time, so
+	// a costly passphrase KDF cannot get the operator OOM-killed.
+	maxMemory = 64 << 20
+	// maxConcurrentDerivations bounds the derivations that run at once
+	maxConcurrentDerivations = 1
+)
+
+// derivations holds a slot for every derivation in progress
+var derivations = make(chan struct{}, maxConcurrentDerivations)
+
+// PassphraseParameters are the cost parameters of a passphrase KDF. Zero
+// values are replaced by the defaults of the selected algorithm.
+type PassphraseParameters struct {
+	Algorithm string
+
+	// Argon2id iterations, memory in KiB and lanes. The defaults are the
+	// second recommended option of RFC 9106 (t=3, m=64 MiB, p=4).
+	Iterations  uint32
+	MemoryKiB   uint32
+	Parallelism uint8
+
+	// scrypt log2(N), r and p. The defaults (N=2^15, r=8, p=1) use 32 MiB.
+	CostExponent    int
+	BlockSize       int
+	Parallelization int
+}
+
+// WithDefaults returns the parameters with defaults applied and the fields of
+// the other algorithm cleared, as recorded for re-derivation.
+func (p PassphraseParameters) WithDefaults() PassphraseParameters {
+	if p.Algorithm == "" {
+		p.Algorithm = Argon2id
+	}
+
+	switch p.Algorithm {
+	case Argon2id:
+		p.CostExponent, p.BlockSize, p.Parallelization = 0, 0, 0
+		if p.Iterations == 0 {
+			p.Iterations = 3
+		}
+		if p.MemoryKiB == 0 {
+			p.MemoryKiB = 64 * 1024
+		}
+		if p.Parallelism == 0 {
+			p.Parallelism = 4
+		}
+	case Scrypt:
+		p.Iterations, p.MemoryKiB, p.Parallelism = 0, 0, 0
+		if p.CostExponent == 0 {
+			p.CostExponent = 15
+		}
+		if p.BlockSize == 0 {
+			p.BlockSize = 8
+		}
+		if p.Parallelization == 0 {
+			p.Parallelization = 1
+		}
+	}
+
+	return p
+}
+
+// Validate checks the algorithm and the minimum costs.
+func (p PassphraseParameters) Validate() error {
+	switch p.Algorithm {
+	case Argon2id:
+		// RFC 9106 requires at least 8 KiB per lane
+		if p.MemoryKiB < 8*uint32(p.Parallelism) {
+			return fmt.Errorf("argon2id memory must be at least 8 KiB per lane")
+		}
+		if uint64(p.MemoryKiB)<<10 > maxMemory {
+			return fmt.Errorf("argon2id memory must not exceed %d MiB", maxMemory>>20)
+		}
+	case Scrypt:
+		if p.CostExponent < 1 || p.CostExponent > 30 {
+			return fmt.Errorf("scrypt cost exponent must be between 1 and 30")
+		}
+		// RFC 7914 requires p <= (2^32 - 1) * 32 / (128 * r)
+		if uint64(p.BlockSize)*uint64(p.Parallelization) >= 1<<30 {
+			return fmt.Errorf("scrypt block size and parallelization are too large")
+		}
+		// scrypt uses 128 * r * N bytes
+		if 128*uint64(p.BlockSize)<<p.CostExponent > maxMemory {
+			return fmt.Errorf("scrypt parameters need more than %d MiB of memory", maxMemory>>20)
+		}
+	default:
+		return fmt.Errorf("unsupported passphrase KDF %q", p.Algorithm)
+	}
+	return nil
+}
+
+// NewSalt returns a random salt of SaltSize bytes.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// DerivePassphraseKey derives a 256-bit key from a passphrase and salt with
+// Argon2id or scrypt.
+func DerivePassphraseKey(passphrase []byte, salt []byte, params PassphraseParameters, ctx context.Context) ([]byte, error) {
+	log := log.FromContext(ctx)
+
+	params = params.WithDefaults()
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase is empty")
+	}
+	if len(salt) < SaltSize {
+		return nil, fmt.Errorf("salt is %d bytes, must be at least %d bytes", len(salt), SaltSize)
+	}
+
+	// Wait for a free slot, the memory of concurrent derivations adds up
+	select {
+	case derivations <- struct{}{}:
+		defer func() { <-derivations }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	derivedKey, err := derivePassphraseKey(passphrase, salt, params)
+	if err != nil {
+		log.Error(err, "Failed to derive key using scrypt")
+		return nil, err
+	}
+	return derivedKey, nil
+}
+
+// derivePassphraseKey runs the KDF on validated parameters. It does not
+// check the passphrase and salt, whose published test vectors are shorter
+// than DerivePassphraseKey accepts.
+func derivePassphraseKey(passphrase []byte, salt []byte, params PassphraseParameters) ([]byte, error) {
+	if params.Algorithm == Argon2id {
+		return argon2.IDKey(passphrase, salt, params.Iterations, params.MemoryKiB, params.Parallelism, KeySize), nil
+	}
+	return scrypt.Key(passphrase, salt, 1<<params.CostExponent, params.BlockSize, params.Parallelization, KeySize)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package derivedkey
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestArgon2idVectors checks Argon2id version 0x13 against the test vectors
+// of the RFC 9106 reference implementation (src/test.c). The vectors of RFC
+// 9106 section 5.3 also use a secret key and associated data, which the
+// passphrase KDF does not take.
+func TestArgon2idVectors(t *testing.T) {
+	tests := []struct {
+		iterations  uint32
+		memoryKiB   uint32
+		parallelism uint8
+		tag         string
+	}{
+		{iterations: 2, memoryKiB: 1 << 16, parallelism: 1, tag: "09316115d5cf24ed5a15a31a3ba326e5cf32edc24702987c02b6566f61913cf7"},
+		{iterations: 2, memoryKiB: 1 << 8, parallelism: 1, tag: "9dfeb910e80bad0311fee20f9c0e2b12c17987b4cac90c2ef54d5b3021c68bfe"},
+		{iterations: 2, memoryKiB: 1 << 16, parallelism: 2, tag: "6f681ac1c3384a90119d2763a683f9ac79532d999abfab5644aa8aafd3d0d234"},
+	}
+
+	for _, tt := range tests {
+		params := PassphraseParameters{Algorithm: Argon2id, Iterations: tt.iterations, MemoryKiB: tt.memoryKiB, Parallelism: tt.parallelism}
+		if err := params.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		key, err := derivePassphraseKey([]byte("password"), []byte("somesalt"), params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(key, mustDecodeHex(t, tt.tag)) {
+			t.Errorf("t=%d m=%d p=%d: tag = %x, want %s", tt.iterations, tt.memoryKiB, tt.parallelism, key, tt.tag)
+		}
+	}
+}
+
+// TestScryptVectors checks the first three test vectors of RFC 7914 section
+// 12, truncated to KeySize. The fourth needs 1 GiB and is rejected by
+// TestPassphraseMemoryCap.
+func TestScryptVectors(t *testing.T) {
+	tests := []struct {
+		passphrase      string
+		salt            string
+		costExponent    int
+		blockSize       int
+		parallelization int
+		key             string
+	}{
+		{passphrase: "", salt: "", costExponent: 4, blockSize: 1, parallelization: 1, key: "77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442"},
+		{passphrase: "password", salt: "NaCl", costExponent: 10, blockSize: 8, parallelization: 16, key: "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b373162"},
+		{passphrase: "pleaseletmein", salt: "SodiumChloride", costExponent: 14, blockSize: 8, parallelization: 1, key: "7023bdcb3afd7348461c06cd81fd38ebfda8fbba904f8e3ea9b543f6545da1f2"},
+	}
+
+	for _, tt := range tests {
+		params := PassphraseParameters{Algorithm: Scrypt, CostExponent: tt.costExponent, BlockSize: tt.blockSize, Parallelization: tt.parallelization}
+		if err := params.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		key, err := derivePassphraseKey([]byte(tt.passphrase), []byte(tt.salt), params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(key, mustDecodeHex(t, tt.key)) {
+			t.Errorf("P=%q S=%q: key = %x, want %s", tt.passphrase, tt.salt, key, tt.key)
+		}
+	}
+}
+
+func TestPassphraseMemoryCap(t *testing.T) {
+	tests := []struct {
+		name   string
+		params PassphraseParameters
+		valid  bool
+	}{
+		{name: "argon2id default", params: PassphraseParameters{Algorithm: Argon2id}, valid: true},
+		{name: "argon2id at the cap", params: PassphraseParameters{Algorithm: Argon2id, MemoryKiB: 64 << 10}, valid: true},
+		{name: "argon2id over the cap", params: PassphraseParameters{Algorithm: Argon2id, MemoryKiB: 64<<10 + 1}},
+		{name: "argon2id RFC 9106 first option", params: PassphraseParameters{Algorithm: Argon2id, Iterations: 1, MemoryKiB: 2 << 20}},
+		{name: "scrypt default", params: PassphraseParameters{Algorithm: Scrypt}, valid: true},
+		{name: "scrypt at the cap", params: PassphraseParameters{Algorithm: Scrypt, CostExponent: 16, BlockSize: 8}, valid: true},
+		{name: "scrypt over the cap", params: PassphraseParameters{Algorithm: Scrypt, CostExponent: 17, BlockSize: 8}},
+		{name: "scrypt block size over the cap", params: PassphraseParameters{Algorithm: Scrypt, CostExponent: 15, BlockSize: 17}},
+		{name: "scrypt RFC 7914 fourth vector", params: PassphraseParameters{Algorithm: Scrypt, CostExponent: 20, BlockSize: 8}},
+	}
+
+	salt := bytes.Repeat([]byte{0x02}, SaltSize)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := tt.params.WithDefaults()
+			if err := params.Validate(); (err == nil) != tt.valid {
+				t.Fatalf("Validate = %v, want valid %t", err, tt.valid)
+			}
+			if tt.valid {
+				return
+			}
+			// The derivation is refused before any memory is allocated
+			_, err := DerivePassphraseKey([]byte("passphrase"), salt, tt.params, context.Background())
+			if err == nil || !strings.Contains(err.Error(), "64 MiB") {
+				t.Errorf("DerivePassphraseKey = %v, want the 64 MiB limit", err)
+			}
+		})
+	}
+}
+
+func TestDerivePassphraseKey(t *testing.T) {
+	salt := bytes.Repeat([]byte{0x02}, SaltSize)
+	params := PassphraseParameters{Algorithm: Scrypt, CostExponent: 10}
+
+	key, err := DerivePassphraseKey([]byte("passphrase"), salt, params, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := derivePassphraseKey([]byte("passphrase"), salt, params.WithDefaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != KeySize || !bytes.Equal(key, again) {
+		t.Errorf("key = %x, want %x", key, again)
+	}
+
+	if _, err := DerivePassphraseKey(nil, salt, params, context.Background()); err == nil {
+		t.Error("empty passphrase was accepted")
+	}
+	if _, err := DerivePassphraseKey([]byte("passphrase"), salt[:SaltSize-1], params, context.Background()); err == nil {
+		t.Error("short salt was accepted")
+	}
+	if _, err := DerivePassphraseKey([]byte("passphrase"), salt, PassphraseParameters{Algorithm: "PBKDF2"}, context.Background()); err == nil {
+		t.Error("unsupported KDF was accepted")
+	}
+}
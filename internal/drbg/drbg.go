@@ -0,0 +1,203 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package drbg implements the NIST SP 800-90A deterministic random bit
+// generators used to expand a seed into reproducible output.
+package drbg
+
+import (
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"io"
+)
+
+const (
+	// CTR is CTR_DRBG with AES-256 and no derivation function.
+	CTR = "CTR_DRBG"
+	// HMAC is HMAC_DRBG with SHA-256.
+	HMAC = "HMAC_DRBG"
+
+	// CTRSeedSize is the seedlen of CTR_DRBG with AES-256.
+	CTRSeedSize = 48
+
+	// maxRequestSize is max_number_of_bits_per_request (2^19 bits) in bytes.
+	maxRequestSize = 1 << 16
+)
+
+// New returns the named DRBG instantiated with the seed and personalization string.
+func New(name string, seed, personalization []byte) (io.Reader, error) {
+	switch name {
+	case CTR:
+		return NewCTR(seed, personalization)
+	case HMAC:
+		return NewHMAC(seed, personalization)
+	}
+	return nil, fmt.Errorf("unsupported DRBG %q", name)
+}
+
+// CTRDRBG is CTR_DRBG with AES-256 and no derivation function. It produces
+// the same output as the randombytes_init/randombytes RNG that liboqs and the
+// NIST PQC submissions use to generate known-answer tests.
+type CTRDRBG struct {
+	key [32]byte
+	v   [16]byte
+}
+
+// NewCTR instantiates CTR_DRBG with a 48-byte entropy input and an optional
+// personalization string of up to 48 bytes.
+func NewCTR(entropy, personalization []byte) (*CTRDRBG, error) {
+	if len(entropy) != CTRSeedSize {
+		return nil, fmt.Errorf("CTR_DRBG takes a %d-byte seed, got %d bytes", CTRSeedSize, len(entropy))
+	}
+	if len(personalization) > CTRSeedSize {
+		return nil, fmt.Errorf("CTR_DRBG personalization string is %d bytes, must be at most %d", len(personalization), CTRSeedSize)
+	}
+
+	seedMaterial := make([]byte, CTRSeedSize)
+	copy(seedMaterial, entropy)
+	for i, b := range personalization {
+		seedMaterial[i] ^= b
+	}
+
+	d := &CTRDRBG{}
+	if err := d.update(seedMaterial); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// update is CTR_DRBG_Update. A nil providedData is treated as all zeros.
+func (d *CTRDRBG) update(providedData []byte) error {
+	block, err := aes.NewCipher(d.key[:])
+	if err != nil {
+		return err
+	}
+
+	temp := make([]byte, CTRSeedSize)
+	for i := 0; i < CTRSeedSize; i += aes.BlockSize {
+		d.incrementV()
+		block.Encrypt(temp[i:i+aes.BlockSize], d.v[:])
+	}
+	for i, b := range providedData {
+		temp[i] ^= b
+	}
+
+	copy(d.key[:], temp[:32])
+	copy(d.v[:], temp[32:])
+	return nil
+}
+
+func (d *CTRDRBG) incrementV() {
+	for i := len(d.v) - 1; i >= 0; i-- {
+		d.v[i]++
+		if d.v[i] != 0 {
+			return
+		}
+	}
+}
+
+// generate returns up to maxRequestSize bytes and updates the internal state.
+func (d *CTRDRBG) generate(out []byte) error {
+	block, err := aes.NewCipher(d.key[:])
+	if err != nil {
+		return err
+	}
+
+	var buf [aes.BlockSize]byte
+	for i := 0; i < len(out); i += aes.BlockSize {
+		d.incrementV()
+		block.Encrypt(buf[:], d.v[:])
+		copy(out[i:], buf[:])
+	}
+	return d.update(nil)
+}
+
+// Read fills p with DRBG output, one generate request per 64 KiB.
+func (d *CTRDRBG) Read(p []byte) (int, error) {
+	for i := 0; i < len(p); i += maxRequestSize {
+		if err := d.generate(p[i:min(i+maxRequestSize, len(p))]); err != nil {
+			return i, err
+		}
+	}
+	return len(p), nil
+}
+
+// HMACDRBG is HMAC_DRBG with SHA-256.
+type HMACDRBG struct {
+	key []byte
+	v   []byte
+}
+
+// NewHMAC instantiates HMAC_DRBG with the seed as entropy input and nonce,
+// followed by an optional personalization string.
+func NewHMAC(seed, personalization []byte) (*HMACDRBG, error) {
+	// 256-bit strength needs 256 bits of entropy plus a 128-bit nonce
+	if len(seed) < 48 {
+		return nil, fmt.Errorf("HMAC_DRBG seed is %d bytes, must be at least 48 bytes", len(seed))
+	}
+
+	d := &HMACDRBG{
+		key: make([]byte, sha256.Size),
+		v:   make([]byte, sha256.Size),
+	}
+	for i := range d.v {
+		d.v[i] = 0x01
+	}
+
+	seedMaterial := make([]byte, 0, len(seed)+len(personalization))
+	seedMaterial = append(seedMaterial, seed...)
+	seedMaterial = append(seedMaterial, personalization...)
+	d.update(seedMaterial)
+	return d, nil
+}
+
+func (d *HMACDRBG) mac(data ...[]byte) []byte {
+	h := hmac.New(sha256.New, d.key)
+	for _, b := range data {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// update is HMAC_DRBG_Update.
+func (d *HMACDRBG) update(providedData []byte) {
+	d.key = d.mac(d.v, []byte{0x00}, providedData)
+	d.v = d.mac(d.v)
+	if len(providedData) == 0 {
+		return
+	}
+	d.key = d.mac(d.v, []byte{0x01}, providedData)
+	d.v = d.mac(d.v)
+}
+
+// generate returns up to maxRequestSize bytes and updates the internal state.
+func (d *HMACDRBG) generate(out []byte) {
+	for i := 0; i < len(out); i += sha256.Size {
+		d.v = d.mac(d.v)
+		copy(out[i:], d.v)
+	}
+	d.update(nil)
+}
+
+// Read fills p with DRBG output, one generate request per 64 KiB.
+func (d *HMACDRBG) Read(p []byte) (int, error) {
+	for i := 0; i < len(p); i += maxRequestSize {
+		d.generate(p[i:min(i+maxRequestSize, len(p))])
+	}
+	return len(p), nil
+}
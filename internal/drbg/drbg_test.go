@@ -0,0 +1,159 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drbg
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// TestCAVP runs NIST CAVP known-answer tests without prediction resistance
+// and reseeding. CAVP instantiates the DRBG, generates ReturnedBits twice and
+// compares the second output.
+func TestCAVP(t *testing.T) {
+	tests := []struct {
+		name            string
+		drbg            string
+		entropy         string
+		nonce           string
+		personalization string
+		returnedBits    string
+	}{
+		{
+			name:         "CTR_DRBG AES-256 no df COUNT 0",
+			drbg:         CTR,
+			entropy:      "df5d73faa468649edda33b5cca79b0b05600419ccb7a879ddfec9db32ee494e5531b51de16a30f769262474c73bec010",
+			returnedBits: "d1c07cd95af8a7f11012c84ce48bb8cb87189e99d40fccb1771c619bdf82ab2280b1dc2f2581f39164f7ac0c510494b3a43c41b7db17514c87b107ae793e01c5",
+		},
+		{
+			name:         "HMAC_DRBG SHA-256 COUNT 0",
+			drbg:         HMAC,
+			entropy:      "ca851911349384bffe89de1cbdc46e6831e44d34a4fb935ee285dd14b71a7488",
+			nonce:        "659ba96c601dc69fc902940805ec0ca8",
+			returnedBits: "e528e9abf2dece54d47c7e75e5fe302149f817ea9fb4bee6f4199697d04d5b89d54fbb978a15b5c443c9ec21036d2460b6f73ebad0dc2aba6e624abf07745bc107694bb7547bb0995f70de25d6b29e2d3011bb19d27676c07162c8b5ccde0668961df86803482cb37ed6d5c0bb8d50cf1f50d476aa0458bdaba806f48be9dcb8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// HMAC_DRBG takes the nonce appended to the entropy input
+			seed := append(mustHex(t, tt.entropy), mustHex(t, tt.nonce)...)
+			d, err := New(tt.drbg, seed, mustHex(t, tt.personalization))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := mustHex(t, tt.returnedBits)
+			got := make([]byte, len(want))
+			for range 2 {
+				if _, err := d.Read(got); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("ReturnedBits = %x, want %x", got, want)
+			}
+		})
+	}
+}
+
+// TestNISTKATSeeds checks that CTR_DRBG reproduces the randombytes RNG of the
+// NIST PQC known-answer tests: seeded with bytes 0x00..0x2f, successive
+// 48-byte reads are the per-count seeds of the PQCkemKAT and PQCsignKAT files.
+func TestNISTKATSeeds(t *testing.T) {
+	entropy := make([]byte, CTRSeedSize)
+	for i := range entropy {
+		entropy[i] = byte(i)
+	}
+	d, err := NewCTR(entropy, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for count, want := range []string{
+		"061550234d158c5ec95595fe04ef7a25767f2e24cc2bc479d09d86dc9abcfde7056a8c266f9ef97ed08541dbd2e1ffa1",
+		"d81c4d8d734fcbfbeade3d3f8a039faa2a2c9957e835ad55b22e75bf57bb556ac81adde6aeeb4a5a875c3bfcadfa958f",
+	} {
+		got := make([]byte, 48)
+		if _, err := d.Read(got); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, mustHex(t, want)) {
+			t.Errorf("count %d: seed = %x, want %s", count, got, want)
+		}
+	}
+}
+
+func TestPersonalization(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, CTRSeedSize)
+
+	for _, name := range []string{CTR, HMAC} {
+		t.Run(name, func(t *testing.T) {
+			read := func(personalization string) []byte {
+				d, err := New(name, seed, []byte(personalization))
+				if err != nil {
+					t.Fatal(err)
+				}
+				out := make([]byte, 64)
+				if _, err := d.Read(out); err != nil {
+					t.Fatal(err)
+				}
+				return out
+			}
+
+			if !bytes.Equal(read("tenant-a"), read("tenant-a")) {
+				t.Error("same seed and personalization produced different output")
+			}
+			if bytes.Equal(read("tenant-a"), read("tenant-b")) {
+				t.Error("different personalization strings produced the same output")
+			}
+		})
+	}
+}
+
+func TestInvalidSeeds(t *testing.T) {
+	tests := []struct {
+		name            string
+		drbg            string
+		seed            int
+		personalization int
+	}{
+		{name: "CTR_DRBG short seed", drbg: CTR, seed: 32},
+		{name: "CTR_DRBG long seed", drbg: CTR, seed: 64},
+		{name: "CTR_DRBG long personalization", drbg: CTR, seed: CTRSeedSize, personalization: 49},
+		{name: "HMAC_DRBG short seed", drbg: HMAC, seed: 47},
+		{name: "unknown DRBG", drbg: "Hash_DRBG", seed: CTRSeedSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.drbg, make([]byte, tt.seed), make([]byte, tt.personalization)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
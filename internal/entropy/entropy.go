@@ -0,0 +1,133 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package entropy fetches seed material from external entropy sources and
+// conditions it into a single seed.
+package entropy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// MinSourceBytes is the smallest contribution accepted from a single source.
+const MinSourceBytes = 16
+
+// Source returns entropy input from one origin.
+type Source interface {
+	Fetch(ctx context.Context) ([]byte, error)
+}
+
+// Bytes is a Source for material that has already been loaded, such as the
+// contents of a Secret.
+type Bytes []byte
+
+// Fetch returns the bytes unchanged.
+func (b Bytes) Fetch(ctx context.Context) ([]byte, error) {
+	return b, nil
+}
+
+// Policy restricts the sources that resources may use. It is configured on
+// the operator, not on individual resources, so that users cannot point the
+// controller at arbitrary hosts or files.
+type Policy struct {
+	// AllowedHosts lists the host names HTTPS sources may connect to.
+	// Entries starting with "*." match any subdomain.
+	AllowedHosts []string
+	// AllowedFiles lists the paths File sources may read.
+	AllowedFiles []string
+}
+
+// Validate rejects allowlist entries with a wildcard anywhere but in a
+// leading "*." label.
+func (p Policy) Validate() error {
+	for _, allowed := range p.AllowedHosts {
+		if strings.Contains(strings.TrimPrefix(allowed, "*."), "*") {
+			return fmt.Errorf("invalid entropy host %q: wildcards are only allowed as a leading \"*.\" label", allowed)
+		}
+	}
+	return nil
+}
+
+// allowsHost reports whether host is on the allowlist. A "*." entry matches
+// subdomains only, so "*.example.com" matches "a.example.com" but neither
+// "example.com" nor "evilexample.com".
+func (p Policy) allowsHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, allowed := range p.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok {
+			if strings.HasPrefix(suffix, ".") && strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+			continue
+		}
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsFile reports whether path is on the allowlist.
+func (p Policy) allowsFile(path string) bool {
+	path = filepath.Clean(path)
+	for _, allowed := range p.AllowedFiles {
+		if path == filepath.Clean(allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckHealth rejects contributions that are too short or constant.
+func CheckHealth(data []byte) error {
+	if len(data) < MinSourceBytes {
+		return fmt.Errorf("source returned %d bytes, need at least %d", len(data), MinSourceBytes)
+	}
+	for _, b := range data[1:] {
+		if b != data[0] {
+			return nil
+		}
+	}
+	return fmt.Errorf("source returned %d identical bytes", len(data))
+}
+
+// Condition mixes the inputs into an n-byte seed with Hash_df from
+// NIST SP 800-90A using SHA-256, one of the vetted conditioning functions of
+// SP 800-90B. Each input is length-prefixed so that inputs cannot be shifted
+// between sources.
+func Condition(inputs [][]byte, n int) []byte {
+	var material []byte
+	for _, input := range inputs {
+		material = binary.BigEndian.AppendUint32(material, uint32(len(input)))
+		material = append(material, input...)
+	}
+
+	out := make([]byte, 0, n+sha256.Size)
+	for counter := byte(1); len(out) < n; counter++ {
+		h := sha256.New()
+		h.Write([]byte{counter})
+		h.Write(binary.BigEndian.AppendUint32(nil, uint32(n*8)))
+		h.Write(material)
+		out = h.Sum(out)
+	}
+	return out[:n]
+}
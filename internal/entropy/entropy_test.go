@@ -0,0 +1,339 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entropy
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAllowsHost(t *testing.T) {
+	policy := Policy{AllowedHosts: []string{"qrng.anu.edu.au", "*.entropy.svc.cluster.local", "*example.com"}}
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{host: "qrng.anu.edu.au", want: true},
+		{host: "QRNG.ANU.EDU.AU", want: true},
+		{host: "evil.qrng.anu.edu.au", want: false},
+		{host: "a.entropy.svc.cluster.local", want: true},
+		{host: "a.b.entropy.svc.cluster.local", want: true},
+		{host: "entropy.svc.cluster.local", want: false},
+		{host: "evilentropy.svc.cluster.local", want: false},
+		// Only the "*." form is a wildcard
+		{host: "evilexample.com", want: false},
+		{host: "a.example.com", want: false},
+		{host: "example.com", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := policy.allowsHost(tt.host); got != tt.want {
+				t.Errorf("allowsHost(%q) = %t, want %t", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		hosts   []string
+		wantErr bool
+	}{
+		{hosts: []string{"qrng.example.com", "*.example.com"}},
+		{hosts: []string{"*example.com"}, wantErr: true},
+		{hosts: []string{"qrng.*.com"}, wantErr: true},
+		{hosts: []string{"*"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.hosts, ","), func(t *testing.T) {
+			err := Policy{AllowedHosts: tt.hosts}.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// newStub starts a TLS entropy stub and returns it with its CA bundle
+func newStub(t *testing.T, handler http.HandlerFunc) (*httptest.Server, []byte) {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	return server, caPEM
+}
+
+func TestHTTPSource(t *testing.T) {
+	seed := bytes.Repeat([]byte{0xa5, 0x5a}, 32)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hex", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, hex.EncodeToString(seed))
+	})
+	mux.HandleFunc("/qrng", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"success": true, "result": {"data": %q}}`, hex.EncodeToString(seed))
+	})
+	mux.HandleFunc("/qrng-array", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": [165, 90, 165, 90]}`)
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Repeat("00", maxResponseSize))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		fmt.Fprint(w, hex.EncodeToString(seed))
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://localhost"+strings.TrimPrefix(r.Host, "127.0.0.1")+"/hex", http.StatusFound)
+	})
+	mux.HandleFunc("/error", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	server, caPEM := newStub(t, mux.ServeHTTP)
+	policy := Policy{AllowedHosts: []string{"127.0.0.1"}}
+
+	tests := []struct {
+		name      string
+		path      string
+		jsonField string
+		timeout   time.Duration
+		want      []byte
+		wantErr   string
+	}{
+		{name: "hex body", path: "/hex", want: seed},
+		{name: "QRNG hex field", path: "/qrng", jsonField: "result.data", want: seed},
+		{name: "QRNG byte array", path: "/qrng-array", jsonField: "data", want: []byte{0xa5, 0x5a, 0xa5, 0x5a}},
+		{name: "QRNG missing field", path: "/qrng", jsonField: "result.missing", wantErr: "not found"},
+		{name: "response size limit", path: "/large", wantErr: "exceeds"},
+		{name: "timeout", path: "/slow", timeout: 100 * time.Millisecond, wantErr: "Timeout"},
+		{name: "redirect to host off the allowlist", path: "/redirect", wantErr: "allowlist"},
+		{name: "HTTP error", path: "/error", wantErr: "503"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = 5 * time.Second
+			}
+			source, err := policy.HTTPSource(server.URL+tt.path, caPEM, timeout, tt.jsonField)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := source.Fetch(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Fetch() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Fetch() = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPSourceTLS(t *testing.T) {
+	server, caPEM := newStub(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Repeat("ab", 32))
+	})
+	otherCAPEM := selfSignedPEM(t)
+	policy := Policy{AllowedHosts: []string{"127.0.0.1"}}
+
+	tests := []struct {
+		name    string
+		caPEM   []byte
+		wantErr bool
+	}{
+		{name: "trusted CA", caPEM: caPEM},
+		{name: "system roots", caPEM: nil, wantErr: true},
+		{name: "other CA", caPEM: otherCAPEM, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := policy.HTTPSource(server.URL, tt.caPEM, 5*time.Second, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = source.Fetch(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Fetch() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := policy.HTTPSource(server.URL, []byte("not a certificate"), time.Second, ""); err == nil {
+		t.Error("expected an error for a CA bundle without certificates")
+	}
+}
+
+// selfSignedPEM returns a CA certificate that did not issue the stub's certificate
+func selfSignedPEM(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "other CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestHTTPSourceURL(t *testing.T) {
+	policy := Policy{AllowedHosts: []string{"qrng.example.com"}}
+
+	tests := []struct {
+		url     string
+		wantErr string
+	}{
+		{url: "https://qrng.example.com/seed"},
+		{url: "http://qrng.example.com/seed", wantErr: "https"},
+		{url: "https://other.example.com/seed", wantErr: "allowlist"},
+		{url: "https://qrng.example.com.evil.test/seed", wantErr: "allowlist"},
+		{url: "://invalid", wantErr: "invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			_, err := policy.HTTPSource(tt.url, nil, time.Second, "")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("HTTPSource() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("HTTPSource() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	dir := t.TempDir()
+	allowed := filepath.Join(dir, "hwrng")
+	if err := os.WriteFile(allowed, bytes.Repeat([]byte{1, 2, 3, 4}, 16), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(dir, "other")
+	if err := os.WriteFile(other, bytes.Repeat([]byte{1, 2, 3, 4}, 16), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy := Policy{AllowedFiles: []string{allowed}}
+
+	tests := []struct {
+		name    string
+		path    string
+		n       int
+		wantErr bool
+	}{
+		{name: "allowed file", path: allowed, n: 48},
+		{name: "allowed file, unclean path", path: filepath.Join(dir, ".", "hwrng"), n: 48},
+		{name: "file off the allowlist", path: other, n: 48, wantErr: true},
+		{name: "traversal off the allowlist", path: filepath.Join(dir, "..", filepath.Base(dir), "other"), n: 48, wantErr: true},
+		{name: "short file", path: allowed, n: 128, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := policy.FileSource(tt.path, tt.n)
+			if err == nil {
+				var data []byte
+				data, err = source.Fetch(context.Background())
+				if err == nil && len(data) != tt.n {
+					t.Errorf("Fetch() returned %d bytes, want %d", len(data), tt.n)
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "healthy", data: bytes.Repeat([]byte{1, 2}, MinSourceBytes)},
+		{name: "too short", data: []byte{1, 2, 3}, wantErr: true},
+		{name: "constant", data: make([]byte, 64), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckHealth(tt.data); (err != nil) != tt.wantErr {
+				t.Errorf("CheckHealth() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCondition(t *testing.T) {
+	a := bytes.Repeat([]byte{1}, 32)
+	b := bytes.Repeat([]byte{2}, 32)
+
+	seed := Condition([][]byte{a, b}, 48)
+	if len(seed) != 48 {
+		t.Fatalf("Condition() returned %d bytes, want 48", len(seed))
+	}
+	if !bytes.Equal(seed, Condition([][]byte{a, b}, 48)) {
+		t.Error("Condition() is not deterministic")
+	}
+	// Length prefixes keep bytes from moving between inputs
+	if bytes.Equal(seed, Condition([][]byte{append(a, b[0]), b[1:]}, 48)) {
+		t.Error("Condition() ignores input boundaries")
+	}
+}
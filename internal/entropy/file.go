@@ -0,0 +1,52 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entropy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+type fileSource struct {
+	path string
+	n    int
+}
+
+// FileSource reads n bytes from a file or device such as /dev/hwrng. The path
+// must be on the policy's file allowlist.
+func (p Policy) FileSource(path string, n int) (Source, error) {
+	if !p.allowsFile(path) {
+		return nil, fmt.Errorf("file %q is not on the entropy file allowlist", path)
+	}
+	return &fileSource{path: path, n: n}, nil
+}
+
+func (s *fileSource) Fetch(ctx context.Context) ([]byte, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, s.n)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		return nil, fmt.Errorf("failed to read %d bytes from %s: %w", s.n, s.path, err)
+	}
+	return buf, nil
+}
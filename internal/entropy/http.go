@@ -0,0 +1,174 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entropy
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// maxResponseSize bounds the body read from an HTTPS source.
+	maxResponseSize = 64 << 10
+	// maxRedirects bounds the redirects followed by an HTTPS source.
+	maxRedirects = 3
+)
+
+type httpSource struct {
+	url       string
+	jsonField string
+	client    *http.Client
+}
+
+// HTTPSource fetches entropy from an HTTPS endpoint on the policy's host
+// allowlist. Without jsonField the body must be hex. With jsonField the body
+// is a JSON document, as returned by QRNG APIs, and the dotted field must hold
+// a hex or base64 string or an array of byte values.
+//
+// caPEM replaces the system roots when set. Redirects are only followed to
+// allowlisted HTTPS hosts.
+func (p Policy) HTTPSource(rawURL string, caPEM []byte, timeout time.Duration, jsonField string) (Source, error) {
+	if err := p.checkURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(caPEM) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("CA bundle contains no PEM certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return p.checkURL(req.URL.String())
+		},
+	}
+
+	return &httpSource{url: rawURL, jsonField: jsonField, client: client}, nil
+}
+
+// checkURL requires an https URL whose host is on the allowlist.
+func (p Policy) checkURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid entropy source URL: %w", err)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("entropy source URL %q must use https", rawURL)
+	}
+	if !p.allowsHost(u.Hostname()) {
+		return fmt.Errorf("host %q is not on the entropy host allowlist", u.Hostname())
+	}
+	return nil
+}
+
+func (s *httpSource) Fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("entropy source returned %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxResponseSize {
+		return nil, fmt.Errorf("entropy source response exceeds %d bytes", maxResponseSize)
+	}
+
+	if s.jsonField == "" {
+		return hex.DecodeString(strings.TrimSpace(string(body)))
+	}
+	return decodeJSONField(body, s.jsonField)
+}
+
+// decodeJSONField extracts the bytes stored under a dotted field path.
+func decodeJSONField(body []byte, field string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid JSON response: %w", err)
+	}
+
+	for _, key := range strings.Split(field, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("field %q not found in response", field)
+		}
+		if value, ok = object[key]; !ok {
+			return nil, fmt.Errorf("field %q not found in response", field)
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		if data, err := hex.DecodeString(v); err == nil {
+			return data, nil
+		}
+		return base64.StdEncoding.DecodeString(v)
+	case []any:
+		data := make([]byte, 0, len(v))
+		for _, item := range v {
+			n, ok := item.(json.Number)
+			if !ok {
+				return nil, fmt.Errorf("field %q must contain numbers", field)
+			}
+			b, err := n.Int64()
+			if err != nil || b < 0 || b > 255 {
+				return nil, fmt.Errorf("field %q must contain byte values", field)
+			}
+			data = append(data, byte(b))
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("field %q must be a string or an array of bytes", field)
+}
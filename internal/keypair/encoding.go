@@ -0,0 +1,160 @@
+package keypair
+
+import (
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/QubeSec/QubeSec/internal/certificate"
+)
+
+// Formats of the PEM keys in key pair Secrets
+const (
+	// FormatPKCS8 stores a SubjectPublicKeyInfo "PUBLIC KEY" and a PKCS#8
+	// "PRIVATE KEY" with the IETF OID of the algorithm
+	FormatPKCS8 = "PKCS8"
+	// FormatLegacy stores the raw keys in "<algorithm> PUBLIC KEY" and
+	// "<algorithm> SECRET KEY" blocks
+	FormatLegacy = "Legacy"
+)
+
+var (
+	mu            sync.RWMutex
+	defaultFormat = FormatPKCS8
+)
+
+// SetDefaultFormat sets the operator-wide format of new keys.
+func SetDefaultFormat(format string) error {
+	if format != FormatPKCS8 && format != FormatLegacy {
+		return fmt.Errorf("unsupported key format %q, use %s or %s", format, FormatPKCS8, FormatLegacy)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	defaultFormat = format
+	return nil
+}
+
+// DefaultFormat returns the operator-wide format of new keys.
+func DefaultFormat() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultFormat
+}
+
+// HasStandardEncoding reports whether the algorithm has an IETF OID, so its
+// keys can be stored in the PKCS8 format.
+func HasStandardEncoding(algorithm string) bool {
+	return certificate.Supported(algorithm) || certificate.SupportedKEM(algorithm)
+}
+
+// ResolveFormat returns the format of new keys of the algorithm: the
+// requested format, or the operator-wide default. Algorithms without an IETF
+// OID fall back to the legacy format unless PKCS8 is requested.
+func ResolveFormat(format string, algorithm string) (string, error) {
+	if format == "" {
+		format = DefaultFormat()
+		if !HasStandardEncoding(algorithm) {
+			format = FormatLegacy
+		}
+	}
+	if format == FormatPKCS8 && !HasStandardEncoding(algorithm) {
+		return "", fmt.Errorf("%s has no IETF OID and can only be stored in the %s format", algorithm, FormatLegacy)
+	}
+	return format, nil
+}
+
+// EncodeKeyPair returns the PEM encoded public and private keys in format.
+func EncodeKeyPair(algorithm string, publicKey []byte, privateKey []byte, format string) (string, string, error) {
+	if format == FormatLegacy {
+		return generatePEMBlock(publicKey, privateKey, algorithm)
+	}
+
+	spki, err := certificate.MarshalPublicKey(certificate.PublicKey{Algorithm: algorithm, Bytes: publicKey})
+	if err != nil {
+		return "", "", err
+	}
+	pkcs8, err := certificate.MarshalPrivateKey(algorithm, privateKey)
+	if err != nil {
+		return "", "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: spki})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})), nil
+}
+
+// Format returns the format of a PEM key, or an empty string if data holds
+// no PEM block.
+func Format(data []byte) string {
+	block, _ := pem.Decode(data)
+	switch {
+	case block == nil:
+		return ""
+	case block.Type == "PUBLIC KEY" || block.Type == "PRIVATE KEY":
+		return FormatPKCS8
+	default:
+		return FormatLegacy
+	}
+}
+
+// DecodePublicKey returns the raw public key of a PEM key in either format.
+// A PKCS8 key must be a key of the algorithm.
+func DecodePublicKey(algorithm string, data []byte) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+	if block.Type != "PUBLIC KEY" {
+		if !strings.HasSuffix(block.Type, " PUBLIC KEY") {
+			return nil, fmt.Errorf("PEM block %q is not a public key", block.Type)
+		}
+		return block.Bytes, nil
+	}
+
+	key, err := certificate.ParsePublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if key.Algorithm != algorithm {
+		return nil, fmt.Errorf("public key is a %s key, expected %s", key.Algorithm, algorithm)
+	}
+	return key.Bytes, nil
+}
+
+// DecodePrivateKey returns the raw private key of a PEM key in either format.
+// A PKCS8 key must be a key of the algorithm.
+func DecodePrivateKey(algorithm string, data []byte) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+	if block.Type != "PRIVATE KEY" {
+		if !strings.HasSuffix(block.Type, " SECRET KEY") {
+			return nil, fmt.Errorf("PEM block %q is not a private key", block.Type)
+		}
+		return block.Bytes, nil
+	}
+
+	keyAlgorithm, key, err := certificate.ParsePrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if keyAlgorithm != algorithm {
+		return nil, fmt.Errorf("private key is a %s key, expected %s", keyAlgorithm, algorithm)
+	}
+	return key, nil
+}
+
+// Reencode returns the PEM encoded public and private keys of a key pair in
+// format, reading them in either format.
+func Reencode(algorithm string, publicKeyPEM []byte, privateKeyPEM []byte, format string) (string, string, error) {
+	publicKey, err := DecodePublicKey(algorithm, publicKeyPEM)
+	if err != nil {
+		return "", "", err
+	}
+	privateKey, err := DecodePrivateKey(algorithm, privateKeyPEM)
+	if err != nil {
+		return "", "", err
+	}
+	return EncodeKeyPair(algorithm, publicKey, privateKey, format)
+}
@@ -0,0 +1,234 @@
+package keypair
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
+)
+
+// ietfOIDs are the NIST OIDs the IETF X.509 profiles of ML-KEM and ML-DSA use
+// for keys in SubjectPublicKeyInfo and PKCS#8
+var ietfOIDs = map[string]asn1.ObjectIdentifier{
+	"ML-KEM-512":  {2, 16, 840, 1, 101, 3, 4, 4, 1},
+	"ML-KEM-768":  {2, 16, 840, 1, 101, 3, 4, 4, 2},
+	"ML-KEM-1024": {2, 16, 840, 1, 101, 3, 4, 4, 3},
+	"ML-DSA-44":   {2, 16, 840, 1, 101, 3, 4, 3, 17},
+	"ML-DSA-65":   {2, 16, 840, 1, 101, 3, 4, 3, 18},
+	"ML-DSA-87":   {2, 16, 840, 1, 101, 3, 4, 3, 19},
+}
+
+// generateRawKeyPair returns a raw key pair from the Go provider
+func generateRawKeyPair(t *testing.T, algorithm string) ([]byte, []byte) {
+	t.Helper()
+	ctx := context.Background()
+
+	var publicKey, privateKey []byte
+	if strings.HasPrefix(algorithm, "ML-KEM-") {
+		provider, err := cryptoprovider.ForKEM(cryptoprovider.Go, algorithm, ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if publicKey, privateKey, err = provider.GenerateKEMKeyPair(algorithm, nil); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		provider, err := cryptoprovider.ForSignature(cryptoprovider.Go, algorithm, ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if publicKey, privateKey, err = provider.GenerateSignatureKeyPair(algorithm, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return publicKey, privateKey
+}
+
+// pemOID returns the algorithm OID of a PEM SubjectPublicKeyInfo or PKCS#8
+// private key
+func pemOID(t *testing.T, data string) asn1.ObjectIdentifier {
+	t.Helper()
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		t.Fatal("no PEM block")
+	}
+
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if block.Type == "PUBLIC KEY" {
+		if _, err := asn1.Unmarshal(block.Bytes, &spki); err != nil {
+			t.Fatal(err)
+		}
+		return spki.Algorithm.Algorithm
+	}
+
+	var pkcs8 struct {
+		Version    int
+		Algorithm  pkix.AlgorithmIdentifier
+		PrivateKey []byte
+	}
+	if _, err := asn1.Unmarshal(block.Bytes, &pkcs8); err != nil {
+		t.Fatal(err)
+	}
+	return pkcs8.Algorithm.Algorithm
+}
+
+func TestEncodeKeyPairRoundTrip(t *testing.T) {
+	for algorithm, oid := range ietfOIDs {
+		t.Run(algorithm, func(t *testing.T) {
+			publicKey, privateKey := generateRawKeyPair(t, algorithm)
+
+			for _, format := range []string{FormatPKCS8, FormatLegacy} {
+				publicKeyPEM, privateKeyPEM, err := EncodeKeyPair(algorithm, publicKey, privateKey, format)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := Format([]byte(publicKeyPEM)); got != format {
+					t.Errorf("%s: Format(public key) = %q", format, got)
+				}
+				if got := Format([]byte(privateKeyPEM)); got != format {
+					t.Errorf("%s: Format(private key) = %q", format, got)
+				}
+
+				decodedPublicKey, err := DecodePublicKey(algorithm, []byte(publicKeyPEM))
+				if err != nil {
+					t.Fatalf("%s: %v", format, err)
+				}
+				if !bytes.Equal(decodedPublicKey, publicKey) {
+					t.Errorf("%s: public key changed", format)
+				}
+				decodedPrivateKey, err := DecodePrivateKey(algorithm, []byte(privateKeyPEM))
+				if err != nil {
+					t.Fatalf("%s: %v", format, err)
+				}
+				if !bytes.Equal(decodedPrivateKey, privateKey) {
+					t.Errorf("%s: private key changed", format)
+				}
+
+				if format == FormatLegacy {
+					if !strings.HasPrefix(publicKeyPEM, "-----BEGIN "+algorithm+" PUBLIC KEY-----") ||
+						!strings.HasPrefix(privateKeyPEM, "-----BEGIN "+algorithm+" SECRET KEY-----") {
+						t.Errorf("legacy blocks:\n%s%s", publicKeyPEM[:40], privateKeyPEM[:40])
+					}
+					continue
+				}
+
+				// The PKCS8 format carries the IETF OID in both keys
+				if got := pemOID(t, publicKeyPEM); !got.Equal(oid) {
+					t.Errorf("public key OID = %v, want %v", got, oid)
+				}
+				if got := pemOID(t, privateKeyPEM); !got.Equal(oid) {
+					t.Errorf("private key OID = %v, want %v", got, oid)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeWrongAlgorithm(t *testing.T) {
+	publicKey, privateKey := generateRawKeyPair(t, "ML-DSA-44")
+	publicKeyPEM, privateKeyPEM, err := EncodeKeyPair("ML-DSA-44", publicKey, privateKey, FormatPKCS8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := DecodePublicKey("ML-DSA-65", []byte(publicKeyPEM)); err == nil {
+		t.Error("ML-DSA-44 public key decoded as ML-DSA-65")
+	}
+	if _, err := DecodePrivateKey("ML-DSA-65", []byte(privateKeyPEM)); err == nil {
+		t.Error("ML-DSA-44 private key decoded as ML-DSA-65")
+	}
+	if _, err := DecodePublicKey("ML-DSA-44", []byte(privateKeyPEM)); err == nil {
+		t.Error("private key decoded as a public key")
+	}
+	if _, err := DecodePrivateKey("ML-DSA-44", []byte(publicKeyPEM)); err == nil {
+		t.Error("public key decoded as a private key")
+	}
+	if _, err := DecodePublicKey("ML-DSA-44", []byte("not PEM")); err == nil {
+		t.Error("garbage decoded as a public key")
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	tests := []struct {
+		format    string
+		algorithm string
+		want      string
+		wantErr   bool
+	}{
+		{format: "", algorithm: "ML-KEM-768", want: FormatPKCS8},
+		{format: "", algorithm: "Kyber768", want: FormatLegacy},
+		{format: FormatLegacy, algorithm: "ML-DSA-65", want: FormatLegacy},
+		{format: FormatPKCS8, algorithm: "ML-DSA-65", want: FormatPKCS8},
+		{format: FormatPKCS8, algorithm: "Falcon-512", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ResolveFormat(tt.format, tt.algorithm)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ResolveFormat(%q, %q) = %q, %v, want %q", tt.format, tt.algorithm, got, err, tt.want)
+		}
+	}
+
+	if err := SetDefaultFormat("DER"); err == nil {
+		t.Error("unsupported default format was accepted")
+	}
+}
+
+// TestReencodeLegacySecret migrates the keys of a Secret written before the
+// PKCS8 format and back
+func TestReencodeLegacySecret(t *testing.T) {
+	for _, algorithm := range []string{"ML-KEM-768", "ML-DSA-65"} {
+		t.Run(algorithm, func(t *testing.T) {
+			publicKey, privateKey := generateRawKeyPair(t, algorithm)
+			legacyPublicKey, legacyPrivateKey, err := generatePEMBlock(publicKey, privateKey, algorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := map[string][]byte{
+				"public-key":  []byte(legacyPublicKey),
+				"private-key": []byte(legacyPrivateKey),
+			}
+
+			publicKeyPEM, privateKeyPEM, err := Reencode(algorithm, data["public-key"], data["private-key"], FormatPKCS8)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if Format([]byte(publicKeyPEM)) != FormatPKCS8 || Format([]byte(privateKeyPEM)) != FormatPKCS8 {
+				t.Fatalf("keys were not re-encoded:\n%s", publicKeyPEM)
+			}
+			if got := pemOID(t, publicKeyPEM); !got.Equal(ietfOIDs[algorithm]) {
+				t.Errorf("public key OID = %v, want %v", got, ietfOIDs[algorithm])
+			}
+
+			// The keys themselves do not change
+			decodedPublicKey, err := DecodePublicKey(algorithm, []byte(publicKeyPEM))
+			if err != nil {
+				t.Fatal(err)
+			}
+			decodedPrivateKey, err := DecodePrivateKey(algorithm, []byte(privateKeyPEM))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decodedPublicKey, publicKey) || !bytes.Equal(decodedPrivateKey, privateKey) {
+				t.Error("keys changed during the migration")
+			}
+
+			// And back to the legacy format
+			publicKeyPEM, privateKeyPEM, err = Reencode(algorithm, []byte(publicKeyPEM), []byte(privateKeyPEM), FormatLegacy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if publicKeyPEM != legacyPublicKey || privateKeyPEM != legacyPrivateKey {
+				t.Error("legacy keys differ after a round trip")
+			}
+		})
+	}
+}
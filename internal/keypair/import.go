@@ -0,0 +1,189 @@
+package keypair
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/QubeSec/QubeSec/internal/certificate"
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
+)
+
+// consistencyMessage is signed with an imported private key to check that it
+// matches the public key
+var consistencyMessage = []byte("QubeSec key pair consistency check")
+
+// ImportKEMKeyPair checks an existing KEM key pair with the selected crypto
+// provider and returns the public and private keys PEM encoded in format.
+// Each key may be PEM in either format, DER SPKI/PKCS#8 or raw.
+func ImportKEMKeyPair(provider string, algorithm string, publicKeyData []byte, privateKeyData []byte, format string, ctx context.Context) (string, string, error) {
+	log := log.FromContext(ctx)
+
+	// Resolve crypto provider
+	cryptoProvider, err := cryptoprovider.ForKEM(provider, algorithm, ctx)
+	if err != nil {
+		log.Error(err, "Failed to resolve crypto provider")
+		return "", "", err
+	}
+
+	publicKey, privateKey, err := decodeImportedKeyPair(algorithm, publicKeyData, privateKeyData)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Check the key sizes against a key pair generated by the provider
+	referencePublicKey, referencePrivateKey, err := cryptoProvider.GenerateKEMKeyPair(algorithm, nil)
+	if err != nil {
+		log.Error(err, "Failed to generate reference key pair", "provider", cryptoProvider.Name())
+		return "", "", err
+	}
+	if err := checkKeySizes(algorithm, publicKey, privateKey, referencePublicKey, referencePrivateKey); err != nil {
+		return "", "", err
+	}
+
+	// Check that the private key decapsulates what the public key encapsulates
+	ciphertext, sharedSecret, err := cryptoProvider.Encapsulate(algorithm, publicKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encapsulate with imported public key: %w", err)
+	}
+	decapsulated, err := cryptoProvider.Decapsulate(algorithm, privateKey, ciphertext)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decapsulate with imported private key: %w", err)
+	}
+	if !bytes.Equal(sharedSecret, decapsulated) {
+		return "", "", fmt.Errorf("imported %s private key does not match the public key", algorithm)
+	}
+
+	return EncodeKeyPair(algorithm, publicKey, privateKey, format)
+}
+
+// ImportSIGKeyPair checks an existing signature key pair with the selected
+// crypto provider and returns the public and private keys PEM encoded in
+// format. Each key may be PEM in either format, DER SPKI/PKCS#8 or raw.
+func ImportSIGKeyPair(provider string, algorithm string, publicKeyData []byte, privateKeyData []byte, format string, ctx context.Context) (string, string, error) {
+	log := log.FromContext(ctx)
+
+	// Resolve crypto provider
+	cryptoProvider, err := cryptoprovider.ForSignature(provider, algorithm, ctx)
+	if err != nil {
+		log.Error(err, "Failed to resolve crypto provider")
+		return "", "", err
+	}
+
+	publicKey, privateKey, err := decodeImportedKeyPair(algorithm, publicKeyData, privateKeyData)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Check the key sizes against a key pair generated by the provider
+	referencePublicKey, referencePrivateKey, err := cryptoProvider.GenerateSignatureKeyPair(algorithm, nil)
+	if err != nil {
+		log.Error(err, "Failed to generate reference key pair", "provider", cryptoProvider.Name())
+		return "", "", err
+	}
+	if err := checkKeySizes(algorithm, publicKey, privateKey, referencePublicKey, referencePrivateKey); err != nil {
+		return "", "", err
+	}
+
+	// Check that the public key verifies what the private key signs
+	signature, err := cryptoProvider.Sign(algorithm, privateKey, consistencyMessage)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to sign with imported private key: %w", err)
+	}
+	valid, err := cryptoProvider.Verify(algorithm, publicKey, consistencyMessage, signature)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to verify with imported public key: %w", err)
+	}
+	if !valid {
+		return "", "", fmt.Errorf("imported %s private key does not match the public key", algorithm)
+	}
+
+	return EncodeKeyPair(algorithm, publicKey, privateKey, format)
+}
+
+// decodeImportedKeyPair returns the raw keys of an imported key pair
+func decodeImportedKeyPair(algorithm string, publicKeyData []byte, privateKeyData []byte) ([]byte, []byte, error) {
+	if len(publicKeyData) == 0 || len(privateKeyData) == 0 {
+		return nil, nil, fmt.Errorf("imported key pair needs both a public and a private key")
+	}
+
+	var publicKey, privateKey []byte
+	var err error
+	switch {
+	case Format(publicKeyData) != "":
+		publicKey, err = DecodePublicKey(algorithm, publicKeyData)
+	default:
+		publicKey, err = decodeDERPublicKey(algorithm, publicKeyData)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode imported public key: %w", err)
+	}
+
+	switch {
+	case certificate.IsEncryptedPrivateKeyPEM(privateKeyData):
+		return nil, nil, fmt.Errorf("imported private key is encrypted")
+	case Format(privateKeyData) != "":
+		privateKey, err = DecodePrivateKey(algorithm, privateKeyData)
+	default:
+		privateKey, err = decodeDERPrivateKey(algorithm, privateKeyData)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode imported private key: %w", err)
+	}
+
+	return publicKey, privateKey, nil
+}
+
+// decodeDERPublicKey returns the raw public key of a DER SubjectPublicKeyInfo,
+// or the data itself if it is no SubjectPublicKeyInfo
+func decodeDERPublicKey(algorithm string, data []byte) ([]byte, error) {
+	key, err := certificate.ParsePublicKey(data)
+	if err != nil {
+		return data, nil
+	}
+	if key.Algorithm != algorithm {
+		return nil, fmt.Errorf("public key is a %s key, expected %s", key.Algorithm, algorithm)
+	}
+	return key.Bytes, nil
+}
+
+// decodeDERPrivateKey returns the raw private key of a DER PKCS#8 private key,
+// or the data itself if it is no PKCS#8 private key
+func decodeDERPrivateKey(algorithm string, data []byte) ([]byte, error) {
+	keyAlgorithm, key, err := certificate.ParsePrivateKey(data)
+	if err != nil {
+		return data, nil
+	}
+	if keyAlgorithm != algorithm {
+		return nil, fmt.Errorf("private key is a %s key, expected %s", keyAlgorithm, algorithm)
+	}
+	return key, nil
+}
+
+// checkKeySizes checks that imported keys have the sizes of the reference keys
+// of the algorithm. ML-KEM private keys may be in the seed or expanded form.
+func checkKeySizes(algorithm string, publicKey []byte, privateKey []byte, referencePublicKey []byte, referencePrivateKey []byte) error {
+	if len(publicKey) != len(referencePublicKey) {
+		return fmt.Errorf("%s public key must be %d bytes, got %d", algorithm, len(referencePublicKey), len(publicKey))
+	}
+
+	sizes := cryptoprovider.MLKEMPrivateKeySizes(algorithm)
+	if sizes == nil {
+		sizes = []int{len(referencePrivateKey)}
+	}
+	if !slices.Contains(sizes, len(privateKey)) {
+		return fmt.Errorf("%s private key must be %s bytes, got %d", algorithm, formatSizes(sizes), len(privateKey))
+	}
+	return nil
+}
+
+// formatSizes returns the accepted key sizes for an error message
+func formatSizes(sizes []int) string {
+	if len(sizes) == 1 {
+		return fmt.Sprint(sizes[0])
+	}
+	return fmt.Sprintf("%d or %d", sizes[0], sizes[1])
+}
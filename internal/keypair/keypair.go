@@ -4,76 +4,94 @@ import (
 	"bytes"
 	"context"
 	"encoding/pem"
+	"io"
 
-	"github.com/open-quantum-safe/liboqs-go/oqs"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
 )
 
-func GenerateKEMKeyPair(algorithm string, ctx context.Context) (string, string, error) {
+// GenerateKEMKeyPair generates a KEM key pair with the selected crypto provider
+// and RNG provider and returns the public and private keys PEM encoded in format.
+func GenerateKEMKeyPair(provider string, rngProvider string, algorithm string, format string, ctx context.Context) (string, string, error) {
 	log := log.FromContext(ctx)
 
-	quantumKeys := oqs.KeyEncapsulation{}
-	defer quantumKeys.Clean()
+	// Resolve crypto provider
+	cryptoProvider, err := cryptoprovider.ForKEM(provider, algorithm, ctx)
+	if err != nil {
+		log.Error(err, "Failed to resolve crypto provider")
+		return "", "", err
+	}
 
-	// Initialize liboqs-go
-	err := quantumKeys.Init(algorithm, nil)
+	// Resolve random source, nil selects the provider's system RNG
+	rand, err := newRandom(rngProvider)
 	if err != nil {
-		log.Error(err, "Failed to initialize liboqs-go")
+		log.Error(err, "Failed to resolve RNG provider")
 		return "", "", err
 	}
 
 	// Generate key pair
-	quantumPublicKey, err := quantumKeys.GenerateKeyPair()
+	quantumPublicKey, quantumPrivateKey, err := cryptoProvider.GenerateKEMKeyPair(algorithm, rand)
 	if err != nil {
-		log.Error(err, "Failed to generate key pair")
+		log.Error(err, "Failed to generate key pair", "provider", cryptoProvider.Name())
 		return "", "", err
 	}
 
-	// Export private key
-	quantumPrivateKey := quantumKeys.ExportSecretKey()
-
-	publicKeyPEM, privateKeyPEM, err := generatePEMBlock(quantumPublicKey, quantumPrivateKey, algorithm, ctx)
+	publicKeyPEM, privateKeyPEM, err := EncodeKeyPair(algorithm, quantumPublicKey, quantumPrivateKey, format)
 	if err != nil {
+		log.Error(err, "Failed to encode key pair")
 		return "", "", err
 	}
 
 	return publicKeyPEM, privateKeyPEM, nil
 }
 
-func GenerateSIGKeyPair(algorithm string, ctx context.Context) (string, string, error) {
+// GenerateSIGKeyPair generates a signature key pair with the selected crypto
+// provider and RNG provider and returns the public and private keys PEM encoded
+// in format.
+func GenerateSIGKeyPair(provider string, rngProvider string, algorithm string, format string, ctx context.Context) (string, string, error) {
 	log := log.FromContext(ctx)
 
-	quantumKeys := oqs.Signature{}
-	defer quantumKeys.Clean()
+	// Resolve crypto provider
+	cryptoProvider, err := cryptoprovider.ForSignature(provider, algorithm, ctx)
+	if err != nil {
+		log.Error(err, "Failed to resolve crypto provider")
+		return "", "", err
+	}
 
-	// Initialize liboqs-go
-	err := quantumKeys.Init(algorithm, nil)
+	// Resolve random source, nil selects the provider's system RNG
+	rand, err := newRandom(rngProvider)
 	if err != nil {
-		log.Error(err, "Failed to initialize liboqs-go")
+		log.Error(err, "Failed to resolve RNG provider")
 		return "", "", err
 	}
 
 	// Generate key pair
-	quantumPublicKey, err := quantumKeys.GenerateKeyPair()
+	quantumPublicKey, quantumPrivateKey, err := cryptoProvider.GenerateSignatureKeyPair(algorithm, rand)
 	if err != nil {
-		log.Error(err, "Failed to generate key pair")
+		log.Error(err, "Failed to generate key pair", "provider", cryptoProvider.Name())
 		return "", "", err
 	}
 
-	// Export private key
-	quantumPrivateKey := quantumKeys.ExportSecretKey()
-
-	publicKeyPEM, privateKeyPEM, err := generatePEMBlock(quantumPublicKey, quantumPrivateKey, algorithm, ctx)
+	publicKeyPEM, privateKeyPEM, err := EncodeKeyPair(algorithm, quantumPublicKey, quantumPrivateKey, format)
 	if err != nil {
+		log.Error(err, "Failed to encode key pair")
 		return "", "", err
 	}
 
 	return publicKeyPEM, privateKeyPEM, nil
 }
 
-func generatePEMBlock(publicKey []byte, privateKey []byte, algorithm string, ctx context.Context) (string, string, error) {
-	log := log.FromContext(ctx)
+// newRandom returns the reader for an explicitly requested RNG provider.
+func newRandom(rngProvider string) (io.Reader, error) {
+	if rngProvider == "" {
+		return nil, nil
+	}
+	return cryptoprovider.NewRandom("", rngProvider)
+}
 
+// generatePEMBlock encodes raw keys in the legacy format
+func generatePEMBlock(publicKey []byte, privateKey []byte, algorithm string) (string, string, error) {
 	// Generate PEM block
 	publicKeyBlock := &pem.Block{
 		Type:  algorithm + " PUBLIC KEY",
@@ -84,7 +102,6 @@ func generatePEMBlock(publicKey []byte, privateKey []byte, algorithm string, ctx
 	var publicKeyRow bytes.Buffer
 	err := pem.Encode(&publicKeyRow, publicKeyBlock)
 	if err != nil {
-		log.Error(err, "Failed to encode public key")
 		return "", "", err
 	}
 
@@ -98,7 +115,6 @@ func generatePEMBlock(publicKey []byte, privateKey []byte, algorithm string, ctx
 	var privateKeyRow bytes.Buffer
 	err = pem.Encode(&privateKeyRow, privateKeyBlock)
 	if err != nil {
-		log.Error(err, "Failed to encode private key")
 		return "", "", err
 	}
 
@@ -0,0 +1,218 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package keywrap implements the AES key wrap algorithms of RFC 3394 (AES-KW)
+// and RFC 5649 (AES-KWP, key wrap with padding), also specified as KW and KWP
+// in NIST SP 800-38F.
+package keywrap
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// KW is AES key wrap without padding (RFC 3394)
+	KW = "AES-KW"
+	// KWP is AES key wrap with padding (RFC 5649)
+	KWP = "AES-KWP"
+
+	// semiblock is the 64-bit unit the algorithms operate on
+	semiblock = 8
+)
+
+var (
+	// defaultIV is the RFC 3394 initial value
+	defaultIV = [semiblock]byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+	// kwpICV is the RFC 5649 alternative initial value prefix
+	kwpICV = [4]byte{0xa6, 0x59, 0x59, 0xa6}
+
+	// ErrIntegrity is returned when the wrapped key fails the integrity
+	// check, usually because it was wrapped under a different KEK.
+	ErrIntegrity = errors.New("wrapped key integrity check failed")
+)
+
+// Wrap wraps key under kek with the named algorithm.
+func Wrap(algorithm string, kek, key []byte) ([]byte, error) {
+	switch algorithm {
+	case KW:
+		return WrapKW(kek, key)
+	case KWP:
+		return WrapKWP(kek, key)
+	}
+	return nil, fmt.Errorf("unsupported key wrap algorithm %q", algorithm)
+}
+
+// Unwrap unwraps wrapped under kek with the named algorithm.
+func Unwrap(algorithm string, kek, wrapped []byte) ([]byte, error) {
+	switch algorithm {
+	case KW:
+		return UnwrapKW(kek, wrapped)
+	case KWP:
+		return UnwrapKWP(kek, wrapped)
+	}
+	return nil, fmt.Errorf("unsupported key wrap algorithm %q", algorithm)
+}
+
+// WrapKW wraps key with RFC 3394. The key must be a multiple of 8 bytes and
+// at least 16 bytes long.
+func WrapKW(kek, key []byte) ([]byte, error) {
+	if len(key) < 2*semiblock || len(key)%semiblock != 0 {
+		return nil, fmt.Errorf("AES-KW needs a key of at least 16 bytes in multiples of 8, got %d bytes", len(key))
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(block, defaultIV, key), nil
+}
+
+// UnwrapKW unwraps an RFC 3394 wrapped key.
+func UnwrapKW(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 3*semiblock || len(wrapped)%semiblock != 0 {
+		return nil, fmt.Errorf("AES-KW wrapped keys are at least 24 bytes in multiples of 8, got %d bytes", len(wrapped))
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	iv, key := unwrap(block, wrapped)
+	if subtle.ConstantTimeCompare(iv[:], defaultIV[:]) != 1 {
+		return nil, ErrIntegrity
+	}
+	return key, nil
+}
+
+// WrapKWP wraps a key of any non-zero length with RFC 5649.
+func WrapKWP(kek, key []byte) ([]byte, error) {
+	if len(key) == 0 || uint64(len(key)) > 1<<32-1 {
+		return nil, fmt.Errorf("AES-KWP needs a key of 1 to 2^32-1 bytes, got %d bytes", len(key))
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	// The alternative initial value carries the key length
+	var aiv [semiblock]byte
+	copy(aiv[:4], kwpICV[:])
+	binary.BigEndian.PutUint32(aiv[4:], uint32(len(key)))
+
+	padded := make([]byte, (len(key)+semiblock-1)/semiblock*semiblock)
+	copy(padded, key)
+
+	// A single semiblock is encrypted as one AES block
+	if len(padded) == semiblock {
+		wrapped := make([]byte, 2*semiblock)
+		copy(wrapped, aiv[:])
+		copy(wrapped[semiblock:], padded)
+		block.Encrypt(wrapped, wrapped)
+		return wrapped, nil
+	}
+
+	return wrap(block, aiv, padded), nil
+}
+
+// UnwrapKWP unwraps an RFC 5649 wrapped key.
+func UnwrapKWP(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 2*semiblock || len(wrapped)%semiblock != 0 {
+		return nil, fmt.Errorf("AES-KWP wrapped keys are at least 16 bytes in multiples of 8, got %d bytes", len(wrapped))
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	var aiv [semiblock]byte
+	var padded []byte
+	if len(wrapped) == 2*semiblock {
+		plain := make([]byte, 2*semiblock)
+		block.Decrypt(plain, wrapped)
+		copy(aiv[:], plain[:semiblock])
+		padded = plain[semiblock:]
+	} else {
+		aiv, padded = unwrap(block, wrapped)
+	}
+
+	// Check the prefix, the length and the zero padding
+	n := int(binary.BigEndian.Uint32(aiv[4:]))
+	if subtle.ConstantTimeCompare(aiv[:4], kwpICV[:]) != 1 || n <= len(padded)-semiblock || n > len(padded) {
+		return nil, ErrIntegrity
+	}
+	var pad byte
+	for _, b := range padded[n:] {
+		pad |= b
+	}
+	if pad != 0 {
+		return nil, ErrIntegrity
+	}
+
+	return padded[:n], nil
+}
+
+// wrap is the RFC 3394 wrapping process (W in SP 800-38F) over the
+// semiblocks of plaintext.
+func wrap(block cipher.Block, iv [semiblock]byte, plaintext []byte) []byte {
+	n := len(plaintext) / semiblock
+	r := make([]byte, len(plaintext))
+	copy(r, plaintext)
+
+	var b [aes.BlockSize]byte
+	a := iv
+	for j := 0; j < 6; j++ {
+		for i := 0; i < n; i++ {
+			copy(b[:semiblock], a[:])
+			copy(b[semiblock:], r[i*semiblock:(i+1)*semiblock])
+			block.Encrypt(b[:], b[:])
+
+			t := uint64(n*j + i + 1)
+			binary.BigEndian.PutUint64(a[:], binary.BigEndian.Uint64(b[:semiblock])^t)
+			copy(r[i*semiblock:], b[semiblock:])
+		}
+	}
+
+	return append(a[:], r...)
+}
+
+// unwrap is the RFC 3394 unwrapping process (W^-1 in SP 800-38F). It returns
+// the recovered initial value and plaintext for the caller to check.
+func unwrap(block cipher.Block, wrapped []byte) ([semiblock]byte, []byte) {
+	n := len(wrapped)/semiblock - 1
+	var a [semiblock]byte
+	copy(a[:], wrapped[:semiblock])
+	r := make([]byte, n*semiblock)
+	copy(r, wrapped[semiblock:])
+
+	var b [aes.BlockSize]byte
+	for j := 5; j >= 0; j-- {
+		for i := n - 1; i >= 0; i-- {
+			t := uint64(n*j + i + 1)
+			binary.BigEndian.PutUint64(b[:semiblock], binary.BigEndian.Uint64(a[:])^t)
+			copy(b[semiblock:], r[i*semiblock:(i+1)*semiblock])
+			block.Decrypt(b[:], b[:])
+
+			copy(a[:], b[:semiblock])
+			copy(r[i*semiblock:], b[semiblock:])
+		}
+	}
+
+	return a, r
+}
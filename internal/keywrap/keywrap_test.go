@@ -0,0 +1,295 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keywrap
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+const (
+	kek128 = "000102030405060708090A0B0C0D0E0F"
+	kek192 = "000102030405060708090A0B0C0D0E0F1011121314151617"
+	kek256 = "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// TestKWVectors checks the test vectors of RFC 3394 sections 4.1 to 4.6
+func TestKWVectors(t *testing.T) {
+	tests := []struct {
+		name    string
+		kek     string
+		key     string
+		wrapped string
+	}{
+		{
+			name:    "4.1 128-bit key with a 128-bit KEK",
+			kek:     kek128,
+			key:     "00112233445566778899AABBCCDDEEFF",
+			wrapped: "1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5",
+		},
+		{
+			name:    "4.2 128-bit key with a 192-bit KEK",
+			kek:     kek192,
+			key:     "00112233445566778899AABBCCDDEEFF",
+			wrapped: "96778B25AE6CA435F92B5B97C050AED2468AB8A17AD84E5D",
+		},
+		{
+			name:    "4.3 128-bit key with a 256-bit KEK",
+			kek:     kek256,
+			key:     "00112233445566778899AABBCCDDEEFF",
+			wrapped: "64E8C3F9CE0F5BA263E9777905818A2A93C8191E7D6E8AE7",
+		},
+		{
+			name:    "4.4 192-bit key with a 192-bit KEK",
+			kek:     kek192,
+			key:     "00112233445566778899AABBCCDDEEFF0001020304050607",
+			wrapped: "031D33264E15D33268F24EC260743EDCE1C6C7DDEE725A936BA814915C6762D2",
+		},
+		{
+			name:    "4.5 192-bit key with a 256-bit KEK",
+			kek:     kek256,
+			key:     "00112233445566778899AABBCCDDEEFF0001020304050607",
+			wrapped: "A8F9BC1612C68B3FF6E6F4FBE30E71E4769C8B80A32CB8958CD5D17D6B254DA1",
+		},
+		{
+			name:    "4.6 256-bit key with a 256-bit KEK",
+			kek:     kek256,
+			key:     "00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F",
+			wrapped: "28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kek, key, want := mustDecodeHex(t, tt.kek), mustDecodeHex(t, tt.key), mustDecodeHex(t, tt.wrapped)
+
+			wrapped, err := Wrap(KW, kek, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(wrapped, want) {
+				t.Errorf("Wrap = %X, want %X", wrapped, want)
+			}
+
+			unwrapped, err := Unwrap(KW, kek, want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(unwrapped, key) {
+				t.Errorf("Unwrap = %X, want %X", unwrapped, key)
+			}
+		})
+	}
+}
+
+// TestKWPVectors checks the test vectors of RFC 5649 section 6
+func TestKWPVectors(t *testing.T) {
+	const kek = "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8"
+
+	tests := []struct {
+		name    string
+		key     string
+		wrapped string
+	}{
+		{
+			name:    "20 octets",
+			key:     "c37b7e6492584340bed12207808941155068f738",
+			wrapped: "138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a",
+		},
+		{
+			name:    "7 octets",
+			key:     "466f7250617369",
+			wrapped: "afbeb0f07dfbf5419200f2ccb50bb24f",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kek, key, want := mustDecodeHex(t, kek), mustDecodeHex(t, tt.key), mustDecodeHex(t, tt.wrapped)
+
+			wrapped, err := Wrap(KWP, kek, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(wrapped, want) {
+				t.Errorf("Wrap = %x, want %x", wrapped, want)
+			}
+
+			unwrapped, err := Unwrap(KWP, kek, want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(unwrapped, key) {
+				t.Errorf("Unwrap = %x, want %x", unwrapped, key)
+			}
+		})
+	}
+}
+
+func TestKWPRoundTrip(t *testing.T) {
+	kek := mustDecodeHex(t, kek256)
+	for n := 1; n <= 72; n++ {
+		key := bytes.Repeat([]byte{byte(n)}, n)
+		wrapped, err := WrapKWP(kek, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (n+7)/8*8 + 8; len(wrapped) != want {
+			t.Errorf("%d bytes: wrapped key is %d bytes, want %d", n, len(wrapped), want)
+		}
+		unwrapped, err := UnwrapKWP(kek, wrapped)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		if !bytes.Equal(unwrapped, key) {
+			t.Errorf("%d bytes: Unwrap = %x, want %x", n, unwrapped, key)
+		}
+	}
+}
+
+// TestUnwrapIntegrity checks that tampered wrapped keys and wrong KEKs fail
+// the integrity check
+func TestUnwrapIntegrity(t *testing.T) {
+	kek := mustDecodeHex(t, kek256)
+	otherKEK := mustDecodeHex(t, kek128)
+
+	for _, algorithm := range []string{KW, KWP} {
+		for _, size := range []int{8, 16, 32} {
+			if algorithm == KW && size < 16 {
+				continue
+			}
+			key := bytes.Repeat([]byte{0x5a}, size)
+			wrapped, err := Wrap(algorithm, kek, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Changing any byte of the wrapped key changes the ICV
+			for i := range wrapped {
+				tampered := bytes.Clone(wrapped)
+				tampered[i] ^= 0x01
+				if _, err := Unwrap(algorithm, kek, tampered); !errors.Is(err, ErrIntegrity) {
+					t.Errorf("%s %d bytes, byte %d changed: err = %v, want %v", algorithm, size, i, err, ErrIntegrity)
+				}
+			}
+
+			for _, wrong := range [][]byte{otherKEK, bytes.Repeat([]byte{0xff}, 32)} {
+				if _, err := Unwrap(algorithm, wrong, wrapped); !errors.Is(err, ErrIntegrity) {
+					t.Errorf("%s %d bytes, wrong KEK: err = %v, want %v", algorithm, size, err, ErrIntegrity)
+				}
+			}
+		}
+	}
+
+	// A key wrapped with one algorithm does not unwrap with the other
+	key := bytes.Repeat([]byte{0x5a}, 16)
+	kw, _ := WrapKW(kek, key)
+	if _, err := UnwrapKWP(kek, kw); !errors.Is(err, ErrIntegrity) {
+		t.Errorf("AES-KW key unwrapped with AES-KWP: err = %v", err)
+	}
+	kwp, _ := WrapKWP(kek, key)
+	if _, err := UnwrapKW(kek, kwp); !errors.Is(err, ErrIntegrity) {
+		t.Errorf("AES-KWP key unwrapped with AES-KW: err = %v", err)
+	}
+}
+
+// TestUnwrapKWPPadding checks the length and padding checks of RFC 5649
+// section 3, on ciphertexts wrapped with a valid ICV prefix
+func TestUnwrapKWPPadding(t *testing.T) {
+	kek := mustDecodeHex(t, kek256)
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapWithLength := func(n uint32, padded []byte) []byte {
+		var aiv [semiblock]byte
+		copy(aiv[:4], kwpICV[:])
+		binary.BigEndian.PutUint32(aiv[4:], n)
+		return wrap(block, aiv, padded)
+	}
+
+	tests := []struct {
+		name    string
+		wrapped []byte
+	}{
+		{name: "non-zero padding", wrapped: wrapWithLength(13, append(bytes.Repeat([]byte{0x5a}, 13), 0, 0, 1))},
+		{name: "length below the last semiblock", wrapped: wrapWithLength(8, bytes.Repeat([]byte{0x5a}, 16))},
+		{name: "length beyond the plaintext", wrapped: wrapWithLength(17, bytes.Repeat([]byte{0x5a}, 16))},
+		{name: "zero length", wrapped: wrapWithLength(0, bytes.Repeat([]byte{0x00}, 16))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := UnwrapKWP(kek, tt.wrapped); !errors.Is(err, ErrIntegrity) {
+				t.Errorf("err = %v, want %v", err, ErrIntegrity)
+			}
+		})
+	}
+
+	// The same construction with valid padding unwraps
+	key, err := UnwrapKWP(kek, wrapWithLength(13, append(bytes.Repeat([]byte{0x5a}, 13), 0, 0, 0)))
+	if err != nil || len(key) != 13 {
+		t.Errorf("UnwrapKWP = %x, %v", key, err)
+	}
+}
+
+func TestInvalidLengths(t *testing.T) {
+	kek := mustDecodeHex(t, kek128)
+
+	tests := []struct {
+		name string
+		err  func() error
+	}{
+		{name: "KW key of one semiblock", err: func() error { _, err := WrapKW(kek, make([]byte, 8)); return err }},
+		{name: "KW key not a multiple of 8", err: func() error { _, err := WrapKW(kek, make([]byte, 20)); return err }},
+		{name: "KW empty key", err: func() error { _, err := WrapKW(kek, nil); return err }},
+		{name: "KW wrapped key too short", err: func() error { _, err := UnwrapKW(kek, make([]byte, 16)); return err }},
+		{name: "KW wrapped key not a multiple of 8", err: func() error { _, err := UnwrapKW(kek, make([]byte, 25)); return err }},
+		{name: "KWP empty key", err: func() error { _, err := WrapKWP(kek, nil); return err }},
+		{name: "KWP wrapped key too short", err: func() error { _, err := UnwrapKWP(kek, make([]byte, 8)); return err }},
+		{name: "KWP wrapped key not a multiple of 8", err: func() error { _, err := UnwrapKWP(kek, make([]byte, 17)); return err }},
+		{name: "KEK size", err: func() error { _, err := WrapKWP(make([]byte, 20), []byte("key")); return err }},
+		{name: "unwrap KEK size", err: func() error { _, err := UnwrapKW(make([]byte, 15), make([]byte, 24)); return err }},
+		{name: "wrap algorithm", err: func() error { _, err := Wrap("AES-GCM", kek, make([]byte, 16)); return err }},
+		{name: "unwrap algorithm", err: func() error { _, err := Unwrap("AES-GCM", kek, make([]byte, 24)); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err()
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if errors.Is(err, ErrIntegrity) {
+				t.Errorf("err = %v, want an input error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package randomness
+
+import (
+	"math"
+)
+
+// zAlpha is the 99% confidence bound used by the SP 800-90B estimators.
+const zAlpha = 2.576
+
+// MostCommonValueEstimate returns the SP 800-90B 6.3.1 min-entropy estimate
+// in bits per byte. Short outputs give low estimates because of the upper
+// confidence bound on the most common value.
+func MostCommonValueEstimate(data []byte) float64 {
+	if len(data) < 2 {
+		return 0
+	}
+
+	var counts [256]int
+	maxCount := 0
+	for _, b := range data {
+		counts[b]++
+		maxCount = max(maxCount, counts[b])
+	}
+
+	n := float64(len(data))
+	p := float64(maxCount) / n
+	pu := math.Min(1, p+zAlpha*math.Sqrt(p*(1-p)/(n-1)))
+	return -math.Log2(pu)
+}
+
+// CollisionEstimate returns an approximation of the SP 800-90B 6.3.2
+// min-entropy estimate in bits per bit, computed over the bit string of data.
+// The collision distances and their lower confidence bound follow 6.3.2, but
+// p is solved in closed form from the binary expectation E[t] = 2 + 2p(1-p)
+// instead of with the general formula of step 7, and an incomplete last
+// sequence is dropped.
+func CollisionEstimate(data []byte) float64 {
+	bits := len(data) * 8
+
+	// Collect the distances to the first collision in each sequence
+	var sum, sumSquares float64
+	v := 0
+	for i := 0; i+1 < bits; {
+		t := 3
+		if bit(data, i) == bit(data, i+1) {
+			t = 2
+		} else if i+2 >= bits {
+			break
+		}
+		i += t
+		sum += float64(t)
+		sumSquares += float64(t * t)
+		v++
+	}
+	if v < 2 {
+		return 0
+	}
+
+	mean := sum / float64(v)
+	stddev := math.Sqrt((sumSquares - float64(v)*mean*mean) / float64(v-1))
+	lower := mean - zAlpha*stddev/math.Sqrt(float64(v))
+
+	// For binary samples E[t] = 2 + 2p(1-p); solve for the most likely value p >= 1/2
+	if lower >= 2.5 {
+		return 1
+	}
+	if lower <= 2 {
+		return 0
+	}
+	p := (1 + math.Sqrt(1-2*(lower-2))) / 2
+	return -math.Log2(p)
+}
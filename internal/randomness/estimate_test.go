@@ -0,0 +1,87 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package randomness
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+// uniform returns n bytes from a fixed ChaCha8 stream
+func uniform(n int) []byte {
+	data := make([]byte, n)
+	_, _ = rand.NewChaCha8([32]byte{1}).Read(data)
+	return data
+}
+
+func TestEstimatesConstant(t *testing.T) {
+	for _, data := range [][]byte{make([]byte, 4096), {0xff, 0xff, 0xff, 0xff}} {
+		if got := MostCommonValueEstimate(data); got != 0 {
+			t.Errorf("%d constant bytes: most common value estimate = %f, want 0", len(data), got)
+		}
+		if got := CollisionEstimate(data); got != 0 {
+			t.Errorf("%d constant bytes: collision estimate = %f, want 0", len(data), got)
+		}
+	}
+}
+
+func TestEstimatesUniform(t *testing.T) {
+	data := uniform(1 << 16)
+
+	// The upper confidence bound keeps the estimate below 8 bits per byte
+	if got := MostCommonValueEstimate(data); got < 7.5 || got >= 8 {
+		t.Errorf("most common value estimate = %f bits per byte, want in [7.5, 8)", got)
+	}
+	// The confidence bound on the mean distance costs about 0.1 bit per bit
+	if got := CollisionEstimate(data); got < 0.85 || got > 1 {
+		t.Errorf("collision estimate = %f bits per bit, want in [0.85, 1]", got)
+	}
+
+	result := Assess(data, true)
+	if err := result.Err(); err != nil {
+		t.Error(err)
+	}
+	if got := result.MinEntropy(); got < 6.8 {
+		t.Errorf("min-entropy = %f bits per byte", got)
+	}
+}
+
+func TestEstimatesBiased(t *testing.T) {
+	// Every byte is 0x00 or 0x01, so at most one bit per byte is random
+	data := uniform(1 << 14)
+	for i := range data {
+		data[i] &= 1
+	}
+
+	if got := MostCommonValueEstimate(data); got > 1 {
+		t.Errorf("most common value estimate = %f bits per byte, want at most 1", got)
+	}
+	if got := 8 * CollisionEstimate(data); got >= 8*0.85 {
+		t.Errorf("collision estimate = %f bits per byte", got)
+	}
+	if result := Assess(data, true); result.Err() == nil {
+		t.Error("biased output passed")
+	}
+}
+
+func TestEstimatesShort(t *testing.T) {
+	if got := MostCommonValueEstimate([]byte{0x42}); got != 0 {
+		t.Errorf("most common value estimate of one byte = %f, want 0", got)
+	}
+	if got := CollisionEstimate(nil); got != 0 {
+		t.Errorf("collision estimate of no data = %f, want 0", got)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package randomness
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// claimedEntropy is the min-entropy per byte claimed for generator output.
+	claimedEntropy = 8
+	// falsePositiveExponent sets the health test false positive rate to
+	// 2^-40, the lowest rate SP 800-90B recommends.
+	falsePositiveExponent = 40
+	// aptWindow is the adaptive proportion window for non-binary samples.
+	aptWindow = 512
+)
+
+// repetitionCountCutoff is C = 1 + ceil(-log2(alpha) / H) from SP 800-90B 4.4.1.
+var repetitionCountCutoff = 1 + int(math.Ceil(float64(falsePositiveExponent)/claimedEntropy))
+
+// adaptiveProportionCutoff is C = 1 + CRITBINOM(W, 2^-H, 1 - alpha) from SP 800-90B 4.4.2.
+var adaptiveProportionCutoff = 1 + critBinom(aptWindow, math.Exp2(-claimedEntropy), math.Exp2(-falsePositiveExponent))
+
+// RepetitionCountTest fails if any byte value repeats cutoff times in a row.
+func RepetitionCountTest(data []byte) TestResult {
+	result := TestResult{Name: "RepetitionCount", Passed: true}
+
+	run := 1
+	for i := 1; i < len(data); i++ {
+		if data[i] != data[i-1] {
+			run = 1
+			continue
+		}
+		run++
+		if run >= repetitionCountCutoff {
+			result.Passed = false
+			result.Detail = fmt.Sprintf("byte 0x%02x repeated %d times at offset %d", data[i], run, i-run+1)
+			return result
+		}
+	}
+
+	result.Detail = fmt.Sprintf("cutoff %d", repetitionCountCutoff)
+	return result
+}
+
+// AdaptiveProportionTest fails if the first byte of any 512-byte window
+// occurs cutoff times or more within that window. Outputs shorter than one
+// window are skipped.
+func AdaptiveProportionTest(data []byte) TestResult {
+	result := TestResult{Name: "AdaptiveProportion", Passed: true}
+
+	if len(data) < aptWindow {
+		result.Skipped = true
+		result.Detail = fmt.Sprintf("needs at least %d bytes", aptWindow)
+		return result
+	}
+
+	for start := 0; start+aptWindow <= len(data); start += aptWindow {
+		window := data[start : start+aptWindow]
+		count := 0
+		for _, b := range window {
+			if b == window[0] {
+				count++
+			}
+		}
+		if count >= adaptiveProportionCutoff {
+			result.Passed = false
+			result.Detail = fmt.Sprintf("byte 0x%02x occurred %d times in the window at offset %d", window[0], count, start)
+			return result
+		}
+	}
+
+	result.Detail = fmt.Sprintf("cutoff %d", adaptiveProportionCutoff)
+	return result
+}
+
+// critBinom returns the smallest k for which P(X > k) <= alpha, where X is
+// binomial with n trials and success probability p.
+func critBinom(n int, p float64, alpha float64) int {
+	tail := 0.0
+	for k := n; k >= 0; k-- {
+		tail += binomialPMF(n, k, p)
+		if tail > alpha {
+			return k
+		}
+	}
+	return 0
+}
+
+// binomialPMF returns P(X = k) computed in log space.
+func binomialPMF(n, k int, p float64) float64 {
+	lgN, _ := math.Lgamma(float64(n + 1))
+	lgK, _ := math.Lgamma(float64(k + 1))
+	lgNK, _ := math.Lgamma(float64(n - k + 1))
+	return math.Exp(lgN - lgK - lgNK + float64(k)*math.Log(p) + float64(n-k)*math.Log1p(-p))
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package randomness
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+// TestCutoffs checks the cutoffs against SP 800-90B 4.4. Table 2 lists the
+// adaptive proportion cutoffs for alpha = 2^-20 and a 512-sample window.
+func TestCutoffs(t *testing.T) {
+	// C = 1 + ceil(40 / 8) for alpha = 2^-40 and H = 8
+	if repetitionCountCutoff != 6 {
+		t.Errorf("repetition count cutoff = %d, want 6", repetitionCountCutoff)
+	}
+
+	table2 := map[float64]int{0.5: 410, 1: 311, 2: 177, 4: 62, 8: 13}
+	for h, want := range table2 {
+		if got := 1 + critBinom(aptWindow, math.Exp2(-h), math.Exp2(-20)); got != want {
+			t.Errorf("H = %g: adaptive proportion cutoff = %d, want %d", h, got, want)
+		}
+	}
+
+	if adaptiveProportionCutoff != 19 {
+		t.Errorf("adaptive proportion cutoff = %d, want 19", adaptiveProportionCutoff)
+	}
+}
+
+func TestRepetitionCountTest(t *testing.T) {
+	distinct := func(n int) []byte {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		return data
+	}
+	withRun := func(length int) []byte {
+		return append(append(distinct(10), bytes.Repeat([]byte{0x42}, length)...), distinct(10)...)
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		wantPassed bool
+	}{
+		{name: "no repetitions", data: distinct(256), wantPassed: true},
+		{name: "run below the cutoff", data: withRun(repetitionCountCutoff - 1), wantPassed: true},
+		{name: "run at the cutoff", data: withRun(repetitionCountCutoff)},
+		{name: "constant", data: make([]byte, 64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := RepetitionCountTest(tt.data); result.Passed != tt.wantPassed {
+				t.Errorf("passed = %t, want %t: %s", result.Passed, tt.wantPassed, result.Detail)
+			}
+		})
+	}
+}
+
+func TestAdaptiveProportionTest(t *testing.T) {
+	// window returns a window whose first byte occurs count times, spread out
+	// so that the repetition count test is not involved
+	window := func(count int) []byte {
+		data := make([]byte, aptWindow)
+		for i := range data {
+			data[i] = byte(1 + i%255)
+		}
+		for i := 0; i < count; i++ {
+			data[i*(aptWindow/count)] = 0
+		}
+		return data
+	}
+
+	tests := []struct {
+		name        string
+		data        []byte
+		wantPassed  bool
+		wantSkipped bool
+	}{
+		{name: "below the cutoff", data: window(adaptiveProportionCutoff - 1), wantPassed: true},
+		{name: "at the cutoff", data: window(adaptiveProportionCutoff)},
+		{name: "at the cutoff in the second window", data: append(window(1), window(adaptiveProportionCutoff)...)},
+		{name: "shorter than a window", data: make([]byte, aptWindow-1), wantPassed: true, wantSkipped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := AdaptiveProportionTest(tt.data)
+			if result.Passed != tt.wantPassed || result.Skipped != tt.wantSkipped {
+				t.Errorf("passed = %t, skipped = %t, want %t, %t: %s", result.Passed, result.Skipped, tt.wantPassed, tt.wantSkipped, result.Detail)
+			}
+		})
+	}
+}
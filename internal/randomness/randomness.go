@@ -0,0 +1,92 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package randomness assesses generated random output with the NIST
+// SP 800-90B health tests and min-entropy estimators and an optional subset
+// of the SP 800-22 statistical tests.
+package randomness
+
+import (
+	"fmt"
+	"math"
+)
+
+// TestResult is the outcome of a single test.
+type TestResult struct {
+	Name    string
+	Passed  bool
+	Skipped bool
+	// PValue is set for SP 800-22 tests
+	PValue float64
+	Detail string
+}
+
+// Result is the assessment of one output.
+type Result struct {
+	// HealthTests are the SP 800-90B repetition count and adaptive proportion tests
+	HealthTests []TestResult
+	// MostCommonValue is the SP 800-90B 6.3.1 estimate in bits per byte
+	MostCommonValue float64
+	// Collision approximates the SP 800-90B 6.3.2 estimate over the bit string, scaled to bits per byte
+	Collision float64
+	// StatisticalTests are the SP 800-22 tests, if requested
+	StatisticalTests []TestResult
+}
+
+// MinEntropy is the lowest estimate in bits per byte.
+func (r Result) MinEntropy() float64 {
+	return math.Min(r.MostCommonValue, r.Collision)
+}
+
+// Err returns an error naming the first failed test, or nil.
+func (r Result) Err() error {
+	for _, tests := range [][]TestResult{r.HealthTests, r.StatisticalTests} {
+		for _, t := range tests {
+			if !t.Passed && !t.Skipped {
+				return fmt.Errorf("random output failed the %s test: %s", t.Name, t.Detail)
+			}
+		}
+	}
+	return nil
+}
+
+// Assess runs the health tests and estimators on data, and the SP 800-22
+// tests when statistical is true.
+func Assess(data []byte, statistical bool) Result {
+	result := Result{
+		HealthTests: []TestResult{
+			RepetitionCountTest(data),
+			AdaptiveProportionTest(data),
+		},
+		MostCommonValue: MostCommonValueEstimate(data),
+		Collision:       8 * CollisionEstimate(data),
+	}
+
+	if statistical {
+		result.StatisticalTests = []TestResult{
+			FrequencyTest(data),
+			RunsTest(data),
+			CumulativeSumsTest(data),
+		}
+	}
+
+	return result
+}
+
+// bit returns bit i of data, most significant bit first.
+func bit(data []byte, i int) int {
+	return int(data[i/8]>>(7-i%8)) & 1
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package randomness
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// significance is the SP 800-22 level below which a test fails. The
+	// lowest recommended level keeps false rejections of good output rare.
+	significance = 0.001
+	// minStatisticalBits is the smallest input SP 800-22 recommends.
+	minStatisticalBits = 100
+)
+
+// statisticalResult builds a TestResult from a p-value.
+func statisticalResult(name string, pValue float64) TestResult {
+	return TestResult{
+		Name:   name,
+		Passed: pValue >= significance,
+		PValue: pValue,
+		Detail: fmt.Sprintf("p-value %.6f, significance %.3f", pValue, significance),
+	}
+}
+
+// skippedResult marks a test as skipped for outputs that are too short.
+func skippedResult(name string) TestResult {
+	return TestResult{
+		Name:    name,
+		Skipped: true,
+		Detail:  fmt.Sprintf("needs at least %d bits", minStatisticalBits),
+	}
+}
+
+// FrequencyTest is the SP 800-22 2.1 frequency (monobit) test.
+func FrequencyTest(data []byte) TestResult {
+	return frequencyTest(data, len(data)*8)
+}
+
+// frequencyTest runs the frequency test on the first n bits of data.
+func frequencyTest(data []byte, n int) TestResult {
+	if n < minStatisticalBits {
+		return skippedResult("Frequency")
+	}
+
+	s := 0
+	for i := 0; i < n; i++ {
+		s += 2*bit(data, i) - 1
+	}
+
+	sObs := math.Abs(float64(s)) / math.Sqrt(float64(n))
+	return statisticalResult("Frequency", math.Erfc(sObs/math.Sqrt2))
+}
+
+// RunsTest is the SP 800-22 2.3 runs test.
+func RunsTest(data []byte) TestResult {
+	return runsTest(data, len(data)*8)
+}
+
+// runsTest runs the runs test on the first n bits of data.
+func runsTest(data []byte, n int) TestResult {
+	if n < minStatisticalBits {
+		return skippedResult("Runs")
+	}
+
+	ones := 0
+	runs := 1
+	for i := 0; i < n; i++ {
+		ones += bit(data, i)
+		if i > 0 && bit(data, i) != bit(data, i-1) {
+			runs++
+		}
+	}
+
+	// The frequency prerequisite must hold for the runs statistic to be meaningful
+	pi := float64(ones) / float64(n)
+	if math.Abs(pi-0.5) >= 2/math.Sqrt(float64(n)) {
+		return statisticalResult("Runs", 0)
+	}
+
+	num := math.Abs(float64(runs) - 2*float64(n)*pi*(1-pi))
+	den := 2 * math.Sqrt(2*float64(n)) * pi * (1 - pi)
+	return statisticalResult("Runs", math.Erfc(num/den))
+}
+
+// CumulativeSumsTest is the forward SP 800-22 2.13 cumulative sums test.
+func CumulativeSumsTest(data []byte) TestResult {
+	return cumulativeSumsTest(data, len(data)*8)
+}
+
+// cumulativeSumsTest runs the cumulative sums test on the first n bits of data.
+func cumulativeSumsTest(data []byte, n int) TestResult {
+	if n < minStatisticalBits {
+		return skippedResult("CumulativeSums")
+	}
+
+	s, z := 0, 0
+	for i := 0; i < n; i++ {
+		s += 2*bit(data, i) - 1
+		z = max(z, abs(s))
+	}
+
+	fn := float64(n)
+	fz := float64(z)
+	sqrtN := math.Sqrt(fn)
+
+	sum1 := 0.0
+	for k := int(math.Floor((-fn/fz + 1) / 4)); k <= int(math.Floor((fn/fz-1)/4)); k++ {
+		sum1 += normalCDF(float64(4*k+1)*fz/sqrtN) - normalCDF(float64(4*k-1)*fz/sqrtN)
+	}
+	sum2 := 0.0
+	for k := int(math.Floor((-fn/fz - 3) / 4)); k <= int(math.Floor((fn/fz-1)/4)); k++ {
+		sum2 += normalCDF(float64(4*k+3)*fz/sqrtN) - normalCDF(float64(4*k+1)*fz/sqrtN)
+	}
+
+	return statisticalResult("CumulativeSums", 1-sum1+sum2)
+}
+
+func normalCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package randomness
+
+import (
+	"math"
+	"testing"
+)
+
+// sp80022Epsilon is the 100-bit input of the SP 800-22 worked examples of the
+// frequency, runs and cumulative sums tests (sections 2.1.8, 2.3.8 and 2.13.8)
+const sp80022Epsilon = "1100100100001111110110101010001000100001011010001100001000110100110001001100011001100010100010111000"
+
+// parseBits packs a string of 0 and 1 into bytes, most significant bit first
+func parseBits(t *testing.T, s string) []byte {
+	t.Helper()
+	data := make([]byte, (len(s)+7)/8)
+	for i, c := range s {
+		switch c {
+		case '1':
+			data[i/8] |= 0x80 >> (i % 8)
+		case '0':
+		default:
+			t.Fatalf("invalid bit %q", c)
+		}
+	}
+	return data
+}
+
+func TestSP80022Examples(t *testing.T) {
+	data := parseBits(t, sp80022Epsilon)
+	n := len(sp80022Epsilon)
+
+	tests := []struct {
+		name       string
+		result     TestResult
+		wantPValue float64
+	}{
+		{name: "Frequency", result: frequencyTest(data, n), wantPValue: 0.109599},
+		{name: "Runs", result: runsTest(data, n), wantPValue: 0.500798},
+		{name: "CumulativeSums", result: cumulativeSumsTest(data, n), wantPValue: 0.219194},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.result.Name != tt.name {
+				t.Errorf("name = %q, want %q", tt.result.Name, tt.name)
+			}
+			if math.Abs(tt.result.PValue-tt.wantPValue) > 1e-6 {
+				t.Errorf("p-value = %.6f, want %.6f", tt.result.PValue, tt.wantPValue)
+			}
+			if !tt.result.Passed || tt.result.Skipped {
+				t.Errorf("passed = %t, skipped = %t", tt.result.Passed, tt.result.Skipped)
+			}
+		})
+	}
+}
+
+func TestSP80022Failures(t *testing.T) {
+	constant := make([]byte, 128)
+	alternating := make([]byte, 128)
+	for i := range alternating {
+		alternating[i] = 0xaa
+	}
+
+	tests := []struct {
+		name   string
+		result TestResult
+	}{
+		{name: "frequency of a constant", result: FrequencyTest(constant)},
+		{name: "runs of a constant", result: RunsTest(constant)},
+		{name: "runs of alternating bits", result: RunsTest(alternating)},
+		{name: "cumulative sums of a constant", result: CumulativeSumsTest(constant)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.result.Passed {
+				t.Errorf("passed with p-value %f", tt.result.PValue)
+			}
+		})
+	}
+}
+
+func TestSP80022Short(t *testing.T) {
+	short := make([]byte, 12)
+	for _, result := range []TestResult{FrequencyTest(short), RunsTest(short), CumulativeSumsTest(short)} {
+		if !result.Skipped {
+			t.Errorf("%s: %d bits were not skipped", result.Name, len(short)*8)
+		}
+	}
+}
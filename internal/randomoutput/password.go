@@ -0,0 +1,177 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package randomoutput
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+	"unicode"
+)
+
+const (
+	// DefaultPasswordLength is used when the policy does not set a length
+	DefaultPasswordLength = 24
+
+	lowercase = "abcdefghijklmnopqrstuvwxyz"
+	uppercase = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digits    = "0123456789"
+	// DefaultSymbols avoids quotes, backslashes and whitespace so passwords
+	// can be pasted into shells, connection strings and config files.
+	DefaultSymbols = "!#$%&()*+,-./:;<=>?@[]^_{|}~"
+)
+
+// PasswordPolicy describes the passwords to generate.
+type PasswordPolicy struct {
+	// Length of the password in characters
+	Length int
+	// Charset replaces the default alphabet of letters, digits and DefaultSymbols
+	Charset string
+	// Minimum number of characters from each class. Characters that are not
+	// letters or digits count as symbols.
+	MinLowercase int
+	MinUppercase int
+	MinDigits    int
+	MinSymbols   int
+}
+
+// alphabet returns the deduplicated characters passwords are drawn from.
+func (p PasswordPolicy) alphabet() []rune {
+	charset := p.Charset
+	if charset == "" {
+		charset = lowercase + uppercase + digits + DefaultSymbols
+	}
+
+	var alphabet []rune
+	for _, c := range charset {
+		if !slices.Contains(alphabet, c) {
+			alphabet = append(alphabet, c)
+		}
+	}
+	return alphabet
+}
+
+// length returns the password length, applying the default.
+func (p PasswordPolicy) length() int {
+	if p.Length == 0 {
+		return DefaultPasswordLength
+	}
+	return p.Length
+}
+
+// characterClass is a class of characters with a minimum count.
+type characterClass struct {
+	name     string
+	min      int
+	contains func(rune) bool
+}
+
+func (p PasswordPolicy) classes() []characterClass {
+	return []characterClass{
+		{"lowercase", p.MinLowercase, unicode.IsLower},
+		{"uppercase", p.MinUppercase, unicode.IsUpper},
+		{"digits", p.MinDigits, unicode.IsDigit},
+		{"symbols", p.MinSymbols, func(c rune) bool { return !unicode.IsLetter(c) && !unicode.IsDigit(c) }},
+	}
+}
+
+// Validate checks that passwords satisfying the policy exist.
+func (p PasswordPolicy) Validate() error {
+	alphabet := p.alphabet()
+	if len(alphabet) < 2 {
+		return fmt.Errorf("password charset must contain at least 2 distinct characters")
+	}
+	for _, c := range alphabet {
+		if unicode.IsSpace(c) || unicode.IsControl(c) {
+			return fmt.Errorf("password charset must not contain whitespace or control characters")
+		}
+	}
+
+	required := 0
+	for _, class := range p.classes() {
+		if class.min < 0 {
+			return fmt.Errorf("minimum %s must not be negative", class.name)
+		}
+		if class.min > 0 && !slices.ContainsFunc(alphabet, class.contains) {
+			return fmt.Errorf("password charset has no %s but %d are required", class.name, class.min)
+		}
+		required += class.min
+	}
+	if required > p.length() {
+		return fmt.Errorf("password length %d is shorter than the %d required characters", p.length(), required)
+	}
+
+	return nil
+}
+
+// NewPassword reads a password satisfying the policy from rand. Every
+// character is drawn uniformly with rejection sampling: the required
+// characters from their class, the rest from the whole alphabet. The result
+// is then shuffled so the required characters are not in fixed positions.
+func NewPassword(rand io.Reader, policy PasswordPolicy) ([]byte, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	alphabet := policy.alphabet()
+	password := make([]rune, 0, policy.length())
+
+	for _, class := range policy.classes() {
+		members := slices.DeleteFunc(slices.Clone(alphabet), func(c rune) bool { return !class.contains(c) })
+		for range class.min {
+			i, err := uniform(rand, len(members))
+			if err != nil {
+				return nil, err
+			}
+			password = append(password, members[i])
+		}
+	}
+	for len(password) < policy.length() {
+		i, err := uniform(rand, len(alphabet))
+		if err != nil {
+			return nil, err
+		}
+		password = append(password, alphabet[i])
+	}
+
+	// Fisher-Yates shuffle
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := uniform(rand, i+1)
+		if err != nil {
+			return nil, err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+
+	return []byte(string(password)), nil
+}
+
+// uniform returns an unbiased integer in [0, n) for n <= 65536.
+func uniform(rand io.Reader, n int) (int, error) {
+	// Reject values in the incomplete last block of n
+	limit := 65536 - 65536%n
+	var buf [2]byte
+	for {
+		if _, err := io.ReadFull(rand, buf[:]); err != nil {
+			return 0, err
+		}
+		if v := int(binary.BigEndian.Uint16(buf[:])); v < limit {
+			return v % n, nil
+		}
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package randomoutput
+
+import (
+	"bytes"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy PasswordPolicy
+		valid  bool
+	}{
+		{name: "default", policy: PasswordPolicy{}, valid: true},
+		{name: "every class", policy: PasswordPolicy{Length: 8, MinLowercase: 2, MinUppercase: 2, MinDigits: 2, MinSymbols: 2}, valid: true},
+		{name: "one character", policy: PasswordPolicy{Charset: "aaaa"}},
+		{name: "whitespace", policy: PasswordPolicy{Charset: "ab c"}},
+		{name: "control character", policy: PasswordPolicy{Charset: "ab\x00"}},
+		{name: "negative minimum", policy: PasswordPolicy{MinDigits: -1}},
+		{name: "missing class", policy: PasswordPolicy{Charset: "abcdef", MinDigits: 1}},
+		{name: "too short", policy: PasswordPolicy{Length: 8, MinLowercase: 3, MinUppercase: 3, MinDigits: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate = %v, want valid %t", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestNewPassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  PasswordPolicy
+		length  int
+		charset string
+	}{
+		{
+			name:    "default",
+			policy:  PasswordPolicy{},
+			length:  DefaultPasswordLength,
+			charset: lowercase + uppercase + digits + DefaultSymbols,
+		},
+		{
+			name:    "required classes fill the password",
+			policy:  PasswordPolicy{Length: 8, MinLowercase: 2, MinUppercase: 2, MinDigits: 2, MinSymbols: 2},
+			length:  8,
+			charset: lowercase + uppercase + digits + DefaultSymbols,
+		},
+		{
+			name:    "custom charset",
+			policy:  PasswordPolicy{Length: 32, Charset: "abcABC123-ß", MinUppercase: 4, MinSymbols: 1},
+			length:  32,
+			charset: "abcABC123-ß",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 50 {
+				password, err := NewPassword(rand.Reader, tt.policy)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n := utf8.RuneCount(password); n != tt.length {
+					t.Fatalf("password %q has %d characters, want %d", password, n, tt.length)
+				}
+
+				counts := map[string]int{}
+				for _, c := range string(password) {
+					if !strings.ContainsRune(tt.charset, c) {
+						t.Fatalf("password %q contains %q", password, c)
+					}
+					for _, class := range tt.policy.classes() {
+						if class.contains(c) {
+							counts[class.name]++
+						}
+					}
+				}
+				for _, class := range tt.policy.classes() {
+					if counts[class.name] < class.min {
+						t.Fatalf("password %q has %d %s, want at least %d", password, counts[class.name], class.name, class.min)
+					}
+				}
+			}
+		})
+	}
+
+	if _, err := NewPassword(rand.Reader, PasswordPolicy{Charset: "a"}); err == nil {
+		t.Error("NewPassword accepted an invalid policy")
+	}
+	if _, err := NewPassword(bytes.NewReader(nil), PasswordPolicy{}); err == nil {
+		t.Error("NewPassword succeeded without random bytes")
+	}
+}
+
+// TestNewPasswordDeduplicatesCharset checks that repeated characters do not
+// weight the alphabet
+func TestNewPasswordDeduplicatesCharset(t *testing.T) {
+	policy := PasswordPolicy{Charset: "aaaaaaab"}
+	if alphabet := policy.alphabet(); string(alphabet) != "ab" {
+		t.Errorf("alphabet = %q, want \"ab\"", string(alphabet))
+	}
+}
+
+func TestUniformRejectsBiasedValues(t *testing.T) {
+	// For n = 10 the last complete block ends at 65530: 65530..65535 are
+	// rejected, 65529 is accepted as 65529 % 10 = 9.
+	input := []byte{
+		0xff, 0xff, // 65535, rejected
+		0xff, 0xfa, // 65530, rejected
+		0xff, 0xf9, // 65529, accepted
+	}
+	got, err := uniform(bytes.NewReader(input), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 9 {
+		t.Errorf("uniform = %d, want 9", got)
+	}
+
+	// A power of two divides 65536 and nothing is rejected
+	got, err = uniform(bytes.NewReader([]byte{0xff, 0xff}), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 63 {
+		t.Errorf("uniform = %d, want 63", got)
+	}
+
+	// Only rejected values exhaust the reader
+	if _, err := uniform(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xfa}), 10); err == nil {
+		t.Error("uniform accepted a value in the incomplete block")
+	}
+}
+
+func TestUniformDistribution(t *testing.T) {
+	const n, draws = 7, 70000
+	var counts [n]int
+	for range draws {
+		i, err := uniform(rand.Reader, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[i]++
+	}
+
+	// Each count is binomial with mean 10000 and standard deviation ~93;
+	// allow six standard deviations
+	for i, count := range counts {
+		if count < 10000-560 || count > 10000+560 {
+			t.Errorf("value %d drawn %d times, want about 10000", i, count)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package randomoutput turns random bytes into the values stored by a
+// QuantumRandomNumber: encoded byte strings, UUIDs and passwords.
+package randomoutput
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+const (
+	// Raw stores the bytes as they are
+	Raw = "raw"
+	// Hex stores the bytes hex encoded
+	Hex = "hex"
+	// Base64 stores the bytes in standard, padded base64
+	Base64 = "base64"
+	// Base64URL stores the bytes in unpadded URL-safe base64
+	Base64URL = "base64url"
+	// UUID stores an RFC 9562 version 4 UUID
+	UUID = "uuid"
+	// Password stores a password generated from a PasswordPolicy
+	Password = "password"
+
+	// uuidSize is the number of bytes in a UUID
+	uuidSize = 16
+)
+
+// Generate reads one value in the given format from rand. size is the
+// number of random bytes for the byte formats and is ignored otherwise.
+func Generate(rand io.Reader, format string, size int, policy PasswordPolicy) ([]byte, error) {
+	switch format {
+	case "", Raw, Hex, Base64, Base64URL:
+		data := make([]byte, size)
+		if _, err := io.ReadFull(rand, data); err != nil {
+			return nil, err
+		}
+		return Encode(format, data)
+	case UUID:
+		return NewUUID(rand)
+	case Password:
+		return NewPassword(rand, policy)
+	}
+	return nil, fmt.Errorf("unsupported output format %q", format)
+}
+
+// Encode encodes data in one of the byte formats.
+func Encode(format string, data []byte) ([]byte, error) {
+	switch format {
+	case "", Raw:
+		return data, nil
+	case Hex:
+		return []byte(hex.EncodeToString(data)), nil
+	case Base64:
+		return []byte(base64.StdEncoding.EncodeToString(data)), nil
+	case Base64URL:
+		return []byte(base64.RawURLEncoding.EncodeToString(data)), nil
+	}
+	return nil, fmt.Errorf("format %q is not a byte encoding", format)
+}
+
+// NewUUID reads a version 4 UUID from rand in its canonical string form.
+func NewUUID(rand io.Reader) ([]byte, error) {
+	var u [uuidSize]byte
+	if _, err := io.ReadFull(rand, u[:]); err != nil {
+		return nil, err
+	}
+
+	// Set the version (4) and the RFC 9562 variant bits
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+
+	return fmt.Appendf(nil, "%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16]), nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package randomoutput
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0x00, 0x10}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "", want: "\xfb\xff\x00\x10"},
+		{format: Raw, want: "\xfb\xff\x00\x10"},
+		{format: Hex, want: "fbff0010"},
+		{format: Base64, want: "+/8AEA=="},
+		{format: Base64URL, want: "-_8AEA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := Encode(tt.format, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Encode = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, format := range []string{UUID, Password, "base32"} {
+		if _, err := Encode(format, data); err == nil {
+			t.Errorf("Encode(%q) succeeded", format)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	random := bytes.Repeat([]byte{0xab}, 64)
+
+	got, err := Generate(bytes.NewReader(random), Hex, 8, PasswordPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "abababababababab" {
+		t.Errorf("Generate = %q", got)
+	}
+
+	if _, err := Generate(bytes.NewReader(random[:4]), Raw, 8, PasswordPolicy{}); err == nil {
+		t.Error("Generate succeeded on a short read")
+	}
+	if _, err := Generate(bytes.NewReader(random), "base32", 8, PasswordPolicy{}); err == nil {
+		t.Error("Generate succeeded with an unsupported format")
+	}
+}
+
+func TestNewUUID(t *testing.T) {
+	canonical := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	// All-zero and all-one input must still get the version and variant bits
+	for _, fill := range []byte{0x00, 0xff} {
+		u, err := NewUUID(bytes.NewReader(bytes.Repeat([]byte{fill}, uuidSize)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !canonical.Match(u) {
+			t.Errorf("NewUUID(%#x...) = %s", fill, u)
+		}
+
+		raw, err := hex.DecodeString(string(bytes.ReplaceAll(u, []byte("-"), nil)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version := raw[6] >> 4; version != 4 {
+			t.Errorf("version = %d, want 4", version)
+		}
+		if variant := raw[8] >> 6; variant != 0b10 {
+			t.Errorf("variant = %02b, want 10", variant)
+		}
+	}
+
+	u, err := NewUUID(bytes.NewReader([]byte{
+		0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+		0xfe, 0xdc, 0xba, 0x98, 0x76, 0x54, 0x32, 0x10,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "01234567-89ab-4def-bedc-ba9876543210"; string(u) != want {
+		t.Errorf("NewUUID = %s, want %s", u, want)
+	}
+
+	for range 100 {
+		u, err := NewUUID(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !canonical.Match(u) {
+			t.Fatalf("NewUUID = %s", u)
+		}
+	}
+
+	if _, err := NewUUID(bytes.NewReader(make([]byte, uuidSize-1))); err == nil {
+		t.Error("NewUUID succeeded on a short read")
+	}
+}
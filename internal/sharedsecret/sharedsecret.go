@@ -18,37 +18,27 @@ package sharedsecret
 
 import (
 	"context"
-	"encoding/pem"
-	"fmt"
 
-	"github.com/open-quantum-safe/liboqs-go/oqs"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
+	"github.com/QubeSec/QubeSec/internal/keypair"
 )
 
-// DeriveSharedSecret uses KEM to encapsulate and derive a shared secret
-func DeriveSharedSecret(algorithm string, publicKeyPEM []byte, ctx context.Context) ([]byte, []byte, error) {
+// Encapsulate uses KEM to derive a shared secret for a raw public key and
+// returns the ciphertext and the shared secret
+func Encapsulate(provider string, algorithm string, publicKey []byte, ctx context.Context) ([]byte, []byte, error) {
 	log := log.FromContext(ctx)
 
-	// Decode PEM block
-	block, _ := pem.Decode(publicKeyPEM)
-	if block == nil {
-		return nil, nil, fmt.Errorf("failed to decode PEM block")
-	}
-
-	publicKey := block.Bytes
-
-	// Initialize KEM
-	quantumKEM := oqs.KeyEncapsulation{}
-	defer quantumKEM.Clean()
-
-	err := quantumKEM.Init(algorithm, nil)
+	// Resolve crypto provider
+	cryptoProvider, err := cryptoprovider.ForKEM(provider, algorithm, ctx)
 	if err != nil {
-		log.Error(err, "Failed to initialize KEM")
+		log.Error(err, "Failed to resolve crypto provider")
 		return nil, nil, err
 	}
 
 	// Encapsulate to derive shared secret
-	ciphertext, sharedSecret, err := quantumKEM.EncapSecret(publicKey)
+	ciphertext, sharedSecret, err := cryptoProvider.Encapsulate(algorithm, publicKey)
 	if err != nil {
 		log.Error(err, "Failed to encapsulate secret")
 		return nil, nil, err
@@ -58,29 +48,24 @@ func DeriveSharedSecret(algorithm string, publicKeyPEM []byte, ctx context.Conte
 }
 
 // DecapsulateSharedSecret uses KEM to decapsulate and recover a shared secret
-func DecapsulateSharedSecret(algorithm string, privateKeyPEM []byte, ciphertext []byte, ctx context.Context) ([]byte, error) {
+func DecapsulateSharedSecret(provider string, algorithm string, privateKeyPEM []byte, ciphertext []byte, ctx context.Context) ([]byte, error) {
 	log := log.FromContext(ctx)
 
-	// Decode PEM block
-	block, _ := pem.Decode(privateKeyPEM)
-	if block == nil {
-		return nil, fmt.Errorf("failed to decode PEM block")
+	// Decode the private key, stored as PKCS#8 or in the legacy format
+	privateKey, err := keypair.DecodePrivateKey(algorithm, privateKeyPEM)
+	if err != nil {
+		return nil, err
 	}
 
-	privateKey := block.Bytes
-
-	// Initialize KEM with private key for decapsulation
-	quantumKEM := oqs.KeyEncapsulation{}
-	defer quantumKEM.Clean()
-
-	err := quantumKEM.Init(algorithm, privateKey)
+	// Resolve crypto provider
+	cryptoProvider, err := cryptoprovider.ForKEM(provider, algorithm, ctx)
 	if err != nil {
-		log.Error(err, "Failed to initialize KEM")
+		log.Error(err, "Failed to resolve crypto provider")
 		return nil, err
 	}
 
 	// Decapsulate to recover shared secret
-	sharedSecret, err := quantumKEM.DecapSecret(ciphertext)
+	sharedSecret, err := cryptoProvider.Decapsulate(algorithm, privateKey, ciphertext)
 	if err != nil {
 		log.Error(err, "Failed to decapsulate secret")
 		return nil, err
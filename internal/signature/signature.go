@@ -5,37 +5,32 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
-	"encoding/pem"
-	"fmt"
 
-	"github.com/open-quantum-safe/liboqs-go/oqs"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/QubeSec/QubeSec/internal/cryptoprovider"
+	"github.com/QubeSec/QubeSec/internal/keypair"
 )
 
 // SignMessage signs a message using the provided private key and algorithm.
-func SignMessage(algorithm string, privateKeyPEM []byte, message []byte, ctx context.Context) ([]byte, error) {
+func SignMessage(provider string, algorithm string, privateKeyPEM []byte, message []byte, ctx context.Context) ([]byte, error) {
 	log := log.FromContext(ctx)
 
-	// Decode PEM block
-	block, _ := pem.Decode(privateKeyPEM)
-	if block == nil {
-		return nil, fmt.Errorf("failed to decode PEM block")
+	// Decode the private key, stored as PKCS#8 or in the legacy format
+	privateKey, err := keypair.DecodePrivateKey(algorithm, privateKeyPEM)
+	if err != nil {
+		return nil, err
 	}
 
-	privateKey := block.Bytes
-
-	// Initialize signature scheme
-	signer := oqs.Signature{}
-	defer signer.Clean()
-
-	err := signer.Init(algorithm, privateKey)
+	// Resolve crypto provider
+	cryptoProvider, err := cryptoprovider.ForSignature(provider, algorithm, ctx)
 	if err != nil {
-		log.Error(err, "Failed to initialize signature scheme")
+		log.Error(err, "Failed to resolve crypto provider")
 		return nil, err
 	}
 
 	// Sign the message
-	signature, err := signer.Sign(message)
+	signature, err := cryptoProvider.Sign(algorithm, privateKey, message)
 	if err != nil {
 		log.Error(err, "Failed to sign message")
 		return nil, err
@@ -45,29 +40,24 @@ func SignMessage(algorithm string, privateKeyPEM []byte, message []byte, ctx con
 }
 
 // VerifySignature verifies a message signature using the provided public key and algorithm.
-func VerifySignature(algorithm string, publicKeyPEM []byte, message []byte, signature []byte, ctx context.Context) (bool, error) {
+func VerifySignature(provider string, algorithm string, publicKeyPEM []byte, message []byte, signature []byte, ctx context.Context) (bool, error) {
 	log := log.FromContext(ctx)
 
-	// Decode PEM block
-	block, _ := pem.Decode(publicKeyPEM)
-	if block == nil {
-		return false, fmt.Errorf("failed to decode PEM block")
+	// Decode the public key, stored as SubjectPublicKeyInfo or in the legacy format
+	publicKey, err := keypair.DecodePublicKey(algorithm, publicKeyPEM)
+	if err != nil {
+		return false, err
 	}
 
-	publicKey := block.Bytes
-
-	// Initialize signature scheme
-	verifier := oqs.Signature{}
-	defer verifier.Clean()
-
-	err := verifier.Init(algorithm, nil)
+	// Resolve crypto provider
+	cryptoProvider, err := cryptoprovider.ForSignature(provider, algorithm, ctx)
 	if err != nil {
-		log.Error(err, "Failed to initialize signature scheme")
+		log.Error(err, "Failed to resolve crypto provider")
 		return false, err
 	}
 
 	// Verify the signature
-	valid, err := verifier.Verify(message, signature, publicKey)
+	valid, err := cryptoProvider.Verify(algorithm, publicKey, message, signature)
 	if err != nil {
 		log.Error(err, "Failed to verify signature")
 		return false, err